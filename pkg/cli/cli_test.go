@@ -1829,7 +1829,10 @@ writing ` + os.DevNull + `
   debug/schema/system/lease
   debug/schema/system/namespace
   debug/schema/system/rangelog
+  debug/schema/system/role_members
   debug/schema/system/settings
+  debug/schema/system/statement_hints
+  debug/schema/system/table_statistics
   debug/schema/system/ui
   debug/schema/system/users
   debug/schema/system/web_sessions
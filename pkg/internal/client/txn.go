@@ -622,6 +622,60 @@ func (txn *Txn) rollback(ctx context.Context) *roachpb.Error {
 	return txn.sendEndTxnReq(ctx, false /* commit */, nil)
 }
 
+// Savepoint identifies the writes performed by a transaction up to some
+// point. See CreateSavepoint.
+type Savepoint struct {
+	epoch uint32
+	seq   int32
+}
+
+// CreateSavepoint returns a savepoint which can later be passed to
+// RollbackToSavepoint to undo the writes performed after this call. It is
+// not safe to call while requests are executing concurrently.
+func (txn *Txn) CreateSavepoint() Savepoint {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	// Tell the writes from now on to preserve the values written before the
+	// savepoint which they overwrite.
+	if txn.mu.Proto.SavepointSeq < txn.mu.Proto.Sequence {
+		txn.mu.Proto.SavepointSeq = txn.mu.Proto.Sequence
+	}
+	return Savepoint{epoch: txn.mu.Proto.Epoch, seq: txn.mu.Proto.Sequence}
+}
+
+// RollbackToSavepoint undoes the writes performed by the transaction since
+// the savepoint was created: their sequence numbers are marked as ignored,
+// which makes the corresponding intents invisible to the transaction's
+// subsequent reads and prevents them from being committed. The transaction
+// remains usable. It is not safe to call while requests are executing
+// concurrently.
+func (txn *Txn) RollbackToSavepoint(ctx context.Context, sp Savepoint) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	if txn.mu.Proto.Status != roachpb.PENDING || txn.mu.finalized {
+		return errors.Errorf(
+			"attempting to roll back to savepoint in transaction with wrong status or finalized: %s %v",
+			txn.mu.Proto.Status, txn.mu.finalized)
+	}
+	if sp.epoch != txn.mu.Proto.Epoch {
+		return errors.Errorf("cannot roll back to savepoint created in epoch %d; transaction is at epoch %d",
+			sp.epoch, txn.mu.Proto.Epoch)
+	}
+	seq := txn.mu.Proto.Sequence
+	if seq <= sp.seq {
+		// Nothing was sent since the savepoint was created.
+		return nil
+	}
+	log.VEventf(ctx, 2, "rolling back to savepoint: ignoring sequence numbers [%d,%d]", sp.seq+1, seq)
+	// The slice may be shared with clones of the proto, so don't append to it
+	// in place.
+	prev := txn.mu.Proto.IgnoredSeqNums
+	ignored := make([]enginepb.IgnoredSeqNumRange, len(prev), len(prev)+1)
+	copy(ignored, prev)
+	txn.mu.Proto.IgnoredSeqNums = append(ignored, enginepb.IgnoredSeqNumRange{Start: sp.seq + 1, End: seq})
+	return nil
+}
+
 // AddCommitTrigger adds a closure to be executed on successful commit
 // of the transaction.
 func (txn *Txn) AddCommitTrigger(trigger func()) {
@@ -998,6 +1052,12 @@ func (txn *Txn) Send(
 		}
 		if retryErr, ok := pErr.GetDetail().(*roachpb.HandledRetryableTxnError); ok {
 			txn.updateStateOnRetryableErrLocked(ctx, *retryErr, requestTxnID)
+		} else if errTxn := pErr.GetTxn(); errTxn != nil && errTxn.ID == txn.mu.Proto.ID &&
+			errTxn.Epoch == txn.mu.Proto.Epoch && txn.mu.Proto.Sequence < errTxn.Sequence {
+			// Some of the writes in the failed batch may have been carried out.
+			// Make sure their sequence numbers aren't reused, so that they can
+			// be rolled back to a savepoint.
+			txn.mu.Proto.Sequence = errTxn.Sequence
 		}
 		if pErr.TransactionRestart != roachpb.TransactionRestart_NONE &&
 			!txn.acceptUnhandledRetryableErrors {
@@ -292,12 +292,25 @@ const (
 	UsersTableID      = 4
 	ZonesTableID      = 5
 	SettingsTableID   = 6
+	// StatementHintsTableID is part of the system config so that pinned plan
+	// hints are distributed to all nodes through gossip.
+	StatementHintsTableID = 7
+	// RoleMembersTableID is part of the system config so that changes to role
+	// memberships invalidate the membership caches of all nodes.
+	RoleMembersTableID = 8
 
 	// IDs for the important columns and indexes in the zones table live here to
 	// avoid introducing a dependency on sql/sqlbase throughout the codebase.
 	ZonesTablePrimaryIndexID = 1
 	ZonesTableConfigColumnID = 2
 
+	// SequenceIndexID is the ID of the single index on each special single-column,
+	// single-row sequence table.
+	SequenceIndexID = 1
+	// SequenceColumnFamilyID is the ID of the column family on each special single-column,
+	// single-row sequence table.
+	SequenceColumnFamilyID = 0
+
 	// Reserved IDs for other system tables. Note that some of these IDs refer
 	// to "Ranges" instead of a Table - these IDs are needed to store custom
 	// configuration for non-table ranges (e.g. Zone Configs).
@@ -311,4 +324,7 @@ const (
 	SystemRangesID     = 17
 	TimeseriesRangesID = 18
 	WebSessionsTableID = 19
+	// TableStatisticsTableID is not part of the system config; statistics
+	// are read on demand by the planner.
+	TableStatisticsTableID = 20
 )
@@ -590,6 +590,15 @@ func MakeTablePrefix(tableID uint32) []byte {
 	return encoding.EncodeUvarintAscending(nil, uint64(tableID))
 }
 
+// MakeSequenceKey returns the key used to store the value of a sequence.
+func MakeSequenceKey(tableID uint32) []byte {
+	key := MakeTablePrefix(tableID)
+	key = encoding.EncodeUvarintAscending(key, SequenceIndexID) // Index id
+	key = encoding.EncodeUvarintAscending(key, 0)               // Primary key value
+	key = MakeFamilyKey(key, SequenceColumnFamilyID)            // Column family
+	return key
+}
+
 // DecodeTablePrefix validates that the given key has a table prefix, returning
 // the remainder of the key (with the prefix removed) and the decoded descriptor
 // ID of the table.
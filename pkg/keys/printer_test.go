@@ -91,6 +91,7 @@ func TestPrettyPrint(t *testing.T) {
 		{SystemConfigSpan.Key, "/Table/SystemConfigSpan/Start"},
 		{UserTableDataMin, "/Table/50"},
 		{MakeTablePrefix(111), "/Table/111"},
+		{MakeSequenceKey(111), "/Table/111/1/0/0"},
 		{makeKey(MakeTablePrefix(42), roachpb.RKey("foo")), `/Table/42/"foo"`},
 		{makeKey(MakeTablePrefix(42),
 			roachpb.RKey(encoding.EncodeFloatAscending(nil, float64(233.221112)))),
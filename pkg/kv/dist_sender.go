@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -422,6 +423,24 @@ func (ds *DistSender) getDescriptor(
 	return desc, returnToken, nil
 }
 
+// canSendToFollower returns whether the batch is a read far enough in the
+// past that it is likely below the closed timestamp of the range, in which
+// case any replica can serve it. A replica which can't serve it redirects the
+// batch to the lease holder.
+func (ds *DistSender) canSendToFollower(ba *roachpb.BatchRequest) bool {
+	if !storagebase.FollowerReadsEnabled.Get(&ds.st.SV) || !storagebase.IsFollowerReadCandidate(ba) {
+		return false
+	}
+	target := storagebase.ClosedTimestampTargetDuration.Get(&ds.st.SV)
+	if target == 0 {
+		return false
+	}
+	// The closed timestamp of a range trails the current time by at least the
+	// target duration, and by more when the range isn't written to frequently.
+	threshold := ds.clock.Now().Add(-2*target.Nanoseconds(), 0)
+	return storagebase.FollowerReadTimestamp(ba).Less(threshold)
+}
+
 // sendSingleRange gathers and rearranges the replicas, and makes an RPC call.
 func (ds *DistSender) sendSingleRange(
 	ctx context.Context, ba roachpb.BatchRequest, desc *roachpb.RangeDescriptor,
@@ -435,8 +454,9 @@ func (ds *DistSender) sendSingleRange(
 	replicas.OptimizeReplicaOrder(ds.getNodeDescriptor())
 
 	// If this request needs to go to a lease holder and we know who that is, move
-	// it to the front.
-	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) {
+	// it to the front. Historical reads which can likely be served by any
+	// replica are sent to the closest one instead.
+	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) && !ds.canSendToFollower(&ba) {
 		if storeID, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(storeID); i >= 0 {
 				replicas.MoveToFront(i)
@@ -658,6 +678,23 @@ type response struct {
 	pErr      *roachpb.Error
 }
 
+// updateErrTxnSequence makes sure that the transaction attached to pErr
+// reflects the highest sequence number used to send the batch, which is
+// the one of txn. Some of the batch's writes may have been carried out
+// before the error occurred; the client must not reuse their sequence
+// numbers if the transaction continues, for instance after rolling back
+// to a savepoint.
+func updateErrTxnSequence(pErr *roachpb.Error, txn *roachpb.Transaction) {
+	if pErr == nil || pErr == errNo1PCTxn || txn == nil {
+		return
+	}
+	if errTxn := pErr.GetTxn(); errTxn == nil {
+		pErr.SetTxn(txn)
+	} else if errTxn.ID == txn.ID && errTxn.Sequence < txn.Sequence {
+		errTxn.Sequence = txn.Sequence
+	}
+}
+
 // divideAndSendBatchToRanges sends the supplied batch to all of the
 // ranges which comprise the span specified by rs. The batch request
 // is trimmed against each range which is part of the span and sent
@@ -687,6 +724,7 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 	if !ri.NeedAnother(rs) {
 		ba.SetNewRequest()
 		resp := ds.sendPartialBatch(ctx, ba, rs, ri.Desc(), ri.Token(), batchIdx, false /* needsTruncate */)
+		updateErrTxnSequence(resp.pErr, ba.Txn)
 		return resp.reply, resp.pErr
 	}
 
@@ -739,6 +777,7 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 			if br.Txn != nil {
 				pErr.UpdateTxn(br.Txn)
 			}
+			updateErrTxnSequence(pErr, ba.Txn)
 		} else if couldHaveSkippedResponses {
 			fillSkippedResponses(ba, br, seekKey, resumeReason)
 		}
@@ -33,7 +33,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -2237,3 +2239,152 @@ func TestGatewayNodeID(t *testing.T) {
 		t.Errorf("got GatewayNodeID=%d, want %d", observedNodeID, expNodeID)
 	}
 }
+
+func TestCanSendToFollower(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	g, clock := makeGossip(t, stopper)
+	st := cluster.MakeTestingClusterSettings()
+	ds := NewDistSender(DistSenderConfig{
+		AmbientCtx:        log.AmbientContext{Tracer: tracing.NewTracer()},
+		Clock:             clock,
+		Settings:          st,
+		RangeDescriptorDB: defaultMockRangeDescriptorDB,
+	}, g)
+	storagebase.ClosedTimestampTargetDuration.Override(&st.SV, time.Second)
+
+	makeBatch := func(
+		req roachpb.Request, consistency roachpb.ReadConsistencyType, age time.Duration,
+	) *roachpb.BatchRequest {
+		ba := &roachpb.BatchRequest{}
+		ba.Timestamp = clock.Now().Add(-age.Nanoseconds(), 0)
+		ba.ReadConsistency = consistency
+		ba.Add(req)
+		return ba
+	}
+	get := &roachpb.GetRequest{Span: roachpb.Span{Key: roachpb.Key("a")}}
+	put := roachpb.NewPut(roachpb.Key("a"), roachpb.MakeValueFromString("value"))
+	historicalTxn := makeBatch(get, roachpb.CONSISTENT, time.Minute)
+	historicalTxn.Txn = &roachpb.Transaction{
+		TxnMeta:       enginepb.TxnMeta{Timestamp: historicalTxn.Timestamp},
+		MaxTimestamp:  historicalTxn.Timestamp,
+		OrigTimestamp: historicalTxn.Timestamp,
+	}
+	uncertainTxn := makeBatch(get, roachpb.CONSISTENT, time.Minute)
+	uncertainTxn.Txn = &roachpb.Transaction{
+		TxnMeta:       enginepb.TxnMeta{Timestamp: uncertainTxn.Timestamp},
+		MaxTimestamp:  clock.Now(),
+		OrigTimestamp: uncertainTxn.Timestamp,
+	}
+
+	testCases := []struct {
+		ba       *roachpb.BatchRequest
+		enabled  bool
+		expected bool
+	}{
+		{makeBatch(get, roachpb.CONSISTENT, time.Minute), false, false},
+		{makeBatch(get, roachpb.CONSISTENT, time.Minute), true, true},
+		{historicalTxn, true, true},
+		// The read is too recent to be below the closed timestamp.
+		{makeBatch(get, roachpb.CONSISTENT, 0), true, false},
+		{uncertainTxn, true, false},
+		// Writes and inconsistent reads are not follower reads.
+		{makeBatch(put, roachpb.CONSISTENT, time.Minute), true, false},
+		{makeBatch(get, roachpb.INCONSISTENT, time.Minute), true, false},
+	}
+	for i, tc := range testCases {
+		storagebase.FollowerReadsEnabled.Override(&st.SV, tc.enabled)
+		if a := ds.canSendToFollower(tc.ba); a != tc.expected {
+			t.Errorf("%d: expected %t, got %t for %s", i, tc.expected, a, tc.ba)
+		}
+	}
+
+	// No timestamps are closed without a target duration.
+	storagebase.ClosedTimestampTargetDuration.Override(&st.SV, 0)
+	if ds.canSendToFollower(makeBatch(get, roachpb.CONSISTENT, time.Minute)) {
+		t.Errorf("expected no follower read without closed timestamps")
+	}
+}
+
+// TestSendFollowerReadOrder verifies that historical reads are sent to the
+// closest replica rather than to the lease holder.
+func TestSendFollowerReadOrder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	g, clock := makeGossip(t, stopper)
+	rangeID := roachpb.RangeID(99)
+	descriptor := roachpb.RangeDescriptor{
+		StartKey: roachpb.RKeyMin,
+		EndKey:   roachpb.RKeyMax,
+		RangeID:  rangeID,
+	}
+	for i := int32(1); i <= 3; i++ {
+		nd := &roachpb.NodeDescriptor{
+			NodeID:  roachpb.NodeID(i),
+			Address: util.MakeUnresolvedAddr("tcp", fmt.Sprintf("node%d:1", i)),
+			Attrs:   roachpb.Attributes{Attrs: []string{fmt.Sprintf("dc%d", i)}},
+		}
+		if err := g.AddInfoProto(gossip.MakeNodeIDKey(roachpb.NodeID(i)), nd, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		descriptor.Replicas = append(descriptor.Replicas, roachpb.ReplicaDescriptor{
+			NodeID:  roachpb.NodeID(i),
+			StoreID: roachpb.StoreID(i),
+		})
+	}
+	// The local node is closest to node 2.
+	nd := &roachpb.NodeDescriptor{NodeID: 6, Attrs: roachpb.Attributes{Attrs: []string{"dc2"}}}
+	g.NodeID.Reset(nd.NodeID)
+	if err := g.SetNodeDescriptor(nd); err != nil {
+		t.Fatal(err)
+	}
+
+	var firstNodeID roachpb.NodeID
+	var testFn rpcSendFn = func(
+		_ context.Context,
+		_ SendOptions,
+		replicas ReplicaSlice,
+		args roachpb.BatchRequest,
+		_ *rpc.Context,
+	) (*roachpb.BatchResponse, error) {
+		firstNodeID = replicas[0].NodeDesc.NodeID
+		return args.CreateReply(), nil
+	}
+	st := cluster.MakeTestingClusterSettings()
+	storagebase.FollowerReadsEnabled.Override(&st.SV, true)
+	storagebase.ClosedTimestampTargetDuration.Override(&st.SV, time.Second)
+	ds := NewDistSender(DistSenderConfig{
+		AmbientCtx: log.AmbientContext{Tracer: tracing.NewTracer()},
+		Clock:      clock,
+		Settings:   st,
+		TestingKnobs: DistSenderTestingKnobs{
+			TransportFactory: adaptLegacyTransport(testFn),
+		},
+		RangeDescriptorDB: mockRangeDescriptorDBForDescs(descriptor),
+	}, g)
+	ds.leaseHolderCache.Update(context.TODO(), rangeID, roachpb.StoreID(3))
+
+	testCases := []struct {
+		age      time.Duration
+		expected roachpb.NodeID
+	}{
+		// Recent reads go to the lease holder.
+		{0, 3},
+		{time.Minute, 2},
+	}
+	for i, tc := range testCases {
+		get := &roachpb.GetRequest{Span: roachpb.Span{Key: roachpb.Key("a")}}
+		if _, pErr := client.SendWrappedWith(context.Background(), ds, roachpb.Header{
+			Timestamp: clock.Now().Add(-tc.age.Nanoseconds(), 0),
+		}, get); pErr != nil {
+			t.Fatalf("%d: %s", i, pErr)
+		}
+		if firstNodeID != tc.expected {
+			t.Errorf("%d: expected read to be sent to n%d first, got n%d", i, tc.expected, firstNodeID)
+		}
+	}
+}
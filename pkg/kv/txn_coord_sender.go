@@ -374,6 +374,18 @@ func (tc *TxnCoordSender) Send(
 				return pErr
 			}
 
+			// Never reuse a sequence number which the transaction may already
+			// have written at, even if the client didn't learn about it (for
+			// instance because the batch using it failed). Writes are rolled
+			// back to a savepoint by ignoring the sequence numbers they were
+			// performed at, so later writes must not share them.
+			if txnMeta := tc.txnMu.txns[txnID]; txnMeta != nil &&
+				txnMeta.txn.Epoch == ba.Txn.Epoch && ba.Txn.Sequence < txnMeta.txn.Sequence {
+				txn := *ba.Txn
+				txn.Sequence = txnMeta.txn.Sequence
+				ba.Txn = &txn
+			}
+
 			if !hasET {
 				return nil
 			}
@@ -547,6 +559,13 @@ func (tc *TxnCoordSender) validateTxnForBatch(ctx context.Context, ba *roachpb.B
 	}
 	ba.Txn.AssertInitialized(ctx)
 
+	for _, r := range ba.Txn.IgnoredSeqNums {
+		if r.Start > r.End || r.End > ba.Txn.Sequence {
+			return errors.Errorf("invalid ignored sequence number range [%d,%d] in txn %s",
+				r.Start, r.End, ba.Txn)
+		}
+	}
+
 	// Check for a begin transaction to set txn key based on the key of
 	// the first transactional write. Also enforce that no transactional
 	// writes occur before a begin transaction.
@@ -722,6 +722,7 @@ func (t Transaction) Clone() Transaction {
 	// Note that we're not cloning the span keys under the assumption that the
 	// keys themselves are not mutable.
 	t.Intents = append([]Span(nil), t.Intents...)
+	t.IgnoredSeqNums = append([]enginepb.IgnoredSeqNumRange(nil), t.IgnoredSeqNums...)
 	return t
 }
 
@@ -845,9 +846,11 @@ func (t *Transaction) Restart(
 
 // BumpEpoch increments the transaction's epoch, allowing for an in-place
 // restart. This invalidates all write intents previously written at lower
-// epochs.
+// epochs, so sequence numbers rolled back in the old epoch are forgotten.
 func (t *Transaction) BumpEpoch() {
 	t.Epoch++
+	t.IgnoredSeqNums = nil
+	t.SavepointSeq = 0
 }
 
 // Update ratchets priority, timestamp and original timestamp values (among
@@ -870,6 +873,8 @@ func (t *Transaction) Update(o *Transaction) {
 	}
 	if t.Epoch < o.Epoch {
 		t.Epoch = o.Epoch
+		t.IgnoredSeqNums = o.IgnoredSeqNums
+		t.SavepointSeq = o.SavepointSeq
 	}
 	t.Timestamp.Forward(o.Timestamp)
 	t.LastHeartbeat.Forward(o.LastHeartbeat)
@@ -895,6 +900,14 @@ func (t *Transaction) Update(o *Transaction) {
 	if t.Sequence < o.Sequence {
 		t.Sequence = o.Sequence
 	}
+	// Within an epoch, sequence number ranges are only ever added to the
+	// list of ignored ranges.
+	if t.Epoch == o.Epoch && len(t.IgnoredSeqNums) < len(o.IgnoredSeqNums) {
+		t.IgnoredSeqNums = o.IgnoredSeqNums
+	}
+	if t.Epoch == o.Epoch && t.SavepointSeq < o.SavepointSeq {
+		t.SavepointSeq = o.SavepointSeq
+	}
 	if len(o.Intents) > 0 {
 		t.Intents = o.Intents
 	}
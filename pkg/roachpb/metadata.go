@@ -265,12 +265,12 @@ func (p Percentiles) String() string {
 // String returns a string representation of the StoreCapacity.
 func (sc StoreCapacity) String() string {
 	return fmt.Sprintf("disk (capacity=%s, available=%s, used=%s, logicalBytes=%s), "+
-		"ranges=%d, leases=%d, writes=%.2f, "+
-		"bytesPerReplica={%s}, writesPerReplica={%s}",
+		"ranges=%d, leases=%d, writes=%.2f, queries=%.2f, "+
+		"bytesPerReplica={%s}, writesPerReplica={%s}, queriesPerReplica={%s}",
 		humanizeutil.IBytes(sc.Capacity), humanizeutil.IBytes(sc.Available),
 		humanizeutil.IBytes(sc.Used), humanizeutil.IBytes(sc.LogicalBytes),
-		sc.RangeCount, sc.LeaseCount, sc.WritesPerSecond,
-		sc.BytesPerReplica, sc.WritesPerReplica)
+		sc.RangeCount, sc.LeaseCount, sc.WritesPerSecond, sc.QueriesPerSecond,
+		sc.BytesPerReplica, sc.WritesPerReplica, sc.QueriesPerReplica)
 }
 
 // FractionUsed computes the fraction of storage capacity that is in use.
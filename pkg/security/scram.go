@@ -0,0 +1,250 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ScramSHA256 is the name of the SASL mechanism implemented by ScramServer,
+// described in RFC 5802 and RFC 7677.
+const ScramSHA256 = "SCRAM-SHA-256"
+
+const (
+	// scramIterations is the iteration count of the verifiers, which is the
+	// minimum recommended by RFC 7677.
+	scramIterations = 4096
+	scramSaltLen    = 16
+	scramNonceLen   = 18
+)
+
+// ScramVerifier holds the information stored by the server to verify SCRAM
+// authentication exchanges: the password cannot be recovered from it.
+type ScramVerifier struct {
+	Iterations int
+	Salt       []byte
+	StoredKey  []byte
+	ServerKey  []byte
+}
+
+// MakeScramVerifier computes the SCRAM-SHA-256 verifier of a password, with
+// a random salt. The password is used as is, without SASLprep
+// normalization.
+func MakeScramVerifier(password string) (ScramVerifier, error) {
+	salt := make([]byte, scramSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return ScramVerifier{}, err
+	}
+	saltedPassword := scramHi([]byte(password), salt, scramIterations)
+	return ScramVerifier{
+		Iterations: scramIterations,
+		Salt:       salt,
+		StoredKey:  scramH(scramHMAC(saltedPassword, []byte("Client Key"))),
+		ServerKey:  scramHMAC(saltedPassword, []byte("Server Key")),
+	}, nil
+}
+
+// Encode returns the verifier in the format used by PostgreSQL:
+//   SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+func (v ScramVerifier) Encode() []byte {
+	enc := base64.StdEncoding.EncodeToString
+	return []byte(fmt.Sprintf("%s$%d:%s$%s:%s",
+		ScramSHA256, v.Iterations, enc(v.Salt), enc(v.StoredKey), enc(v.ServerKey)))
+}
+
+// DecodeScramVerifier decodes a verifier encoded by Encode.
+func DecodeScramVerifier(b []byte) (ScramVerifier, error) {
+	var v ScramVerifier
+	parts := strings.Split(string(b), "$")
+	if len(parts) != 3 || parts[0] != ScramSHA256 {
+		return v, errors.New("invalid SCRAM verifier")
+	}
+	iterSalt := strings.SplitN(parts[1], ":", 2)
+	keys := strings.SplitN(parts[2], ":", 2)
+	if len(iterSalt) != 2 || len(keys) != 2 {
+		return v, errors.New("invalid SCRAM verifier")
+	}
+	var err error
+	if v.Iterations, err = strconv.Atoi(iterSalt[0]); err != nil || v.Iterations <= 0 {
+		return v, errors.New("invalid SCRAM verifier iteration count")
+	}
+	dec := base64.StdEncoding.DecodeString
+	if v.Salt, err = dec(iterSalt[1]); err != nil {
+		return v, errors.Wrap(err, "invalid SCRAM verifier salt")
+	}
+	if v.StoredKey, err = dec(keys[0]); err != nil || len(v.StoredKey) != sha256.Size {
+		return v, errors.New("invalid SCRAM verifier stored key")
+	}
+	if v.ServerKey, err = dec(keys[1]); err != nil || len(v.ServerKey) != sha256.Size {
+		return v, errors.New("invalid SCRAM verifier server key")
+	}
+	return v, nil
+}
+
+// ScramServer is the server side of a SCRAM-SHA-256 exchange. The exchange
+// consists of two messages from the client, which the server answers with
+// ServerFirst and ServerFinal. Channel binding is not supported.
+//
+// The user name of the exchange is ignored: the user is the one given in
+// the connection parameters, like in PostgreSQL.
+type ScramServer struct {
+	verifier ScramVerifier
+	// gs2Header is the GS2 header of the client-first message.
+	gs2Header string
+	// nonce is the concatenation of the client and server nonces.
+	nonce           string
+	clientFirstBare string
+	serverFirst     string
+}
+
+// NewScramServer starts an exchange authenticating the password of the
+// verifier.
+func NewScramServer(verifier ScramVerifier) *ScramServer {
+	return &ScramServer{verifier: verifier}
+}
+
+// ServerFirst processes the client-first message and returns the
+// server-first message.
+func (s *ScramServer) ServerFirst(clientFirst []byte) ([]byte, error) {
+	// client-first-message = gs2-header client-first-message-bare
+	// gs2-header = gs2-cbind-flag "," [ authzid ] ","
+	msg := string(clientFirst)
+	parts := strings.SplitN(msg, ",", 3)
+	if len(parts) != 3 {
+		return nil, errors.New("malformed SCRAM client-first message")
+	}
+	switch {
+	case parts[0] == "n", parts[0] == "y":
+	case strings.HasPrefix(parts[0], "p="):
+		return nil, errors.New("SCRAM channel binding is not supported")
+	default:
+		return nil, errors.Errorf("invalid SCRAM channel binding flag %q", parts[0])
+	}
+	if parts[1] != "" {
+		return nil, errors.New("SCRAM authorization identities are not supported")
+	}
+	s.gs2Header = parts[0] + "," + parts[1] + ","
+	s.clientFirstBare = parts[2]
+
+	// client-first-message-bare = [reserved-mext ","] username "," nonce ["," extensions]
+	attrs := strings.Split(s.clientFirstBare, ",")
+	if len(attrs) < 2 || !strings.HasPrefix(attrs[0], "n=") {
+		return nil, errors.New("malformed SCRAM client-first message")
+	}
+	clientNonce, ok := scramAttr(attrs[1], 'r')
+	if !ok || clientNonce == "" {
+		return nil, errors.New("malformed SCRAM client nonce")
+	}
+
+	serverNonce := make([]byte, scramNonceLen)
+	if _, err := rand.Read(serverNonce); err != nil {
+		return nil, err
+	}
+	s.nonce = clientNonce + base64.StdEncoding.EncodeToString(serverNonce)
+	s.serverFirst = fmt.Sprintf("r=%s,s=%s,i=%d",
+		s.nonce, base64.StdEncoding.EncodeToString(s.verifier.Salt), s.verifier.Iterations)
+	return []byte(s.serverFirst), nil
+}
+
+// ServerFinal verifies the proof of the client-final message and returns
+// the server-final message, which lets the client authenticate the server.
+func (s *ScramServer) ServerFinal(clientFinal []byte) ([]byte, error) {
+	if s.serverFirst == "" {
+		return nil, errors.New("SCRAM client-final message received before client-first message")
+	}
+	// client-final-message = channel-binding "," nonce ["," extensions] "," proof
+	msg := string(clientFinal)
+	pos := strings.LastIndex(msg, ",p=")
+	if pos < 0 {
+		return nil, errors.New("malformed SCRAM client-final message")
+	}
+	withoutProof := msg[:pos]
+	proof, err := base64.StdEncoding.DecodeString(msg[pos+len(",p="):])
+	if err != nil || len(proof) != sha256.Size {
+		return nil, errors.New("malformed SCRAM client proof")
+	}
+
+	attrs := strings.Split(withoutProof, ",")
+	if len(attrs) < 2 {
+		return nil, errors.New("malformed SCRAM client-final message")
+	}
+	binding, ok := scramAttr(attrs[0], 'c')
+	if !ok || binding != base64.StdEncoding.EncodeToString([]byte(s.gs2Header)) {
+		return nil, errors.New("invalid SCRAM channel binding")
+	}
+	if nonce, ok := scramAttr(attrs[1], 'r'); !ok || nonce != s.nonce {
+		return nil, errors.New("invalid SCRAM nonce")
+	}
+
+	authMessage := []byte(s.clientFirstBare + "," + s.serverFirst + "," + withoutProof)
+	clientSignature := scramHMAC(s.verifier.StoredKey, authMessage)
+	clientKey := make([]byte, len(proof))
+	for i := range proof {
+		clientKey[i] = proof[i] ^ clientSignature[i]
+	}
+	if subtle.ConstantTimeCompare(scramH(clientKey), s.verifier.StoredKey) != 1 {
+		return nil, errors.New("invalid password")
+	}
+
+	serverSignature := scramHMAC(s.verifier.ServerKey, authMessage)
+	return []byte("v=" + base64.StdEncoding.EncodeToString(serverSignature)), nil
+}
+
+// scramAttr returns the value of a SCRAM attribute of the given name.
+func scramAttr(attr string, name byte) (string, bool) {
+	if len(attr) < 2 || attr[0] != name || attr[1] != '=' {
+		return "", false
+	}
+	return attr[2:], true
+}
+
+func scramH(b []byte) []byte {
+	h := sha256.Sum256(b)
+	return h[:]
+}
+
+func scramHMAC(key, b []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(b)
+	return mac.Sum(nil)
+}
+
+// scramHi is the Hi function of RFC 5802, i.e. PBKDF2 with HMAC-SHA-256
+// and an output of a single block.
+func scramHi(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	_, _ = mac.Write(salt)
+	_, _ = mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+	result := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		_, _ = mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range result {
+			result[j] ^= u[j]
+		}
+	}
+	return result
+}
@@ -0,0 +1,179 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// scramClientFinal computes the client-final message of an exchange, as a
+// client knowing the password would.
+func scramClientFinal(password, clientFirstBare, serverFirst string) (string, error) {
+	var nonce, salt string
+	var iterations int
+	for _, attr := range strings.Split(serverFirst, ",") {
+		switch attr[0] {
+		case 'r':
+			nonce = attr[2:]
+		case 's':
+			salt = attr[2:]
+		case 'i':
+			var err error
+			if iterations, err = strconv.Atoi(attr[2:]); err != nil {
+				return "", err
+			}
+		}
+	}
+	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		return "", err
+	}
+	saltedPassword := scramHi([]byte(password), saltBytes, iterations)
+	clientKey := scramHMAC(saltedPassword, []byte("Client Key"))
+	withoutProof := "c=biws,r=" + nonce
+	authMessage := clientFirstBare + "," + serverFirst + "," + withoutProof
+	clientSignature := scramHMAC(scramH(clientKey), []byte(authMessage))
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ clientSignature[i]
+	}
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// TestScramRFC7677 checks the exchange of section 3 of RFC 7677.
+func TestScramRFC7677(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	salt, err := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saltedPassword := scramHi([]byte("pencil"), salt, 4096)
+	verifier := ScramVerifier{
+		Iterations: 4096,
+		Salt:       salt,
+		StoredKey:  scramH(scramHMAC(saltedPassword, []byte("Client Key"))),
+		ServerKey:  scramHMAC(saltedPassword, []byte("Server Key")),
+	}
+
+	s := NewScramServer(verifier)
+	if _, err := s.ServerFirst([]byte("n,,n=user,r=rOprNGfwEbeRWgbNEkqO")); err != nil {
+		t.Fatal(err)
+	}
+	// Replace the random server nonce with the one of the RFC.
+	s.nonce = "rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"
+	s.serverFirst = "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+		"s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+
+	serverFinal, err := s.ServerFinal([]byte(
+		"c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+			"p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=", string(serverFinal); e != a {
+		t.Fatalf("expected server-final message %q, got %q", e, a)
+	}
+}
+
+func TestScramExchange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	encoded, err := MakeScramVerifier("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := DecodeScramVerifier(encoded.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(encoded, verifier) {
+		t.Fatalf("expected %+v after decoding, got %+v", encoded, verifier)
+	}
+
+	const clientFirstBare = "n=,r=fyko+d2lbbFgONRv9qkxdawL"
+	for _, tc := range []struct {
+		password string
+		err      string
+	}{
+		{"hunter2", ""},
+		{"hunter3", "invalid password"},
+	} {
+		s := NewScramServer(verifier)
+		serverFirst, err := s.ServerFirst([]byte("n,," + clientFirstBare))
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientFinal, err := scramClientFinal(tc.password, clientFirstBare, string(serverFirst))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.ServerFinal([]byte(clientFinal)); !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.password, tc.err, err)
+		}
+	}
+}
+
+func TestScramMalformedMessages(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	verifier, err := MakeScramVerifier("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		clientFirst string
+		err         string
+	}{
+		{"n,,r=abc", "malformed SCRAM client-first message"},
+		{"p=tls-server-end-point,,n=,r=abc", "channel binding is not supported"},
+		{"n,a=admin,n=,r=abc", "authorization identities are not supported"},
+		{"n,,n=,s=abc", "malformed SCRAM client nonce"},
+	} {
+		if _, err := NewScramServer(verifier).ServerFirst([]byte(tc.clientFirst)); !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.clientFirst, tc.err, err)
+		}
+	}
+
+	s := NewScramServer(verifier)
+	if _, err := s.ServerFinal([]byte("c=biws,r=abc,p=")); !testutils.IsError(err, "before client-first") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := s.ServerFirst([]byte("n,,n=,r=abc")); err != nil {
+		t.Fatal(err)
+	}
+	proof := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	for _, tc := range []struct {
+		clientFinal string
+		err         string
+	}{
+		{"c=biws,r=abc", "malformed SCRAM client-final message"},
+		{"c=biws,r=abc,p=!!", "malformed SCRAM client proof"},
+		{"c=eSws,r=abc,p=" + proof, "invalid SCRAM channel binding"},
+		{"c=biws,r=abc,p=" + proof, "invalid SCRAM nonce"},
+	} {
+		if _, err := s.ServerFinal([]byte(tc.clientFinal)); !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.clientFinal, tc.err, err)
+		}
+	}
+}
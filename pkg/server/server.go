@@ -58,6 +58,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	migrations "github.com/cockroachdb/cockroach/pkg/sqlmigrations"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
@@ -79,6 +80,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
+// tableStatsCacheSize is the number of tables whose statistics are cached
+// by the SQL planner.
+const tableStatsCacheSize = 256
+
 var (
 	// Allocation pool for gzipResponseWriters.
 	gzipResponseWriterPool sync.Pool
@@ -473,6 +478,9 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		HistogramWindowInterval: s.cfg.HistogramWindowInterval(),
 		RangeDescriptorCache:    s.distSender.RangeDescriptorCache(),
 		LeaseHolderCache:        s.distSender.LeaseHolderCache(),
+		TableStatsCache: stats.NewTableStatisticsCache(
+			tableStatsCacheSize, s.db, sqlExecutor,
+		),
 	}
 	if sqlExecutorTestingKnobs := s.cfg.TestingKnobs.SQLExecutor; sqlExecutorTestingKnobs != nil {
 		execCfg.TestingKnobs = sqlExecutorTestingKnobs.(*sql.ExecutorTestingKnobs)
@@ -490,6 +498,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	s.pgServer = pgwire.MakeServer(
 		s.cfg.AmbientCtx,
 		s.cfg.Config,
+		s.st,
 		s.sqlExecutor,
 		&s.internalMemMetrics,
 		&rootSQLMemoryMonitor,
@@ -1161,6 +1170,8 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 		return err
 	}
 
+	s.sqlExecutor.StartTempTableSweeper(s.stopper, s.nodeLiveness, sql.DefaultTempTableSweepInterval)
+
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
 	// We have to do this after actually starting up the server to be able to
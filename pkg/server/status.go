@@ -1255,6 +1255,34 @@ func (s *statusServer) CancelQuery(
 	return output, nil
 }
 
+// CancelQueryByKey responds to a pgwire CancelRequest by cancelling the
+// queries of the session with the given cancellation key.
+func (s *statusServer) CancelQueryByKey(
+	ctx context.Context, req *serverpb.CancelQueryByKeyRequest,
+) (*serverpb.CancelQueryResponse, error) {
+	ctx = s.AnnotateCtx(ctx)
+	nodeID, local, err := s.parseNodeID(req.NodeId)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if !local {
+		status, err := s.dialNode(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.CancelQueryByKey(ctx, req)
+	}
+
+	output := &serverpb.CancelQueryResponse{}
+	cancelled, err := s.sessionRegistry.CancelQueryByKey(req.CancelKey)
+	if err != nil {
+		output.Error = err.Error()
+	}
+	output.Cancelled = cancelled
+	return output, nil
+}
+
 // SpanStats requests the total statistics stored on a node for a given key
 // span, which may include multiple ranges.
 func (s *statusServer) SpanStats(
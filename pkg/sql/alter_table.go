@@ -37,7 +37,7 @@ type alterTableNode struct {
 //   notes: postgres requires CREATE on the table.
 //          mysql requires ALTER, CREATE, INSERT on the table.
 func (p *planner) AlterTable(ctx context.Context, n *parser.AlterTable) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -66,6 +66,7 @@ func (n *alterTableNode) Start(params runParams) error {
 	descriptorChanged := false
 	origNumMutations := len(n.tableDesc.Mutations)
 	var droppedViews []string
+	prevSeqIDs := n.tableDesc.UsedSequenceIDs()
 
 	for _, cmd := range n.n.Cmds {
 		switch t := cmd.(type) {
@@ -83,6 +84,9 @@ func (n *alterTableNode) Start(params runParams) error {
 			if err != nil {
 				return err
 			}
+			if err := params.p.resolveColumnSequences(params.ctx, col); err != nil {
+				return err
+			}
 			// We're checking to see if a user is trying add a non-nullable column without a default to a
 			// non empty table by scanning the primary index span with a limit of 1 to see if any key exists.
 			if !col.Nullable && col.DefaultExpr == nil {
@@ -147,9 +151,16 @@ func (n *alterTableNode) Start(params runParams) error {
 						return fmt.Errorf("index %q being dropped, try again later", d.Name)
 					}
 				}
+				exprCols, err := n.tableDesc.MakeIndexExpressionColumns(&idx)
+				if err != nil {
+					return err
+				}
 				if err := n.tableDesc.AddIndexMutation(idx, sqlbase.DescriptorMutation_ADD); err != nil {
 					return err
 				}
+				for _, col := range exprCols {
+					n.tableDesc.AddColumnMutation(col, sqlbase.DescriptorMutation_ADD)
+				}
 
 			case *parser.CheckConstraintTableDef:
 				ck, err := makeCheckConstraint(*n.tableDesc, d, inuseNames, &params.p.semaCtx, &params.p.evalCtx)
@@ -171,6 +182,9 @@ func (n *alterTableNode) Start(params runParams) error {
 				}
 				descriptorChanged = true
 				for _, updated := range affected {
+					if err := params.p.checkTempFKReference(params.ctx, n.tableDesc, updated); err != nil {
+						return err
+					}
 					if err := params.p.saveNonmutationAndNotify(params.ctx, updated); err != nil {
 						return err
 					}
@@ -196,6 +210,9 @@ func (n *alterTableNode) Start(params runParams) error {
 			if dropped {
 				continue
 			}
+			if err := checkColumnNotChanging(n.tableDesc, col); err != nil {
+				return err
+			}
 			// You can't drop a column depended on by a view unless CASCADE was
 			// specified.
 			for _, ref := range n.tableDesc.DependedOnBy {
@@ -230,6 +247,10 @@ func (n *alterTableNode) Start(params runParams) error {
 			if n.tableDesc.PrimaryIndex.ContainsColumnID(col.ID) {
 				return fmt.Errorf("column %q is referenced by the primary key", col.Name)
 			}
+			if n.tableDesc.IsIndexExpressionColumn(col.ID) {
+				return pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
+					"column %q holds the values of an index expression, drop the index instead", col.Name)
+			}
 			for _, idx := range n.tableDesc.AllNonDropIndexes() {
 				// We automatically drop indexes on that column that only
 				// index that column (and no other columns). If CASCADE is
@@ -252,8 +273,23 @@ func (n *alterTableNode) Start(params runParams) error {
 				// includes non-PK columns other than the one being dropped.
 				containsOnlyThisColumn := true
 
-				// Analyze the index.
-				for _, id := range idx.ColumnIDs {
+				// Analyze the index. An indexed expression is defined over the
+				// columns it refers to.
+				for i, id := range idx.ColumnIDs {
+					if i < len(idx.ColumnExpressions) && idx.ColumnExpressions[i] != "" {
+						srcIDs, err := n.tableDesc.IndexExpressionColumnIDs(idx.ColumnExpressions[i])
+						if err != nil {
+							return err
+						}
+						for _, srcID := range srcIDs {
+							if srcID == col.ID {
+								containsThisColumn = true
+							} else {
+								containsOnlyThisColumn = false
+							}
+						}
+						continue
+					}
 					if id == col.ID {
 						containsThisColumn = true
 					} else {
@@ -281,6 +317,16 @@ func (n *alterTableNode) Start(params runParams) error {
 						containsThisColumn = true
 					}
 				}
+				// The predicate of a partial index also refers to columns.
+				predCols, err := n.tableDesc.IndexPredicateColumnIDs(&idx)
+				if err != nil {
+					return err
+				}
+				for _, id := range predCols {
+					if id == col.ID {
+						containsThisColumn = true
+					}
+				}
 
 				// Perform the DROP.
 				if containsThisColumn {
@@ -411,6 +457,117 @@ func (n *alterTableNode) Start(params runParams) error {
 				return errors.Errorf("validating %s constraint %q unsupported", constraint.Kind, t.Constraint)
 			}
 
+		case *parser.AlterTableAlterColumnType:
+			col, dropped, err := n.tableDesc.FindColumnByName(t.Column)
+			if err != nil {
+				return err
+			}
+			if dropped {
+				return fmt.Errorf("column %q in the middle of being dropped", t.Column)
+			}
+			if _, err := n.tableDesc.FindActiveColumnByName(string(t.Column)); err != nil {
+				return fmt.Errorf("column %q in the middle of being added, try again later", t.Column)
+			}
+			if err := checkColumnNotChanging(n.tableDesc, col); err != nil {
+				return err
+			}
+			newCol, _, err := sqlbase.MakeColumnDefDescs(
+				&parser.ColumnTableDef{Name: t.Column, Type: t.ToType}, &params.p.semaCtx, &params.p.evalCtx,
+			)
+			if err != nil {
+				return err
+			}
+			if newCol.DefaultExpr != nil {
+				return fmt.Errorf("cannot alter column %q to type %s", t.Column, t.ToType)
+			}
+
+			// Conversions that don't change the encoding of existing values
+			// only need the descriptor to be updated.
+			if t.Using == nil && columnTypeIsWidening(col.Type, newCol.Type) {
+				col.Type = newCol.Type
+				n.tableDesc.UpdateColumnDescriptor(col)
+				descriptorChanged = true
+				continue
+			}
+
+			// Other conversions add a new column computed from the old one,
+			// which replaces it once backfilled.
+			if err := params.p.checkColumnTypeConvertible(params.ctx, n.tableDesc, col); err != nil {
+				return err
+			}
+			using := t.Using
+			if using == nil {
+				using = &parser.CastExpr{Expr: parser.NewOrdinalReference(0), Type: t.ToType}
+			} else {
+				if using, err = bindUsingExpr(n.tableDesc, col, using); err != nil {
+					return err
+				}
+				if err := params.p.txCtx.AssertNoAggregationOrWindowing(
+					using, "USING", params.p.session.SearchPath,
+				); err != nil {
+					return err
+				}
+			}
+			if col.DefaultExpr != nil {
+				def, err := parser.ParseExpr(*col.DefaultExpr)
+				if err != nil {
+					return err
+				}
+				if _, err := sqlbase.SanitizeVarFreeExpr(
+					def, newCol.Type.ToDatumType(), "DEFAULT", &params.p.semaCtx, &params.p.evalCtx,
+				); err != nil {
+					return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+						"default for column %q cannot be converted to type %s: %v", t.Column, t.ToType, err)
+				}
+			}
+			newCol.Name = convertedColumnName(n.tableDesc, col.Name)
+			newCol.Nullable = col.Nullable
+			newCol.Hidden = col.Hidden
+			newCol.DefaultExpr = col.DefaultExpr
+			newCol.UsesSequenceIds = col.UsesSequenceIds
+			newCol.TypeConversion = &sqlbase.ColumnDescriptor_TypeConversion{
+				SourceColumnID: col.ID,
+				Expr:           parser.Serialize(using),
+			}
+			if err := sqlbase.ValidateTypeConversion(n.tableDesc, *newCol); err != nil {
+				return err
+			}
+			n.tableDesc.AddColumnMutation(*newCol, sqlbase.DescriptorMutation_ADD)
+			// The new column is stored in the family of the old one.
+			for i := range n.tableDesc.Families {
+				family := &n.tableDesc.Families[i]
+				for _, id := range family.ColumnIDs {
+					if id == col.ID {
+						family.ColumnNames = append(family.ColumnNames, newCol.Name)
+						break
+					}
+				}
+			}
+
+		case *parser.AlterTableSetNotNull:
+			col, dropped, err := n.tableDesc.FindColumnByName(t.Column)
+			if err != nil {
+				return err
+			}
+			if dropped {
+				return fmt.Errorf("column %q in the middle of being dropped", t.Column)
+			}
+			if _, err := n.tableDesc.FindActiveColumnByName(string(t.Column)); err != nil {
+				return fmt.Errorf("column %q in the middle of being added, try again later", t.Column)
+			}
+			if err := checkColumnNotChanging(n.tableDesc, col); err != nil {
+				return err
+			}
+			if !col.Nullable {
+				continue
+			}
+			if err := checkFKActionsAllowNotNull(n.tableDesc, col); err != nil {
+				return err
+			}
+			// The existing rows are validated by the schema changer, once
+			// all the nodes reject writes of NULL values to the column.
+			n.tableDesc.AddNotNullMutation(col.ID)
+
 		case parser.ColumnMutationCmd:
 			// Column mutations
 			col, dropped, err := n.tableDesc.FindColumnByName(t.GetColumn())
@@ -420,11 +577,19 @@ func (n *alterTableNode) Start(params runParams) error {
 			if dropped {
 				return fmt.Errorf("column %q in the middle of being dropped", t.GetColumn())
 			}
+			if err := checkColumnNotChanging(n.tableDesc, col); err != nil {
+				return err
+			}
 			if err := applyColumnMutation(
 				&col, t, &params.p.semaCtx, &params.p.evalCtx,
 			); err != nil {
 				return err
 			}
+			if _, ok := t.(*parser.AlterTableSetDefault); ok {
+				if err := params.p.resolveColumnSequences(params.ctx, &col); err != nil {
+					return err
+				}
+			}
 			n.tableDesc.UpdateColumnDescriptor(col)
 			descriptorChanged = true
 
@@ -463,6 +628,9 @@ func (n *alterTableNode) Start(params runParams) error {
 	if err := params.p.writeTableDesc(params.ctx, n.tableDesc); err != nil {
 		return err
 	}
+	if err := params.p.updateSequenceDependents(params.ctx, n.tableDesc, prevSeqIDs); err != nil {
+		return err
+	}
 
 	// Record this table alteration in the event log. This is an auditable log
 	// event and is recorded in the same transaction as the table descriptor
@@ -520,6 +688,201 @@ func applyColumnMutation(
 	return nil
 }
 
+// checkColumnNotChanging returns an error if a schema change in progress is
+// converting the column to a new type or making it NOT NULL, in which case
+// the column can't be altered until the schema change completes.
+func checkColumnNotChanging(desc *sqlbase.TableDescriptor, col sqlbase.ColumnDescriptor) error {
+	for _, m := range desc.Mutations {
+		if c := m.GetColumn(); c != nil && c.TypeConversion != nil &&
+			c.TypeConversion.SourceColumnID == col.ID {
+			return fmt.Errorf("column %q in the middle of being converted, try again later", col.Name)
+		}
+	}
+	if desc.HasNotNullMutation(col.ID) {
+		return fmt.Errorf("column %q in the middle of being made NOT NULL, try again later", col.Name)
+	}
+	return nil
+}
+
+// checkFKActionsAllowNotNull returns an error if the column is set to NULL by
+// the cascading action of a foreign key, in which case it can't be made NOT
+// NULL.
+func checkFKActionsAllowNotNull(desc *sqlbase.TableDescriptor, col sqlbase.ColumnDescriptor) error {
+	for _, idx := range desc.AllNonDropIndexes() {
+		fk := idx.ForeignKey
+		if !fk.IsSet() {
+			continue
+		}
+		numCols := len(idx.ColumnIDs)
+		if fk.SharedPrefixLen > 0 {
+			numCols = int(fk.SharedPrefixLen)
+		}
+		for _, id := range idx.ColumnIDs[:numCols] {
+			if id != col.ID {
+				continue
+			}
+			for _, action := range []sqlbase.ForeignKeyReference_Action{fk.OnDelete, fk.OnUpdate} {
+				if action == sqlbase.ForeignKeyReference_SET_NULL ||
+					(action == sqlbase.ForeignKeyReference_SET_DEFAULT && col.DefaultExpr == nil) {
+					return pgerror.NewErrorf(pgerror.CodeInvalidForeignKeyError,
+						"column %q can't be made NOT NULL: foreign key %q sets it to NULL", col.Name, fk.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// columnTypeIsWidening returns true if all the values of type from are also
+// values of type to, with the same encoding, in which case a column can be
+// converted from one type to the other without rewriting its data.
+func columnTypeIsWidening(from, to sqlbase.ColumnType) bool {
+	if from.SemanticType != to.SemanticType {
+		return false
+	}
+	// A zero width or precision means that the values are unbounded.
+	isWider := func(from, to int32) bool {
+		return to == 0 || (from != 0 && to >= from)
+	}
+	switch from.SemanticType {
+	case sqlbase.ColumnType_INT, sqlbase.ColumnType_STRING:
+		return isWider(from.Width, to.Width)
+	case sqlbase.ColumnType_COLLATEDSTRING:
+		return from.Locale != nil && to.Locale != nil && *from.Locale == *to.Locale &&
+			isWider(from.Width, to.Width)
+	case sqlbase.ColumnType_FLOAT:
+		return isWider(from.Precision, to.Precision)
+	case sqlbase.ColumnType_DECIMAL:
+		// The scale must be preserved and the number of digits before the
+		// decimal point must not shrink.
+		if to.Precision == 0 {
+			return true
+		}
+		return from.Precision != 0 && from.Width == to.Width && to.Precision >= from.Precision
+	case sqlbase.ColumnType_ARRAY:
+		return from.ArrayContents != nil && to.ArrayContents != nil &&
+			*from.ArrayContents == *to.ArrayContents
+	default:
+		return true
+	}
+}
+
+// checkColumnTypeConvertible returns an error if the column can't be replaced
+// by a column of a different type, which is the case when other schema
+// objects refer to it.
+func (p *planner) checkColumnTypeConvertible(
+	ctx context.Context, desc *sqlbase.TableDescriptor, col sqlbase.ColumnDescriptor,
+) error {
+	for _, idx := range desc.AllNonDropIndexes() {
+		dependsOn := idx.ContainsColumnID(col.ID)
+		for _, expr := range idx.ColumnExpressions {
+			if expr == "" {
+				continue
+			}
+			srcIDs, err := desc.IndexExpressionColumnIDs(expr)
+			if err != nil {
+				return err
+			}
+			for _, id := range srcIDs {
+				dependsOn = dependsOn || id == col.ID
+			}
+		}
+		predCols, err := desc.IndexPredicateColumnIDs(&idx)
+		if err != nil {
+			return err
+		}
+		for _, id := range predCols {
+			dependsOn = dependsOn || id == col.ID
+		}
+		if dependsOn {
+			return pgerror.Unimplemented("alter column type index",
+				fmt.Sprintf("cannot alter type of column %q because index %q depends on it", col.Name, idx.Name))
+		}
+	}
+	for _, ref := range desc.DependedOnBy {
+		for _, colID := range ref.ColumnIDs {
+			if colID == col.ID {
+				return p.dependentViewError(ctx, "alter type of", "column", col.Name, desc.ParentID, ref.ID)
+			}
+		}
+	}
+	for _, check := range desc.Checks {
+		expr, err := parser.ParseExpr(check.Expr)
+		if err != nil {
+			return err
+		}
+		found := false
+		if _, err := parser.SimpleVisit(expr, func(expr parser.Expr) (error, bool, parser.Expr) {
+			if vBase, ok := expr.(parser.VarName); ok {
+				v, err := vBase.NormalizeVarName()
+				if err != nil {
+					return err, false, nil
+				}
+				if c, ok := v.(*parser.ColumnItem); ok && string(c.ColumnName) == col.Name {
+					found = true
+				}
+				return nil, false, expr
+			}
+			return nil, true, expr
+		}); err != nil {
+			return err
+		}
+		if found {
+			return pgerror.Unimplemented("alter column type check",
+				fmt.Sprintf("cannot alter type of column %q because constraint %q depends on it", col.Name, check.Name))
+		}
+	}
+	return nil
+}
+
+// bindUsingExpr replaces the references to col in the USING expression of
+// an ALTER COLUMN TYPE command with the ordinal reference @1, which is how
+// the expression is stored in the descriptor of the converted column.
+func bindUsingExpr(
+	desc *sqlbase.TableDescriptor, col sqlbase.ColumnDescriptor, using parser.Expr,
+) (parser.Expr, error) {
+	return parser.SimpleVisit(using, func(expr parser.Expr) (error, bool, parser.Expr) {
+		switch t := expr.(type) {
+		case *parser.Subquery:
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot use subquery in USING expression"), false, nil
+		case *parser.IndexedVar:
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot use ordinal reference %s in USING expression", t), false, nil
+		case parser.VarName:
+			v, err := t.NormalizeVarName()
+			if err != nil {
+				return err, false, nil
+			}
+			c, ok := v.(*parser.ColumnItem)
+			if !ok {
+				return nil, false, v
+			}
+			if string(c.ColumnName) != col.Name {
+				if _, err := desc.FindActiveColumnByName(string(c.ColumnName)); err != nil {
+					return err, false, nil
+				}
+				return pgerror.Unimplemented("alter column type using",
+					fmt.Sprintf("USING expression can only refer to column %q", col.Name)), false, nil
+			}
+			return nil, false, parser.NewOrdinalReference(0)
+		}
+		return nil, true, expr
+	})
+}
+
+// convertedColumnName returns an unused name for the column replacing the
+// column with the given name during an ALTER COLUMN TYPE schema change.
+func convertedColumnName(desc *sqlbase.TableDescriptor, name string) string {
+	newName := name + "_converted"
+	for i := 1; ; i++ {
+		if _, _, err := desc.FindColumnByName(parser.Name(newName)); err != nil {
+			return newName
+		}
+		newName = fmt.Sprintf("%s_converted%d", name, i)
+	}
+}
+
 func labeledRowValues(cols []sqlbase.ColumnDescriptor, values parser.Datums) string {
 	var s bytes.Buffer
 	for i := range cols {
@@ -54,7 +54,21 @@ func CheckPrivilege(
 func (p *planner) CheckPrivilege(
 	descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
 ) error {
-	return CheckPrivilege(p.session.User, descriptor, privilege)
+	err := CheckPrivilege(p.session.User, descriptor, privilege)
+	if err == nil {
+		return nil
+	}
+	// The user also has the privileges of the roles it belongs to.
+	ok, roleErr := p.anyRole(func(role string) bool {
+		return descriptor.GetPrivileges().CheckPrivilege(role, privilege)
+	})
+	if roleErr != nil {
+		return roleErr
+	}
+	if ok {
+		return nil
+	}
+	return err
 }
 
 // anyPrivilege implements the AuthorizationAccessor interface.
@@ -62,6 +76,15 @@ func (p *planner) anyPrivilege(descriptor sqlbase.DescriptorProto) error {
 	if userCanSeeDescriptor(descriptor, p.session.User) {
 		return nil
 	}
+	ok, err := p.anyRole(func(role string) bool {
+		return descriptor.GetPrivileges().AnyPrivilege(role)
+	})
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
 	return fmt.Errorf("user %s has no privileges on %s %s",
 		p.session.User, descriptor.TypeName(), descriptor.GetName())
 }
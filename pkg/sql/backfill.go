@@ -111,6 +111,7 @@ func (sc *SchemaChanger) runBackfill(
 	// mutations. Collect the elements that are part of the mutation.
 	var droppedIndexDescs []sqlbase.IndexDescriptor
 	var addedIndexDescs []sqlbase.IndexDescriptor
+	var notNullColumns []sqlbase.ColumnID
 	// Indexes within the Mutations slice for checkpointing.
 	mutationSentinel := -1
 	var droppedIndexMutationIdx int
@@ -142,11 +143,13 @@ func (sc *SchemaChanger) runBackfill(
 			switch t := m.Descriptor_.(type) {
 			case *sqlbase.DescriptorMutation_Column:
 				desc := m.GetColumn()
-				if desc.DefaultExpr != nil || !desc.Nullable {
+				if desc.DefaultExpr != nil || !desc.Nullable || tableDesc.IsComputedColumn(*desc) {
 					needColumnBackfill = true
 				}
 			case *sqlbase.DescriptorMutation_Index:
 				addedIndexDescs = append(addedIndexDescs, *t.Index)
+			case *sqlbase.DescriptorMutation_Constraint:
+				notNullColumns = append(notNullColumns, t.Constraint.NotNullColumn)
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
 			}
@@ -160,13 +163,16 @@ func (sc *SchemaChanger) runBackfill(
 				if droppedIndexMutationIdx == mutationSentinel {
 					droppedIndexMutationIdx = i
 				}
+			case *sqlbase.DescriptorMutation_Constraint:
+				// Nothing to do.
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
 			}
 		}
 	}
 
-	// First drop indexes, then add/drop columns, and only then add indexes.
+	// First drop indexes, then add/drop columns, and only then add indexes
+	// and validate constraints.
 
 	// Drop indexes.
 	if err := sc.truncateIndexes(
@@ -189,6 +195,18 @@ func (sc *SchemaChanger) runBackfill(
 		}
 	}
 
+	// Validate new constraints.
+	for _, colID := range notNullColumns {
+		if err := sc.ExtendLease(ctx, lease); err != nil {
+			return err
+		}
+		if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return sc.validateNotNull(ctx, txn, tableDesc, colID)
+		}); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
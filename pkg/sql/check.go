@@ -22,10 +22,13 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
@@ -255,3 +258,66 @@ func (p *planner) validateForeignKey(
 	}
 	return nil
 }
+
+// validateNotNull returns an error if the column of tableDesc with the given
+// ID contains NULL values. The table is scanned by a DistSQL flow, and the
+// error reports the primary key of the first offending row found.
+func (sc *SchemaChanger) validateNotNull(
+	ctx context.Context, txn *client.Txn, tableDesc *sqlbase.TableDescriptor, colID sqlbase.ColumnID,
+) error {
+	col, err := tableDesc.FindColumnByID(colID)
+	if err != nil {
+		return err
+	}
+	pkCols := make([]sqlbase.ColumnDescriptor, len(tableDesc.PrimaryIndex.ColumnIDs))
+	pkNames := make([]string, len(pkCols))
+	for i, id := range tableDesc.PrimaryIndex.ColumnIDs {
+		pkCol, err := tableDesc.FindColumnByID(id)
+		if err != nil {
+			return err
+		}
+		pkCols[i] = *pkCol
+		pkNames[i] = parser.Name(pkCol.Name).String()
+	}
+	query := fmt.Sprintf(`SELECT %s FROM [%d AS t] WHERE %s IS NULL LIMIT 1`,
+		strings.Join(pkNames, ", "), tableDesc.ID, parser.Name(col.Name).String())
+
+	p := makeInternalPlanner("validate-not-null", txn, security.RootUser, sc.leaseMgr.memMetrics)
+	defer finishInternalPlanner(p)
+	InternalExecutor{LeaseManager: sc.leaseMgr}.initSession(p)
+	plan, err := p.makeInternalPlan(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer plan.Close(ctx)
+
+	rows := sqlbase.NewRowContainer(
+		p.session.TxnState.mon.MakeBoundAccount(), sqlbase.ColTypeInfoFromColDescs(pkCols), 0,
+	)
+	defer rows.Close(ctx)
+	recv, err := makeDistSQLReceiver(
+		ctx,
+		NewRowResultWriter(parser.Rows, rows),
+		sc.rangeDescriptorCache,
+		sc.leaseHolderCache,
+		txn,
+		func(ts hlc.Timestamp) {
+			_ = sc.clock.Update(ts)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if err := sc.distSQLPlanner.PlanAndRun(ctx, txn, plan, &recv, p.evalCtx); err != nil {
+		return err
+	}
+	if recv.err != nil {
+		return recv.err
+	}
+	if rows.Len() > 0 {
+		return pgerror.NewErrorf(pgerror.CodeNotNullViolationError,
+			"validation of NOT NULL constraint on column %q failed on row: %s",
+			col.Name, labeledRowValues(pkCols, rows.At(0)))
+	}
+	return nil
+}
@@ -279,6 +279,12 @@ CREATE TABLE crdb_internal.schema_changes (
 					mutType = "INDEX"
 					targetID = parser.NewDInt(parser.DInt(int64(d.Index.ID)))
 					targetName = parser.NewDString(d.Index.Name)
+				case *sqlbase.DescriptorMutation_Constraint:
+					mutType = "CONSTRAINT"
+					targetID = parser.NewDInt(parser.DInt(int64(d.Constraint.NotNullColumn)))
+					if col, err := table.FindColumnByID(d.Constraint.NotNullColumn); err == nil {
+						targetName = parser.NewDString(col.Name)
+					}
 				}
 				if err := addRow(
 					tableID,
@@ -871,8 +877,8 @@ CREATE TABLE crdb_internal.builtin_functions (
 	},
 }
 
-// crdbInternalCreateStmtsTable exposes the CREATE TABLE/CREATE VIEW
-// statements.
+// crdbInternalCreateStmtsTable exposes the CREATE TABLE/CREATE VIEW/CREATE
+// SEQUENCE statements.
 var crdbInternalCreateStmtsTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE crdb_internal.create_statements (
@@ -893,9 +899,13 @@ CREATE TABLE crdb_internal.create_statements (
 				var err error
 				var typeView = parser.DString("view")
 				var typeTable = parser.DString("table")
+				var typeSequence = parser.DString("sequence")
 				if table.IsView() {
 					descType = &typeView
 					stmt, err = p.showCreateView(ctx, parser.Name(table.Name), table)
+				} else if table.IsSequence() {
+					descType = &typeSequence
+					stmt, err = p.showCreateSequence(ctx, parser.Name(table.Name), table)
 				} else {
 					descType = &typeTable
 					stmt, err = p.showCreateTable(ctx, parser.Name(table.Name), prefix, table)
@@ -50,6 +50,11 @@ func (p *planner) CreateDatabase(n *parser.CreateDatabase) (planNode, error) {
 		return nil, errEmptyDatabaseName
 	}
 
+	if isTempDatabaseName(string(n.Name)) {
+		return nil, pgerror.NewErrorf(pgerror.CodeReservedNameError,
+			"database names starting with %q are reserved for temporary tables", tempDatabasePrefix)
+	}
+
 	if tmpl := n.Template; tmpl != "" {
 		// See https://www.postgresql.org/docs/current/static/manage-ag-templatedbs.html
 		if !strings.EqualFold(tmpl, "template0") {
@@ -131,7 +136,7 @@ type createIndexNode struct {
 //   notes: postgres requires CREATE on the table.
 //          mysql requires INDEX on the table.
 func (p *planner) CreateIndex(ctx context.Context, n *parser.CreateIndex) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -167,11 +172,30 @@ func (n *createIndexNode) Start(params runParams) error {
 	if err := indexDesc.FillColumns(n.n.Columns); err != nil {
 		return err
 	}
+	if n.n.Interleave != nil && len(indexDesc.ColumnExpressions) > 0 {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"interleaved indexes on expressions are not supported")
+	}
+	if n.n.Predicate != nil {
+		indexDesc.Predicate = parser.Serialize(n.n.Predicate)
+		if err := n.tableDesc.ValidateIndexPredicate(indexDesc.Predicate); err != nil {
+			return err
+		}
+	}
+	// The values of the indexed expressions are stored in hidden columns
+	// added along with the index.
+	exprCols, err := n.tableDesc.MakeIndexExpressionColumns(&indexDesc)
+	if err != nil {
+		return err
+	}
 
 	mutationIdx := len(n.tableDesc.Mutations)
 	if err := n.tableDesc.AddIndexMutation(indexDesc, sqlbase.DescriptorMutation_ADD); err != nil {
 		return err
 	}
+	for _, col := range exprCols {
+		n.tableDesc.AddColumnMutation(col, sqlbase.DescriptorMutation_ADD)
+	}
 	if err := n.tableDesc.AllocateIDs(); err != nil {
 		return err
 	}
@@ -229,7 +253,9 @@ type userAuthInfo struct {
 }
 
 type createUserNode struct {
-	ifNotExists  bool
+	ifNotExists bool
+	// isRole is set for CREATE ROLE. Roles cannot have a password.
+	isRole       bool
 	rowsAffected int
 	userAuthInfo
 }
@@ -249,37 +275,43 @@ func (p *planner) getUserAuthInfo(nameE, passwordE parser.Expr, ctx string) (use
 	return userAuthInfo{name: name, password: password}, nil
 }
 
-// resolve returns the actual user name and (hashed) password.
-func (ua *userAuthInfo) resolve() (string, []byte, error) {
+// resolve returns the actual user name, (hashed) password and SCRAM
+// verifier of the password.
+func (ua *userAuthInfo) resolve() (string, []byte, []byte, error) {
 	name, err := ua.name()
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	if name == "" {
-		return "", nil, errNoUserNameSpecified
+		return "", nil, nil, errNoUserNameSpecified
 	}
 	normalizedUsername, err := NormalizeAndValidateUsername(name)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 
-	var hashedPassword []byte
+	var hashedPassword, scramVerifier []byte
 	if ua.password != nil {
 		resolvedPassword, err := ua.password()
 		if err != nil {
-			return "", nil, err
+			return "", nil, nil, err
 		}
 		if resolvedPassword == "" {
-			return "", nil, security.ErrEmptyPassword
+			return "", nil, nil, security.ErrEmptyPassword
 		}
 
 		hashedPassword, err = security.HashPassword(resolvedPassword)
 		if err != nil {
-			return "", nil, err
+			return "", nil, nil, err
 		}
+		verifier, err := security.MakeScramVerifier(resolvedPassword)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		scramVerifier = verifier.Encode()
 	}
 
-	return normalizedUsername, hashedPassword, nil
+	return normalizedUsername, hashedPassword, scramVerifier, nil
 }
 
 // CreateUser creates a user.
@@ -307,6 +339,30 @@ func (p *planner) CreateUser(ctx context.Context, n *parser.CreateUser) (planNod
 	}, nil
 }
 
+// CreateRole creates a role.
+// Privileges: INSERT on system.users.
+func (p *planner) CreateRole(ctx context.Context, n *parser.CreateRole) (planNode, error) {
+	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &parser.TableName{DatabaseName: "system", TableName: "users"})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(tDesc, privilege.INSERT); err != nil {
+		return nil, err
+	}
+
+	ua, err := p.getUserAuthInfo(n.Name, nil, "CREATE ROLE")
+	if err != nil {
+		return nil, err
+	}
+
+	return &createUserNode{
+		userAuthInfo: ua,
+		ifNotExists:  n.IfNotExists,
+		isRole:       true,
+	}, nil
+}
+
 const usernameHelp = "usernames are case insensitive, must start with a letter " +
 	"or underscore, may contain letters, digits or underscores, and must not exceed 63 characters"
 
@@ -332,7 +388,7 @@ func NormalizeAndValidateUsername(username string) (string, error) {
 var errNoUserNameSpecified = errors.New("no username specified")
 
 func (n *createUserNode) Start(params runParams) error {
-	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve()
+	normalizedUsername, hashedPassword, scramVerifier, err := n.userAuthInfo.resolve()
 	if err != nil {
 		return err
 	}
@@ -342,9 +398,11 @@ func (n *createUserNode) Start(params runParams) error {
 		params.ctx,
 		"create-user",
 		params.p.txn,
-		"INSERT INTO system.users VALUES ($1, $2);",
+		"INSERT INTO system.users VALUES ($1, $2, $3, $4);",
 		normalizedUsername,
 		hashedPassword,
+		n.isRole,
+		scramVerifier,
 	)
 	if err != nil {
 		if sqlbase.IsUniquenessConstraintViolationError(err) {
@@ -359,7 +417,7 @@ func (n *createUserNode) Start(params runParams) error {
 				n.rowsAffected = 0
 				return nil
 			}
-			err = errors.Errorf("user %s already exists", normalizedUsername)
+			err = errors.Errorf("%s %s already exists", n.kind(), normalizedUsername)
 		}
 		return err
 	} else if n.rowsAffected != 1 {
@@ -371,6 +429,15 @@ func (n *createUserNode) Start(params runParams) error {
 	return nil
 }
 
+// kind returns the kind of principal created by the node, for error
+// messages.
+func (n *createUserNode) kind() string {
+	if n.isRole {
+		return "role"
+	}
+	return "user"
+}
+
 func (n *createUserNode) FastPathResults() (int, bool) { return n.rowsAffected, true }
 func (*createUserNode) Next(runParams) (bool, error)   { return false, nil }
 func (*createUserNode) Close(context.Context)          {}
@@ -411,7 +478,7 @@ func (n *alterUserSetPasswordNode) FastPathResults() (int, bool) {
 }
 
 func (n *alterUserSetPasswordNode) Start(params runParams) error {
-	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve()
+	normalizedUsername, hashedPassword, scramVerifier, err := n.userAuthInfo.resolve()
 	if err != nil {
 		return err
 	}
@@ -421,9 +488,11 @@ func (n *alterUserSetPasswordNode) Start(params runParams) error {
 		params.ctx,
 		"create-user",
 		params.p.txn,
-		`UPDATE system.users SET "hashedPassword" = $2 WHERE username = $1`,
+		`UPDATE system.users SET "hashedPassword" = $2, "scramVerifier" = $3 `+
+			`WHERE username = $1 AND "isRole" = false`,
 		normalizedUsername,
 		hashedPassword,
+		scramVerifier,
 	)
 	if err != nil {
 		return err
@@ -513,6 +582,10 @@ func (p *planner) CreateView(ctx context.Context, n *parser.CreateView) (planNod
 			numColumns, util.Pluralize(int64(numColumns))))
 	}
 
+	if err := p.checkTempViewDependencies(ctx, dbDesc.ID, planDeps); err != nil {
+		return nil, err
+	}
+
 	log.VEventf(ctx, 2, "collected view dependencies:\n%s", planDeps.String())
 
 	return &createViewNode{
@@ -596,7 +669,7 @@ func (n *createViewNode) Start(params runParams) error {
 
 	// Log Create View event. This is an auditable log event and is
 	// recorded in the same transaction as the table descriptor update.
-	return MakeEventLogger(n.p.LeaseMgr()).InsertEventRecord(
+	if err := MakeEventLogger(n.p.LeaseMgr()).InsertEventRecord(
 		params.ctx,
 		n.p.txn,
 		EventLogCreateView,
@@ -607,7 +680,18 @@ func (n *createViewNode) Start(params runParams) error {
 			Statement string
 			User      string
 		}{n.n.Name.String(), n.n.String(), n.p.session.User},
-	)
+	); err != nil {
+		return err
+	}
+
+	if n.n.Materialized {
+		// The view is populated last, because in auto-commit mode the
+		// insertion also commits the transaction.
+		if _, err := populateMaterializedView(params, &n.n.Name, n.n.AsSource); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (n *createViewNode) Close(ctx context.Context) {}
@@ -626,28 +710,39 @@ type createTableNode struct {
 // Privileges: CREATE on database.
 //   Notes: postgres/mysql require CREATE on database.
 func (p *planner) CreateTable(ctx context.Context, n *parser.CreateTable) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := n.Table.Normalize()
 	if err != nil {
 		return nil, err
 	}
 
-	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), tn.Database())
-	if err != nil {
-		return nil, err
-	}
+	var dbDesc *sqlbase.DatabaseDescriptor
+	if n.Temporary {
+		// The temporary database is created, if need be, by Start.
+		if err := p.qualifyTempTableName(tn); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+			return nil, err
+		}
+		if isTempDatabaseName(tn.Database()) {
+			return nil, pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"cannot create relation in temporary database without TEMP")
+		}
 
-	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
-		return nil, err
+		dbDesc, err = MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), tn.Database())
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+			return nil, err
+		}
 	}
 
 	HoistConstraints(n)
-	for _, def := range n.Defs {
-		switch t := def.(type) {
-		case *parser.ForeignKeyConstraintTableDef:
-			if _, err := t.Table.NormalizeWithDatabaseName(p.session.Database); err != nil {
-				return nil, err
-			}
-		}
+	if err := p.checkTempTableReferences(ctx, n); err != nil {
+		return nil, err
 	}
 
 	var sourcePlan planNode
@@ -710,6 +805,14 @@ func HoistConstraints(n *parser.CreateTable) {
 }
 
 func (n *createTableNode) Start(params runParams) error {
+	if n.n.Temporary {
+		dbDesc, err := params.p.getOrCreateTempDatabase(params.ctx)
+		if err != nil {
+			return err
+		}
+		n.dbDesc = dbDesc
+	}
+
 	tKey := tableKey{parentID: n.dbDesc.ID, name: n.n.Table.TableName().Table()}
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
@@ -745,6 +848,11 @@ func (n *createTableNode) Start(params runParams) error {
 	if err != nil {
 		return err
 	}
+	for i := range desc.Columns {
+		if err := params.p.resolveColumnSequences(params.ctx, &desc.Columns[i]); err != nil {
+			return err
+		}
+	}
 
 	// We need to validate again after adding the FKs.
 	// Only validate the table because backreferences aren't created yet.
@@ -763,6 +871,9 @@ func (n *createTableNode) Start(params runParams) error {
 			return err
 		}
 	}
+	if err := params.p.updateSequenceDependents(params.ctx, &desc, nil); err != nil {
+		return err
+	}
 	if desc.Adding() {
 		params.p.notifySchemaChange(&desc, sqlbase.InvalidMutationID)
 	}
@@ -864,6 +975,10 @@ func matchesIndex(
 	if len(cols) > len(idx.ColumnIDs) || (exact && len(cols) != len(idx.ColumnIDs)) {
 		return false
 	}
+	// Partial indexes don't contain all the rows to check.
+	if idx.IsPartial() {
+		return false
+	}
 
 	for i := range cols {
 		if cols[i].ID != idx.ColumnIDs[i] {
@@ -998,15 +1113,10 @@ func resolveFK(
 		}
 	}
 
-	if d.Actions.Delete != parser.NoAction &&
-		d.Actions.Delete != parser.Restrict {
-		feature := fmt.Sprintf("unsupported: ON DELETE %s", d.Actions.Delete)
-		return pgerror.Unimplemented(feature, feature)
-	}
-	if d.Actions.Update != parser.NoAction &&
-		d.Actions.Update != parser.Restrict {
-		feature := fmt.Sprintf("unsupported: ON UPDATE %s", d.Actions.Update)
-		return pgerror.Unimplemented(feature, feature)
+	for _, action := range []parser.ReferenceAction{d.Actions.Delete, d.Actions.Update} {
+		if err := validateFKAction(tbl, action, srcCols); err != nil {
+			return err
+		}
 	}
 	ref := sqlbase.ForeignKeyReference{
 		Table:           target.ID,
@@ -1060,6 +1170,29 @@ func resolveFK(
 	return nil
 }
 
+// validateFKAction checks that the referencing columns of a foreign key can
+// be set by its cascading action.
+func validateFKAction(
+	tbl *sqlbase.TableDescriptor, action parser.ReferenceAction, srcCols []sqlbase.ColumnDescriptor,
+) error {
+	for _, col := range srcCols {
+		// A column being made NOT NULL by a mutation will reject NULL values
+		// once the mutation completes, whatever its current state.
+		nullable := col.Nullable && !tbl.HasNotNullMutation(col.ID)
+		switch {
+		case action == parser.SetNull && !nullable:
+			return pgerror.NewErrorf(pgerror.CodeInvalidForeignKeyError,
+				"cannot add a SET NULL cascading action on column %q which has a NOT NULL constraint",
+				col.Name)
+		case action == parser.SetDefault && !nullable && col.DefaultExpr == nil:
+			return pgerror.NewErrorf(pgerror.CodeInvalidForeignKeyError,
+				"cannot add a SET DEFAULT cascading action on column %q which has a NOT NULL constraint and no default",
+				col.Name)
+		}
+	}
+	return nil
+}
+
 // Adds an index to a table descriptor (that is in the process of being created)
 // that will support using `srcCols` as the referencing (src) side of an FK.
 func addIndexForFK(
@@ -1421,12 +1554,17 @@ func (n *createViewNode) makeViewTableDesc(
 ) (sqlbase.TableDescriptor, error) {
 	desc := initTableDescriptor(id, parentID, viewName, n.p.txn.OrigTimestamp(), privileges)
 	desc.ViewQuery = parser.AsStringWithFlags(n.n.AsSource, parser.FmtParsable)
+	desc.IsMaterializedView = n.n.Materialized
 	for i, colRes := range resultColumns {
 		colType, err := parser.DatumTypeToColumnType(colRes.Typ)
 		if err != nil {
 			return desc, err
 		}
 		columnTableDef := parser.ColumnTableDef{Name: parser.Name(colRes.Name), Type: colType}
+		if desc.IsMaterializedView {
+			// The stored results may contain NULLs.
+			columnTableDef.Nullable.Nullability = parser.SilentNull
+		}
 		if len(columnNames) > i {
 			columnTableDef.Name = columnNames[i]
 		}
@@ -1543,6 +1681,15 @@ func MakeTableDesc(
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				idx.Predicate = parser.Serialize(d.Predicate)
+				if err := desc.ValidateIndexPredicate(idx.Predicate); err != nil {
+					return desc, err
+				}
+			}
+			if err := addIndexExpressionColumns(&desc, &idx); err != nil {
+				return desc, err
+			}
 			if err := desc.AddIndex(idx, false); err != nil {
 				return desc, err
 			}
@@ -1558,6 +1705,15 @@ func MakeTableDesc(
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				idx.Predicate = parser.Serialize(d.Predicate)
+				if err := desc.ValidateIndexPredicate(idx.Predicate); err != nil {
+					return desc, err
+				}
+			}
+			if err := addIndexExpressionColumns(&desc, &idx); err != nil {
+				return desc, err
+			}
 			if err := desc.AddIndex(idx, d.PrimaryKey); err != nil {
 				return desc, err
 			}
@@ -1733,6 +1889,19 @@ func (d dummyColumnItem) ResolvedType() types.T {
 	return d.typ
 }
 
+// addIndexExpressionColumns adds to desc, a table being created, the hidden
+// columns holding the values of the expressions of idx.
+func addIndexExpressionColumns(desc *sqlbase.TableDescriptor, idx *sqlbase.IndexDescriptor) error {
+	cols, err := desc.MakeIndexExpressionColumns(idx)
+	if err != nil {
+		return err
+	}
+	for _, col := range cols {
+		desc.AddColumn(col)
+	}
+	return nil
+}
+
 func makeCheckConstraint(
 	desc sqlbase.TableDescriptor,
 	d *parser.CheckConstraintTableDef,
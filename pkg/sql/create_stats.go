@@ -0,0 +1,239 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+const (
+	// statsSampleSize is the number of rows sampled to build a histogram.
+	statsSampleSize = 10000
+	// statsHistogramBuckets is the maximum number of histogram buckets.
+	statsHistogramBuckets = 200
+)
+
+var statsIntType = sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+var statsBytesType = sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_BYTES}
+
+// sampleAggregatorResultTypes is the output schema of the sample
+// aggregator; see distsqlrun.SampleAggregatorSpec.
+var sampleAggregatorResultTypes = []sqlbase.ColumnType{
+	statsIntType,   // sketch index
+	statsIntType,   // row count
+	statsIntType,   // distinct count
+	statsIntType,   // NULL count
+	statsBytesType, // encoded histogram
+}
+
+// createStatsNode represents a CREATE STATISTICS statement.
+type createStatsNode struct {
+	n         *parser.CreateStats
+	desc      *sqlbase.TableDescriptor
+	columnIDs []sqlbase.ColumnID
+}
+
+// CreateStats computes statistics on a set of columns of a table and
+// stores them in system.table_statistics.
+// Privileges: SELECT on table.
+//   Notes: postgres requires the table owner for ANALYZE.
+func (p *planner) CreateStats(ctx context.Context, n *parser.CreateStats) (planNode, error) {
+	// The job tracking the collection is updated outside of the statement's
+	// transaction (see runStatementJob).
+	if !p.autoCommit {
+		return nil, pgerror.NewError(pgerror.CodeActiveSQLTransactionError,
+			"CREATE STATISTICS cannot run inside a transaction block")
+	}
+
+	tn, err := p.normalizeTableName(ctx, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+	// Materialized views store their rows like tables, so statistics can be
+	// collected on them.
+	desc, err := MustGetTableOrViewDesc(ctx, p.txn, p.getVirtualTabler(), tn, false /*allowAdding*/)
+	if err != nil {
+		return nil, err
+	}
+	if desc.IsVirtualTable() || desc.IsSequence() || (desc.IsView() && !desc.IsMaterializedView) {
+		return nil, sqlbase.NewWrongObjectTypeError(tn, "table")
+	}
+	if err := p.CheckPrivilege(desc, privilege.SELECT); err != nil {
+		return nil, err
+	}
+
+	columnIDs := make([]sqlbase.ColumnID, len(n.ColumnNames))
+	seen := make(map[sqlbase.ColumnID]struct{}, len(n.ColumnNames))
+	for i, name := range n.ColumnNames {
+		col, err := desc.FindActiveColumnByName(string(name))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[col.ID]; ok {
+			return nil, pgerror.NewErrorf(pgerror.CodeDuplicateColumnError,
+				"column %q specified more than once", col.Name)
+		}
+		seen[col.ID] = struct{}{}
+		columnIDs[i] = col.ID
+	}
+
+	return &createStatsNode{n: n, desc: desc, columnIDs: columnIDs}, nil
+}
+
+func (n *createStatsNode) Start(params runParams) error {
+	return params.p.runStatementJob(
+		params.ctx, n.n.String(), n.desc.ID,
+		jobs.CreateStatsDetails{TableID: n.desc.ID},
+		func() error { return n.createStats(params) },
+	)
+}
+
+// createStats runs a DistSQL flow which samples the table and computes the
+// statistics, and inserts the result into system.table_statistics.
+func (n *createStatsNode) createStats(params runParams) error {
+	ctx := params.ctx
+	p := params.p
+	dsp := p.session.distSQLPlanner
+
+	wantedColumns := make([]parser.ColumnID, len(n.columnIDs))
+	for i, id := range n.columnIDs {
+		wantedColumns[i] = parser.ColumnID(id)
+	}
+	scan := p.Scan()
+	if err := scan.initTable(p, n.desc, nil /* indexHints */, publicColumns, wantedColumns); err != nil {
+		return err
+	}
+	scan.spans = []roachpb.Span{n.desc.PrimaryIndexSpan()}
+
+	planCtx := dsp.newPlanningCtx(ctx, &p.evalCtx, p.txn)
+	plan, err := dsp.createTableReaders(&planCtx, scan, nil /* overrideResultColumns */)
+	if err != nil {
+		return err
+	}
+
+	// The statistic is computed on the tuple of all the requested columns.
+	// Histograms are only supported on a single column.
+	sketchColumns := make([]uint32, len(n.columnIDs))
+	for i, id := range n.columnIDs {
+		sketchColumns[i] = uint32(plan.planToStreamColMap[scan.colIdxMap[id]])
+	}
+	sketches := []distsqlrun.SketchSpec{{
+		SketchType:          distsqlrun.SketchType_HLL_V1,
+		Columns:             sketchColumns,
+		GenerateHistogram:   len(sketchColumns) == 1,
+		HistogramMaxBuckets: statsHistogramBuckets,
+	}}
+
+	// Each table reader feeds a local sampler; the samplers output the
+	// sampled rows followed by the rank, sketch index, row count, NULL count
+	// and sketch data columns (see distsqlrun.SamplerSpec).
+	samplerTypes := append([]sqlbase.ColumnType(nil), plan.ResultTypes...)
+	samplerTypes = append(samplerTypes,
+		statsIntType, statsIntType, statsIntType, statsIntType, statsBytesType,
+	)
+	plan.AddNoGroupingStage(
+		distsqlrun.ProcessorCoreUnion{Sampler: &distsqlrun.SamplerSpec{
+			Sketches:   sketches,
+			SampleSize: statsSampleSize,
+		}},
+		distsqlrun.PostProcessSpec{},
+		samplerTypes,
+		distsqlrun.Ordering{},
+	)
+	// The sample aggregator on the gateway combines the samples and sketches.
+	plan.AddSingleGroupStage(
+		dsp.nodeDesc.NodeID,
+		distsqlrun.ProcessorCoreUnion{SampleAggregator: &distsqlrun.SampleAggregatorSpec{
+			Sketches:   sketches,
+			SampleSize: statsSampleSize,
+		}},
+		distsqlrun.PostProcessSpec{},
+		sampleAggregatorResultTypes,
+	)
+	plan.planToStreamColMap = identityMapInPlace(make([]int, len(sampleAggregatorResultTypes)))
+	dsp.FinalizePlan(&planCtx, &plan)
+
+	ci := sqlbase.ColTypeInfoFromColTypes(sampleAggregatorResultTypes)
+	rows := sqlbase.NewRowContainer(*p.evalCtx.ActiveMemAcc, ci, 0)
+	defer rows.Close(ctx)
+	recv, err := makeDistSQLReceiver(
+		ctx,
+		NewRowResultWriter(parser.Rows, rows),
+		p.ExecCfg().RangeDescriptorCache,
+		p.ExecCfg().LeaseHolderCache,
+		p.txn,
+		func(ts hlc.Timestamp) {
+			_ = p.ExecCfg().Clock.Update(ts)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if err := dsp.Run(&planCtx, p.txn, &plan, &recv, p.evalCtx); err != nil {
+		return err
+	}
+	if recv.err != nil {
+		return recv.err
+	}
+	if rows.Len() != len(sketches) {
+		return errors.Errorf("expected %d statistics, got %d", len(sketches), rows.Len())
+	}
+
+	columnIDs := parser.NewDArray(types.Int)
+	for _, id := range n.columnIDs {
+		if err := columnIDs.Append(parser.NewDInt(parser.DInt(id))); err != nil {
+			return err
+		}
+	}
+	row := rows.At(0)
+	internalExecutor := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	_, err = internalExecutor.ExecuteStatementInTransaction(
+		ctx,
+		"insert-statistic",
+		p.txn,
+		`INSERT INTO system.table_statistics (
+					"tableID", name, "columnIDs", "rowCount", "distinctCount", "nullCount", histogram
+				) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		parser.NewDInt(parser.DInt(n.desc.ID)),
+		string(n.n.Name),
+		columnIDs,
+		row[1],
+		row[2],
+		row[3],
+		row[4],
+	)
+	if err != nil {
+		return err
+	}
+	if cache := p.ExecCfg().TableStatsCache; cache != nil {
+		cache.InvalidateTableStats(n.desc.ID)
+	}
+	return nil
+}
+
+func (*createStatsNode) Next(runParams) (bool, error) { return false, nil }
+func (*createStatsNode) Close(context.Context)        {}
+func (*createStatsNode) Values() parser.Datums        { return parser.Datums{} }
@@ -267,7 +267,7 @@ func (p *planner) getSources(
 		if err != nil {
 			return planDataSource{}, err
 		}
-		return p.makeJoin(ctx, "CROSS JOIN", left, right, nil)
+		return p.makeJoin(ctx, "CROSS JOIN", "" /* hint */, left, right, nil)
 	}
 }
 
@@ -377,6 +377,15 @@ func (p *planner) getDataSource(
 ) (planDataSource, error) {
 	switch t := src.(type) {
 	case *parser.NormalizableTableName:
+		// Is this perhaps the name of a common table expression?
+		cte, err := p.lookupCTE(t)
+		if err != nil {
+			return planDataSource{}, err
+		}
+		if cte != nil {
+			return p.getCTEDataSource(ctx, cte)
+		}
+
 		// Usual case: a table.
 		tn, err := p.QualifyWithDatabase(ctx, t)
 		if err != nil {
@@ -409,7 +418,7 @@ func (p *planner) getDataSource(
 		if err != nil {
 			return right, err
 		}
-		return p.makeJoin(ctx, t.Join, left, right, t.Cond)
+		return p.makeJoin(ctx, t.Join, p.pinnedHints.JoinHint(t), left, right, t.Cond)
 
 	case *parser.StatementSource:
 		plan, err := p.newPlan(ctx, t.Statement, nil)
@@ -430,8 +439,8 @@ func (p *planner) getDataSource(
 	case *parser.AliasedTableExpr:
 		// Alias clause: source AS alias(cols...)
 
-		if t.Hints != nil {
-			hints = t.Hints
+		if h := p.pinnedHints.IndexHints(t); h != nil {
+			hints = h
 		}
 
 		src, err := p.getDataSource(ctx, t.Expr, hints, scanVisibility, lockForUpdate)
@@ -465,6 +474,8 @@ func (p *planner) QualifyWithDatabase(
 		if err := p.searchAndQualifyDatabase(ctx, tn); err != nil {
 			return nil, err
 		}
+	} else if !p.session.canSeeTempDatabase(tn.Database()) {
+		return nil, errTempTablesOfOtherSession
 	}
 	return tn, nil
 }
@@ -617,18 +628,21 @@ func (p *planner) getPlanForDesc(
 	lockForUpdate bool,
 	wantedColumns []parser.ColumnID,
 ) (planDataSource, error) {
-	if desc.IsView() {
+	if desc.IsView() && !desc.IsMaterializedView {
 		if wantedColumns != nil {
 			return planDataSource{},
 				errors.Errorf("cannot specify an explicit column list when accessing a view by reference")
 		}
 		return p.getViewPlan(ctx, tn, desc)
-	} else if !desc.IsTable() {
+	} else if desc.IsSequence() {
+		return p.getSequenceSource(*tn, desc)
+	} else if !desc.IsTable() && !desc.IsMaterializedView {
 		return planDataSource{}, errors.Errorf(
 			"unexpected table descriptor of type %s for %q", desc.TypeName(), parser.ErrString(tn))
 	}
 
-	// This name designates a real table.
+	// This name designates a real table or a materialized view, whose
+	// contents are stored like those of a table.
 	scan := p.Scan()
 	if err := scan.initTable(p, desc, hints, scanVisibility, wantedColumns); err != nil {
 		return planDataSource{}, err
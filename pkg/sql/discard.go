@@ -42,9 +42,26 @@ func (p *planner) Discard(ctx context.Context, s *parser.Discard) (planNode, err
 
 		// DEALLOCATE ALL
 		p.session.PreparedStatements.DeleteAll(ctx)
+
+		// DISCARD TEMP
+		return p.discardTempTables(ctx)
+	case parser.DiscardModeTemp:
+		return p.discardTempTables(ctx)
 	default:
 		return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
 			"unknown mode for DISCARD: %d", s.Mode)
 	}
 	return &zeroNode{}, nil
 }
+
+// discardTempTables drops the temporary tables of the session.
+func (p *planner) discardTempTables(ctx context.Context) (planNode, error) {
+	if !p.session.hasTempTables {
+		return &zeroNode{}, nil
+	}
+	return p.DropDatabase(ctx, &parser.DropDatabase{
+		Name:         parser.Name(p.session.tempDatabaseName),
+		IfExists:     true,
+		DropBehavior: parser.DropCascade,
+	})
+}
@@ -287,6 +287,9 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return rec, nil
 
 	case *joinNode:
+		if n.lookup != nil {
+			return 0, newQueryNotSupportedError("lookup joins not supported yet")
+		}
 		if err := dsp.checkExpr(n.pred.onCond); err != nil {
 			return 0, err
 		}
@@ -1228,37 +1231,46 @@ func (dsp *DistSQLPlanner) selectRenders(p *physicalPlan, n *renderNode) {
 
 // addSorters adds sorters corresponding to a sortNode and updates the plan to
 // reflect the sort node.
+// addSortStage adds a stage of sorting processors to the plan, which sort
+// the results according to the given ordering. The results must already be
+// ordered on the first matchLen columns of the ordering.
+func (dsp *DistSQLPlanner) addSortStage(
+	p *physicalPlan, columnOrdering sqlbase.ColumnOrdering, matchLen int,
+) {
+	var ordering distsqlrun.Ordering
+	ordering.Columns = make([]distsqlrun.Ordering_Column, len(columnOrdering))
+	for i, o := range columnOrdering {
+		streamColIdx := p.planToStreamColMap[o.ColIdx]
+		if streamColIdx == -1 {
+			panic(fmt.Sprintf("column %d in sort ordering not available", o.ColIdx))
+		}
+		ordering.Columns[i].ColIdx = uint32(streamColIdx)
+		ordering.Columns[i].Direction = distsqlrun.Ordering_Column_ASC
+		if o.Direction == encoding.Descending {
+			ordering.Columns[i].Direction = distsqlrun.Ordering_Column_DESC
+		}
+	}
+
+	p.AddNoGroupingStage(
+		distsqlrun.ProcessorCoreUnion{
+			Sorter: &distsqlrun.SorterSpec{
+				OutputOrdering:   ordering,
+				OrderingMatchLen: uint32(matchLen),
+			},
+		},
+		distsqlrun.PostProcessSpec{},
+		p.ResultTypes,
+		ordering,
+	)
+}
+
 func (dsp *DistSQLPlanner) addSorters(p *physicalPlan, n *sortNode) {
 
 	matchLen := planPhysicalProps(n.plan).computeMatch(n.ordering)
 
 	if matchLen < len(n.ordering) {
 		// Sorting is needed; we add a stage of sorting processors.
-		var ordering distsqlrun.Ordering
-		ordering.Columns = make([]distsqlrun.Ordering_Column, len(n.ordering))
-		for i, o := range n.ordering {
-			streamColIdx := p.planToStreamColMap[o.ColIdx]
-			if streamColIdx == -1 {
-				panic(fmt.Sprintf("column %d in sort ordering not available", o.ColIdx))
-			}
-			ordering.Columns[i].ColIdx = uint32(streamColIdx)
-			ordering.Columns[i].Direction = distsqlrun.Ordering_Column_ASC
-			if o.Direction == encoding.Descending {
-				ordering.Columns[i].Direction = distsqlrun.Ordering_Column_DESC
-			}
-		}
-
-		p.AddNoGroupingStage(
-			distsqlrun.ProcessorCoreUnion{
-				Sorter: &distsqlrun.SorterSpec{
-					OutputOrdering:   ordering,
-					OrderingMatchLen: uint32(matchLen),
-				},
-			},
-			distsqlrun.PostProcessSpec{},
-			p.ResultTypes,
-			ordering,
-		)
+		dsp.addSortStage(p, n.ordering, matchLen)
 	}
 
 	if len(n.columns) != len(p.planToStreamColMap) {
@@ -1915,6 +1927,35 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 		return physicalPlan{}, err
 	}
 
+	// mergeOrdering is the ordering on the equality columns used by the merge
+	// joiner, if we use one; see joinNode.mergeJoinOrdering.
+	var mergeOrdering sqlbase.ColumnOrdering
+	numEq := len(n.pred.leftEqualityIndices)
+	switch {
+	case numEq == 0 || n.hint == parser.AstHash:
+
+	case n.hint == parser.AstMerge ||
+		(planMergeJoins.Get(&dsp.st.SV) && n.joinType == joinTypeInner && len(n.mergeJoinOrdering) > 0):
+		// Merge joins are used when requested, or for inner joins when the inputs
+		// are ordered on (some of) the equality columns, which is the case in
+		// particular for joins between indexes on the equality columns.
+		//
+		// TODO(radu): instead of sorting both sides, we could implement a hybrid
+		// hash/merge processor which implements merge logic on the columns we
+		// have an ordering on, and within each merge group uses a hashmap on the
+		// remaining columns.
+		mergeOrdering = n.mergeJoinOrdering
+		if len(mergeOrdering) < numEq {
+			// The inputs are not ordered on all the equality columns; complete
+			// the ordering and sort both sides. The sorts only need to order the
+			// rows within groups of the existing ordering.
+			mergeOrdering = completeEqualityOrdering(n.mergeJoinOrdering, numEq)
+			matchLen := len(n.mergeJoinOrdering)
+			dsp.addSortStage(&leftPlan, n.pred.leftOrdering(mergeOrdering), matchLen)
+			dsp.addSortStage(&rightPlan, n.pred.rightOrdering(mergeOrdering), matchLen)
+		}
+	}
+
 	var p physicalPlan
 	var leftRouters, rightRouters []distsqlplan.ProcessorIdx
 	p.PhysicalPlan, leftRouters, rightRouters = distsqlplan.MergePlans(
@@ -1951,7 +1992,7 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 	rightTypes := rightPlan.ResultTypes
 
 	// Set up the output columns.
-	if numEq := len(n.pred.leftEqualityIndices); numEq != 0 {
+	if numEq != 0 {
 		// TODO(radu): for now we run a join processor on every node that produces
 		// data for either source. In the future we should be smarter here.
 		seen := make(map[roachpb.NodeID]struct{})
@@ -1979,28 +2020,18 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 		for i, rightPlanCol := range n.pred.rightEqualityIndices {
 			rightEqCols[i] = uint32(rightPlan.planToStreamColMap[rightPlanCol])
 		}
-		if planMergeJoins.Get(&dsp.st.SV) && len(n.mergeJoinOrdering) > 0 &&
-			joinType == distsqlrun.JoinType_INNER {
-			// TODO(radu): we currently only use merge joins when we have an ordering on
-			// all equality columns. We should relax this by either:
-			//  - implementing a hybrid hash/merge processor which implements merge
-			//    logic on the columns we have an ordering on, and within each merge
-			//    group uses a hashmap on the remaining columns
-			//  - or: adding a sort processor to complete the order
-			if len(n.mergeJoinOrdering) == len(n.pred.leftEqualityIndices) {
-				// Excellent! We can use the merge joiner.
-				leftMergeOrd.Columns = make([]distsqlrun.Ordering_Column, len(n.mergeJoinOrdering))
-				rightMergeOrd.Columns = make([]distsqlrun.Ordering_Column, len(n.mergeJoinOrdering))
-				for i, c := range n.mergeJoinOrdering {
-					leftMergeOrd.Columns[i].ColIdx = leftEqCols[c.ColIdx]
-					rightMergeOrd.Columns[i].ColIdx = rightEqCols[c.ColIdx]
-					dir := distsqlrun.Ordering_Column_ASC
-					if c.Direction == encoding.Descending {
-						dir = distsqlrun.Ordering_Column_DESC
-					}
-					leftMergeOrd.Columns[i].Direction = dir
-					rightMergeOrd.Columns[i].Direction = dir
+		if mergeOrdering != nil {
+			leftMergeOrd.Columns = make([]distsqlrun.Ordering_Column, len(mergeOrdering))
+			rightMergeOrd.Columns = make([]distsqlrun.Ordering_Column, len(mergeOrdering))
+			for i, c := range mergeOrdering {
+				leftMergeOrd.Columns[i].ColIdx = leftEqCols[c.ColIdx]
+				rightMergeOrd.Columns[i].ColIdx = rightEqCols[c.ColIdx]
+				dir := distsqlrun.Ordering_Column_ASC
+				if c.Direction == encoding.Descending {
+					dir = distsqlrun.Ordering_Column_DESC
 				}
+				leftMergeOrd.Columns[i].Direction = dir
+				rightMergeOrd.Columns[i].Direction = dir
 			}
 		}
 	} else {
@@ -92,8 +92,17 @@ func (cb *columnBackfiller) init() error {
 		return err
 	}
 
+	// Columns added by ALTER COLUMN TYPE and the columns of index expressions
+	// are computed by the RowUpdater; they only need a placeholder value here.
+	converted := false
+	for i := range cb.added {
+		if desc.IsComputedColumn(cb.added[i]) {
+			converted = true
+		}
+	}
+
 	cb.updateCols = append(cb.added, cb.dropped...)
-	if len(cb.dropped) > 0 || len(defaultExprs) > 0 {
+	if len(cb.dropped) > 0 || len(defaultExprs) > 0 || converted {
 		// Populate default values.
 		cb.updateExprs = make([]parser.TypedExpr, len(cb.updateCols))
 		for j := range cb.added {
@@ -213,7 +222,8 @@ func (cb *columnBackfiller) runChunk(
 				if err != nil {
 					return sqlbase.NewInvalidSchemaDefinitionError(err)
 				}
-				if j < len(cb.added) && !cb.added[j].Nullable && val == parser.DNull {
+				if j < len(cb.added) && !cb.added[j].Nullable && val == parser.DNull &&
+					!tableDesc.IsComputedColumn(cb.added[j]) {
 					return sqlbase.NewNonNullViolationError(cb.added[j].Name)
 				}
 				updateValues[j] = val
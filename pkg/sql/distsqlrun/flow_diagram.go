@@ -252,6 +252,31 @@ func (s *SSTWriterSpec) summary() (string, []string) {
 	return "SSTWriter", []string{fmt.Sprintf("%s/%s", s.Destination, s.Name)}
 }
 
+func (s *SketchSpec) summary() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s(%s)", s.SketchType, colListStr(s.Columns))
+	if s.GenerateHistogram {
+		fmt.Fprintf(&buf, " histogram(%d)", s.HistogramMaxBuckets)
+	}
+	return buf.String()
+}
+
+func (s *SamplerSpec) summary() (string, []string) {
+	details := []string{fmt.Sprintf("SampleSize: %d", s.SampleSize)}
+	for _, sk := range s.Sketches {
+		details = append(details, sk.summary())
+	}
+	return "Sampler", details
+}
+
+func (s *SampleAggregatorSpec) summary() (string, []string) {
+	details := []string{fmt.Sprintf("SampleSize: %d", s.SampleSize)}
+	for _, sk := range s.Sketches {
+		details = append(details, sk.summary())
+	}
+	return "SampleAggregator", details
+}
+
 type diagramCell struct {
 	Title   string   `json:"title"`
 	Details []string `json:"details"`
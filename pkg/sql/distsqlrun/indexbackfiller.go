@@ -103,6 +103,15 @@ func (ib *indexBackfiller) init() error {
 			for i, col := range cols {
 				valNeededForCol[i] = valNeededForCol[i] || idx.ContainsColumnID(col.ID)
 			}
+			// The columns of the predicate of a partial index determine which
+			// rows it contains.
+			predCols, err := desc.IndexPredicateColumnIDs(idx)
+			if err != nil {
+				return err
+			}
+			for _, colID := range predCols {
+				valNeededForCol[ib.colIdxMap[colID]] = true
+			}
 		}
 	}
 
@@ -136,6 +145,10 @@ func (ib *indexBackfiller) runChunk(
 		added[i] = *m.GetIndex()
 	}
 	secondaryIndexEntries := make([]sqlbase.IndexEntry, len(mutations))
+	partialIndexes, err := sqlbase.MakePartialIndexFilter(&ib.spec.Table, added)
+	if err != nil {
+		return nil, err
+	}
 
 	buildIndexEntries := func(ctx context.Context, txn *client.Txn) ([]sqlbase.IndexEntry, error) {
 		entries := make([]sqlbase.IndexEntry, 0, chunkSize*int64(len(added)))
@@ -174,6 +187,19 @@ func (ib *indexBackfiller) runChunk(
 				ib.rowVals, secondaryIndexEntries); err != nil {
 				return nil, err
 			}
+			if !partialIndexes.Empty() {
+				if err := partialIndexes.Filter(
+					ib.colIdxMap, ib.rowVals, secondaryIndexEntries,
+				); err != nil {
+					return nil, err
+				}
+				for _, entry := range secondaryIndexEntries {
+					if entry.Key != nil {
+						entries = append(entries, entry)
+					}
+				}
+				continue
+			}
 			entries = append(entries, secondaryIndexEntries...)
 		}
 		return entries, nil
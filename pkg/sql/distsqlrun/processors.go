@@ -478,6 +478,18 @@ func newProcessor(
 		}
 		return NewSSTWriterProcessor(flowCtx, *core.SSTWriter, inputs[0], outputs[0])
 	}
+	if core.Sampler != nil {
+		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
+			return nil, err
+		}
+		return newSamplerProcessor(flowCtx, core.Sampler, inputs[0], post, outputs[0])
+	}
+	if core.SampleAggregator != nil {
+		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
+			return nil, err
+		}
+		return newSampleAggregator(flowCtx, core.SampleAggregator, inputs[0], post, outputs[0])
+	}
 	return nil, errors.Errorf("unsupported processor core %s", core)
 }
 
@@ -0,0 +1,260 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// sampleAggregator combines the results of multiple samplers and computes the
+// final statistics; see SampleAggregatorSpec.
+type sampleAggregator struct {
+	processorBase
+
+	flowCtx  *FlowCtx
+	input    RowSource
+	inTypes  []sqlbase.ColumnType
+	sr       stats.SampleReservoir
+	sketches []sketchInfo
+
+	// Input column indices for special columns.
+	rankCol      int
+	sketchIdxCol int
+	numRowsCol   int
+	numNullsCol  int
+	sketchCol    int
+}
+
+var _ Processor = &sampleAggregator{}
+
+// sampleAggregatorOutputTypes is the output schema of the sample aggregator;
+// see SampleAggregatorSpec.
+var sampleAggregatorOutputTypes = []sqlbase.ColumnType{
+	// The sketch index.
+	intType,
+	// The number of rows.
+	intType,
+	// The estimated number of distinct values.
+	intType,
+	// The number of NULL values.
+	intType,
+	// The encoded histogram.
+	bytesType,
+}
+
+func newSampleAggregator(
+	flowCtx *FlowCtx,
+	spec *SampleAggregatorSpec,
+	input RowSource,
+	post *PostProcessSpec,
+	output RowReceiver,
+) (*sampleAggregator, error) {
+	for _, s := range spec.Sketches {
+		if s.SketchType != SketchType_HLL_V1 {
+			return nil, errors.Errorf("unsupported sketch type %s", s.SketchType)
+		}
+		if len(s.Columns) == 0 {
+			return nil, errors.Errorf("no columns specified for sketch")
+		}
+		if s.GenerateHistogram && s.HistogramMaxBuckets == 0 {
+			return nil, errors.Errorf("histogram max buckets not specified")
+		}
+	}
+
+	inTypes := input.Types()
+	// The input schema is the sampled columns followed by the five columns
+	// added by the sampler.
+	if len(inTypes) < 5 {
+		return nil, errors.Errorf("invalid sampler output schema: %v", inTypes)
+	}
+	rankCol := len(inTypes) - 5
+	s := &sampleAggregator{
+		flowCtx:      flowCtx,
+		input:        input,
+		inTypes:      inTypes,
+		sketches:     make([]sketchInfo, len(spec.Sketches)),
+		rankCol:      rankCol,
+		sketchIdxCol: rankCol + 1,
+		numRowsCol:   rankCol + 2,
+		numNullsCol:  rankCol + 3,
+		sketchCol:    rankCol + 4,
+	}
+	for i := range spec.Sketches {
+		sketch, err := stats.NewHyperLogLog(stats.DefaultHLLPrecision)
+		if err != nil {
+			return nil, err
+		}
+		s.sketches[i] = sketchInfo{spec: spec.Sketches[i], sketch: sketch}
+	}
+	s.sr.Init(int(spec.SampleSize), inTypes[:rankCol])
+
+	if err := s.out.Init(post, sampleAggregatorOutputTypes, &flowCtx.EvalCtx, output); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Run is part of the Processor interface.
+func (s *sampleAggregator) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+
+	ctx = log.WithLogTag(ctx, "SampleAggregator", nil)
+	ctx, span := processorSpan(ctx, "sample aggregator")
+	defer tracing.FinishSpan(span)
+
+	earlyExit, err := s.mainLoop(ctx)
+	if err != nil {
+		DrainAndClose(ctx, s.out.output, err, s.input)
+	} else if !earlyExit {
+		sendTraceData(ctx, s.out.output)
+		s.input.ConsumerClosed()
+		s.out.Close()
+	}
+}
+
+func (s *sampleAggregator) mainLoop(ctx context.Context) (earlyExit bool, _ error) {
+	var da sqlbase.DatumAlloc
+	for {
+		row, meta := s.input.Next()
+		if !meta.Empty() {
+			if meta.Err != nil {
+				return false, meta.Err
+			}
+			if !emitHelper(ctx, &s.out, nil /* row */, meta, s.input) {
+				// No cleanup required; emitHelper() took care of it.
+				return true, nil
+			}
+			continue
+		}
+		if row == nil {
+			break
+		}
+
+		if !row[s.rankCol].IsNull() {
+			// This is a sampled row.
+			rank, err := s.decodeInt(row, s.rankCol, &da)
+			if err != nil {
+				return false, err
+			}
+			if err := s.sr.SampleRow(row[:s.rankCol], uint64(rank)); err != nil {
+				return false, err
+			}
+			continue
+		}
+		// This is a sketch row.
+		sketchIdx, err := s.decodeInt(row, s.sketchIdxCol, &da)
+		if err != nil {
+			return false, err
+		}
+		if sketchIdx < 0 || sketchIdx >= int64(len(s.sketches)) {
+			return false, errors.Errorf("invalid sketch index %d", sketchIdx)
+		}
+		sk := &s.sketches[sketchIdx]
+		numRows, err := s.decodeInt(row, s.numRowsCol, &da)
+		if err != nil {
+			return false, err
+		}
+		numNulls, err := s.decodeInt(row, s.numNullsCol, &da)
+		if err != nil {
+			return false, err
+		}
+		sk.numRows += numRows
+		sk.numNulls += numNulls
+
+		if err := row[s.sketchCol].EnsureDecoded(&s.inTypes[s.sketchCol], &da); err != nil {
+			return false, err
+		}
+		data, ok := row[s.sketchCol].Datum.(*parser.DBytes)
+		if !ok {
+			return false, errors.Errorf("invalid sketch data %s", row[s.sketchCol].Datum)
+		}
+		var other stats.HyperLogLog
+		if err := other.UnmarshalBinary([]byte(*data)); err != nil {
+			return false, err
+		}
+		if err := sk.sketch.Merge(&other); err != nil {
+			return false, err
+		}
+	}
+
+	// Emit one row per sketch.
+	for i, sk := range s.sketches {
+		histogram := parser.DNull
+		if sk.spec.GenerateHistogram {
+			h, err := s.generateHistogram(sk)
+			if err != nil {
+				return false, err
+			}
+			data, err := h.Marshal()
+			if err != nil {
+				return false, err
+			}
+			histogram = parser.NewDBytes(parser.DBytes(data))
+		}
+
+		row := sqlbase.EncDatumRow{
+			sqlbase.DatumToEncDatum(intType, parser.NewDInt(parser.DInt(i))),
+			sqlbase.DatumToEncDatum(intType, parser.NewDInt(parser.DInt(sk.numRows))),
+			sqlbase.DatumToEncDatum(intType, parser.NewDInt(parser.DInt(sk.sketch.Estimate()))),
+			sqlbase.DatumToEncDatum(intType, parser.NewDInt(parser.DInt(sk.numNulls))),
+			sqlbase.DatumToEncDatum(bytesType, histogram),
+		}
+		if !emitHelper(ctx, &s.out, row, ProducerMetadata{}, s.input) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// generateHistogram builds a histogram for the first column of the sketch
+// from the sampled rows.
+func (s *sampleAggregator) generateHistogram(sk sketchInfo) (stats.HistogramData, error) {
+	colIdx := sk.spec.Columns[0]
+	samples := s.sr.Get()
+	values := make(parser.Datums, 0, len(samples))
+	for _, sample := range samples {
+		// The sampled rows were decoded by the reservoir.
+		if d := sample.Row[colIdx].Datum; d != parser.DNull {
+			values = append(values, d)
+		}
+	}
+	return stats.EquiDepthHistogram(
+		&s.flowCtx.EvalCtx, values, sk.numRows-sk.numNulls, int(sk.spec.HistogramMaxBuckets),
+	)
+}
+
+func (s *sampleAggregator) decodeInt(
+	row sqlbase.EncDatumRow, col int, da *sqlbase.DatumAlloc,
+) (int64, error) {
+	if err := row[col].EnsureDecoded(&s.inTypes[col], da); err != nil {
+		return 0, err
+	}
+	d, ok := row[col].Datum.(*parser.DInt)
+	if !ok {
+		return 0, errors.Errorf("expected INT value in column %d, got %s", col, row[col].Datum)
+	}
+	return int64(*d), nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// sketchInfo contains the specification and run-time state for each sketch.
+type sketchInfo struct {
+	spec     SketchSpec
+	sketch   *stats.HyperLogLog
+	numNulls int64
+	numRows  int64
+}
+
+// samplerProcessor computes reservoir samples and cardinality sketches; see
+// SamplerSpec.
+type samplerProcessor struct {
+	processorBase
+
+	flowCtx  *FlowCtx
+	input    RowSource
+	sr       stats.SampleReservoir
+	sketches []sketchInfo
+	rng      *rand.Rand
+	outTypes []sqlbase.ColumnType
+	rowAlloc sqlbase.EncDatumRowAlloc
+
+	// Output column indices for special columns.
+	rankCol      int
+	sketchIdxCol int
+	numRowsCol   int
+	numNullsCol  int
+	sketchCol    int
+}
+
+var _ Processor = &samplerProcessor{}
+
+var intType = sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+var bytesType = sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_BYTES}
+
+// samplerOutputTypes returns the output schema of a sampler processor with
+// the given input schema; see SamplerSpec.
+func samplerOutputTypes(inTypes []sqlbase.ColumnType) []sqlbase.ColumnType {
+	outTypes := make([]sqlbase.ColumnType, 0, len(inTypes)+5)
+	outTypes = append(outTypes, inTypes...)
+	// An INT column for the rank of each row.
+	outTypes = append(outTypes, intType)
+	// An INT column indicating the sketch index.
+	outTypes = append(outTypes, intType)
+	// An INT column indicating the number of rows processed.
+	outTypes = append(outTypes, intType)
+	// An INT column indicating the number of rows that have a NULL in the first
+	// sketch column.
+	outTypes = append(outTypes, intType)
+	// A BYTES column with the sketch data.
+	outTypes = append(outTypes, bytesType)
+	return outTypes
+}
+
+func newSamplerProcessor(
+	flowCtx *FlowCtx, spec *SamplerSpec, input RowSource, post *PostProcessSpec, output RowReceiver,
+) (*samplerProcessor, error) {
+	for _, s := range spec.Sketches {
+		if s.SketchType != SketchType_HLL_V1 {
+			return nil, errors.Errorf("unsupported sketch type %s", s.SketchType)
+		}
+		if len(s.Columns) == 0 {
+			return nil, errors.Errorf("no columns specified for sketch")
+		}
+	}
+
+	s := &samplerProcessor{
+		flowCtx:  flowCtx,
+		input:    input,
+		sketches: make([]sketchInfo, len(spec.Sketches)),
+		rng:      rand.New(rand.NewSource(rand.Int63())),
+	}
+	for i := range spec.Sketches {
+		sketch, err := stats.NewHyperLogLog(stats.DefaultHLLPrecision)
+		if err != nil {
+			return nil, err
+		}
+		s.sketches[i] = sketchInfo{spec: spec.Sketches[i], sketch: sketch}
+	}
+
+	inTypes := input.Types()
+	s.sr.Init(int(spec.SampleSize), inTypes)
+
+	s.outTypes = samplerOutputTypes(inTypes)
+	s.rankCol = len(inTypes)
+	s.sketchIdxCol = len(inTypes) + 1
+	s.numRowsCol = len(inTypes) + 2
+	s.numNullsCol = len(inTypes) + 3
+	s.sketchCol = len(inTypes) + 4
+
+	if err := s.out.Init(post, s.outTypes, &flowCtx.EvalCtx, output); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Run is part of the Processor interface.
+func (s *samplerProcessor) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+
+	ctx = log.WithLogTag(ctx, "Sampler", nil)
+	ctx, span := processorSpan(ctx, "sampler")
+	defer tracing.FinishSpan(span)
+
+	earlyExit, err := s.mainLoop(ctx)
+	if err != nil {
+		DrainAndClose(ctx, s.out.output, err, s.input)
+	} else if !earlyExit {
+		sendTraceData(ctx, s.out.output)
+		s.input.ConsumerClosed()
+		s.out.Close()
+	}
+}
+
+func (s *samplerProcessor) mainLoop(ctx context.Context) (earlyExit bool, _ error) {
+	var da sqlbase.DatumAlloc
+	var buf []byte
+	inTypes := s.input.Types()
+	for {
+		row, meta := s.input.Next()
+		if !meta.Empty() {
+			if meta.Err != nil {
+				return false, meta.Err
+			}
+			if !emitHelper(ctx, &s.out, nil /* row */, meta, s.input) {
+				// No cleanup required; emitHelper() took care of it.
+				return true, nil
+			}
+			continue
+		}
+		if row == nil {
+			break
+		}
+
+		for i := range s.sketches {
+			sk := &s.sketches[i]
+			sk.numRows++
+			// Rows with a NULL in the first column are only counted; they are
+			// not added to the sketch.
+			if row[sk.spec.Columns[0]].IsNull() {
+				sk.numNulls++
+				continue
+			}
+			buf = buf[:0]
+			for _, col := range sk.spec.Columns {
+				var err error
+				buf, err = row[col].Encode(&inTypes[col], &da, sqlbase.DatumEncoding_ASCENDING_KEY, buf)
+				if err != nil {
+					return false, err
+				}
+			}
+			sk.sketch.InsertBytes(buf)
+		}
+
+		// Use Int63 so the rank fits in an INT column.
+		rank := uint64(s.rng.Int63())
+		if err := s.sr.SampleRow(row, rank); err != nil {
+			return false, err
+		}
+	}
+
+	// Emit the sampled rows.
+	for _, sample := range s.sr.Get() {
+		outRow := s.newNullRow()
+		copy(outRow, sample.Row)
+		outRow[s.rankCol] = sqlbase.DatumToEncDatum(
+			intType, parser.NewDInt(parser.DInt(sample.Rank)),
+		)
+		if !emitHelper(ctx, &s.out, outRow, ProducerMetadata{}, s.input) {
+			return true, nil
+		}
+	}
+	// Emit the sketch rows.
+	for i, sk := range s.sketches {
+		data, err := sk.sketch.MarshalBinary()
+		if err != nil {
+			return false, err
+		}
+		outRow := s.newNullRow()
+		outRow[s.sketchIdxCol] = sqlbase.DatumToEncDatum(intType, parser.NewDInt(parser.DInt(i)))
+		outRow[s.numRowsCol] = sqlbase.DatumToEncDatum(intType, parser.NewDInt(parser.DInt(sk.numRows)))
+		outRow[s.numNullsCol] = sqlbase.DatumToEncDatum(intType, parser.NewDInt(parser.DInt(sk.numNulls)))
+		outRow[s.sketchCol] = sqlbase.DatumToEncDatum(bytesType, parser.NewDBytes(parser.DBytes(data)))
+		if !emitHelper(ctx, &s.out, outRow, ProducerMetadata{}, s.input) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// newNullRow allocates an output row with all columns set to NULL. Emitted
+// rows are retained by the consumer, so each row is freshly allocated.
+func (s *samplerProcessor) newNullRow() sqlbase.EncDatumRow {
+	row := s.rowAlloc.AllocRow(len(s.outTypes))
+	for i := range row {
+		row[i] = sqlbase.DatumToEncDatum(s.outTypes[i], parser.DNull)
+	}
+	return row
+}
@@ -0,0 +1,171 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func drainRowBuffer(t *testing.T, rb *RowBuffer) sqlbase.EncDatumRows {
+	if !rb.ProducerClosed {
+		t.Fatalf("output RowReceiver not closed")
+	}
+	var res sqlbase.EncDatumRows
+	for {
+		row, meta := rb.Next()
+		if !meta.Empty() {
+			t.Fatalf("unexpected metadata: %v", meta)
+		}
+		if row == nil {
+			return res
+		}
+		res = append(res, row)
+	}
+}
+
+func TestSamplerAndAggregator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	evalCtx := parser.MakeTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+	flowCtx := FlowCtx{
+		Settings: cluster.MakeTestingClusterSettings(),
+		EvalCtx:  evalCtx,
+	}
+
+	// The input has two columns: the first has NULLs every tenth row and 90
+	// distinct values otherwise, and the second is unique.
+	const numRows = 10000
+	const numSamplers = 3
+	const sampleSize = 500
+	var inputs [numSamplers]sqlbase.EncDatumRows
+	numNulls := 0
+	for i := 0; i < numRows; i++ {
+		a := parser.Datum(parser.NewDInt(parser.DInt(i % 100)))
+		if i%10 == 3 {
+			a = parser.DNull
+			numNulls++
+		}
+		row := sqlbase.EncDatumRow{
+			sqlbase.DatumToEncDatum(intType, a),
+			sqlbase.DatumToEncDatum(intType, parser.NewDInt(parser.DInt(i))),
+		}
+		inputs[i%numSamplers] = append(inputs[i%numSamplers], row)
+	}
+
+	sketches := []SketchSpec{
+		{
+			SketchType:          SketchType_HLL_V1,
+			Columns:             []uint32{0},
+			GenerateHistogram:   true,
+			HistogramMaxBuckets: 4,
+		},
+		{
+			SketchType: SketchType_HLL_V1,
+			Columns:    []uint32{1},
+		},
+	}
+
+	// Run the samplers.
+	var samplerOutput sqlbase.EncDatumRows
+	for i := range inputs {
+		in := NewRowBuffer(twoIntCols, inputs[i], RowBufferArgs{})
+		out := &RowBuffer{}
+		spec := &SamplerSpec{Sketches: sketches, SampleSize: sampleSize}
+		s, err := newSamplerProcessor(&flowCtx, spec, in, &PostProcessSpec{}, out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Run(context.Background(), nil)
+		rows := drainRowBuffer(t, out)
+		// Each sampler outputs a full sample set plus one row per sketch.
+		if expected := sampleSize + len(sketches); len(rows) != expected {
+			t.Fatalf("expected %d rows from sampler, got %d", expected, len(rows))
+		}
+		samplerOutput = append(samplerOutput, rows...)
+	}
+
+	// Run the aggregator on the combined output.
+	in := NewRowBuffer(samplerOutputTypes(twoIntCols), samplerOutput, RowBufferArgs{})
+	out := &RowBuffer{}
+	spec := &SampleAggregatorSpec{Sketches: sketches, SampleSize: sampleSize}
+	agg, err := newSampleAggregator(&flowCtx, spec, in, &PostProcessSpec{}, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg.Run(context.Background(), nil)
+	rows := drainRowBuffer(t, out)
+	if len(rows) != len(sketches) {
+		t.Fatalf("expected %d rows, got %d", len(sketches), len(rows))
+	}
+
+	var da sqlbase.DatumAlloc
+	for i, row := range rows {
+		for j := range row {
+			if err := row[j].EnsureDecoded(&sampleAggregatorOutputTypes[j], &da); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if idx := int(*row[0].Datum.(*parser.DInt)); idx != i {
+			t.Errorf("expected sketch index %d, got %d", i, idx)
+		}
+		if rowCount := int(*row[1].Datum.(*parser.DInt)); rowCount != numRows {
+			t.Errorf("sketch %d: expected row count %d, got %d", i, numRows, rowCount)
+		}
+
+		expectedDistinct, expectedNulls := 90, numNulls
+		if i == 1 {
+			expectedDistinct, expectedNulls = numRows, 0
+		}
+		distinctCount := int(*row[2].Datum.(*parser.DInt))
+		if d := distinctCount - expectedDistinct; d < -expectedDistinct/20 || d > expectedDistinct/20 {
+			t.Errorf("sketch %d: expected distinct count ~%d, got %d", i, expectedDistinct, distinctCount)
+		}
+		if nullCount := int(*row[3].Datum.(*parser.DInt)); nullCount != expectedNulls {
+			t.Errorf("sketch %d: expected null count %d, got %d", i, expectedNulls, nullCount)
+		}
+
+		if !sketches[i].GenerateHistogram {
+			if row[4].Datum != parser.DNull {
+				t.Errorf("sketch %d: unexpected histogram", i)
+			}
+			continue
+		}
+		var h stats.HistogramData
+		if err := h.Unmarshal([]byte(*row[4].Datum.(*parser.DBytes))); err != nil {
+			t.Fatal(err)
+		}
+		if len(h.Buckets) != int(sketches[i].HistogramMaxBuckets) {
+			t.Errorf("expected %d buckets, got %d", sketches[i].HistogramMaxBuckets, len(h.Buckets))
+		}
+		// The bucket counts are scaled to the number of non-NULL rows; allow
+		// for rounding.
+		var total int64
+		for _, b := range h.Buckets {
+			total += b.NumEq + b.NumRange
+		}
+		if expected := int64(numRows - numNulls); total > expected || total < expected-2*int64(len(h.Buckets)) {
+			t.Errorf("expected histogram to cover %d rows, got %d", expected, total)
+		}
+	}
+}
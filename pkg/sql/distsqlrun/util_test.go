@@ -36,7 +36,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
-var intType = sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
 var boolType = sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_BOOL}
 var decType = sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_DECIMAL}
 var strType = sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_STRING}
@@ -169,6 +169,10 @@ func (n *dropDatabaseNode) Start(params runParams) error {
 				return err
 			}
 			tbNameStrings = append(tbNameStrings, cascadedViews...)
+		} else if tbDesc.IsSequence() {
+			if err := p.dropSequenceImpl(ctx, tbDesc, parser.DropCascade); err != nil {
+				return err
+			}
 		} else {
 			cascadedViews, err := p.dropTableImpl(ctx, tbDesc)
 			if err != nil {
@@ -404,6 +408,20 @@ func (p *planner) dropIndexByName(
 	if !found {
 		return fmt.Errorf("index %q in the middle of being added, try again later", idxName)
 	}
+	// The hidden columns holding the values of the indexed expressions are
+	// dropped along with the index.
+	for i, expr := range idx.ColumnExpressions {
+		if expr == "" {
+			continue
+		}
+		for j := range tableDesc.Columns {
+			if tableDesc.Columns[j].ID == idx.ColumnIDs[i] {
+				tableDesc.AddColumnMutation(tableDesc.Columns[j], sqlbase.DescriptorMutation_DROP)
+				tableDesc.Columns = append(tableDesc.Columns[:j], tableDesc.Columns[j+1:]...)
+				break
+			}
+		}
+	}
 
 	if err := tableDesc.Validate(ctx, p.txn); err != nil {
 		return err
@@ -461,7 +479,7 @@ func (p *planner) DropView(ctx context.Context, n *parser.DropView) (planNode, e
 		if err != nil {
 			return nil, err
 		}
-		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		if err := p.qualifyTableName(ctx, tn); err != nil {
 			return nil, err
 		}
 
@@ -476,7 +494,10 @@ func (p *planner) DropView(ctx context.Context, n *parser.DropView) (planNode, e
 			// View does not exist, but we want it to: error out.
 			return nil, sqlbase.NewUndefinedRelationError(tn)
 		}
-		if !droppedDesc.IsView() {
+		if !droppedDesc.IsView() || droppedDesc.IsMaterializedView != n.IsMaterialized {
+			if n.IsMaterialized {
+				return nil, sqlbase.NewWrongObjectTypeError(tn, "materialized view")
+			}
 			return nil, sqlbase.NewWrongObjectTypeError(tn, "view")
 		}
 
@@ -550,6 +571,83 @@ func (*dropViewNode) Next(runParams) (bool, error) { return false, nil }
 func (*dropViewNode) Close(context.Context)        {}
 func (*dropViewNode) Values() parser.Datums        { return parser.Datums{} }
 
+type dropSequenceNode struct {
+	n  *parser.DropSequence
+	td []*sqlbase.TableDescriptor
+}
+
+// DropSequence drops a sequence.
+// Privileges: DROP on sequence.
+//   Notes: postgres allows only the sequence owner to DROP a sequence.
+func (p *planner) DropSequence(ctx context.Context, n *parser.DropSequence) (planNode, error) {
+	td := make([]*sqlbase.TableDescriptor, 0, len(n.Names))
+	for _, name := range n.Names {
+		tn, err := name.NormalizeTableName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.qualifyTableName(ctx, tn); err != nil {
+			return nil, err
+		}
+
+		droppedDesc, err := p.dropTableOrViewPrepare(ctx, tn)
+		if err != nil {
+			return nil, err
+		}
+		if droppedDesc == nil {
+			if n.IfExists {
+				continue
+			}
+			// Sequence does not exist, but we want it to: error out.
+			return nil, sqlbase.NewUndefinedRelationError(tn)
+		}
+		if !droppedDesc.IsSequence() {
+			return nil, sqlbase.NewWrongObjectTypeError(tn, "sequence")
+		}
+		if err := p.canRemoveSequence(ctx, droppedDesc, n.DropBehavior); err != nil {
+			return nil, err
+		}
+
+		td = append(td, droppedDesc)
+	}
+
+	if len(td) == 0 {
+		return &zeroNode{}, nil
+	}
+	return &dropSequenceNode{n: n, td: td}, nil
+}
+
+func (n *dropSequenceNode) Start(params runParams) error {
+	ctx := params.ctx
+	for _, droppedDesc := range n.td {
+		if err := params.p.dropSequenceImpl(ctx, droppedDesc, n.n.DropBehavior); err != nil {
+			return err
+		}
+		// Log a Drop Sequence event for this sequence. This is an auditable log
+		// event and is recorded in the same transaction as the table descriptor
+		// update.
+		if err := MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+			ctx,
+			params.p.txn,
+			EventLogDropSequence,
+			int32(droppedDesc.ID),
+			int32(params.p.evalCtx.NodeID),
+			struct {
+				SequenceName string
+				Statement    string
+				User         string
+			}{droppedDesc.Name, n.n.String(), params.p.session.User},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*dropSequenceNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropSequenceNode) Close(context.Context)        {}
+func (*dropSequenceNode) Values() parser.Datums        { return parser.Datums{} }
+
 type dropTableNode struct {
 	n  *parser.DropTable
 	td []*sqlbase.TableDescriptor
@@ -566,7 +664,7 @@ func (p *planner) DropTable(ctx context.Context, n *parser.DropTable) (planNode,
 		if err != nil {
 			return nil, err
 		}
-		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		if err := p.qualifyTableName(ctx, tn); err != nil {
 			return nil, err
 		}
 
@@ -843,6 +941,13 @@ func (p *planner) dropTableImpl(
 		}
 	}
 
+	// Remove the back-references from the sequences used by the table.
+	for _, seqID := range tableDesc.UsedSequenceIDs() {
+		if err := p.removeSequenceDependent(ctx, seqID, tableDesc.ID); err != nil {
+			return droppedViews, err
+		}
+	}
+
 	// Drop all views that depend on this table, assuming that we wouldn't have
 	// made it to this point if `cascade` wasn't enabled.
 	for _, ref := range tableDesc.DependedOnBy {
@@ -874,6 +979,93 @@ func (p *planner) dropTableImpl(
 	return droppedViews, nil
 }
 
+// canRemoveSequence returns an error if the sequence is used by the default
+// expression of a column and CASCADE wasn't specified.
+func (p *planner) canRemoveSequence(
+	ctx context.Context, seqDesc *sqlbase.TableDescriptor, behavior parser.DropBehavior,
+) error {
+	if len(seqDesc.SequenceDependents) == 0 || behavior == parser.DropCascade {
+		return nil
+	}
+	tableDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, seqDesc.SequenceDependents[0])
+	if err != nil {
+		return errors.Wrapf(err, "error resolving dependent table ID %d", seqDesc.SequenceDependents[0])
+	}
+	tableName, err := p.getQualifiedTableName(ctx, tableDesc)
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("cannot drop sequence %q because the default of a column of table %q depends on it",
+		seqDesc.Name, tableName)
+	hint := "use DROP SEQUENCE ... CASCADE to remove the column defaults too."
+	return sqlbase.NewDependentObjectErrorWithHint(msg, hint)
+}
+
+// dropSequenceImpl marks a sequence as dropped. Like for tables, the
+// sequence value is deleted asynchronously by the schema changer. With
+// CASCADE, the column defaults which use the sequence are removed.
+func (p *planner) dropSequenceImpl(
+	ctx context.Context, seqDesc *sqlbase.TableDescriptor, behavior parser.DropBehavior,
+) error {
+	if behavior == parser.DropCascade {
+		for _, tableID := range seqDesc.SequenceDependents {
+			if err := p.removeSequenceDefaults(ctx, tableID, seqDesc.ID); err != nil {
+				return err
+			}
+		}
+		seqDesc.SequenceDependents = nil
+	}
+	if err := p.initiateDropTable(ctx, seqDesc); err != nil {
+		return err
+	}
+
+	p.session.setTestingVerifyMetadata(func(systemConfig config.SystemConfig) error {
+		return verifyDropTableMetadata(systemConfig, seqDesc.ID, "sequence")
+	})
+	return nil
+}
+
+// removeSequenceDefaults removes the defaults of the columns of the table
+// with ID tableID which use the sequence with ID seqID.
+func (p *planner) removeSequenceDefaults(ctx context.Context, tableID, seqID sqlbase.ID) error {
+	tableDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, tableID)
+	if err != nil {
+		return errors.Errorf("error resolving dependent table ID %d: %v", tableID, err)
+	}
+	if tableDesc.Dropped() {
+		// The table is being dropped. No need to modify it further.
+		return nil
+	}
+	prevSeqIDs := tableDesc.UsedSequenceIDs()
+	removeDefault := func(col *sqlbase.ColumnDescriptor) {
+		if containsID(col.UsesSequenceIds, seqID) {
+			col.DefaultExpr = nil
+			col.UsesSequenceIds = nil
+		}
+	}
+	for i := range tableDesc.Columns {
+		removeDefault(&tableDesc.Columns[i])
+	}
+	for _, m := range tableDesc.Mutations {
+		if col := m.GetColumn(); col != nil {
+			removeDefault(col)
+		}
+	}
+	if err := p.saveNonmutationAndNotify(ctx, tableDesc); err != nil {
+		return err
+	}
+	// The removed defaults may also have used other sequences.
+	seqIDs := tableDesc.UsedSequenceIDs()
+	for _, id := range prevSeqIDs {
+		if id != seqID && !containsID(seqIDs, id) {
+			if err := p.removeSequenceDependent(ctx, id, tableID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (p *planner) initiateDropTable(ctx context.Context, tableDesc *sqlbase.TableDescriptor) error {
 	if err := tableDesc.SetUpVersion(); err != nil {
 		return err
@@ -1069,11 +1261,22 @@ func (p *planner) getViewDescForCascade(
 
 type dropUserNode struct {
 	ifExists bool
-	names    func() ([]string, error)
+	// isRole is set for DROP ROLE.
+	isRole bool
+	names  func() ([]string, error)
 	// The number of users deleted.
 	numDeleted int
 }
 
+// kind returns the kind of principal dropped by the node, for error
+// messages.
+func (n *dropUserNode) kind() string {
+	if n.isRole {
+		return "role"
+	}
+	return "user"
+}
+
 func (n *dropUserNode) Start(params runParams) error {
 	names, err := n.names()
 	if err != nil {
@@ -1131,8 +1334,8 @@ func (n *dropUserNode) Start(params runParams) error {
 			parser.Name(name).Format(&nameList, parser.FmtSimple)
 		}
 		return pgerror.NewErrorf(pgerror.CodeGroupingError,
-			"cannot drop user%s %s: grants still exist on %s",
-			util.Pluralize(int64(nameList.Len())), nameList.String(), usedBy.String(),
+			"cannot drop %s%s %s: grants still exist on %s",
+			n.kind(), util.Pluralize(int64(nameList.Len())), nameList.String(), usedBy.String(),
 		)
 	}
 
@@ -1149,21 +1352,35 @@ func (n *dropUserNode) Start(params runParams) error {
 			params.ctx,
 			"drop-user",
 			params.p.txn,
-			"DELETE FROM system.users WHERE username=$1",
+			`DELETE FROM system.users WHERE username=$1 AND "isRole" = $2`,
 			normalizedUsername,
+			n.isRole,
 		)
 		if err != nil {
 			return err
 		}
 
 		if rowsAffected == 0 && !n.ifExists {
-			return errors.Errorf("user %s does not exist", normalizedUsername)
+			return errors.Errorf("%s %s does not exist", n.kind(), normalizedUsername)
+		}
+
+		// Remove the memberships of the principal, and the members of the
+		// role.
+		if _, err := internalExecutor.ExecuteStatementInTransaction(
+			params.ctx,
+			"drop-user",
+			params.p.txn,
+			`DELETE FROM system.role_members WHERE role = $1 OR member = $1`,
+			normalizedUsername,
+		); err != nil {
+			return err
 		}
 
 		numDeleted += rowsAffected
 	}
 
 	n.numDeleted = numDeleted
+	params.p.session.roleMembers.clear(params.p.txn.OrigTimestamp())
 
 	return nil
 }
@@ -1195,3 +1412,27 @@ func (p *planner) DropUser(ctx context.Context, n *parser.DropUser) (planNode, e
 		names:    names,
 	}, nil
 }
+
+// DropRole drops a list of roles.
+// Privileges: DELETE on system.users.
+func (p *planner) DropRole(ctx context.Context, n *parser.DropRole) (planNode, error) {
+	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &parser.TableName{DatabaseName: "system", TableName: "users"})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(tDesc, privilege.DELETE); err != nil {
+		return nil, err
+	}
+
+	names, err := p.TypeAsStringArray(n.Names, "DROP ROLE")
+	if err != nil {
+		return nil, err
+	}
+
+	return &dropUserNode{
+		ifExists: n.IfExists,
+		isRole:   true,
+		names:    names,
+	}, nil
+}
@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -238,6 +239,12 @@ type Executor struct {
 	databaseCache    atomic.Value
 	systemConfigMu   syncutil.Mutex
 	systemConfigCond *sync.Cond
+	// statementHints holds the *statementHintsCache built from the system
+	// config. It is updated like databaseCache.
+	statementHints atomic.Value
+	// roleMembers caches the role memberships. It is cleared whenever the
+	// system config changes.
+	roleMembers *roleMembershipCache
 
 	distSQLPlanner *DistSQLPlanner
 
@@ -286,6 +293,10 @@ type ExecutorConfig struct {
 	// Caches updated by DistSQL.
 	RangeDescriptorCache *kv.RangeDescriptorCache
 	LeaseHolderCache     *kv.LeaseHolderCache
+
+	// TableStatsCache caches the table statistics used by the planner. It
+	// can be nil, in which case no statistics are used.
+	TableStatsCache *stats.TableStatisticsCache
 }
 
 // Organization returns the value of cluster.organization.
@@ -376,6 +387,8 @@ func NewExecutor(cfg ExecutorConfig, stopper *stop.Stopper) *Executor {
 		stopper: stopper,
 		reCache: parser.NewRegexpCache(512),
 
+		roleMembers: newRoleMembershipCache(),
+
 		TxnBeginCount:      metric.NewCounter(MetaTxnBegin),
 		TxnCommitCount:     metric.NewCounter(MetaTxnCommit),
 		TxnAbortCount:      metric.NewCounter(MetaTxnAbort),
@@ -409,6 +422,7 @@ func (e *Executor) Start(
 	e.distSQLPlanner = dsp
 
 	e.databaseCache.Store(newDatabaseCache(e.systemConfig))
+	e.statementHints.Store(newStatementHintsCache(ctx, e.systemConfig))
 	e.systemConfigCond = sync.NewCond(&e.systemConfigMu)
 
 	gossipUpdateC := e.cfg.Gossip.RegisterSystemConfigChannel()
@@ -457,6 +471,8 @@ func (e *Executor) updateSystemConfig(cfg config.SystemConfig) {
 	e.systemConfig = cfg
 	// The database cache gets reset whenever the system config changes.
 	e.databaseCache.Store(newDatabaseCache(cfg))
+	e.statementHints.Store(newStatementHintsCache(e.AnnotateCtx(context.TODO()), cfg))
+	e.roleMembers.clear(systemConfigTimestamp(cfg))
 	e.systemConfigCond.Broadcast()
 }
 
@@ -469,6 +485,35 @@ func (e *Executor) getDatabaseCache() *databaseCache {
 	return nil
 }
 
+// getStatementHints returns the planner hints pinned to statements in the
+// latest system config.
+func (e *Executor) getStatementHints() *statementHintsCache {
+	if v := e.statementHints.Load(); v != nil {
+		return v.(*statementHintsCache)
+	}
+	return nil
+}
+
+// CancelQueryByKey cancels the queries of the session of the given node
+// with the given cancellation key, on behalf of a pgwire CancelRequest.
+// The request is forwarded to the node through the status server, and the
+// error it reports, if any, is returned.
+func (e *Executor) CancelQueryByKey(
+	ctx context.Context, nodeID roachpb.NodeID, cancelKey int32,
+) error {
+	response, err := e.cfg.StatusServer.CancelQueryByKey(ctx, &serverpb.CancelQueryByKeyRequest{
+		NodeId:    fmt.Sprintf("%d", nodeID),
+		CancelKey: cancelKey,
+	})
+	if err != nil {
+		return err
+	}
+	if !response.Cancelled && response.Error != "" {
+		return errors.New(response.Error)
+	}
+	return nil
+}
+
 // Prepare returns the result types of the given statement. pinfo may
 // contain partial type information for placeholders. Prepare will
 // populate the missing types. The PreparedStatement is returned (or
@@ -849,8 +894,10 @@ func (e *Executor) execParsed(
 		}
 
 		// Sanity check about not leaving KV txns open on errors (other than
-		// retriable errors).
-		if err != nil && txnState.mu.txn != nil && !txnState.mu.txn.IsFinalized() {
+		// retriable errors). In the Aborted state, the KV txn may have been kept
+		// open for ROLLBACK TO SAVEPOINT.
+		if err != nil && txnState.mu.txn != nil && !txnState.mu.txn.IsFinalized() &&
+			txnState.State() != Aborted {
 			if _, retryable := err.(*roachpb.HandledRetryableTxnError); !retryable {
 				log.Fatalf(session.Ctx(), "got a non-retryable error but the KV "+
 					"transaction is not finalized. TxnState: %s, err: %s\n"+
@@ -1310,6 +1357,7 @@ func (e *Executor) execSingleStatement(
 	if _, ok := stmt.AST.(*parser.ShowTransactionStatus); ok {
 		err = runShowTransactionState(session, res)
 	} else {
+		timer := startStatementTimer(session.StatementTimeout, queryMeta)
 		switch txnState.State() {
 		case Open, AutoRetry:
 			err = e.execStmtInOpenTxn(
@@ -1322,6 +1370,7 @@ func (e *Executor) execSingleStatement(
 		default:
 			panic(fmt.Sprintf("unexpected txn state: %s", txnState.State()))
 		}
+		err = timer.stop(err)
 
 		if (e.cfg.TestingKnobs.CheckStmtStringChange && false) ||
 			(e.cfg.TestingKnobs.StatementFilter != nil) {
@@ -1386,6 +1435,8 @@ func runShowTransactionState(session *Session, res StatementResult) error {
 // - COMMIT / ROLLBACK: aborts the current transaction.
 // - ROLLBACK TO SAVEPOINT / SAVEPOINT: reopens the current transaction,
 //   allowing it to be retried.
+// - ROLLBACK TO SAVEPOINT <name>: if the KV txn survived the error, undoes
+//   the writes performed since the savepoint and reopens the transaction.
 func (e *Executor) execStmtInAbortedTxn(
 	session *Session, stmt Statement, res StatementResult,
 ) error {
@@ -1406,8 +1457,10 @@ func (e *Executor) execStmtInAbortedTxn(
 			return transition.err
 		}
 		// Reset the state to allow new transactions to start.
-		// The KV txn has already been rolled back when we entered the Aborted state.
+		// The KV txn has already been rolled back when we entered the Aborted
+		// state, unless it was kept around for ROLLBACK TO SAVEPOINT.
 		// Note: postgres replies to COMMIT of failed txn with "ROLLBACK" too.
+		txnState.cleanupRetainedTxn(errors.New("transaction rolled back"), e)
 		txnState.resetStateAndTxn(NoTxn)
 		res.BeginResult((*parser.RollbackTransaction)(nil))
 		return res.CloseResult()
@@ -1424,11 +1477,22 @@ func (e *Executor) execStmtInAbortedTxn(
 		default:
 			panic("unreachable")
 		}
-		if err := parser.ValidateRestartCheckpoint(spName); err != nil {
-			if txnState.State() == RestartWait {
-				err = txnState.updateStateAndCleanupOnErr(err, e)
+		if !parser.IsRestartCheckpoint(spName) {
+			rollback, ok := s.(*parser.RollbackToSavepoint)
+			if !ok || txnState.State() == RestartWait || txnState.mu.txn == nil {
+				break
 			}
-			return err
+			// The KV txn survived the error that got us here, so we can undo the
+			// effects of the failed statements and continue using it.
+			if err := txnState.rollbackToSavepoint(rollback.Savepoint); err != nil {
+				return err
+			}
+			res.BeginResult((*parser.RollbackToSavepoint)(nil))
+			if err := res.CloseResult(); err != nil {
+				return err
+			}
+			txnState.SetState(Open)
+			return nil
 		}
 		if !txnState.retryIntent {
 			err := fmt.Errorf("SAVEPOINT %s has not been used", parser.RestartSavepointName)
@@ -1454,6 +1518,7 @@ func (e *Executor) execStmtInAbortedTxn(
 			// The old txn has already been rolled back; we start a new txn with the
 			// same sql timestamp and isolation as the current one.
 			curTs, curIso, curPri := txnState.sqlTimestamp, txnState.isolation, txnState.priority
+			txnState.cleanupRetainedTxn(errors.New("transaction restarted"), e)
 			txnState.finishSQLTxn(session)
 			txnState.resetForNewSQLTxn(
 				e, session,
@@ -1462,19 +1527,18 @@ func (e *Executor) execStmtInAbortedTxn(
 		}
 		// TODO(andrei/cdo): add a counter for user-directed retries.
 		return nil
-	default:
-		if txnState.State() == RestartWait {
-			err := sqlbase.NewTransactionAbortedError(
-				"Expected \"ROLLBACK TO SAVEPOINT COCKROACH_RESTART\"" /* customMsg */)
-			// If we were waiting for a restart, but the client failed to perform it,
-			// we'll cleanup the txn. The client is not respecting the protocol, so
-			// there seems to be little point in staying in RestartWait (plus,
-			// higher-level code asserts that we're only in RestartWait when returning
-			// retryable errors to the client).
-			return txnState.updateStateAndCleanupOnErr(err, e)
-		}
-		return sqlbase.NewTransactionAbortedError("" /* customMsg */)
 	}
+	if txnState.State() == RestartWait {
+		err := sqlbase.NewTransactionAbortedError(
+			"Expected \"ROLLBACK TO SAVEPOINT COCKROACH_RESTART\"" /* customMsg */)
+		// If we were waiting for a restart, but the client failed to perform it,
+		// we'll cleanup the txn. The client is not respecting the protocol, so
+		// there seems to be little point in staying in RestartWait (plus,
+		// higher-level code asserts that we're only in RestartWait when returning
+		// retryable errors to the client).
+		return txnState.updateStateAndCleanupOnErr(err, e)
+	}
+	return sqlbase.NewTransactionAbortedError("" /* customMsg */)
 }
 
 // execStmtInCommitWaitTxn executes a statement in a txn that's in state
@@ -1672,8 +1736,12 @@ func (e *Executor) execStmtInOpenTxn(
 		return nil
 
 	case *parser.ReleaseSavepoint:
-		if err := parser.ValidateRestartCheckpoint(s.Savepoint); err != nil {
-			return err
+		if !parser.IsRestartCheckpoint(s.Savepoint) {
+			if err := txnState.releaseSavepoint(s.Savepoint); err != nil {
+				return err
+			}
+			res.BeginResult((*parser.ReleaseSavepoint)(nil))
+			return res.CloseResult()
 		}
 		// ReleaseSavepoint is executed fully here; there's no planNode for it
 		// and a planner is not involved at all.
@@ -1692,8 +1760,16 @@ func (e *Executor) execStmtInOpenTxn(
 		return nil
 
 	case *parser.Savepoint:
-		if err := parser.ValidateRestartCheckpoint(s.Name); err != nil {
-			return err
+		if !parser.IsRestartCheckpoint(s.Name) {
+			// Note that Savepoint doesn't have a corresponding plan node.
+			// This here is all the execution there is.
+			txnState.savepoints = append(txnState.savepoints, sqlSavepoint{
+				name:              s.Name,
+				sp:                txnState.mu.txn.CreateSavepoint(),
+				schemaChangeCount: txnState.schemaChangeCount,
+			})
+			res.BeginResult((*parser.Savepoint)(nil))
+			return res.CloseResult()
 		}
 		// We want to disallow SAVEPOINTs to be issued after a transaction has
 		// started running. The client txn's statement count indicates how many
@@ -1709,8 +1785,12 @@ func (e *Executor) execStmtInOpenTxn(
 		return res.CloseResult()
 
 	case *parser.RollbackToSavepoint:
-		if err := parser.ValidateRestartCheckpoint(s.Savepoint); err != nil {
-			return err
+		if !parser.IsRestartCheckpoint(s.Savepoint) {
+			if err := txnState.rollbackToSavepoint(s.Savepoint); err != nil {
+				return err
+			}
+			res.BeginResult((*parser.RollbackToSavepoint)(nil))
+			return res.CloseResult()
 		}
 		if !txnState.retryIntent {
 			err := fmt.Errorf("SAVEPOINT %s has not been used", parser.RestartSavepointName)
@@ -1724,6 +1804,7 @@ func (e *Executor) execStmtInOpenTxn(
 
 		// Move the state to AutoRetry; we're morally beginning a new transaction.
 		txnState.SetState(AutoRetry)
+		txnState.savepoints = nil
 		// If commands have already been sent through the transaction,
 		// restart the client txn's proto to increment the epoch.
 		if txnState.mu.txn.CommandCount() > 0 {
@@ -1757,6 +1838,10 @@ func (e *Executor) execStmtInOpenTxn(
 		stmt.AnonymizedStr = ps.AnonymizedStr
 	}
 
+	if stmt.AST.StatementType() == parser.DDL {
+		txnState.schemaChangeCount++
+	}
+
 	var p *planner
 	runInParallel := parallelize && !txnState.implicitTxn
 	if runInParallel {
@@ -2202,6 +2287,9 @@ func (e *Executor) execStmtInParallel(
 	// send the mock result back to the client.
 	session.setQueryExecutionMode(stmt.queryID, false /* isDistributed */, true /* isParallel */)
 
+	// The statement timer of the caller is disarmed as soon as the statement
+	// is queued, so the statement gets its own timer once it starts running.
+	statementTimeout := session.StatementTimeout
 	if err := session.parallelizeQueue.Add(params, plan, func(plan planNode) error {
 		// TODO(andrei): this should really be a result writer implementation that
 		// does nothing.
@@ -2216,7 +2304,9 @@ func (e *Executor) execStmtInParallel(
 		}
 
 		planner.phaseTimes[plannerStartExecStmt] = timeutil.Now()
+		timer := startStatementTimer(statementTimeout, stmt.queryMeta)
 		err = e.execClassic(planner, plan, bufferedWriter)
+		err = timer.stop(err)
 		planner.phaseTimes[plannerEndExecStmt] = timeutil.Now()
 		e.recordStatementSummary(planner, stmt, false, 0, bufferedWriter, err)
 		if e.cfg.TestingKnobs.AfterExecute != nil {
@@ -2492,10 +2582,11 @@ func isAsOf(session *Session, stmt parser.Statement, max hlc.Timestamp) (*hlc.Ti
 	return &ts, err
 }
 
-// isSavepoint returns true if stmt is a SAVEPOINT statement.
-func isSavepoint(stmt Statement) bool {
-	_, isSavepoint := stmt.AST.(*parser.Savepoint)
-	return isSavepoint
+// isRestartSavepoint returns true if stmt is a "SAVEPOINT cockroach_restart"
+// statement.
+func isRestartSavepoint(stmt Statement) bool {
+	s, isSavepoint := stmt.AST.(*parser.Savepoint)
+	return isSavepoint && parser.IsRestartCheckpoint(s.Name)
 }
 
 // isBegin returns true if stmt is a BEGIN statement.
@@ -2510,11 +2601,11 @@ func isSetTransaction(stmt Statement) bool {
 	return isSet
 }
 
-// isRollbackToSavepoint returns true if stmt is a "ROLLBACK TO SAVEPOINT"
-// statement.
-func isRollbackToSavepoint(stmt Statement) bool {
-	_, isSet := stmt.AST.(*parser.RollbackToSavepoint)
-	return isSet
+// isRollbackToRestartSavepoint returns true if stmt is a "ROLLBACK TO SAVEPOINT
+// cockroach_restart" statement.
+func isRollbackToRestartSavepoint(stmt Statement) bool {
+	s, isSet := stmt.AST.(*parser.RollbackToSavepoint)
+	return isSet && parser.IsRestartCheckpoint(s.Savepoint)
 }
 
 // canStayInAutoRetryState returns true if the statement, by itself, should not
@@ -2533,11 +2624,11 @@ func isRollbackToSavepoint(stmt Statement) bool {
 // statements ran in the transaction.
 func canStayInAutoRetryState(stmt Statement) bool {
 	return isBegin(stmt) ||
-		isSavepoint(stmt) ||
+		isRestartSavepoint(stmt) ||
 		isSetTransaction(stmt) ||
 		// ROLLBACK TO SAVEPOINT does its own state transitions; if it leaves the
 		// transaction in the AutoRetriable state, don't mess with it.
-		isRollbackToSavepoint(stmt)
+		isRollbackToRestartSavepoint(stmt)
 }
 
 // convertToErrWithPGCode recognizes errs that should have SQL error codes to be
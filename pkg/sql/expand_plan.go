@@ -108,26 +108,58 @@ func doExpandPlan(
 		}
 		n.left, err = doExpandPlan(ctx, p, params, n.left)
 
+	case *recursiveCTENode:
+		n.initial, err = doExpandPlan(ctx, p, noParams, n.initial)
+		if err != nil {
+			return plan, err
+		}
+		n.recursive, err = doExpandPlan(ctx, p, noParams, n.recursive)
+
 	case *filterNode:
 		n.source.plan, err = doExpandPlan(ctx, p, params, n.source.plan)
 
 	case *joinNode:
-		n.left.plan, err = doExpandPlan(ctx, p, noParams, n.left.plan)
+		if n.isReorderable() && p.session.ReorderJoinsLimit > 0 {
+			// This is the root of a tree of inner joins; expand its data sources
+			// and search for a better join order.
+			plan, err = p.expandJoinTree(ctx, n)
+			break
+		}
+
+		leftParams, rightParams := noParams, noParams
+		if n.hint == parser.AstMerge || n.hint == parser.AstLookup {
+			if len(n.pred.leftEqualityIndices) == 0 {
+				// Merge and lookup joins need equality columns.
+				return plan, n.hintError()
+			}
+		}
+		switch n.hint {
+		case parser.AstMerge:
+			// Favor indexes that provide the orderings needed by the merge
+			// join on both sides.
+			leftParams.desiredOrdering = equalityOrdering(n.pred.leftEqualityIndices)
+			rightParams.desiredOrdering = equalityOrdering(n.pred.rightEqualityIndices)
+		case parser.AstLookup:
+			if err := n.chooseLookupIndex(); err != nil {
+				return plan, err
+			}
+		}
+
+		n.left.plan, err = doExpandPlan(ctx, p, leftParams, n.left.plan)
 		if err != nil {
 			return plan, err
 		}
-		n.right.plan, err = doExpandPlan(ctx, p, noParams, n.right.plan)
+		n.right.plan, err = doExpandPlan(ctx, p, rightParams, n.right.plan)
 		if err != nil {
 			return plan, err
 		}
+		if n.hint == parser.AstLookup {
+			if err := n.initLookup(); err != nil {
+				return plan, err
+			}
+		}
 
-		n.mergeJoinOrdering = computeMergeJoinOrdering(
-			planPhysicalProps(n.left.plan),
-			planPhysicalProps(n.right.plan),
-			n.pred.leftEqualityIndices,
-			n.pred.rightEqualityIndices,
-		)
-		n.props = n.joinOrdering()
+		n.computeOrderings()
 
 	case *ordinalityNode:
 		// There may be too many columns in the required ordering. Filter them.
@@ -253,6 +285,7 @@ func doExpandPlan(
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueryNode:
 	case *scrubNode:
@@ -262,10 +295,14 @@ func doExpandPlan(
 	case *createIndexNode:
 	case *createUserNode:
 	case *createViewNode:
+	case *createSequenceNode:
+	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *refreshMaterializedViewNode:
+	case *dropSequenceNode:
 	case *dropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -278,6 +315,7 @@ func doExpandPlan(
 	case *showRangesNode:
 	case *showFingerprintsNode:
 	case *scatterNode:
+	case *sequenceSelectNode:
 	case nil:
 
 	default:
@@ -467,6 +505,10 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 		n.right = p.simplifyOrderings(n.right, nil)
 		n.left = p.simplifyOrderings(n.left, nil)
 
+	case *recursiveCTENode:
+		n.initial = p.simplifyOrderings(n.initial, nil)
+		n.recursive = p.simplifyOrderings(n.recursive, nil)
+
 	case *filterNode:
 		n.source.plan = p.simplifyOrderings(n.source.plan, usefulOrdering)
 		n.computePhysicalProps(&p.evalCtx)
@@ -580,6 +622,7 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueryNode:
 	case *scrubNode:
@@ -589,10 +632,14 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *createIndexNode:
 	case *createUserNode:
 	case *createViewNode:
+	case *createSequenceNode:
+	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *refreshMaterializedViewNode:
+	case *dropSequenceNode:
 	case *dropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -605,6 +652,7 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *showRangesNode:
 	case *showFingerprintsNode:
 	case *scatterNode:
+	case *sequenceSelectNode:
 
 	default:
 		panic(fmt.Sprintf("unhandled node type: %T", plan))
@@ -254,6 +254,17 @@ func (p *planner) propagateFilters(
 			return plan, extraFilter, err
 		}
 
+	case *recursiveCTENode:
+		// A filter cannot be propagated into the terms of a recursive
+		// CTE, as this would change the rows fed back into the
+		// recursion.
+		if n.initial, err = p.triggerFilterPropagation(ctx, n.initial); err != nil {
+			return plan, extraFilter, err
+		}
+		if n.recursive, err = p.triggerFilterPropagation(ctx, n.recursive); err != nil {
+			return plan, extraFilter, err
+		}
+
 	case *createTableNode:
 		if n.n.As() {
 			if n.sourcePlan, err = p.triggerFilterPropagation(ctx, n.sourcePlan); err != nil {
@@ -311,6 +322,7 @@ func (p *planner) propagateFilters(
 		}
 
 	case *alterTableNode:
+	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueryNode:
 	case *scrubNode:
@@ -320,10 +332,14 @@ func (p *planner) propagateFilters(
 	case *createIndexNode:
 	case *createUserNode:
 	case *createViewNode:
+	case *createSequenceNode:
+	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *refreshMaterializedViewNode:
+	case *dropSequenceNode:
 	case *dropUserNode:
 	case *hookFnNode:
 	case *valueGenerator:
@@ -335,6 +351,7 @@ func (p *planner) propagateFilters(
 	case *showRangesNode:
 	case *showFingerprintsNode:
 	case *scatterNode:
+	case *sequenceSelectNode:
 
 	default:
 		panic(fmt.Sprintf("unhandled node type: %T", plan))
@@ -17,6 +17,7 @@ package sql
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"sort"
 
 	"github.com/pkg/errors"
@@ -24,6 +25,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -79,6 +82,9 @@ type analyzeOrderingFn func(indexProps physicalProps) (matchingCols, totalCols i
 //
 // If preferOrderMatching is true, we prefer an index that matches the desired
 // ordering completely, even if it is not a covering index.
+//
+// If the table has statistics, the cost of each candidate index is estimated
+// from the number of rows it is expected to scan instead.
 func (p *planner) selectIndex(
 	ctx context.Context, s *scanNode, analyzeOrdering analyzeOrderingFn, preferOrderMatching bool,
 ) (planNode, error) {
@@ -88,6 +94,8 @@ func (p *planner) selectIndex(
 		return s, nil
 	}
 
+	ts := p.getTableStats(ctx, s.desc)
+
 	if s.filter == nil && analyzeOrdering == nil && s.specifiedIndex == nil {
 		// No where-clause, no ordering, and no specified index.
 		s.initOrdering(0)
@@ -96,6 +104,12 @@ func (p *planner) selectIndex(
 		if err != nil {
 			return nil, errors.Wrapf(err, "table ID = %d, index ID = %d", s.desc.ID, s.index.ID)
 		}
+		if ts != nil {
+			c := indexInfo{desc: s.desc, index: s.index}
+			c.init(s)
+			c.estimateCost(ts)
+			s.setEstimate(&c)
+		}
 		return s, nil
 	}
 
@@ -120,6 +134,34 @@ func (p *planner) selectIndex(
 		}
 	}
 
+	// Partial indexes can only be used when the filter implies their
+	// predicate. This must be checked before the indexed expressions are
+	// replaced in the filter.
+	for i := 0; i < len(candidates); {
+		implied, err := p.partialIndexImplied(s, candidates[i].index)
+		if err != nil {
+			return nil, err
+		}
+		if implied {
+			i++
+			continue
+		}
+		if s.specifiedIndex != nil {
+			return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"index %q is a partial index whose predicate is not implied by the filter",
+				s.specifiedIndex.Name)
+		}
+		candidates = append(candidates[:i], candidates[i+1:]...)
+	}
+
+	if s.filter != nil {
+		// This must happen before the candidates are initialized, since the
+		// columns needed by the filter can change.
+		if err := p.replaceIndexedExpressions(s); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, c := range candidates {
 		c.init(s)
 	}
@@ -167,6 +209,14 @@ func (p *planner) selectIndex(
 		}
 	}
 
+	if ts != nil {
+		// Replace the heuristic costs derived from the shape of the constraints
+		// with costs derived from the estimated number of scanned rows.
+		for _, c := range candidates {
+			c.estimateCost(ts)
+		}
+	}
+
 	if s.noIndexJoin {
 		// Eliminate non-covering indexes. We do this after the check above for
 		// constant false filter.
@@ -202,8 +252,8 @@ func (p *planner) selectIndex(
 
 	if log.V(2) {
 		for i, c := range candidates {
-			log.Infof(ctx, "%d: selectIndex(%s): cost=%v constraints=%s reverse=%t",
-				i, c.index.Name, c.cost, c.constraints, c.reverse)
+			log.Infof(ctx, "%d: selectIndex(%s): cost=%v rows=%v constraints=%s reverse=%t",
+				i, c.index.Name, c.cost, c.estimatedRows, c.constraints, c.reverse)
 		}
 	}
 
@@ -244,6 +294,9 @@ func (p *planner) selectIndex(
 	s.filterVars.Rebind(s.filter, true, false)
 
 	s.reverse = c.reverse
+	if ts != nil {
+		s.setEstimate(c)
+	}
 
 	var plan planNode
 	if c.covering {
@@ -266,6 +319,133 @@ func (p *planner) selectIndex(
 	return plan, nil
 }
 
+// resolveIndexExpr resolves exprString, an expression or the predicate of
+// index, against the columns of s, so that it is formatted like the matching
+// parts of the filter of s. The expression is type checked and normalized.
+func (p *planner) resolveIndexExpr(
+	s *scanNode,
+	h *parser.IndexedVarHelper,
+	index *sqlbase.IndexDescriptor,
+	exprString string,
+	desired types.T,
+) (parser.TypedExpr, error) {
+	expr, err := parser.ParseExpr(exprString)
+	if err != nil {
+		return nil, err
+	}
+	expr, err = parser.SimpleVisit(expr, func(e parser.Expr) (error, bool, parser.Expr) {
+		vBase, ok := e.(parser.VarName)
+		if !ok {
+			return nil, true, e
+		}
+		v, err := vBase.NormalizeVarName()
+		if err != nil {
+			return err, false, nil
+		}
+		if c, ok := v.(*parser.ColumnItem); ok {
+			for k := range s.cols {
+				if s.cols[k].Name == string(c.ColumnName) {
+					return nil, false, h.IndexedVar(k)
+				}
+			}
+		}
+		return fmt.Errorf("invalid expression %s in index %q", exprString, index.Name), false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	typedExpr, err := parser.TypeCheck(expr, nil, desired)
+	if err != nil {
+		return nil, err
+	}
+	return p.evalCtx.NormalizeExpr(typedExpr)
+}
+
+// partialIndexImplied returns whether the filter of s implies the predicate
+// of index, in which case the index contains all the rows the scan can
+// return. Indexes which are not partial are always usable. The implication
+// is only recognized when each conjunct of the predicate is also a conjunct
+// of the filter.
+func (p *planner) partialIndexImplied(s *scanNode, index *sqlbase.IndexDescriptor) (bool, error) {
+	if !index.IsPartial() {
+		return true, nil
+	}
+	if s.filter == nil {
+		return false, nil
+	}
+	h := parser.MakeIndexedVarHelper(s, len(s.cols))
+	pred, err := p.resolveIndexExpr(s, &h, index, index.Predicate, types.Bool)
+	if err != nil {
+		return false, err
+	}
+	conjuncts := make(map[string]struct{})
+	for _, e := range splitAndExpr(&p.evalCtx, s.filter, nil) {
+		conjuncts[e.String()] = struct{}{}
+	}
+	for _, e := range splitAndExpr(&p.evalCtx, pred, nil) {
+		if e == parser.DBoolTrue {
+			continue
+		}
+		if _, ok := conjuncts[e.String()]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// replaceIndexedExpressions replaces, in the filter of s, the expressions
+// indexed by the expression indexes of the table with the hidden columns
+// holding their values, so that the filter can constrain these indexes.
+func (p *planner) replaceIndexedExpressions(s *scanNode) error {
+	var indexed map[string]int
+	h := parser.MakeIndexedVarHelper(s, len(s.cols))
+	for i := range s.desc.Indexes {
+		index := &s.desc.Indexes[i]
+		for j, exprString := range index.ColumnExpressions {
+			if exprString == "" {
+				continue
+			}
+			colIdx, ok := s.colIdxMap[index.ColumnIDs[j]]
+			if !ok {
+				continue
+			}
+			typedExpr, err := p.resolveIndexExpr(s, &h, index, exprString, types.Any)
+			if err != nil {
+				return err
+			}
+			if indexed == nil {
+				indexed = make(map[string]int)
+			}
+			indexed[typedExpr.String()] = colIdx
+		}
+	}
+	if indexed == nil {
+		return nil
+	}
+
+	filter, err := parser.SimpleVisit(s.filter, func(e parser.Expr) (error, bool, parser.Expr) {
+		switch e.(type) {
+		case *parser.IndexedVar, parser.Datum:
+			return nil, false, e
+		}
+		typedExpr, ok := e.(parser.TypedExpr)
+		if !ok {
+			return nil, true, e
+		}
+		colIdx, ok := indexed[typedExpr.String()]
+		if !ok || !typedExpr.ResolvedType().Equivalent(s.resultColumns[colIdx].Typ) {
+			return nil, true, e
+		}
+		s.valNeededForCol[colIdx] = true
+		return nil, false, s.filterVars.IndexedVar(colIdx)
+	})
+	if err != nil {
+		return err
+	}
+	s.filter = filter.(parser.TypedExpr)
+	return nil
+}
+
 type indexConstraint struct {
 	start *parser.ComparisonExpr
 	end   *parser.ComparisonExpr
@@ -345,25 +525,39 @@ type indexInfo struct {
 	covering    bool // Does the index cover the required IndexedVars?
 	reverse     bool
 	exactPrefix int
+	// estimatedRows is the number of rows the index scan is expected to
+	// produce; it is only set when the table has statistics.
+	estimatedRows float64
 }
 
 func (v *indexInfo) init(s *scanNode) {
 	v.covering = v.isCoveringIndex(s)
+	v.cost = v.costPerRow()
+}
 
-	// The base cost is the number of keys per row.
+// costPerRow returns the base cost of the index, which is the number of keys
+// read per row.
+func (v *indexInfo) costPerRow() float64 {
+	// The primary index contains 1 key per column plus the sentinel key per
+	// row.
+	primaryKeys := float64(1 + len(v.desc.Columns) - len(v.desc.PrimaryIndex.ColumnIDs))
 	if v.index == &v.desc.PrimaryIndex {
-		// The primary index contains 1 key per column plus the sentinel key per
-		// row.
-		v.cost = float64(1 + len(v.desc.Columns) - len(v.desc.PrimaryIndex.ColumnIDs))
-	} else {
-		v.cost = 1
-		if !v.covering {
-			v.cost += float64(1 + len(v.desc.Columns) - len(v.desc.PrimaryIndex.ColumnIDs))
-			// Non-covering indexes are significantly more expensive than covering
-			// indexes.
-			v.cost *= nonCoveringIndexPenalty
-		}
+		return primaryKeys
 	}
+	if v.covering {
+		return 1
+	}
+	// Non-covering indexes are significantly more expensive than covering
+	// indexes.
+	return (1 + primaryKeys) * nonCoveringIndexPenalty
+}
+
+// estimateCost sets the cost of the index to its base cost multiplied by the
+// number of rows it is expected to scan, as estimated from the table
+// statistics and the index constraints.
+func (v *indexInfo) estimateCost(ts *tableStats) {
+	v.estimatedRows = ts.rowCount * v.selectivity(ts)
+	v.cost = v.costPerRow() * math.Max(v.estimatedRows, 1)
 }
 
 // analyzeExprs examines the range map to determine the cost of using the
@@ -16,6 +16,7 @@ package sql
 
 import (
 	"sort"
+	"strconv"
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
@@ -305,10 +306,26 @@ CREATE TABLE information_schema.sequences (
     CYCLE_OPTION STRING NOT NULL DEFAULT 'NO'
 );`,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...parser.Datum) error) error {
-		// Sequences are not yet supported: #5811
-		// However, we support an empty information_schema table to enable
-		// clients to observe that there are no sequences.
-		return nil
+		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+			if !table.IsSequence() {
+				return nil
+			}
+			opts := table.SequenceOpts
+			return addRow(
+				defString,                     // catalog
+				parser.NewDString(db.Name),    // schema
+				parser.NewDString(table.Name), // name
+				parser.NewDString("INT"),      // type
+				parser.NewDInt(64),            // numeric precision
+				parser.NewDInt(2),             // numeric precision radix
+				parser.NewDInt(0),             // numeric scale
+				parser.NewDString(strconv.FormatInt(opts.Start, 10)),     // start value
+				parser.NewDString(strconv.FormatInt(opts.MinValue, 10)),  // min value
+				parser.NewDString(strconv.FormatInt(opts.MaxValue, 10)),  // max value
+				parser.NewDString(strconv.FormatInt(opts.Increment, 10)), // increment
+				noString, // cycle
+			)
+		})
 	},
 }
 
@@ -373,9 +390,15 @@ CREATE TABLE information_schema.statistics (
 
 				sequence := 1
 				for i, col := range index.ColumnNames {
-					// We add a row for each column of index.
+					// We add a row for each column of index. The rows of indexed
+					// expressions show the expressions instead of the hidden
+					// columns holding their values.
 					dir := dStringForIndexDirection(index.ColumnDirections[i])
-					if err := appendRow(index, col, sequence, dir, false, false); err != nil {
+					colName := col
+					if expr := index.ColumnExpression(i); expr != "" {
+						colName = expr
+					}
+					if err := appendRow(index, colName, sequence, dir, false, false); err != nil {
 						return err
 					}
 					sequence++
@@ -512,6 +535,7 @@ var (
 	tableTypeSystemView = parser.NewDString("SYSTEM VIEW")
 	tableTypeBaseTable  = parser.NewDString("BASE TABLE")
 	tableTypeView       = parser.NewDString("VIEW")
+	tableTypeSequence   = parser.NewDString("SEQUENCE")
 )
 
 var informationSchemaTablesTable = virtualSchemaTable{
@@ -530,6 +554,8 @@ CREATE TABLE information_schema.tables (
 				tableType = tableTypeSystemView
 			} else if table.IsView() {
 				tableType = tableTypeView
+			} else if table.IsSequence() {
+				tableType = tableTypeSequence
 			}
 			return addRow(
 				defString,                     // table_catalog
@@ -612,7 +638,7 @@ func forEachDatabaseDesc(
 
 	sort.Sort(sortedDBDescs(dbDescs))
 	for _, db := range dbDescs {
-		if userCanSeeDatabase(db, p.session.User) {
+		if userCanSeeDatabase(db, p.session.User) && p.session.canSeeTempDatabase(db.Name) {
 			if err := fn(db); err != nil {
 				return err
 			}
@@ -796,7 +822,8 @@ func forEachTableDescWithTableLookupInternal(
 	}
 	sort.Strings(dbNames)
 	for _, dbName := range dbNames {
-		if !isDatabaseVisible(dbName, prefix, p.session.User) {
+		if !isDatabaseVisible(dbName, prefix, p.session.User) ||
+			!p.session.canSeeTempDatabase(dbName) {
 			continue
 		}
 		db := databases[dbName]
@@ -820,7 +847,9 @@ func forEachTableDescWithTableLookupInternal(
 func forEachIndexInTable(
 	table *sqlbase.TableDescriptor, fn func(*sqlbase.IndexDescriptor) error,
 ) error {
-	if table.IsPhysicalTable() {
+	// The primary index of a sequence is an implementation detail and is
+	// not exposed.
+	if table.IsPhysicalTable() && !table.IsSequence() {
 		if err := fn(&table.PrimaryIndex); err != nil {
 			return err
 		}
@@ -866,8 +895,11 @@ func forEachColumnInIndex(
 	return nil
 }
 
-func forEachUser(ctx context.Context, origPlanner *planner, fn func(username string) error) error {
-	query := `SELECT username FROM system.users`
+// forEachUser calls fn on every user and role.
+func forEachUser(
+	ctx context.Context, origPlanner *planner, fn func(username string, isRole bool) error,
+) error {
+	query := `SELECT username, "isRole" FROM system.users`
 	p := makeInternalPlanner("for-each-user", origPlanner.txn, security.RootUser, origPlanner.session.memMetrics)
 	defer finishInternalPlanner(p)
 	rows, err := p.queryRows(ctx, query)
@@ -877,13 +909,14 @@ func forEachUser(ctx context.Context, origPlanner *planner, fn func(username str
 
 	// TODO(cuongdo/asubiotto): Get rid of root user special-casing if/when a row
 	// for "root" exists in system.user.
-	if err := fn(security.RootUser); err != nil {
+	if err := fn(security.RootUser, false); err != nil {
 		return err
 	}
 
 	for _, row := range rows {
 		username := parser.MustBeDString(row[0])
-		if err := fn(string(username)); err != nil {
+		isRole := *row[1].(*parser.DBool)
+		if err := fn(string(username), bool(isRole)); err != nil {
 			return err
 		}
 	}
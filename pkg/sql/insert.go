@@ -78,7 +78,15 @@ type insertNode struct {
 func (p *planner) Insert(
 	ctx context.Context, n *parser.Insert, desiredTypes []types.T,
 ) (planNode, error) {
-	tn, err := p.getAliasedTableName(n.Table)
+	resetter, err := p.initWith(ctx, n.With)
+	if err != nil {
+		return nil, err
+	}
+	if resetter != nil {
+		defer resetter(p)
+	}
+
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -453,7 +461,7 @@ func GenerateInsertRow(
 
 	// Check to see if NULL is being inserted into any non-nullable column.
 	for _, col := range tableDesc.Columns {
-		if !col.Nullable {
+		if tableDesc.EnforcesNotNull(&col) {
 			if i, ok := insertColIDtoRowIndex[col.ID]; !ok || rowVals[i] == parser.DNull {
 				return nil, sqlbase.NewNonNullViolationError(col.Name)
 			}
@@ -67,6 +67,8 @@ type Details interface{}
 var _ Details = BackupDetails{}
 var _ Details = RestoreDetails{}
 var _ Details = SchemaChangeDetails{}
+var _ Details = RefreshMaterializedViewDetails{}
+var _ Details = CreateStatsDetails{}
 
 // Record stores the job fields that are not automatically managed by Job.
 type Record struct {
@@ -626,6 +628,10 @@ func (p *Payload) Type() Type {
 		return TypeSchemaChange
 	case *Payload_Import:
 		return TypeImport
+	case *Payload_RefreshMaterializedView:
+		return TypeRefreshMaterializedView
+	case *Payload_CreateStats:
+		return TypeCreateStats
 	default:
 		panic("Payload.Type called on a payload with an unknown details type")
 	}
@@ -648,6 +654,10 @@ func WrapPayloadDetails(details Details) interface {
 		return &Payload_SchemaChange{SchemaChange: &d}
 	case ImportDetails:
 		return &Payload_Import{Import: &d}
+	case RefreshMaterializedViewDetails:
+		return &Payload_RefreshMaterializedView{RefreshMaterializedView: &d}
+	case CreateStatsDetails:
+		return &Payload_CreateStats{CreateStats: &d}
 	default:
 		panic(fmt.Sprintf("jobs.WrapPayloadDetails: unknown details type %T", d))
 	}
@@ -669,6 +679,10 @@ func (p *Payload) UnwrapDetails() (Details, error) {
 		return *d.SchemaChange, nil
 	case *Payload_Import:
 		return *d.Import, nil
+	case *Payload_RefreshMaterializedView:
+		return *d.RefreshMaterializedView, nil
+	case *Payload_CreateStats:
+		return *d.CreateStats, nil
 	default:
 		return nil, errors.Errorf("jobs.Payload: unsupported details type %T", d)
 	}
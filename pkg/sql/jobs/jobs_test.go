@@ -403,6 +403,8 @@ func TestJobLifecycle(t *testing.T) {
 		}{
 			{jobs.TypeSchemaChange, jobs.SchemaChangeDetails{}, "schema change"},
 			{jobs.TypeImport, jobs.ImportDetails{}, "import"},
+			{jobs.TypeRefreshMaterializedView, jobs.RefreshMaterializedViewDetails{}, "refresh materialized view"},
+			{jobs.TypeCreateStats, jobs.CreateStatsDetails{}, "create stats"},
 		}
 		for _, tc := range testCases {
 			job, _ := createJob(tc.typ, jobs.WithoutCancel, jobs.Record{
@@ -22,9 +22,11 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 )
 
 type joinType int
@@ -133,6 +135,14 @@ type joinNode struct {
 	// pred represents the join predicate.
 	pred *joinPredicate
 
+	// hint is the join algorithm requested by the query, if any. It is one of
+	// parser.AstHash, parser.AstMerge or parser.AstLookup.
+	hint string
+
+	// lookup is set during expandPlan for joins executed as lookup joins. See
+	// join_lookup.go.
+	lookup *lookupJoin
+
 	// mergeJoinOrdering is set during expandPlan if the left and right sides have
 	// similar ordering on the equality columns (or a subset of them). The column
 	// indices refer to equality columns: a ColIdx of i refers to left column
@@ -171,6 +181,40 @@ type joinNode struct {
 	finishedOutput bool
 }
 
+// hintError returns the error reported when the join cannot be executed as
+// requested by its hint.
+func (n *joinNode) hintError() error {
+	return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+		"could not produce a query plan conforming to the %s JOIN hint", n.hint)
+}
+
+// equalityOrdering returns an ascending ordering on the given equality
+// columns.
+func equalityOrdering(cols []int) sqlbase.ColumnOrdering {
+	ord := make(sqlbase.ColumnOrdering, len(cols))
+	for i, c := range cols {
+		ord[i] = sqlbase.ColumnOrderInfo{ColIdx: c, Direction: encoding.Ascending}
+	}
+	return ord
+}
+
+// completeEqualityOrdering extends an ordering on some of the numEq
+// equality columns of a join (see joinNode.mergeJoinOrdering) into an
+// ordering on all of them.
+func completeEqualityOrdering(ord sqlbase.ColumnOrdering, numEq int) sqlbase.ColumnOrdering {
+	res := append(sqlbase.ColumnOrdering(nil), ord...)
+	var seen util.FastIntSet
+	for _, o := range ord {
+		seen.Add(o.ColIdx)
+	}
+	for i := 0; i < numEq; i++ {
+		if !seen.Contains(i) {
+			res = append(res, sqlbase.ColumnOrderInfo{ColIdx: i, Direction: encoding.Ascending})
+		}
+	}
+	return res
+}
+
 // commonColumns returns the names of columns common on the
 // right and left sides, for use by NATURAL JOIN.
 func commonColumns(left, right *dataSourceInfo) parser.NameList {
@@ -195,9 +239,12 @@ func commonColumns(left, right *dataSourceInfo) parser.NameList {
 // makeJoin constructs a planDataSource for a JOIN.
 // The source might be a joinNode, or it could be a renderNode on top of a
 // joinNode (in the case of outer natural joins).
+// The hint, if not empty, forces the join algorithm; see
+// parser.JoinTableExpr.Hint.
 func (p *planner) makeJoin(
 	ctx context.Context,
 	astJoinType string,
+	hint string,
 	left planDataSource,
 	right planDataSource,
 	cond parser.JoinCond,
@@ -215,6 +262,10 @@ func (p *planner) makeJoin(
 	default:
 		return planDataSource{}, errors.Errorf("unsupported JOIN type %T", astJoinType)
 	}
+	if hint == parser.AstLookup && typ != joinTypeInner && typ != joinTypeLeftOuter {
+		return planDataSource{}, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"%s JOIN hint is only supported for inner and left outer joins", hint)
+	}
 
 	leftInfo, rightInfo := left.info, right.info
 
@@ -266,6 +317,7 @@ func (p *planner) makeJoin(
 		right:    right,
 		joinType: typ,
 		pred:     pred,
+		hint:     hint,
 		columns:  info.sourceColumns,
 	}
 
@@ -471,7 +523,13 @@ func (n *joinNode) Start(params runParams) error {
 		return err
 	}
 
-	if err := n.hashJoinStart(params); err != nil {
+	if n.lookup != nil {
+		// The rows of the right side are looked up for each batch of left
+		// rows, see lookupJoin.nextLeftRow.
+		if err := n.lookup.start(params, n); err != nil {
+			return err
+		}
+	} else if err := n.hashJoinStart(params); err != nil {
 		return err
 	}
 
@@ -543,7 +601,7 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 	wantUnmatchedLeft := n.joinType == joinTypeLeftOuter || n.joinType == joinTypeFullOuter
 	wantUnmatchedRight := n.joinType == joinTypeRightOuter || n.joinType == joinTypeFullOuter
 
-	if len(n.buckets.Buckets()) == 0 {
+	if len(n.buckets.Buckets()) == 0 && n.lookup == nil {
 		if !wantUnmatchedLeft {
 			// No rows on right; don't even try.
 			return false, nil
@@ -557,15 +615,14 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 			return false, err
 		}
 
-		leftHasRow, err := n.left.plan.Next(params)
+		lrow, leftHasRow, err := n.nextLeftRow(params)
 		if err != nil {
-			return false, nil
+			return false, err
 		}
 		if !leftHasRow {
 			break
 		}
 
-		lrow := n.left.plan.Values()
 		encoding, containsNull, err := n.pred.encode(scratch, lrow, n.pred.leftEqualityIndices)
 		if err != nil {
 			return false, err
@@ -708,6 +765,18 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 	return n.buffer.Next(), nil
 }
 
+// nextLeftRow returns the next row of the left side.
+func (n *joinNode) nextLeftRow(params runParams) (parser.Datums, bool, error) {
+	if n.lookup != nil {
+		return n.lookup.nextLeftRow(params, n)
+	}
+	hasRow, err := n.left.plan.Next(params)
+	if err != nil || !hasRow {
+		return nil, false, err
+	}
+	return n.left.plan.Values(), true, nil
+}
+
 // Values implements the planNode interface.
 func (n *joinNode) Values() parser.Datums {
 	return n.buffer.Values()
@@ -715,13 +784,33 @@ func (n *joinNode) Values() parser.Datums {
 
 // Close implements the planNode interface.
 func (n *joinNode) Close(ctx context.Context) {
+	n.closeBuffers(ctx)
+	n.right.plan.Close(ctx)
+	n.left.plan.Close(ctx)
+}
+
+// closeBuffers releases the resources held by the join itself, but not those
+// of its sources.
+func (n *joinNode) closeBuffers(ctx context.Context) {
 	n.buffer.Close(ctx)
 	n.buffer = nil
 	n.buckets.Close(ctx)
 	n.bucketsMemAcc.Wtxn(n.planner.session).Close(ctx)
+	if n.lookup != nil {
+		n.lookup.close(ctx)
+	}
+}
 
-	n.right.plan.Close(ctx)
-	n.left.plan.Close(ctx)
+// computeOrderings initializes mergeJoinOrdering and the physical properties
+// of the join. The sources must have been expanded already.
+func (n *joinNode) computeOrderings() {
+	n.mergeJoinOrdering = computeMergeJoinOrdering(
+		planPhysicalProps(n.left.plan),
+		planPhysicalProps(n.right.plan),
+		n.pred.leftEqualityIndices,
+		n.pred.rightEqualityIndices,
+	)
+	n.props = n.joinOrdering()
 }
 
 func (n *joinNode) joinOrdering() physicalProps {
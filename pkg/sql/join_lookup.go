@@ -0,0 +1,277 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// lookupJoinBatchSize is the number of left rows for which the matching
+// right rows are looked up at once.
+const lookupJoinBatchSize = 100
+
+// lookupJoin contains the state of a joinNode executed as a lookup join, as
+// requested by the LOOKUP JOIN hint.
+//
+// Instead of loading all the rows of the right side in the hash table, the
+// rows of the left side are read in batches, and only the rows of the right
+// side that can match a batch are read, using an index of the right table
+// whose first columns are equality columns. The hash table is built from
+// these rows and probed as usual. This is much cheaper than a hash join when
+// the left side is small compared to the right side.
+type lookupJoin struct {
+	// scan is the right side of the join.
+	scan *scanNode
+
+	// leftCols contains, for each of the first len(leftCols) columns of the
+	// index scanned on the right side, the left column whose values are
+	// looked up in it.
+	leftCols []int
+	// colMap maps the IDs of the looked up index columns to their position
+	// in keyVals.
+	colMap    map[sqlbase.ColumnID]int
+	keyVals   parser.Datums
+	keyPrefix []byte
+
+	// scanSpans are the spans of the right side computed during index
+	// selection. The looked up spans are restricted to them.
+	scanSpans roachpb.Spans
+
+	// leftRows is the current batch of left rows, of which the first rowIdx
+	// have been returned already.
+	leftRows *sqlbase.RowContainer
+	rowIdx   int
+	// leftDone is set once all the rows of the left side have been read.
+	leftDone bool
+}
+
+// chooseLookupIndex is called before the expansion of a join hinted with
+// LOOKUP JOIN. It selects the index of the right table used to look up the
+// matching rows: this is the index specified by the query if any, otherwise
+// the covering index with the longest prefix of equality columns.
+func (n *joinNode) chooseLookupIndex() error {
+	scan, ok := n.right.plan.(*scanNode)
+	if !ok || scan.desc.IsEmpty() {
+		return n.hintError()
+	}
+	if scan.specifiedIndex != nil {
+		if len(n.lookupColumns(scan, scan.specifiedIndex)) == 0 {
+			return n.hintError()
+		}
+		return nil
+	}
+
+	var best *sqlbase.IndexDescriptor
+	bestCols := 0
+	consider := func(index *sqlbase.IndexDescriptor) {
+		if index.IsPartial() {
+			// Partial indexes may lack the rows to look up.
+			return
+		}
+		info := indexInfo{desc: scan.desc, index: index}
+		if !info.isCoveringIndex(scan) {
+			// Avoid an index join for each batch.
+			return
+		}
+		if cols := len(n.lookupColumns(scan, index)); cols > bestCols {
+			best, bestCols = index, cols
+		}
+	}
+	consider(&scan.desc.PrimaryIndex)
+	for i := range scan.desc.Indexes {
+		consider(&scan.desc.Indexes[i])
+	}
+	if best == nil {
+		return n.hintError()
+	}
+	scan.specifiedIndex = best
+	return nil
+}
+
+// lookupColumns returns the left columns whose values can be looked up in
+// the longest prefix of the given index of the right table.
+func (n *joinNode) lookupColumns(scan *scanNode, index *sqlbase.IndexDescriptor) []int {
+	leftCols := planColumns(n.left.plan)
+	rightCols := planColumns(scan)
+	var res []int
+	for _, colID := range index.ColumnIDs {
+		rightIdx, ok := scan.colIdxMap[colID]
+		if !ok {
+			break
+		}
+		found := false
+		for i, r := range n.pred.rightEqualityIndices {
+			l := n.pred.leftEqualityIndices[i]
+			// The values of the left column are encoded as keys of the right
+			// column; this requires them to have the same type.
+			if r == rightIdx && leftCols[l].Typ.Equivalent(rightCols[r].Typ) {
+				res = append(res, l)
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return res
+}
+
+// initLookup is called after the expansion of a join hinted with LOOKUP
+// JOIN. It prepares the lookups in the index selected by chooseLookupIndex.
+func (n *joinNode) initLookup() error {
+	var scan *scanNode
+	switch t := n.right.plan.(type) {
+	case *zeroNode:
+		// The right side is known to be empty; there is nothing to look up.
+		return nil
+	case *scanNode:
+		scan = t
+	default:
+		// For example, an index join if the specified index is not covering.
+		return n.hintError()
+	}
+	leftCols := n.lookupColumns(scan, scan.index)
+	if len(leftCols) == 0 {
+		return n.hintError()
+	}
+	colMap := make(map[sqlbase.ColumnID]int, len(leftCols))
+	for i := range leftCols {
+		colMap[scan.index.ColumnIDs[i]] = i
+	}
+	n.lookup = &lookupJoin{
+		scan:      scan,
+		leftCols:  leftCols,
+		colMap:    colMap,
+		keyVals:   make(parser.Datums, len(leftCols)),
+		keyPrefix: sqlbase.MakeIndexKeyPrefix(scan.desc, scan.index.ID),
+		// The spans of the scan are overwritten for each batch.
+		scanSpans: append(roachpb.Spans(nil), scan.spans...),
+	}
+	return nil
+}
+
+func (l *lookupJoin) start(params runParams, n *joinNode) error {
+	l.leftRows = sqlbase.NewRowContainer(
+		params.p.session.TxnState.makeBoundAccount(),
+		sqlbase.ColTypeInfoFromResCols(planColumns(n.left.plan)),
+		lookupJoinBatchSize,
+	)
+	return nil
+}
+
+// nextLeftRow returns the next row of the left side. When the current batch
+// is exhausted, a new batch is read and the hash table of the join is
+// rebuilt with the matching right rows.
+func (l *lookupJoin) nextLeftRow(params runParams, n *joinNode) (parser.Datums, bool, error) {
+	for l.rowIdx >= l.leftRows.Len() {
+		if l.leftDone {
+			return nil, false, nil
+		}
+		if err := l.nextBatch(params, n); err != nil {
+			return nil, false, err
+		}
+	}
+	row := l.leftRows.At(l.rowIdx)
+	l.rowIdx++
+	return row, true, nil
+}
+
+// nextBatch reads the next batch of left rows and loads the right rows that
+// can match them in the hash table.
+func (l *lookupJoin) nextBatch(params runParams, n *joinNode) error {
+	ctx := params.ctx
+	l.leftRows.Clear(ctx)
+	l.rowIdx = 0
+	l.scan.spans = l.scan.spans[:0]
+	seen := make(map[string]struct{})
+	for l.leftRows.Len() < lookupJoinBatchSize {
+		hasRow, err := n.left.plan.Next(params)
+		if err != nil {
+			return err
+		}
+		if !hasRow {
+			l.leftDone = true
+			break
+		}
+		row, err := l.leftRows.AddRow(ctx, n.left.plan.Values())
+		if err != nil {
+			return err
+		}
+		for i, c := range l.leftCols {
+			l.keyVals[i] = row[c]
+		}
+		key, containsNull, err := sqlbase.EncodePartialIndexKey(
+			l.scan.desc, l.scan.index, len(l.leftCols), l.colMap, l.keyVals, l.keyPrefix)
+		if err != nil {
+			return err
+		}
+		if containsNull {
+			// NULL never matches anything.
+			continue
+		}
+		if _, ok := seen[string(key)]; ok {
+			continue
+		}
+		seen[string(key)] = struct{}{}
+		lookupSpan := roachpb.Span{Key: key, EndKey: roachpb.Key(key).PrefixEnd()}
+		for _, sp := range l.scanSpans {
+			if s, ok := intersectSpans(lookupSpan, sp); ok {
+				l.scan.spans = append(l.scan.spans, s)
+			}
+		}
+	}
+
+	// Rebuild the hash table.
+	n.bucketsMemAcc.Wtxn(n.planner.session).Clear(ctx)
+	n.buckets.rowContainer.Clear(ctx)
+	n.buckets.buckets = make(map[string]*bucket)
+	if len(l.scan.spans) == 0 {
+		return nil
+	}
+	if log.V(3) {
+		log.Infof(ctx, "lookup join scan: %s", sqlbase.PrettySpans(l.scan.spans, 0))
+	}
+	l.scan.scanInitialized = false
+	return n.hashJoinStart(params)
+}
+
+func (l *lookupJoin) close(ctx context.Context) {
+	if l.leftRows != nil {
+		l.leftRows.Close(ctx)
+		l.leftRows = nil
+	}
+}
+
+// intersectSpans returns the intersection of two spans, and false if it is
+// empty.
+func intersectSpans(a, b roachpb.Span) (roachpb.Span, bool) {
+	res := a
+	if b.Key.Compare(res.Key) > 0 {
+		res.Key = b.Key
+	}
+	if b.EndKey.Compare(res.EndKey) < 0 {
+		res.EndKey = b.EndKey
+	}
+	if res.Key.Compare(res.EndKey) >= 0 {
+		return roachpb.Span{}, false
+	}
+	return res, true
+}
@@ -0,0 +1,616 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+	"math/bits"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+)
+
+// Join reordering
+//
+// A tree of inner joins (including cross joins) can be executed in any
+// order, as long as every predicate is applied by the first join which has
+// all the columns it refers to. expandJoinTree flattens such a tree into its
+// data sources and predicates, estimates the number of rows produced by
+// joining each subset of the sources, and picks the join order which
+// minimizes the sum of the sizes of the intermediate results:
+//
+//  - when the tree has at most reorder_joins_limit data sources, all the
+//    join orders are considered using dynamic programming over the subsets
+//    of sources;
+//  - otherwise, the tree is built greedily, by repeatedly joining the two
+//    subtrees which produce the smallest result.
+//
+// The original order is kept unless the best order is estimated to be
+// significantly cheaper, so that queries on tables without statistics keep
+// the plan written in the query. Equality predicates are turned into
+// equality columns of the new joins, which lets the DistSQL physical
+// planner choose between hash and merge joins.
+
+const (
+	// defaultReorderJoinsLimit is the default value of the
+	// reorder_joins_limit session variable.
+	defaultReorderJoinsLimit = 8
+	// maxReorderJoinsLimit bounds reorder_joins_limit; the exhaustive search
+	// takes time exponential in the number of data sources.
+	maxReorderJoinsLimit = 12
+	// maxJoinTreeSources is the maximum number of data sources in a tree of
+	// joins that can be reordered (see sourceSet).
+	maxJoinTreeSources = 64
+	// minJoinTreeSources is the minimum number of data sources in a tree of
+	// joins that is reordered. Reordering two data sources would only swap
+	// them.
+	minJoinTreeSources = 3
+	// defaultRowCount is the number of rows assumed for data sources whose
+	// size cannot be estimated.
+	defaultRowCount = 1000
+	// defaultFilterSelectivity is the selectivity assumed for join predicates
+	// other than equalities between columns.
+	defaultFilterSelectivity = 1.0 / 3
+	// joinReorderCostRatio is the maximum ratio between the estimated cost of
+	// a new join order and that of the original order for the joins to be
+	// reordered.
+	joinReorderCostRatio = 0.99
+)
+
+// isReorderable returns true if the join can be reordered with the joins
+// around it.
+func (n *joinNode) isReorderable() bool {
+	return n.joinType == joinTypeInner && n.hint == "" && n.lookup == nil
+}
+
+// sourceSet is a set of data sources of a joinTree, identified by their
+// index.
+type sourceSet uint64
+
+func (s sourceSet) contains(i int) bool       { return s&(1<<uint(i)) != 0 }
+func (s sourceSet) subsetOf(o sourceSet) bool { return s&^o == 0 }
+func (s sourceSet) len() int                  { return bits.OnesCount64(uint64(s)) }
+func (s sourceSet) first() int                { return bits.TrailingZeros64(uint64(s)) }
+
+// joinTree is a tree of inner joins flattened into its data sources and
+// predicates. The columns of the tree are the columns of its data sources, in
+// the order of the original joins.
+type joinTree struct {
+	// joins are the join nodes of the original tree, parents first.
+	joins []*joinNode
+	// orig is the shape of the original tree.
+	orig *joinOrder
+
+	// sources point to the data sources in the original join nodes.
+	sources []*planDataSource
+	// offsets[i] is the index of the first column of sources[i] among the
+	// columns of the tree.
+	offsets []int
+	// rows[i] is the estimated number of rows produced by sources[i].
+	rows []float64
+
+	edges   []joinEdge
+	filters []joinFilter
+
+	// rowCounts memoizes rowCount.
+	rowCounts map[sourceSet]float64
+}
+
+// joinEdge is an equality predicate between columns of two data sources.
+type joinEdge struct {
+	// leftCol and rightCol are columns of the tree, which belong to the data
+	// sources leftSrc and rightSrc.
+	leftCol, rightCol int
+	leftSrc, rightSrc int
+	selectivity       float64
+}
+
+// joinFilter is a join predicate other than an equality between columns.
+type joinFilter struct {
+	// expr is the predicate; its IndexedVars refer to the columns of the
+	// original join node, which are the columns of the tree starting at
+	// offset.
+	expr    parser.TypedExpr
+	offset  int
+	sources sourceSet
+}
+
+// joinOrder is a candidate tree of joins over a set of data sources.
+type joinOrder struct {
+	sources sourceSet
+	// left and right are nil for a single data source.
+	left, right *joinOrder
+	// rows is the estimated number of rows produced by the tree.
+	rows float64
+	// cost is the estimated cost of the tree: the sum of the number of rows
+	// produced by its joins.
+	cost float64
+}
+
+// makeJoinTree flattens the tree of inner joins rooted at n. It returns false
+// if the joins cannot be reordered.
+func makeJoinTree(n *joinNode) (*joinTree, bool) {
+	t := &joinTree{}
+	orig, ok := t.addJoin(n, 0)
+	if !ok || len(t.sources) < minJoinTreeSources {
+		return nil, false
+	}
+	t.orig = orig
+	for i := range t.edges {
+		e := &t.edges[i]
+		e.leftSrc, e.rightSrc = t.sourceOf(e.leftCol), t.sourceOf(e.rightCol)
+	}
+	return t, true
+}
+
+// addJoin adds the data sources and predicates of a join whose columns start
+// at the given column of the tree.
+func (t *joinTree) addJoin(n *joinNode, offset int) (*joinOrder, bool) {
+	t.joins = append(t.joins, n)
+	numLeft := len(n.left.info.sourceColumns)
+	left, ok := t.addSource(&n.left, offset)
+	if !ok {
+		return nil, false
+	}
+	right, ok := t.addSource(&n.right, offset+numLeft)
+	if !ok {
+		return nil, false
+	}
+
+	for i, l := range n.pred.leftEqualityIndices {
+		t.edges = append(t.edges, joinEdge{
+			leftCol:  offset + l,
+			rightCol: offset + numLeft + n.pred.rightEqualityIndices[i],
+		})
+	}
+	if n.pred.onCond != nil {
+		for _, e := range splitAndExpr(&n.planner.evalCtx, n.pred.onCond, nil) {
+			if e == parser.DBoolTrue {
+				continue
+			}
+			f := joinFilter{expr: e, offset: offset}
+			if !exprCheckVars(e, func(v parser.VariableExpr) (bool, parser.Expr) {
+				iv, ok := v.(*parser.IndexedVar)
+				if ok {
+					f.sources |= 1 << uint(t.sourceOf(offset+iv.Idx))
+				}
+				return ok, v
+			}) {
+				return nil, false
+			}
+			t.filters = append(t.filters, f)
+		}
+	}
+	return &joinOrder{sources: left.sources | right.sources, left: left, right: right}, true
+}
+
+// addSource adds a data source whose columns start at the given column of
+// the tree.
+func (t *joinTree) addSource(src *planDataSource, offset int) (*joinOrder, bool) {
+	if n, ok := src.plan.(*joinNode); ok && n.isReorderable() {
+		return t.addJoin(n, offset)
+	}
+	if len(t.sources) == maxJoinTreeSources {
+		return nil, false
+	}
+	i := len(t.sources)
+	t.sources = append(t.sources, src)
+	t.offsets = append(t.offsets, offset)
+	return &joinOrder{sources: 1 << uint(i)}, true
+}
+
+// sourceOf returns the data source which provides the given column of the
+// tree.
+func (t *joinTree) sourceOf(col int) int {
+	for i := len(t.offsets) - 1; i > 0; i-- {
+		if col >= t.offsets[i] {
+			return i
+		}
+	}
+	return 0
+}
+
+// expandJoinTree expands the data sources of the tree of inner joins rooted
+// at n, and reorders the joins if a cheaper join order is found.
+func (p *planner) expandJoinTree(ctx context.Context, n *joinNode) (planNode, error) {
+	t, ok := makeJoinTree(n)
+	if !ok {
+		return p.expandJoinSources(ctx, n)
+	}
+
+	for _, src := range t.sources {
+		var err error
+		src.plan, err = doExpandPlan(ctx, p, noParams, src.plan)
+		if err != nil {
+			return n, err
+		}
+	}
+	t.estimate(ctx, p)
+
+	var best *joinOrder
+	if len(t.sources) <= p.session.ReorderJoinsLimit {
+		best = t.searchExhaustive()
+	} else {
+		best = t.searchGreedy()
+	}
+	orig := t.costOrder(t.orig)
+	if best.cost > orig.cost*joinReorderCostRatio {
+		// Keep the original order. The parents are after their children in
+		// reverse order.
+		for i := len(t.joins) - 1; i >= 0; i-- {
+			t.joins[i].computeOrderings()
+		}
+		return n, nil
+	}
+	return p.buildJoinTree(ctx, t, best, n)
+}
+
+// expandJoinSources expands the data sources of a join which is not
+// reordered, including those of the joins under it.
+func (p *planner) expandJoinSources(ctx context.Context, n *joinNode) (planNode, error) {
+	var err error
+	n.left.plan, err = doExpandPlan(ctx, p, noParams, n.left.plan)
+	if err != nil {
+		return n, err
+	}
+	n.right.plan, err = doExpandPlan(ctx, p, noParams, n.right.plan)
+	if err != nil {
+		return n, err
+	}
+	n.computeOrderings()
+	return n, nil
+}
+
+// estimate computes the estimated size of the data sources and the
+// selectivity of the equality predicates.
+func (t *joinTree) estimate(ctx context.Context, p *planner) {
+	t.rows = make([]float64, len(t.sources))
+	for i, src := range t.sources {
+		t.rows[i] = p.estimateRowCount(src.plan)
+	}
+	for i := range t.edges {
+		e := &t.edges[i]
+		l := p.distinctCount(ctx, t.sources[e.leftSrc].plan, e.leftCol-t.offsets[e.leftSrc], t.rows[e.leftSrc])
+		r := p.distinctCount(ctx, t.sources[e.rightSrc].plan, e.rightCol-t.offsets[e.rightSrc], t.rows[e.rightSrc])
+		e.selectivity = 1 / math.Max(l, r)
+	}
+	t.rowCounts = make(map[sourceSet]float64)
+}
+
+// rowCount returns the estimated number of rows produced by joining the given
+// data sources. The predicates are assumed to be independent.
+func (t *joinTree) rowCount(s sourceSet) float64 {
+	if rows, ok := t.rowCounts[s]; ok {
+		return rows
+	}
+	rows := 1.0
+	for i := range t.sources {
+		if s.contains(i) {
+			rows *= t.rows[i]
+		}
+	}
+	for _, e := range t.edges {
+		if s.contains(e.leftSrc) && s.contains(e.rightSrc) {
+			rows *= e.selectivity
+		}
+	}
+	for _, f := range t.filters {
+		if f.sources.len() > 1 && f.sources.subsetOf(s) {
+			rows *= defaultFilterSelectivity
+		}
+	}
+	t.rowCounts[s] = rows
+	return rows
+}
+
+// leaf returns the joinOrder for a single data source.
+func (t *joinTree) leaf(i int) *joinOrder {
+	return &joinOrder{sources: 1 << uint(i), rows: t.rows[i]}
+}
+
+// joinCost returns the estimated number of rows and cost of joining two
+// subtrees.
+func (t *joinTree) joinCost(left, right *joinOrder) (rows, cost float64) {
+	rows = t.rowCount(left.sources | right.sources)
+	return rows, left.cost + right.cost + rows
+}
+
+// join returns the joinOrder for joining two subtrees.
+func (t *joinTree) join(left, right *joinOrder) *joinOrder {
+	rows, cost := t.joinCost(left, right)
+	return &joinOrder{
+		sources: left.sources | right.sources,
+		left:    left,
+		right:   right,
+		rows:    rows,
+		cost:    cost,
+	}
+}
+
+// costOrder returns a copy of the given tree with its estimates filled in.
+func (t *joinTree) costOrder(o *joinOrder) *joinOrder {
+	if o.left == nil {
+		return t.leaf(o.sources.first())
+	}
+	return t.join(t.costOrder(o.left), t.costOrder(o.right))
+}
+
+// connects returns true if the equality predicate is between columns of the
+// two sets of data sources.
+func (e *joinEdge) connects(a, b sourceSet) bool {
+	return (a.contains(e.leftSrc) && b.contains(e.rightSrc)) ||
+		(a.contains(e.rightSrc) && b.contains(e.leftSrc))
+}
+
+// connected returns true if there is an equality predicate between the two
+// sets of data sources.
+func (t *joinTree) connected(a, b sourceSet) bool {
+	for i := range t.edges {
+		if t.edges[i].connects(a, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchExhaustive returns the cheapest join order, computing the cheapest
+// tree for every subset of the data sources from the trees of its subsets.
+func (t *joinTree) searchExhaustive() *joinOrder {
+	all := sourceSet(1)<<uint(len(t.sources)) - 1
+	best := make([]*joinOrder, all+1)
+	for i := range t.sources {
+		best[1<<uint(i)] = t.leaf(i)
+	}
+	for s := sourceSet(1); s <= all; s++ {
+		if s.len() == 1 {
+			continue
+		}
+		var bestLeft, bestRight *joinOrder
+		bestCost := math.Inf(1)
+		// Iterate over the non-empty proper subsets of s.
+		for l := (s - 1) & s; l != 0; l = (l - 1) & s {
+			left, right := best[l], best[s&^l]
+			if _, cost := t.joinCost(left, right); cost < bestCost {
+				bestLeft, bestRight, bestCost = left, right, cost
+			}
+		}
+		best[s] = t.join(bestLeft, bestRight)
+	}
+	return best[all]
+}
+
+// searchGreedy returns a join order built by repeatedly joining the two
+// subtrees which produce the fewest rows, preferring subtrees connected by an
+// equality predicate over cross joins.
+func (t *joinTree) searchGreedy() *joinOrder {
+	trees := make([]*joinOrder, len(t.sources))
+	for i := range trees {
+		trees[i] = t.leaf(i)
+	}
+	for len(trees) > 1 {
+		var best *joinOrder
+		var bestIdx, otherIdx int
+		bestConnected := false
+		for i := range trees {
+			for j := i + 1; j < len(trees); j++ {
+				connected := t.connected(trees[i].sources, trees[j].sources)
+				if bestConnected && !connected {
+					continue
+				}
+				o := t.join(trees[i], trees[j])
+				if best == nil || (connected && !bestConnected) || o.rows < best.rows {
+					best, bestIdx, otherIdx, bestConnected = o, i, j, connected
+				}
+			}
+		}
+		trees[bestIdx] = best
+		trees = append(trees[:otherIdx], trees[otherIdx+1:]...)
+	}
+	return trees[0]
+}
+
+// buildJoinTree constructs the joins of the given join order, with a
+// renderNode on top which produces the columns of the original root in their
+// original order.
+func (p *planner) buildJoinTree(
+	ctx context.Context, t *joinTree, o *joinOrder, root *joinNode,
+) (planNode, error) {
+	applied := make([]bool, len(t.filters))
+	src, cols, err := p.buildJoinOrder(ctx, t, o, applied)
+	if err != nil {
+		return root, err
+	}
+	// The original joins are replaced; their sources are reused.
+	for _, j := range t.joins {
+		j.closeBuffers(ctx)
+	}
+
+	pos := make([]int, len(cols))
+	for i, c := range cols {
+		pos[c] = i
+	}
+	r := &renderNode{
+		planner:    p,
+		source:     src,
+		sourceInfo: multiSourceInfo{src.info},
+	}
+	r.ivarHelper = parser.MakeIndexedVarHelper(r, len(src.info.sourceColumns))
+	for i, col := range root.columns {
+		expr := r.ivarHelper.IndexedVar(pos[i])
+		r.addRenderColumn(expr, symbolicExprStr(expr), col)
+	}
+	r.computePhysicalProps(planPhysicalProps(src.plan))
+	return r, nil
+}
+
+// buildJoinOrder constructs the joins of the given join order. It returns the
+// data source for the joins and, for each of its columns, the corresponding
+// column of the tree. The filters which are applied by the joins are marked
+// in applied.
+func (p *planner) buildJoinOrder(
+	ctx context.Context, t *joinTree, o *joinOrder, applied []bool,
+) (planDataSource, []int, error) {
+	if o.left == nil {
+		i := o.sources.first()
+		src := *t.sources[i]
+		cols := make([]int, len(src.info.sourceColumns))
+		for j := range cols {
+			cols[j] = t.offsets[i] + j
+		}
+		return src, cols, nil
+	}
+
+	// The smaller side goes on the right, which is the side buffered by hash
+	// joins.
+	leftOrder, rightOrder := o.left, o.right
+	if leftOrder.rows < rightOrder.rows {
+		leftOrder, rightOrder = rightOrder, leftOrder
+	}
+	left, leftCols, err := p.buildJoinOrder(ctx, t, leftOrder, applied)
+	if err != nil {
+		return planDataSource{}, nil, err
+	}
+	right, rightCols, err := p.buildJoinOrder(ctx, t, rightOrder, applied)
+	if err != nil {
+		return planDataSource{}, nil, err
+	}
+	src, err := p.makeJoin(ctx, "CROSS JOIN", "" /* hint */, left, right, nil /* cond */)
+	if err != nil {
+		return planDataSource{}, nil, err
+	}
+	j := src.plan.(*joinNode)
+
+	cols := make([]int, 0, len(leftCols)+len(rightCols))
+	cols = append(cols, leftCols...)
+	cols = append(cols, rightCols...)
+	pos := make(map[int]int, len(cols))
+	for i, c := range cols {
+		pos[c] = i
+	}
+
+	var onCond parser.TypedExpr
+	for _, e := range t.edges {
+		if !e.connects(leftOrder.sources, rightOrder.sources) {
+			continue
+		}
+		eq := parser.NewTypedComparisonExpr(
+			parser.EQ,
+			j.pred.iVarHelper.IndexedVar(pos[e.leftCol]),
+			j.pred.iVarHelper.IndexedVar(pos[e.rightCol]),
+		)
+		if !j.pred.tryAddEqualityFilter(eq, left.info, right.info) {
+			onCond = mergeConj(onCond, eq)
+		}
+	}
+	for i, f := range t.filters {
+		if applied[i] || !f.sources.subsetOf(o.sources) {
+			continue
+		}
+		applied[i] = true
+		expr := exprConvertVars(f.expr, func(v parser.VariableExpr) (bool, parser.Expr) {
+			iv := v.(*parser.IndexedVar)
+			return true, j.pred.iVarHelper.IndexedVar(pos[f.offset+iv.Idx])
+		})
+		onCond = mergeConj(onCond, expr)
+	}
+	j.pred.onCond = onCond
+	j.computeOrderings()
+	return src, cols, nil
+}
+
+// estimateRowCount returns the estimated number of rows produced by an
+// expanded plan.
+func (p *planner) estimateRowCount(plan planNode) float64 {
+	switch n := plan.(type) {
+	case *scanNode:
+		rows := float64(defaultRowCount)
+		if n.hasEstimate {
+			rows = n.estimatedRowCount
+		} else if !n.desc.IsVirtualTable() {
+			span := n.desc.IndexSpan(n.index.ID)
+			if len(n.spans) != 1 || !n.spans[0].EqualValue(span) {
+				rows *= defaultRangeSelectivity
+			}
+		}
+		if n.filter != nil {
+			rows *= defaultRangeSelectivity
+		}
+		if n.hardLimit != 0 {
+			rows = math.Min(rows, float64(n.hardLimit))
+		}
+		return rows
+	case *indexJoinNode:
+		return p.estimateRowCount(n.index)
+	case *renderNode:
+		return p.estimateRowCount(n.source.plan)
+	case *filterNode:
+		return p.estimateRowCount(n.source.plan) * defaultRangeSelectivity
+	case *sortNode:
+		return p.estimateRowCount(n.plan)
+	case *distinctNode:
+		return p.estimateRowCount(n.plan)
+	case *ordinalityNode:
+		return p.estimateRowCount(n.source)
+	case *valuesNode:
+		if n.n != nil {
+			return float64(len(n.tuples))
+		}
+	case *zeroNode:
+		return 0
+	case *unaryNode:
+		return 1
+	case *joinNode:
+		left, right := p.estimateRowCount(n.left.plan), p.estimateRowCount(n.right.plan)
+		if n.joinType == joinTypeInner && len(n.pred.leftEqualityIndices) == 0 {
+			return left * right
+		}
+		return math.Max(left, right)
+	}
+	return defaultRowCount
+}
+
+// distinctCount returns the estimated number of distinct values of a column
+// of an expanded plan which produces the given number of rows.
+func (p *planner) distinctCount(ctx context.Context, plan planNode, col int, rows float64) float64 {
+	distinct := rows
+	if s := p.columnStatistic(ctx, plan, col); s != nil {
+		distinct = math.Min(distinct, float64(s.DistinctCount))
+	}
+	return math.Max(distinct, 1)
+}
+
+// columnStatistic returns the statistic of the table column which provides
+// the given column of an expanded plan, if there is one.
+func (p *planner) columnStatistic(
+	ctx context.Context, plan planNode, col int,
+) *stats.TableStatistic {
+	switch n := plan.(type) {
+	case *scanNode:
+		if ts := p.getTableStats(ctx, n.desc); ts != nil {
+			return ts.cols[n.cols[col].ID]
+		}
+	case *indexJoinNode:
+		return p.columnStatistic(ctx, n.table, col)
+	case *renderNode:
+		if iv, ok := n.render[col].(*parser.IndexedVar); ok {
+			return p.columnStatistic(ctx, n.source.plan, iv.Idx)
+		}
+	case *filterNode:
+		return p.columnStatistic(ctx, n.source.plan, col)
+	}
+	return nil
+}
@@ -371,6 +371,28 @@ func (p *joinPredicate) encode(b []byte, row parser.Datums, cols []int) ([]byte,
 	return b, containsNull, nil
 }
 
+// leftOrdering translates an ordering on the equality columns (see
+// joinNode.mergeJoinOrdering) into an ordering on the columns of the left
+// side.
+func (p *joinPredicate) leftOrdering(ord sqlbase.ColumnOrdering) sqlbase.ColumnOrdering {
+	return p.translateOrdering(ord, p.leftEqualityIndices)
+}
+
+// rightOrdering is the counterpart of leftOrdering for the right side.
+func (p *joinPredicate) rightOrdering(ord sqlbase.ColumnOrdering) sqlbase.ColumnOrdering {
+	return p.translateOrdering(ord, p.rightEqualityIndices)
+}
+
+func (p *joinPredicate) translateOrdering(
+	ord sqlbase.ColumnOrdering, eqCols []int,
+) sqlbase.ColumnOrdering {
+	res := make(sqlbase.ColumnOrdering, len(ord))
+	for i, o := range ord {
+		res[i] = sqlbase.ColumnOrderInfo{ColIdx: eqCols[o.ColIdx], Direction: o.Direction}
+	}
+	return res
+}
+
 // pickUsingColumn searches for a column whose name matches colName.
 // The column index and type are returned if found, otherwise an error
 // is reported.
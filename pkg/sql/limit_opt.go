@@ -113,6 +113,12 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 			applyLimit(n.left, numRows, true)
 		}
 
+	case *recursiveCTENode:
+		// The number of rows produced by the initial term does not
+		// bound the number of rows produced by the CTE.
+		setUnlimited(n.initial)
+		setUnlimited(n.recursive)
+
 	case *distinctNode:
 		applyLimit(n.plan, numRows, true)
 
@@ -164,6 +170,7 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 
 	case *valuesNode:
 	case *alterTableNode:
+	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueryNode:
 	case *scrubNode:
@@ -173,10 +180,14 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 	case *createIndexNode:
 	case *createUserNode:
 	case *createViewNode:
+	case *createSequenceNode:
+	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *refreshMaterializedViewNode:
+	case *dropSequenceNode:
 	case *dropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -189,6 +200,7 @@ func applyLimit(plan planNode, numRows int64, soft bool) {
 	case *showRangesNode:
 	case *showFingerprintsNode:
 	case *scatterNode:
+	case *sequenceSelectNode:
 
 	default:
 		panic(fmt.Sprintf("unhandled node type: %T", plan))
@@ -0,0 +1,138 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// populateMaterializedView inserts the results of the view query into
+// the materialized view designated by tn, using the planner's
+// transaction. It returns the number of rows written.
+//
+// If the planner is in auto-commit mode, the insertion commits the
+// transaction; callers must not perform further writes afterwards.
+func populateMaterializedView(
+	params runParams, tn *parser.NormalizableTableName, query *parser.Select,
+) (int, error) {
+	p := params.p
+	// Materialized views are rejected by the DML statements; allow the
+	// insertion below to bypass that check.
+	defer func(prev bool) { p.populatingMaterializedView = prev }(p.populatingMaterializedView)
+	p.populatingMaterializedView = true
+
+	insert := &parser.Insert{
+		Table:     tn,
+		Rows:      query,
+		Returning: parser.AbsentReturningClause,
+	}
+	insertPlan, err := p.Insert(params.ctx, insert, nil /* desiredTypes */)
+	if err != nil {
+		return 0, err
+	}
+	defer insertPlan.Close(params.ctx)
+	insertPlan, err = p.optimizePlan(params.ctx, insertPlan, allColumns(insertPlan))
+	if err != nil {
+		return 0, err
+	}
+	if err := p.startPlan(params.ctx, insertPlan); err != nil {
+		return 0, err
+	}
+	return countRowsAffected(params, insertPlan)
+}
+
+// refreshMaterializedViewNode represents a REFRESH MATERIALIZED VIEW
+// statement.
+type refreshMaterializedViewNode struct {
+	n    *parser.RefreshMaterializedView
+	desc *sqlbase.TableDescriptor
+}
+
+// RefreshMaterializedView recomputes the contents of a materialized view.
+// Privileges: CREATE on view.
+//   Notes: postgres requires the view owner.
+func (p *planner) RefreshMaterializedView(
+	ctx context.Context, n *parser.RefreshMaterializedView,
+) (planNode, error) {
+	// The job tracking the refresh is updated outside of the statement's
+	// transaction (see runStatementJob).
+	if !p.autoCommit {
+		return nil, pgerror.NewError(pgerror.CodeActiveSQLTransactionError,
+			"REFRESH MATERIALIZED VIEW cannot run inside a transaction block")
+	}
+
+	tn, err := p.normalizeTableName(ctx, &n.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := getTableOrViewDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	if err != nil {
+		return nil, err
+	}
+	if desc == nil {
+		return nil, sqlbase.NewUndefinedRelationError(tn)
+	}
+	if !desc.IsMaterializedView {
+		return nil, sqlbase.NewWrongObjectTypeError(tn, "materialized view")
+	}
+
+	if err := p.CheckPrivilege(desc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &refreshMaterializedViewNode{n: n, desc: desc}, nil
+}
+
+func (n *refreshMaterializedViewNode) Start(params runParams) error {
+	return params.p.runStatementJob(
+		params.ctx, n.n.String(), n.desc.ID,
+		jobs.RefreshMaterializedViewDetails{ViewID: n.desc.ID},
+		func() error { return n.refresh(params) },
+	)
+}
+
+// refresh replaces the contents of the materialized view with the
+// current results of its query. Both steps use the statement's
+// transaction, so concurrent readers observe either the old or the new
+// contents, never a mix of the two.
+func (n *refreshMaterializedViewNode) refresh(params runParams) error {
+	stmt, err := parser.ParseOne(n.desc.ViewQuery)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse underlying query from view %q", n.desc.Name)
+	}
+	sel, ok := stmt.(*parser.Select)
+	if !ok {
+		return errors.Errorf("failed to parse underlying query from view %q as a select", n.desc.Name)
+	}
+
+	span := n.desc.TableSpan()
+	if err := params.p.txn.DelRange(params.ctx, span.Key, span.EndKey); err != nil {
+		return err
+	}
+	_, err = populateMaterializedView(params, &n.n.Name, sel)
+	return err
+}
+
+func (*refreshMaterializedViewNode) Next(runParams) (bool, error) { return false, nil }
+func (*refreshMaterializedViewNode) Close(context.Context)        {}
+func (*refreshMaterializedViewNode) Values() parser.Datums        { return parser.Datums{} }
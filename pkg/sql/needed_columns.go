@@ -64,6 +64,12 @@ func setNeededColumns(plan planNode, needed []bool) {
 		setNeededColumns(n.left, needed)
 		setNeededColumns(n.right, needed)
 
+	case *recursiveCTENode:
+		// The rows of the CTE are fed back into its recursive term, so
+		// all the columns are needed.
+		setNeededColumns(n.initial, allColumns(n.initial))
+		setNeededColumns(n.recursive, allColumns(n.recursive))
+
 	case *joinNode:
 		// Note: getNeededColumns takes into account both the columns
 		// tested for equality and the join predicate expression.
@@ -184,6 +190,7 @@ func setNeededColumns(plan planNode, needed []bool) {
 		setNeededColumns(n.rows, allColumns(n.rows))
 
 	case *alterTableNode:
+	case *alterSequenceNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueryNode:
 	case *controlJobNode:
@@ -193,10 +200,14 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *createIndexNode:
 	case *createUserNode:
 	case *createViewNode:
+	case *createSequenceNode:
+	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
+	case *refreshMaterializedViewNode:
+	case *dropSequenceNode:
 	case *dropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -209,6 +220,7 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *showRangesNode:
 	case *showFingerprintsNode:
 	case *scatterNode:
+	case *sequenceSelectNode:
 
 	default:
 		panic(fmt.Sprintf("unhandled node type: %T", plan))
@@ -367,6 +367,14 @@ func TestSpanBasedDependencyAnalyzer(t *testing.T) {
 	if _, err := db.Exec(`CREATE TABLE baz (a INT DEFAULT 1)`); err != nil {
 		t.Fatal(err)
 	}
+	if _, err := db.Exec(`CREATE TABLE parent (p INT PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE child (c INT REFERENCES parent ON DELETE CASCADE ON UPDATE CASCADE)
+	`); err != nil {
+		t.Fatal(err)
+	}
 
 	for _, test := range []struct {
 		query1, query2 string
@@ -438,6 +446,13 @@ func TestSpanBasedDependencyAnalyzer(t *testing.T) {
 		{`UPDATE foo SET k = 1`, `DELETE FROM foo`, false},
 		{`UPDATE foo SET k = 1`, `DELETE FROM bar`, true},
 
+		// Cascading foreign key actions write to the referencing tables.
+		{`DELETE FROM parent`, `SELECT * FROM child`, false},
+		{`DELETE FROM parent`, `DELETE FROM child`, false},
+		{`DELETE FROM parent`, `SELECT * FROM bar`, true},
+		{`UPDATE parent SET p = 1`, `SELECT * FROM child`, false},
+		{`UPDATE parent SET p = 1`, `UPDATE bar SET k = 1`, true},
+
 		// Statements like statement_timestamp enforce a strict ordering on
 		// statements, restricting reordering and thus independence.
 		{`SELECT * FROM foo`, `SELECT *, statement_timestamp() FROM bar`, false},
@@ -152,7 +152,9 @@ var Aggregates = map[string][]Builtin{
 			ReturnType:    fixedReturnType(types.Int),
 			AggregateFunc: newCountRowsAggregate,
 			WindowFunc: func(params []types.T, evalCtx *EvalContext) WindowFunc {
-				return newAggregateWindow(newCountRowsAggregate(params, evalCtx))
+				return newAggregateWindow(func() AggregateFunc {
+					return newCountRowsAggregate(params, evalCtx)
+				})
 			},
 			Info: "Calculates the number of rows.",
 		},
@@ -262,7 +264,9 @@ func makeAggBuiltinWithReturnType(
 		ReturnType:    retType,
 		AggregateFunc: f,
 		WindowFunc: func(params []types.T, evalCtx *EvalContext) WindowFunc {
-			return newAggregateWindow(f(params, evalCtx))
+			return newAggregateWindow(func() AggregateFunc {
+				return f(params, evalCtx)
+			})
 		},
 		Info: info,
 	}
@@ -0,0 +1,36 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import "bytes"
+
+// AlterSequence represents an ALTER SEQUENCE statement, except in the case of
+// ALTER SEQUENCE <seqName> RENAME TO <newSeqName>, which is represented by a
+// RenameTable node.
+type AlterSequence struct {
+	IfExists bool
+	Name     NormalizableTableName
+	Options  SequenceOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterSequence) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER SEQUENCE ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, &node.Name)
+	FormatNode(buf, f, node.Options)
+}
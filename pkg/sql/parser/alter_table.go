@@ -60,18 +60,22 @@ type AlterTableCmd interface {
 
 func (*AlterTableAddColumn) alterTableCmd()          {}
 func (*AlterTableAddConstraint) alterTableCmd()      {}
+func (*AlterTableAlterColumnType) alterTableCmd()    {}
 func (*AlterTableDropColumn) alterTableCmd()         {}
 func (*AlterTableDropConstraint) alterTableCmd()     {}
 func (*AlterTableDropNotNull) alterTableCmd()        {}
 func (*AlterTableSetDefault) alterTableCmd()         {}
+func (*AlterTableSetNotNull) alterTableCmd()         {}
 func (*AlterTableValidateConstraint) alterTableCmd() {}
 
 var _ AlterTableCmd = &AlterTableAddColumn{}
 var _ AlterTableCmd = &AlterTableAddConstraint{}
+var _ AlterTableCmd = &AlterTableAlterColumnType{}
 var _ AlterTableCmd = &AlterTableDropColumn{}
 var _ AlterTableCmd = &AlterTableDropConstraint{}
 var _ AlterTableCmd = &AlterTableDropNotNull{}
 var _ AlterTableCmd = &AlterTableSetDefault{}
+var _ AlterTableCmd = &AlterTableSetNotNull{}
 var _ AlterTableCmd = &AlterTableValidateConstraint{}
 
 // ColumnMutationCmd is the subset of AlterTableCmds that modify an
@@ -206,6 +210,37 @@ func (node *AlterTableSetDefault) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// AlterTableAlterColumnType represents an ALTER COLUMN [SET DATA] TYPE
+// command.
+type AlterTableAlterColumnType struct {
+	columnKeyword bool
+	Column        Name
+	ToType        ColumnType
+	// Using is the expression computing the new values from the old ones,
+	// or nil if none was specified.
+	Using Expr
+}
+
+// GetColumn implements the ColumnMutationCmd interface.
+func (node *AlterTableAlterColumnType) GetColumn() Name {
+	return node.Column
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableAlterColumnType) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER ")
+	if node.columnKeyword {
+		buf.WriteString("COLUMN ")
+	}
+	FormatNode(buf, f, node.Column)
+	buf.WriteString(" TYPE ")
+	FormatNode(buf, f, node.ToType)
+	if node.Using != nil {
+		buf.WriteString(" USING ")
+		FormatNode(buf, f, node.Using)
+	}
+}
+
 // AlterTableDropNotNull represents an ALTER COLUMN DROP NOT NULL
 // command.
 type AlterTableDropNotNull struct {
@@ -227,3 +262,25 @@ func (node *AlterTableDropNotNull) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, node.Column)
 	buf.WriteString(" DROP NOT NULL")
 }
+
+// AlterTableSetNotNull represents an ALTER COLUMN SET NOT NULL
+// command.
+type AlterTableSetNotNull struct {
+	columnKeyword bool
+	Column        Name
+}
+
+// GetColumn implements the ColumnMutationCmd interface.
+func (node *AlterTableSetNotNull) GetColumn() Name {
+	return node.Column
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetNotNull) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER ")
+	if node.columnKeyword {
+		buf.WriteString("COLUMN ")
+	}
+	FormatNode(buf, f, node.Column)
+	buf.WriteString(" SET NOT NULL")
+}
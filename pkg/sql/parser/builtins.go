@@ -87,6 +87,7 @@ const (
 	categoryMath          = "Math and Numeric"
 	categoryString        = "String and Byte"
 	categoryArray         = "Array"
+	categorySequences     = "Sequence"
 	categorySystemInfo    = "System Info"
 )
 
@@ -1141,6 +1142,119 @@ CockroachDB supports the following flags:
 	"experimental_uuid_v4": {uuidV4Impl},
 	"uuid_v4":              {uuidV4Impl},
 
+	// Sequence functions.
+
+	"nextval": {
+		Builtin{
+			Types:            ArgTypes{{"sequence_name", types.String}},
+			ReturnType:       fixedReturnType(types.Int),
+			category:         categorySequences,
+			impure:           true,
+			distsqlBlacklist: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				qualifiedName, err := qualifySequenceName(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				res, err := ctx.Sequence.IncrementSequence(ctx.Ctx(), qualifiedName)
+				if err != nil {
+					return nil, err
+				}
+				return NewDInt(DInt(res)), nil
+			},
+			Info: "Advances the given sequence and returns its new value.",
+		},
+	},
+
+	"currval": {
+		Builtin{
+			Types:            ArgTypes{{"sequence_name", types.String}},
+			ReturnType:       fixedReturnType(types.Int),
+			category:         categorySequences,
+			impure:           true,
+			distsqlBlacklist: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				qualifiedName, err := qualifySequenceName(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				res, err := ctx.Sequence.GetLatestValueInSessionForSequence(ctx.Ctx(), qualifiedName)
+				if err != nil {
+					return nil, err
+				}
+				return NewDInt(DInt(res)), nil
+			},
+			Info: "Returns the latest value obtained with nextval for this sequence in this session.",
+		},
+	},
+
+	"lastval": {
+		Builtin{
+			Types:            ArgTypes{},
+			ReturnType:       fixedReturnType(types.Int),
+			category:         categorySequences,
+			impure:           true,
+			distsqlBlacklist: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				val, ok := ctx.Sequence.GetLastSequenceValueInSession()
+				if !ok {
+					return nil, pgerror.NewError(
+						pgerror.CodeObjectNotInPrerequisiteStateError, "lastval is not yet defined in this session")
+				}
+				return NewDInt(DInt(val)), nil
+			},
+			Info: "Return value most recently obtained with nextval in this session.",
+		},
+	},
+
+	"setval": {
+		Builtin{
+			Types:            ArgTypes{{"sequence_name", types.String}, {"value", types.Int}},
+			ReturnType:       fixedReturnType(types.Int),
+			category:         categorySequences,
+			impure:           true,
+			distsqlBlacklist: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				qualifiedName, err := qualifySequenceName(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				newVal := MustBeDInt(args[1])
+				if err := ctx.Sequence.SetSequenceValue(
+					ctx.Ctx(), qualifiedName, int64(newVal), true /* isCalled */); err != nil {
+					return nil, err
+				}
+				return args[1], nil
+			},
+			Info: "Set the given sequence's current value. The next call to nextval will return " +
+				"`value + Increment`",
+		},
+		Builtin{
+			Types: ArgTypes{
+				{"sequence_name", types.String}, {"value", types.Int}, {"is_called", types.Bool},
+			},
+			ReturnType:       fixedReturnType(types.Int),
+			category:         categorySequences,
+			impure:           true,
+			distsqlBlacklist: true,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				qualifiedName, err := qualifySequenceName(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				isCalled := bool(*args[2].(*DBool))
+				newVal := MustBeDInt(args[1])
+				if err := ctx.Sequence.SetSequenceValue(
+					ctx.Ctx(), qualifiedName, int64(newVal), isCalled); err != nil {
+					return nil, err
+				}
+				return args[1], nil
+			},
+			Info: "Set the given sequence's current value. If is_called is false, the next call to " +
+				"nextval will return `value`; otherwise `value + Increment`.",
+		},
+	},
+
 	"greatest": {
 		Builtin{
 			Types:        HomogeneousType{},
@@ -1366,7 +1480,7 @@ CockroachDB supports the following flags:
 			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
 				fromTSTZ := args[1].(*DTimestampTZ)
 				timeSpan := strings.ToLower(string(MustBeDString(args[0])))
-				return extractStringFromTimestamp(ctx, fromTSTZ.Time, timeSpan)
+				return extractStringFromTimestamp(ctx, fromTSTZ.Time.In(ctx.GetLocation()), timeSpan)
 			},
 			Info: "Extracts `element` from `input`.\n\n" +
 				"Compatible elements: year, quarter, month, week, dayofweek, dayofyear,\n" +
@@ -1410,6 +1524,65 @@ CockroachDB supports the following flags:
 		},
 	},
 
+	// timezone implements the AT TIME ZONE operator. See
+	// https://www.postgresql.org/docs/10/static/functions-datetime.html#functions-datetime-zoneconvert
+	"timezone": {
+		Builtin{
+			Types:      ArgTypes{{"timezone", types.String}, {"timestamp", types.Timestamp}},
+			ReturnType: fixedReturnType(types.TimestampTZ),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := timeZoneNameToLocation(string(MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return timestampAtTimeZone(args[1].(*DTimestamp), loc), nil
+			},
+			Info: "Treats `timestamp` as a local time in the time zone `timezone` and returns\n" +
+				"the corresponding absolute time.",
+		},
+		Builtin{
+			Types:      ArgTypes{{"timezone", types.String}, {"timestamptz", types.TimestampTZ}},
+			ReturnType: fixedReturnType(types.Timestamp),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := timeZoneNameToLocation(string(MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return timestampTZAtTimeZone(args[1].(*DTimestampTZ), loc), nil
+			},
+			Info: "Returns the local time in the time zone `timezone` at `timestamptz`.",
+		},
+		Builtin{
+			Types:      ArgTypes{{"timezone", types.Interval}, {"timestamp", types.Timestamp}},
+			ReturnType: fixedReturnType(types.TimestampTZ),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := timeZoneOffsetToLocation(args[0].(*DInterval))
+				if err != nil {
+					return nil, err
+				}
+				return timestampAtTimeZone(args[1].(*DTimestamp), loc), nil
+			},
+			Info: "Treats `timestamp` as a local time at the UTC offset `timezone` and returns\n" +
+				"the corresponding absolute time.",
+		},
+		Builtin{
+			Types:      ArgTypes{{"timezone", types.Interval}, {"timestamptz", types.TimestampTZ}},
+			ReturnType: fixedReturnType(types.Timestamp),
+			category:   categoryDateAndTime,
+			fn: func(_ *EvalContext, args Datums) (Datum, error) {
+				loc, err := timeZoneOffsetToLocation(args[0].(*DInterval))
+				if err != nil {
+					return nil, err
+				}
+				return timestampTZAtTimeZone(args[1].(*DTimestampTZ), loc), nil
+			},
+			Info: "Returns the local time at the UTC offset `timezone` at `timestamptz`.",
+		},
+	},
+
 	// https://www.postgresql.org/docs/10/static/functions-datetime.html#functions-datetime-trunc
 	"date_trunc": {
 		Builtin{
@@ -1447,9 +1620,11 @@ CockroachDB supports the following flags:
 			ReturnType: fixedReturnType(types.TimestampTZ),
 			category:   categoryDateAndTime,
 			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				// Truncate in the session time zone, so that e.g. days start at
+				// local midnight.
 				fromTSTZ := args[1].(*DTimestampTZ)
 				timeSpan := strings.ToLower(string(MustBeDString(args[0])))
-				return truncateTimestamp(ctx, fromTSTZ.Time, timeSpan)
+				return truncateTimestamp(ctx, fromTSTZ.Time.In(ctx.GetLocation()), timeSpan)
 			},
 			Info: "Truncates `input` to precision `element`.  Sets all fields that are less\n" +
 				"significant than `element` to zero (or one, for day and month)\n\n" +
@@ -2132,6 +2307,24 @@ CockroachDB supports the following flags:
 		},
 	},
 
+	"crdb_internal.statement_fingerprint": {
+		Builtin{
+			Types:      ArgTypes{{"statement", types.String}},
+			ReturnType: fixedReturnType(types.String),
+			category:   categorySystemInfo,
+			fn: func(ctx *EvalContext, args Datums) (Datum, error) {
+				stmt, err := ParseOne(string(MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return NewDString(StatementFingerprint(stmt)), nil
+			},
+			Info: "Returns the fingerprint of `statement`, that is the statement with its " +
+				"constants and planner hints removed. The fingerprint is the key of the " +
+				"plan hints pinned in system.statement_hints.",
+		},
+	},
+
 	"crdb_internal.force_error": {
 		Builtin{
 			Types:      ArgTypes{{"errorCode", types.String}, {"msg", types.String}},
@@ -2351,6 +2544,16 @@ var substringImpls = []Builtin{
 	},
 }
 
+// qualifySequenceName parses the sequence name passed to a sequence builtin
+// and qualifies it with the current database if necessary.
+func qualifySequenceName(ctx *EvalContext, arg Datum) (*TableName, error) {
+	tn, err := ParseTableName(string(MustBeDString(arg)))
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Planner.QualifyWithDatabase(ctx.Ctx(), tn)
+}
+
 var uuidV4Impl = Builtin{
 	Types:      ArgTypes{},
 	ReturnType: fixedReturnType(types.Bytes),
@@ -3082,6 +3285,46 @@ func extractStringFromTimestamp(
 	}
 }
 
+// timeZoneNameToLocation loads the time zone named by the argument of AT TIME
+// ZONE.
+func timeZoneNameToLocation(name string) (*time.Location, error) {
+	loc, err := timeutil.TimeZoneStringToLocation(name)
+	if err != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"cannot find time zone %q: %v", name, err)
+	}
+	return loc, nil
+}
+
+// timeZoneOffsetToLocation returns a time zone with the UTC offset given as
+// the argument of AT TIME ZONE.
+func timeZoneOffsetToLocation(offset *DInterval) (*time.Location, error) {
+	if offset.Months != 0 || offset.Days != 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"interval time zone %s must not include months or days", offset)
+	}
+	return timeutil.FixedOffsetTimeZoneToLocation(
+		int(offset.Nanos/int64(time.Second)), offset.String()), nil
+}
+
+// timestampAtTimeZone interprets the wall time of a TIMESTAMP in the given
+// time zone.
+func timestampAtTimeZone(ts *DTimestamp, loc *time.Location) Datum {
+	t := ts.Time
+	return MakeDTimestampTZ(time.Date(
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc,
+	), time.Microsecond)
+}
+
+// timestampTZAtTimeZone returns the wall time of a TIMESTAMPTZ in the given
+// time zone.
+func timestampTZAtTimeZone(ts *DTimestampTZ, loc *time.Location) Datum {
+	t := ts.Time.In(loc)
+	return MakeDTimestamp(time.Date(
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC,
+	), time.Microsecond)
+}
+
 func truncateTimestamp(_ *EvalContext, fromTime time.Time, timeSpan string) (Datum, error) {
 	year := fromTime.Year()
 	month := fromTime.Month()
@@ -67,15 +67,25 @@ func (node *CreateDatabase) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
-// IndexElem represents a column with a direction in a CREATE INDEX statement.
+// IndexElem represents a column or an expression with a direction in a
+// CREATE INDEX statement. Expr is nil for columns.
 type IndexElem struct {
 	Column    Name
+	Expr      Expr
 	Direction Direction
 }
 
 // Format implements the NodeFormatter interface.
 func (node IndexElem) Format(buf *bytes.Buffer, f FmtFlags) {
-	FormatNode(buf, f, node.Column)
+	if node.Expr == nil {
+		FormatNode(buf, f, node.Column)
+	} else if _, ok := node.Expr.(*FuncExpr); ok {
+		FormatNode(buf, f, node.Expr)
+	} else {
+		buf.WriteByte('(')
+		FormatNode(buf, f, node.Expr)
+		buf.WriteByte(')')
+	}
 	if node.Direction != DefaultDirection {
 		buf.WriteByte(' ')
 		buf.WriteString(node.Direction.String())
@@ -107,6 +117,9 @@ type CreateIndex struct {
 	// for improved reading performance.
 	Storing    NameList
 	Interleave *InterleaveDef
+	// Predicate restricts the index to the rows which satisfy it. It is nil
+	// for indexes on all the rows of the table.
+	Predicate Expr
 }
 
 // Format implements the NodeFormatter interface.
@@ -136,6 +149,10 @@ func (node *CreateIndex) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.Interleave != nil {
 		FormatNode(buf, f, node.Interleave)
 	}
+	if node.Predicate != nil {
+		buf.WriteString(" WHERE ")
+		FormatNode(buf, f, node.Predicate)
+	}
 }
 
 // TableDef represents a column, index or constraint definition within a CREATE
@@ -462,6 +479,8 @@ type IndexTableDef struct {
 	Columns    IndexElemList
 	Storing    NameList
 	Interleave *InterleaveDef
+	// Predicate restricts partial indexes to the rows which satisfy it.
+	Predicate Expr
 }
 
 func (node *IndexTableDef) setName(name Name) {
@@ -486,6 +505,10 @@ func (node *IndexTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.Interleave != nil {
 		FormatNode(buf, f, node.Interleave)
 	}
+	if node.Predicate != nil {
+		buf.WriteString(" WHERE ")
+		FormatNode(buf, f, node.Predicate)
+	}
 }
 
 // ConstraintTableDef represents a constraint definition within a CREATE TABLE
@@ -508,6 +531,12 @@ type UniqueConstraintTableDef struct {
 
 // Format implements the NodeFormatter interface.
 func (node *UniqueConstraintTableDef) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Predicate != nil {
+		// Partial unique indexes can only be defined as indexes.
+		buf.WriteString("UNIQUE ")
+		node.IndexTableDef.Format(buf, f)
+		return
+	}
 	if node.Name != "" {
 		buf.WriteString("CONSTRAINT ")
 		FormatNode(buf, f, node.Name)
@@ -797,6 +826,9 @@ func (PartitionMaxValue) ResolvedType() types.T { return types.Any }
 
 // CreateTable represents a CREATE TABLE statement.
 type CreateTable struct {
+	// Temporary is set for CREATE TEMP TABLE: the table is only visible to
+	// the session which created it and is dropped when the session ends.
+	Temporary     bool
 	IfNotExists   bool
 	Table         NormalizableTableName
 	Interleave    *InterleaveDef
@@ -814,7 +846,11 @@ func (node *CreateTable) As() bool {
 
 // Format implements the NodeFormatter interface.
 func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("CREATE TABLE ")
+	buf.WriteString("CREATE ")
+	if node.Temporary {
+		buf.WriteString("TEMP ")
+	}
+	buf.WriteString("TABLE ")
 	if node.IfNotExists {
 		buf.WriteString("IF NOT EXISTS ")
 	}
@@ -840,6 +876,21 @@ func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// CreateRole represents a CREATE ROLE statement.
+type CreateRole struct {
+	Name        Expr
+	IfNotExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE ROLE ")
+	if node.IfNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+}
+
 // CreateUser represents a CREATE USER statement.
 type CreateUser struct {
 	Name        Expr
@@ -893,14 +944,19 @@ func (node *AlterUserSetPassword) Format(buf *bytes.Buffer, f FmtFlags) {
 
 // CreateView represents a CREATE VIEW statement.
 type CreateView struct {
-	Name        NormalizableTableName
-	ColumnNames NameList
-	AsSource    *Select
+	Name         NormalizableTableName
+	ColumnNames  NameList
+	AsSource     *Select
+	Materialized bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *CreateView) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("CREATE VIEW ")
+	buf.WriteString("CREATE ")
+	if node.Materialized {
+		buf.WriteString("MATERIALIZED ")
+	}
+	buf.WriteString("VIEW ")
 	FormatNode(buf, f, &node.Name)
 
 	if len(node.ColumnNames) > 0 {
@@ -913,3 +969,99 @@ func (node *CreateView) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString(" AS ")
 	FormatNode(buf, f, node.AsSource)
 }
+
+// CreateSequence represents a CREATE SEQUENCE statement.
+type CreateSequence struct {
+	IfNotExists bool
+	Name        NormalizableTableName
+	Options     SequenceOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateSequence) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE SEQUENCE ")
+	if node.IfNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	FormatNode(buf, f, &node.Name)
+	FormatNode(buf, f, node.Options)
+}
+
+// SequenceOptions represents a list of sequence options.
+type SequenceOptions []SequenceOption
+
+// Format implements the NodeFormatter interface.
+func (node SequenceOptions) Format(buf *bytes.Buffer, f FmtFlags) {
+	for _, option := range node {
+		buf.WriteByte(' ')
+		switch option.Name {
+		case SeqOptCycle, SeqOptNoCycle:
+			buf.WriteString(option.Name)
+		case SeqOptCache:
+			fmt.Fprintf(buf, "%s %d", option.Name, *option.IntVal)
+		case SeqOptMaxValue, SeqOptMinValue:
+			if option.IntVal == nil {
+				fmt.Fprintf(buf, "NO %s", option.Name)
+			} else {
+				fmt.Fprintf(buf, "%s %d", option.Name, *option.IntVal)
+			}
+		case SeqOptStart:
+			buf.WriteString(option.Name)
+			if option.OptionalWord {
+				buf.WriteString(" WITH")
+			}
+			fmt.Fprintf(buf, " %d", *option.IntVal)
+		case SeqOptIncrement:
+			buf.WriteString(option.Name)
+			if option.OptionalWord {
+				buf.WriteString(" BY")
+			}
+			fmt.Fprintf(buf, " %d", *option.IntVal)
+		default:
+			panic(fmt.Sprintf("unexpected SequenceOption: %v", option))
+		}
+	}
+}
+
+// SequenceOption represents an option on a CREATE SEQUENCE or ALTER
+// SEQUENCE statement.
+type SequenceOption struct {
+	Name string
+
+	// IntVal is the value of the option, if any. It is nil for NO MINVALUE
+	// and NO MAXVALUE.
+	IntVal *int64
+
+	// OptionalWord is set if the option was specified with its optional
+	// keyword, e.g. INCREMENT BY or START WITH. It is only used to format
+	// the statement like it was written.
+	OptionalWord bool
+}
+
+// Names of SequenceOption.
+const (
+	SeqOptCycle     = "CYCLE"
+	SeqOptNoCycle   = "NO CYCLE"
+	SeqOptCache     = "CACHE"
+	SeqOptIncrement = "INCREMENT"
+	SeqOptMinValue  = "MINVALUE"
+	SeqOptMaxValue  = "MAXVALUE"
+	SeqOptStart     = "START"
+)
+
+// CreateStats represents a CREATE STATISTICS statement.
+type CreateStats struct {
+	Name        Name
+	ColumnNames NameList
+	Table       NormalizableTableName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateStats) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE STATISTICS ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" ON ")
+	FormatNode(buf, f, node.ColumnNames)
+	buf.WriteString(" FROM ")
+	FormatNode(buf, f, &node.Table)
+}
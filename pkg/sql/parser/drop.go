@@ -104,14 +104,39 @@ func (node *DropTable) Format(buf *bytes.Buffer, f FmtFlags) {
 
 // DropView represents a DROP VIEW statement.
 type DropView struct {
+	Names          TableNameReferences
+	IfExists       bool
+	DropBehavior   DropBehavior
+	IsMaterialized bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropView) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP ")
+	if node.IsMaterialized {
+		buf.WriteString("MATERIALIZED ")
+	}
+	buf.WriteString("VIEW ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Names)
+	if node.DropBehavior != DropDefault {
+		buf.WriteByte(' ')
+		buf.WriteString(node.DropBehavior.String())
+	}
+}
+
+// DropSequence represents a DROP SEQUENCE statement.
+type DropSequence struct {
 	Names        TableNameReferences
 	IfExists     bool
 	DropBehavior DropBehavior
 }
 
 // Format implements the NodeFormatter interface.
-func (node *DropView) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("DROP VIEW ")
+func (node *DropSequence) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP SEQUENCE ")
 	if node.IfExists {
 		buf.WriteString("IF EXISTS ")
 	}
@@ -136,3 +161,18 @@ func (node *DropUser) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 	FormatNode(buf, f, node.Names)
 }
+
+// DropRole represents a DROP ROLE statement
+type DropRole struct {
+	Names    Exprs
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP ROLE ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Names)
+}
@@ -2004,6 +2004,29 @@ type EvalPlanner interface {
 	QualifyWithDatabase(ctx context.Context, t *NormalizableTableName) (*TableName, error)
 }
 
+// SequenceOperators is used for the sequence builtins (nextval() and
+// friends), which need access to the sequence descriptors and to the session.
+type SequenceOperators interface {
+	// IncrementSequence increments the given sequence and returns the result.
+	// It returns an error if the given name is not a sequence.
+	// The caller must ensure that seqName is fully qualified already.
+	IncrementSequence(ctx context.Context, seqName *TableName) (int64, error)
+
+	// GetLatestValueInSessionForSequence returns the value most recently
+	// obtained by nextval() for the given sequence in this session.
+	GetLatestValueInSessionForSequence(ctx context.Context, seqName *TableName) (int64, error)
+
+	// GetLastSequenceValueInSession returns the value most recently obtained
+	// by nextval() in this session, for any sequence. The boolean is false if
+	// nextval() has not been called yet in this session.
+	GetLastSequenceValueInSession() (int64, bool)
+
+	// SetSequenceValue sets the sequence's value. If isCalled is false, the
+	// next call to nextval() returns newVal; otherwise it returns newVal
+	// plus the sequence's increment.
+	SetSequenceValue(ctx context.Context, seqName *TableName, newVal int64, isCalled bool) error
+}
+
 // CtxProvider is anything that can return a Context.
 type CtxProvider interface {
 	// Ctx returns this provider's context.
@@ -2068,6 +2091,8 @@ type EvalContext struct {
 
 	Planner EvalPlanner
 
+	Sequence SequenceOperators
+
 	// Ths transaction in which the statement is executing.
 	Txn *client.Txn
 
@@ -26,6 +26,12 @@ type fmtFlags struct {
 	ShowTableAliases bool
 	symbolicVars     bool
 	hideConstants    bool
+	// If true, join hints and index hints are omitted.
+	hideHints bool
+	// tableExprVisitor will be called on all AliasedTableExprs and
+	// JoinTableExprs, in the order in which they are formatted, if it is
+	// non-nil.
+	tableExprVisitor func(TableExpr)
 	// tableNameFormatter will be called on all NormalizableTableNames if it is
 	// non-nil.
 	tableNameFormatter func(*NormalizableTableName, *bytes.Buffer, FmtFlags)
@@ -137,6 +143,22 @@ func FmtExpr(base FmtFlags, showTypes bool, symbolicVars bool, showTableAliases
 	return &f
 }
 
+// FmtHideHints returns FmtFlags that instructs the pretty-printer to omit
+// join hints and index hints.
+func FmtHideHints(base FmtFlags) FmtFlags {
+	f := *base
+	f.hideHints = true
+	return &f
+}
+
+// fmtVisitTableExprs returns FmtFlags that calls the provided function on
+// all the table expressions that can carry planner hints.
+func fmtVisitTableExprs(base FmtFlags, fn func(TableExpr)) FmtFlags {
+	f := *base
+	f.tableExprVisitor = fn
+	return &f
+}
+
 // FmtIndexedVarFormat returns FmtFlags that customizes the printing of
 // IndexedVars using the provided function.
 func FmtIndexedVarFormat(
@@ -99,10 +99,16 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE USER blih ??`, `CREATE USER`},
 		{`CREATE USER blih WITH ??`, `CREATE USER`},
 
+		{`CREATE ROLE blih ??`, `CREATE ROLE`},
+
 		{`CREATE VIEW blah (??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS (SELECT c FROM x) ??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS SELECT c FROM x ??`, `SELECT`},
 		{`CREATE VIEW blah AS (??`, `<SELECTCLAUSE>`},
+		{`CREATE MATERIALIZED VIEW blah (??`, `CREATE VIEW`},
+
+		{`CREATE STATISTICS ??`, `CREATE STATISTICS`},
+		{`CREATE STATISTICS blah ON x FROM ??`, `CREATE STATISTICS`},
 
 		{`CREATE TABLE blah (??`, `CREATE TABLE`},
 		{`CREATE TABLE IF NOT ??`, `CREATE TABLE`},
@@ -135,10 +141,14 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP VIEW blah ??`, `DROP VIEW`},
 		{`DROP VIEW IF ??`, `DROP VIEW`},
 		{`DROP VIEW IF EXISTS blih, bloh ??`, `DROP VIEW`},
+		{`DROP MATERIALIZED VIEW blah ??`, `DROP VIEW`},
 
 		{`DROP USER IF ??`, `DROP USER`},
 		{`DROP USER IF EXISTS bloh ??`, `DROP USER`},
 
+		{`DROP ROLE IF ??`, `DROP ROLE`},
+		{`DROP ROLE IF EXISTS bloh ??`, `DROP ROLE`},
+
 		{`EXPLAIN (??`, `EXPLAIN`},
 		{`EXPLAIN SELECT 1 ??`, `SELECT`},
 		{`EXPLAIN INSERT INTO xx (SELECT 1) ??`, `INSERT`},
@@ -213,6 +223,9 @@ func TestContextualHelp(t *testing.T) {
 		{`SHOW SESSIONS ??`, `SHOW SESSIONS`},
 		{`SHOW LOCAL SESSIONS ??`, `SHOW SESSIONS`},
 
+		{`SHOW STATISTICS ??`, `SHOW STATISTICS`},
+		{`SHOW STATISTICS FOR TABLE ??`, `SHOW STATISTICS`},
+
 		{`SHOW QUERIES ??`, `SHOW QUERIES`},
 		{`SHOW LOCAL QUERIES ??`, `SHOW QUERIES`},
 
@@ -258,6 +271,8 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW USERS ??`, `SHOW USERS`},
 
+		{`SHOW ROLES ??`, `SHOW ROLES`},
+
 		{`TRUNCATE foo ??`, `TRUNCATE`},
 		{`TRUNCATE foo, ??`, `TRUNCATE`},
 
@@ -311,6 +326,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SAVEPOINT blah ??`, `SAVEPOINT`},
 
+		{`REFRESH ??`, `REFRESH MATERIALIZED VIEW`},
+		{`REFRESH MATERIALIZED VIEW blah ??`, `REFRESH MATERIALIZED VIEW`},
+
 		{`RELEASE blah ??`, `RELEASE`},
 		{`RELEASE SAVEPOINT blah ??`, `RELEASE`},
 
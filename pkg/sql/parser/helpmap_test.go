@@ -8,6 +8,7 @@ var expectedHelpStrings = []string{
 	"<SOURCE>",
 	"ALTER DATABASE",
 	"ALTER INDEX",
+	"ALTER SEQUENCE",
 	"ALTER TABLE",
 	"ALTER USER",
 	"ALTER VIEW",
@@ -20,6 +21,9 @@ var expectedHelpStrings = []string{
 	"COMMIT",
 	"CREATE DATABASE",
 	"CREATE INDEX",
+	"CREATE ROLE",
+	"CREATE SEQUENCE",
+	"CREATE STATISTICS",
 	"CREATE TABLE",
 	"CREATE USER",
 	"CREATE VIEW",
@@ -29,6 +33,8 @@ var expectedHelpStrings = []string{
 	"DISCARD",
 	"DROP DATABASE",
 	"DROP INDEX",
+	"DROP ROLE",
+	"DROP SEQUENCE",
 	"DROP TABLE",
 	"DROP USER",
 	"DROP VIEW",
@@ -40,6 +46,7 @@ var expectedHelpStrings = []string{
 	"INSERT",
 	"PAUSE JOB",
 	"PREPARE",
+	"REFRESH MATERIALIZED VIEW",
 	"RELEASE",
 	"RESET CLUSTER SETTING",
 	"RESET",
@@ -56,6 +63,7 @@ var expectedHelpStrings = []string{
 	"SHOW CLUSTER SETTING",
 	"SHOW COLUMNS",
 	"SHOW CONSTRAINTS",
+	"SHOW CREATE SEQUENCE",
 	"SHOW CREATE TABLE",
 	"SHOW CREATE VIEW",
 	"SHOW DATABASES",
@@ -63,8 +71,10 @@ var expectedHelpStrings = []string{
 	"SHOW INDEXES",
 	"SHOW JOBS",
 	"SHOW QUERIES",
+	"SHOW ROLES",
 	"SHOW SESSION",
 	"SHOW SESSIONS",
+	"SHOW STATISTICS",
 	"SHOW TABLES",
 	"SHOW TRACE",
 	"SHOW TRANSACTION",
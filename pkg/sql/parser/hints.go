@@ -0,0 +1,102 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import "bytes"
+
+// StatementFingerprint returns the fingerprint of a statement, that is its
+// representation with constants and planner hints (join hints and index
+// hints) removed. Two statements that only differ by their constants and
+// hints have the same fingerprint.
+func StatementFingerprint(stmt Statement) string {
+	return AsStringWithFlags(stmt, FmtHideHints(FmtHideConstants))
+}
+
+// PinnedHints records the planner hints of a pinned statement, keyed by the
+// table expressions of another statement with the same fingerprint. Hints
+// are matched by position: the n-th table expression of one statement
+// receives the hints of the n-th table expression of the other.
+//
+// Pinned hints replace the hints of the statement they apply to, including
+// the absence of hints: a join without hint in the pinned statement is
+// planned without hint even if the query specified one.
+type PinnedHints struct {
+	Join  map[*JoinTableExpr]string
+	Index map[*AliasedTableExpr]*IndexHints
+}
+
+// MakePinnedHints matches the table expressions of stmt with those of
+// hinted, which must have the same fingerprint. The second return value is
+// false if the statements do not have the same structure.
+func MakePinnedHints(stmt Statement, hinted Statement) (PinnedHints, bool) {
+	exprs := hintableTableExprs(stmt)
+	hintedExprs := hintableTableExprs(hinted)
+	if len(exprs) != len(hintedExprs) {
+		return PinnedHints{}, false
+	}
+	res := PinnedHints{
+		Join:  make(map[*JoinTableExpr]string),
+		Index: make(map[*AliasedTableExpr]*IndexHints),
+	}
+	for i, expr := range exprs {
+		switch t := expr.(type) {
+		case *JoinTableExpr:
+			h, ok := hintedExprs[i].(*JoinTableExpr)
+			if !ok {
+				return PinnedHints{}, false
+			}
+			res.Join[t] = h.Hint
+		case *AliasedTableExpr:
+			h, ok := hintedExprs[i].(*AliasedTableExpr)
+			if !ok {
+				return PinnedHints{}, false
+			}
+			res.Index[t] = h.Hints
+		}
+	}
+	return res, true
+}
+
+// JoinHint returns the hint to use for the given join.
+func (h *PinnedHints) JoinHint(t *JoinTableExpr) string {
+	if h != nil {
+		if hint, ok := h.Join[t]; ok {
+			return hint
+		}
+	}
+	return t.Hint
+}
+
+// IndexHints returns the index hints to use for the given table expression.
+func (h *PinnedHints) IndexHints(t *AliasedTableExpr) *IndexHints {
+	if h != nil {
+		if hints, ok := h.Index[t]; ok {
+			return hints
+		}
+	}
+	return t.Hints
+}
+
+// hintableTableExprs returns the table expressions of stmt that can carry
+// planner hints, in the order in which they are formatted.
+func hintableTableExprs(stmt Statement) []TableExpr {
+	var res []TableExpr
+	f := fmtVisitTableExprs(FmtHideHints(FmtHideConstants), func(t TableExpr) {
+		res = append(res, t)
+	})
+	var buf bytes.Buffer
+	FormatNode(&buf, f, stmt)
+	return res
+}
@@ -9,6 +9,7 @@ var keywords = map[string]struct {
 }{
 	"action":                    {ACTION, "U"},
 	"add":                       {ADD, "U"},
+	"admin":                     {ADMIN, "U"},
 	"all":                       {ALL, "R"},
 	"alter":                     {ALTER, "U"},
 	"analyse":                   {ANALYSE, "R"},
@@ -34,6 +35,7 @@ var keywords = map[string]struct {
 	"by":                        {BY, "U"},
 	"bytea":                     {BYTEA, "C"},
 	"bytes":                     {BYTES, "C"},
+	"cache":                     {CACHE, "U"},
 	"cancel":                    {CANCEL, "U"},
 	"cascade":                   {CASCADE, "U"},
 	"case":                      {CASE, "R"},
@@ -118,6 +120,7 @@ var keywords = map[string]struct {
 	"greatest":                  {GREATEST, "C"},
 	"group":                     {GROUP, "R"},
 	"grouping":                  {GROUPING, "C"},
+	"hash":                      {HASH, "U"},
 	"having":                    {HAVING, "R"},
 	"high":                      {HIGH, "U"},
 	"hour":                      {HOUR, "U"},
@@ -126,6 +129,7 @@ var keywords = map[string]struct {
 	"ilike":                     {ILIKE, "T"},
 	"import":                    {IMPORT, "U"},
 	"in":                        {IN, "R"},
+	"increment":                 {INCREMENT, "U"},
 	"incremental":               {INCREMENTAL, "U"},
 	"index":                     {INDEX, "R"},
 	"indexes":                   {INDEXES, "U"},
@@ -168,10 +172,14 @@ var keywords = map[string]struct {
 	"local":                     {LOCAL, "U"},
 	"localtime":                 {LOCALTIME, "R"},
 	"localtimestamp":            {LOCALTIMESTAMP, "R"},
+	"lookup":                    {LOOKUP, "U"},
 	"low":                       {LOW, "U"},
 	"match":                     {MATCH, "U"},
+	"materialized":              {MATERIALIZED, "U"},
 	"maxvalue":                  {MAXVALUE, "T"},
+	"merge":                     {MERGE, "U"},
 	"minute":                    {MINUTE, "U"},
+	"minvalue":                  {MINVALUE, "U"},
 	"month":                     {MONTH, "U"},
 	"name":                      {NAME, "C"},
 	"names":                     {NAMES, "U"},
@@ -193,6 +201,7 @@ var keywords = map[string]struct {
 	"oid":                       {OID, "U"},
 	"on":                        {ON, "R"},
 	"only":                      {ONLY, "R"},
+	"option":                    {OPTION, "U"},
 	"options":                   {OPTIONS, "U"},
 	"or":                        {OR, "R"},
 	"order":                     {ORDER, "R"},
@@ -224,6 +233,7 @@ var keywords = map[string]struct {
 	"recursive":                 {RECURSIVE, "U"},
 	"ref":                       {REF, "U"},
 	"references":                {REFERENCES, "R"},
+	"refresh":                   {REFRESH, "U"},
 	"regclass":                  {REGCLASS, "U"},
 	"regnamespace":              {REGNAMESPACE, "U"},
 	"regproc":                   {REGPROC, "U"},
@@ -239,6 +249,8 @@ var keywords = map[string]struct {
 	"returning":                 {RETURNING, "R"},
 	"revoke":                    {REVOKE, "U"},
 	"right":                     {RIGHT, "T"},
+	"role":                      {ROLE, "U"},
+	"roles":                     {ROLES, "U"},
 	"rollback":                  {ROLLBACK, "U"},
 	"rollup":                    {ROLLUP, "U"},
 	"row":                       {ROW, "C"},
@@ -249,6 +261,7 @@ var keywords = map[string]struct {
 	"search":                    {SEARCH, "U"},
 	"second":                    {SECOND, "U"},
 	"select":                    {SELECT, "R"},
+	"sequence":                  {SEQUENCE, "U"},
 	"sequences":                 {SEQUENCES, "U"},
 	"serial":                    {SERIAL, "C"},
 	"serializable":              {SERIALIZABLE, "U"},
@@ -268,6 +281,7 @@ var keywords = map[string]struct {
 	"split":                     {SPLIT, "U"},
 	"sql":                       {SQL, "U"},
 	"start":                     {START, "U"},
+	"statistics":                {STATISTICS, "U"},
 	"status":                    {STATUS, "U"},
 	"stdin":                     {STDIN, "U"},
 	"store":                     {STORE, "U"},
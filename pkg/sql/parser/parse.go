@@ -89,6 +89,20 @@ func ParseTableNameWithIndex(sql string) (TableNameWithIndex, error) {
 	return *rename.Index, nil
 }
 
+// ParseTableName parses a table name.
+func ParseTableName(sql string) (*NormalizableTableName, error) {
+	stmt, err := ParseOne(fmt.Sprintf("ALTER TABLE %s RENAME TO x", sql))
+	if err != nil {
+		return nil, err
+	}
+	rename, ok := stmt.(*RenameTable)
+	if !ok {
+		return nil, pgerror.NewErrorf(
+			pgerror.CodeInternalError, "expected an ALTER TABLE statement, but found %T", stmt)
+	}
+	return &rename.Name, nil
+}
+
 // parseExprs parses one or more sql expressions.
 func parseExprs(exprs []string) (Exprs, error) {
 	stmt, err := ParseOne(fmt.Sprintf("SET ROW (%s)", strings.Join(exprs, ",")))
@@ -80,6 +80,15 @@ func TestParse(t *testing.T) {
 		{`CREATE INDEX ON a (b) INTERLEAVE IN PARENT c (d)`},
 		{`CREATE INDEX ON a (b) INTERLEAVE IN PARENT c.d (e)`},
 		{`CREATE INDEX ON a (b ASC, c DESC)`},
+		{`CREATE INDEX ON a (lower(b))`},
+		{`CREATE INDEX ON a (lower(b) DESC, c)`},
+		{`CREATE INDEX ON a ((b + c) ASC)`},
+		{`CREATE INDEX ON a (((lower(b))))`},
+		{`CREATE UNIQUE INDEX a ON b ((c->>'d'))`},
+		{`CREATE TABLE a (b STRING, INDEX (lower(b)))`},
+		{`CREATE INDEX ON a (b) WHERE c > 0`},
+		{`CREATE UNIQUE INDEX a ON b (c) STORING (d) WHERE e = 'f'`},
+		{`CREATE INDEX IF NOT EXISTS a ON b (lower(c)) WHERE d AND (e IS NULL)`},
 		{`CREATE UNIQUE INDEX a ON b (c)`},
 		{`CREATE UNIQUE INDEX a ON b (c) STORING (d)`},
 		{`CREATE UNIQUE INDEX a ON b (c) INTERLEAVE IN PARENT d (e, f)`},
@@ -89,6 +98,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a ()`},
 		{`CREATE TABLE a (b INT)`},
 		{`CREATE TABLE a (b INT, c INT)`},
+		{`CREATE TEMP TABLE a (b INT)`},
+		{`CREATE TEMP TABLE a AS SELECT 1`},
 		{`CREATE TABLE a (b CHAR)`},
 		{`CREATE TABLE a (b CHAR(3))`},
 		{`CREATE TABLE a (b VARCHAR)`},
@@ -172,6 +183,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT, INDEX (b) STORING (c))`},
 		{`CREATE TABLE a (b INT, c TEXT, INDEX (b ASC, c DESC) STORING (c))`},
 		{`CREATE TABLE a (b INT, INDEX (b) INTERLEAVE IN PARENT c (d, e))`},
+		{`CREATE TABLE a (b INT, c STRING, INDEX (b) WHERE c = 'd')`},
+		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b) STORING (c) WHERE c IS NOT NULL)`},
 		{`CREATE TABLE a (b INT, FAMILY (b))`},
 		{`CREATE TABLE a (b INT, c STRING, FAMILY foo (b), FAMILY (c))`},
 		{`CREATE TABLE a (b INT) INTERLEAVE IN PARENT foo (c, d)`},
@@ -220,6 +233,20 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
+		{`CREATE MATERIALIZED VIEW a AS SELECT c, d FROM b`},
+		{`CREATE MATERIALIZED VIEW a (x, y) AS SELECT c, d FROM b`},
+		{`REFRESH MATERIALIZED VIEW a`},
+		{`REFRESH MATERIALIZED VIEW a.b`},
+
+		{`CREATE STATISTICS a ON col1 FROM t`},
+		{`CREATE STATISTICS a ON col1, col2 FROM d.t`},
+
+		{`CREATE SEQUENCE a`},
+		{`CREATE SEQUENCE IF NOT EXISTS a`},
+		{`CREATE SEQUENCE a.b INCREMENT 5 MINVALUE -10 MAXVALUE 100 START 10`},
+		{`CREATE SEQUENCE a INCREMENT BY -1 NO MINVALUE NO MAXVALUE START WITH -5`},
+		{`CREATE SEQUENCE a CACHE 1 NO CYCLE`},
+		{`CREATE SEQUENCE a CYCLE`},
 
 		{`DELETE FROM a`},
 		{`DELETE FROM a.b`},
@@ -231,6 +258,7 @@ func TestParse(t *testing.T) {
 		{`DELETE FROM a WHERE a = b RETURNING NOTHING`},
 
 		{`DISCARD ALL`},
+		{`DISCARD TEMP`},
 
 		{`DROP DATABASE a`},
 		{`DROP DATABASE IF EXISTS a`},
@@ -264,6 +292,12 @@ func TestParse(t *testing.T) {
 		{`DROP VIEW IF EXISTS a, b RESTRICT`},
 		{`DROP VIEW a.b CASCADE`},
 		{`DROP VIEW a, b CASCADE`},
+		{`DROP MATERIALIZED VIEW a`},
+		{`DROP MATERIALIZED VIEW IF EXISTS a, b CASCADE`},
+		{`DROP SEQUENCE a`},
+		{`DROP SEQUENCE a.b`},
+		{`DROP SEQUENCE IF EXISTS a, b RESTRICT`},
+		{`DROP SEQUENCE a CASCADE`},
 
 		{`CANCEL JOB a`},
 		{`CANCEL QUERY a`},
@@ -292,8 +326,13 @@ func TestParse(t *testing.T) {
 		{`SHOW INDEXES FROM a.b.c`},
 		{`SHOW CONSTRAINTS FROM a`},
 		{`SHOW CONSTRAINTS FROM a.b.c`},
+		{`SHOW CREATE SEQUENCE a`},
+		{`SHOW CREATE SEQUENCE a.b`},
+		{`SHOW STATISTICS FOR TABLE t`},
+		{`SHOW STATISTICS FOR TABLE d.t`},
 		{`SHOW TABLES FROM a; SHOW COLUMNS FROM b`},
 		{`SHOW USERS`},
+		{`SHOW ROLES`},
 		{`SHOW JOBS`},
 		{`SHOW CLUSTER QUERIES`},
 		{`SHOW LOCAL QUERIES`},
@@ -382,6 +421,10 @@ func TestParse(t *testing.T) {
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO "test-user"`},
 
+		{`GRANT foo TO bar`},
+		{`GRANT foo, bar TO baz, qux WITH ADMIN OPTION`},
+		{`GRANT "select" TO foo`},
+
 		// Tables are the default, but can also be specified with
 		// REVOKE x ON TABLE y. However, the stringer does not output TABLE.
 		{`REVOKE SELECT ON foo FROM root`},
@@ -391,6 +434,9 @@ func TestParse(t *testing.T) {
 		{`REVOKE SELECT, INSERT ON DATABASE bar FROM foo, bar, baz`},
 		{`REVOKE SELECT, INSERT ON DATABASE db1, db2 FROM foo, bar, baz`},
 
+		{`REVOKE foo FROM bar`},
+		{`REVOKE ADMIN OPTION FOR foo, bar FROM baz, qux`},
+
 		{`INSERT INTO a VALUES (1)`},
 		{`INSERT INTO a.b VALUES (1)`},
 		{`INSERT INTO a VALUES (1, 2)`},
@@ -653,6 +699,19 @@ func TestParse(t *testing.T) {
 		{`SELECT avg(1) OVER (ORDER BY c) FROM t`},
 		{`SELECT avg(1) OVER (PARTITION BY b ORDER BY c) FROM t`},
 		{`SELECT avg(1) OVER (w PARTITION BY b ORDER BY c) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c ROWS UNBOUNDED PRECEDING) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c ROWS 1 PRECEDING) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c ROWS CURRENT ROW) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c ROWS BETWEEN 2 PRECEDING AND 3 FOLLOWING) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c ROWS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING) FROM t`},
+		{`SELECT avg(1) OVER (ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c RANGE UNBOUNDED PRECEDING) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c RANGE BETWEEN 1 PRECEDING AND 1 FOLLOWING) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c RANGE BETWEEN '1s'::INTERVAL PRECEDING AND CURRENT ROW) FROM t`},
+		{`SELECT avg(1) OVER (PARTITION BY b ORDER BY c ROWS BETWEEN 1 FOLLOWING AND 2 FOLLOWING) FROM t`},
+		{`SELECT avg(1) OVER (w ROWS 1 PRECEDING) FROM t`},
+		{`SELECT avg(1) OVER w FROM t WINDOW w AS (ORDER BY c ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING)`},
 
 		{`SELECT a FROM t UNION SELECT 1 FROM t`},
 		{`SELECT a FROM t UNION SELECT 1 FROM t UNION SELECT 1 FROM t`},
@@ -671,6 +730,12 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t1 NATURAL JOIN t2`},
 		{`SELECT a FROM t1 INNER JOIN t2 USING (a)`},
 		{`SELECT a FROM t1 FULL JOIN t2 USING (a)`},
+		{`SELECT a FROM t1 INNER HASH JOIN t2 ON a = b`},
+		{`SELECT a FROM t1 INNER MERGE JOIN t2 USING (a)`},
+		{`SELECT a FROM t1 INNER LOOKUP JOIN t2@foo ON a = b`},
+		{`SELECT a FROM t1 LEFT LOOKUP JOIN t2 ON a = b`},
+		{`SELECT a FROM t1 FULL MERGE JOIN t2 ON a = b`},
+		{`SELECT a FROM t1 NATURAL INNER HASH JOIN t2`},
 		{`SELECT * FROM (t1 WITH ORDINALITY AS o1 CROSS JOIN t2 WITH ORDINALITY AS o2) WITH ORDINALITY AS o3`},
 
 		{`SELECT a FROM t1 AS OF SYSTEM TIME '2016-01-01'`},
@@ -743,6 +808,10 @@ func TestParse(t *testing.T) {
 		{`ALTER DATABASE a RENAME TO b`},
 		{`ALTER TABLE a RENAME TO b`},
 		{`ALTER TABLE IF EXISTS a RENAME TO b`},
+		{`ALTER SEQUENCE a RENAME TO b`},
+		{`ALTER SEQUENCE IF EXISTS a RENAME TO b`},
+		{`ALTER SEQUENCE a INCREMENT 2 NO MAXVALUE`},
+		{`ALTER SEQUENCE IF EXISTS a.b MINVALUE 0 START 5`},
 		{`ALTER INDEX a@b RENAME TO b`},
 		{`ALTER INDEX b RENAME TO b`},
 		{`ALTER INDEX a@primary RENAME TO like`},
@@ -783,6 +852,11 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a ALTER COLUMN b DROP DEFAULT`},
 		{`ALTER TABLE a ALTER COLUMN b DROP NOT NULL`},
 		{`ALTER TABLE a ALTER b DROP NOT NULL`},
+		{`ALTER TABLE a ALTER COLUMN b SET NOT NULL`},
+		{`ALTER TABLE a ALTER b SET NOT NULL`},
+		{`ALTER TABLE a ALTER COLUMN b TYPE INT`},
+		{`ALTER TABLE a ALTER b TYPE STRING(100)`},
+		{`ALTER TABLE a ALTER COLUMN b TYPE INT USING b::INT + 1`},
 
 		{`COPY t FROM STDIN`},
 		{`COPY t (a, b, c) FROM STDIN`},
@@ -853,6 +927,16 @@ func TestParse(t *testing.T) {
 
 		// Regression for #15926
 		{`SELECT * FROM ((t1 NATURAL JOIN t2 WITH ORDINALITY AS o1)) WITH ORDINALITY AS o2`},
+
+		{`WITH a AS (SELECT 1) SELECT * FROM a`},
+		{`WITH a (x, y) AS (SELECT 1, 2), b AS (SELECT x FROM a) SELECT * FROM a, b ORDER BY x LIMIT 1`},
+		{`WITH RECURSIVE t (n) AS (VALUES (1) UNION ALL SELECT n + 1 FROM t WHERE n < 10) SELECT sum(n) FROM t`},
+		{`WITH a AS (INSERT INTO t VALUES (1) RETURNING x) SELECT * FROM a`},
+		{`WITH a AS (SELECT 1) INSERT INTO t SELECT * FROM a`},
+		{`WITH a AS (SELECT 1) UPSERT INTO t SELECT * FROM a`},
+		{`WITH a AS (SELECT 1) UPDATE t SET x = (SELECT * FROM a) WHERE y = 2`},
+		{`WITH a AS (SELECT 1) DELETE FROM t WHERE x IN (SELECT * FROM a) RETURNING y`},
+		{`SELECT * FROM (WITH a AS (SELECT 1) SELECT * FROM a)`},
 	}
 	for _, d := range testData {
 		stmts, err := Parse(d.sql)
@@ -884,7 +968,10 @@ func TestParse2(t *testing.T) {
 			`CREATE TABLE a (b INT, CONSTRAINT foo UNIQUE (b))`},
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b) INTERLEAVE IN PARENT c (d))`,
 			`CREATE TABLE a (b INT, CONSTRAINT foo UNIQUE (b) INTERLEAVE IN PARENT c (d))`},
+		{`CREATE TEMPORARY TABLE a (b INT)`, `CREATE TEMP TABLE a (b INT)`},
 		{`CREATE INDEX ON a (b) COVERING (c)`, `CREATE INDEX ON a (b) STORING (c)`},
+		{`ALTER TABLE a ALTER COLUMN b SET DATA TYPE INT`, `ALTER TABLE a ALTER COLUMN b TYPE INT`},
+		{`DISCARD TEMPORARY`, `DISCARD TEMP`},
 
 		{`SELECT TIMESTAMP WITHOUT TIME ZONE 'foo'`, `SELECT TIMESTAMP 'foo'`},
 		{`SELECT CAST('foo' AS TIMESTAMP WITHOUT TIME ZONE)`, `SELECT CAST('foo' AS TIMESTAMP)`},
@@ -958,6 +1045,8 @@ func TestParse2(t *testing.T) {
 			`SELECT a FROM t1 LEFT JOIN t2 ON a = b`},
 		{`SELECT a FROM t1 RIGHT OUTER JOIN t2 ON a = b`,
 			`SELECT a FROM t1 RIGHT JOIN t2 ON a = b`},
+		{`SELECT a FROM t1 LEFT OUTER MERGE JOIN t2 ON a = b`,
+			`SELECT a FROM t1 LEFT MERGE JOIN t2 ON a = b`},
 		// Some functions are nearly keywords.
 		{`SELECT CURRENT_SCHEMA`,
 			`SELECT current_schema()`},
@@ -975,10 +1064,16 @@ func TestParse2(t *testing.T) {
 			`SELECT rtrim(b, a)`},
 		{`SELECT TRIM(a, b)`,
 			`SELECT btrim(a, b)`},
+		{`SELECT a AT TIME ZONE 'UTC'`,
+			`SELECT timezone('UTC', a)`},
+		{`SELECT a + b AT TIME ZONE c`,
+			`SELECT a + timezone(c, b)`},
 		{`SELECT CURRENT_USER`,
 			`SELECT current_user()`},
 		{`SELECT SESSION_USER`,
 			`SELECT current_user()`},
+		{`SELECT CURRENT_ROLE`,
+			`SELECT current_user()`},
 		{`SELECT USER`,
 			`SELECT current_user()`},
 		// Offset has an optional ROW/ROWS keyword.
@@ -1185,6 +1280,16 @@ func TestParse2(t *testing.T) {
 			`CREATE USER 'foo' WITH PASSWORD 'bar'`},
 		{`DROP USER foo, bar`,
 			`DROP USER 'foo', 'bar'`},
+		{`CREATE ROLE foo`,
+			`CREATE ROLE 'foo'`},
+		{`CREATE ROLE IF NOT EXISTS foo`,
+			`CREATE ROLE IF NOT EXISTS 'foo'`},
+		{`DROP ROLE IF EXISTS foo, bar`,
+			`DROP ROLE IF EXISTS 'foo', 'bar'`},
+		{`GRANT select TO foo`,
+			`GRANT "select" TO foo`},
+		{`GRANT create, insert ON foo TO bar`,
+			`GRANT CREATE, INSERT ON foo TO bar`},
 		{`ALTER USER foo WITH PASSWORD bar`,
 			`ALTER USER 'foo' WITH PASSWORD 'bar'`},
 
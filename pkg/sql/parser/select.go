@@ -23,6 +23,7 @@ package parser
 import (
 	"bytes"
 	"fmt"
+	"strings"
 )
 
 // SelectStatement represents any SELECT statement.
@@ -38,6 +39,7 @@ func (*ValuesClause) selectStatement() {}
 
 // Select represents a SelectStatement with an ORDER and/or LIMIT.
 type Select struct {
+	With          *With
 	Select        SelectStatement
 	OrderBy       OrderBy
 	Limit         *Limit
@@ -46,6 +48,7 @@ type Select struct {
 
 // Format implements the NodeFormatter interface.
 func (node *Select) Format(buf *bytes.Buffer, f FmtFlags) {
+	FormatNode(buf, f, node.With)
 	FormatNode(buf, f, node.Select)
 	FormatNode(buf, f, node.OrderBy)
 	FormatNode(buf, f, node.Limit)
@@ -164,6 +167,45 @@ func (a AliasClause) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// With represents a WITH statement, i.e. a list of common table
+// expressions that are visible to the statement they are attached to.
+type With struct {
+	Recursive bool
+	CTEList   []*CTE
+}
+
+// Format implements the NodeFormatter interface.
+func (node *With) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node == nil {
+		return
+	}
+	buf.WriteString("WITH ")
+	if node.Recursive {
+		buf.WriteString("RECURSIVE ")
+	}
+	for i, cte := range node.CTEList {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, cte)
+	}
+	buf.WriteByte(' ')
+}
+
+// CTE represents a common table expression inside of a WITH clause.
+type CTE struct {
+	Name AliasClause
+	Stmt Statement
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CTE) Format(buf *bytes.Buffer, f FmtFlags) {
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" AS (")
+	FormatNode(buf, f, node.Stmt)
+	buf.WriteByte(')')
+}
+
 // AsOfClause represents an as of time.
 type AsOfClause struct {
 	Expr Expr
@@ -280,8 +322,11 @@ type AliasedTableExpr struct {
 
 // Format implements the NodeFormatter interface.
 func (node *AliasedTableExpr) Format(buf *bytes.Buffer, f FmtFlags) {
+	if f.tableExprVisitor != nil {
+		f.tableExprVisitor(node)
+	}
 	FormatNode(buf, f, node.Expr)
-	if node.Hints != nil {
+	if node.Hints != nil && !f.hideHints {
 		FormatNode(buf, f, node.Hints)
 	}
 	if node.Ordinality {
@@ -309,7 +354,10 @@ func (node *ParenTableExpr) Format(buf *bytes.Buffer, f FmtFlags) {
 
 // JoinTableExpr represents a TableExpr that's a JOIN operation.
 type JoinTableExpr struct {
-	Join  string
+	Join string
+	// Hint is the join algorithm requested by the query, if any. It is one
+	// of AstHash, AstMerge or AstLookup.
+	Hint  string
 	Left  TableExpr
 	Right TableExpr
 	Cond  JoinCond
@@ -325,20 +373,42 @@ const (
 	astInnerJoin = "INNER JOIN"
 )
 
+// Join hints.
+const (
+	AstHash   = "HASH"
+	AstLookup = "LOOKUP"
+	AstMerge  = "MERGE"
+)
+
+// formatJoin writes the join type of node, including its hint if any.
+func (node *JoinTableExpr) formatJoin(buf *bytes.Buffer, f FmtFlags) {
+	if node.Hint == "" || f.hideHints {
+		buf.WriteString(node.Join)
+		return
+	}
+	// The hint goes right before the JOIN keyword, e.g. "LEFT HASH JOIN".
+	buf.WriteString(strings.TrimSuffix(node.Join, "JOIN"))
+	buf.WriteString(node.Hint)
+	buf.WriteString(" JOIN")
+}
+
 // Format implements the NodeFormatter interface.
 func (node *JoinTableExpr) Format(buf *bytes.Buffer, f FmtFlags) {
+	if f.tableExprVisitor != nil {
+		f.tableExprVisitor(node)
+	}
 	FormatNode(buf, f, node.Left)
 	buf.WriteByte(' ')
 	if _, isNatural := node.Cond.(NaturalJoinCond); isNatural {
 		// Natural joins have a different syntax: "<a> NATURAL <join_type> <b>"
 		FormatNode(buf, f, node.Cond)
 		buf.WriteByte(' ')
-		buf.WriteString(node.Join)
+		node.formatJoin(buf, f)
 		buf.WriteByte(' ')
 		FormatNode(buf, f, node.Right)
 	} else {
 		// General syntax: "<a> <join_type> <b> <condition>"
-		buf.WriteString(node.Join)
+		node.formatJoin(buf, f)
 		buf.WriteByte(' ')
 		FormatNode(buf, f, node.Right)
 		if node.Cond != nil {
@@ -550,6 +620,7 @@ type WindowDef struct {
 	RefName    Name
 	Partitions Exprs
 	OrderBy    OrderBy
+	Frame      *WindowFrame
 }
 
 // Format implements the NodeFormatter interface.
@@ -578,9 +649,102 @@ func (node *WindowDef) Format(buf *bytes.Buffer, f FmtFlags) {
 			buf.WriteString(tmpBuf.String()[1:])
 		}
 		needSpaceSeparator = true
-		_ = needSpaceSeparator // avoid compiler warning until TODO below is addressed.
 	}
-	// TODO(nvanbenschoten): Support Window Frames.
-	// if node.Frame != nil {}
+	if node.Frame != nil {
+		if needSpaceSeparator {
+			buf.WriteRune(' ')
+		}
+		FormatNode(buf, f, node.Frame)
+	}
 	buf.WriteRune(')')
 }
+
+// WindowFrameMode indicates which mode of framing is used.
+type WindowFrameMode int
+
+const (
+	// RangeMode is the mode of specifying frame in terms of logical range (e.g. 100 units cheaper).
+	RangeMode WindowFrameMode = iota
+	// RowsMode is the mode of specifying frame in terms of physical offsets (e.g. 1 row before etc).
+	RowsMode
+)
+
+var windowFrameModeName = [...]string{
+	RangeMode: "RANGE",
+	RowsMode:  "ROWS",
+}
+
+func (m WindowFrameMode) String() string {
+	return windowFrameModeName[m]
+}
+
+// WindowFrameBoundType indicates which type of boundary is used.
+type WindowFrameBoundType int
+
+const (
+	// UnboundedPreceding represents UNBOUNDED PRECEDING type of boundary.
+	UnboundedPreceding WindowFrameBoundType = iota
+	// ValuePreceding represents 'value' PRECEDING type of boundary.
+	ValuePreceding
+	// CurrentRow represents CURRENT ROW type of boundary.
+	CurrentRow
+	// ValueFollowing represents 'value' FOLLOWING type of boundary.
+	ValueFollowing
+	// UnboundedFollowing represents UNBOUNDED FOLLOWING type of boundary.
+	UnboundedFollowing
+)
+
+// WindowFrameBound specifies the offset and the type of boundary.
+type WindowFrameBound struct {
+	BoundType WindowFrameBoundType
+	// OffsetExpr is only set for ValuePreceding and ValueFollowing.
+	OffsetExpr Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *WindowFrameBound) Format(buf *bytes.Buffer, f FmtFlags) {
+	switch node.BoundType {
+	case UnboundedPreceding:
+		buf.WriteString("UNBOUNDED PRECEDING")
+	case ValuePreceding:
+		FormatNode(buf, f, node.OffsetExpr)
+		buf.WriteString(" PRECEDING")
+	case CurrentRow:
+		buf.WriteString("CURRENT ROW")
+	case ValueFollowing:
+		FormatNode(buf, f, node.OffsetExpr)
+		buf.WriteString(" FOLLOWING")
+	case UnboundedFollowing:
+		buf.WriteString("UNBOUNDED FOLLOWING")
+	default:
+		panic(fmt.Sprintf("unhandled case: %d", node.BoundType))
+	}
+}
+
+// WindowFrameBounds specifies boundaries of the window frame.
+type WindowFrameBounds struct {
+	StartBound *WindowFrameBound
+	// EndBound is nil if the frame was specified with a single bound, in
+	// which case the frame ends at the current row.
+	EndBound *WindowFrameBound
+}
+
+// WindowFrame represents static state of window frame over which calculations are made.
+type WindowFrame struct {
+	Mode   WindowFrameMode
+	Bounds WindowFrameBounds
+}
+
+// Format implements the NodeFormatter interface.
+func (node *WindowFrame) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString(node.Mode.String())
+	buf.WriteByte(' ')
+	if node.Bounds.EndBound != nil {
+		buf.WriteString("BETWEEN ")
+		FormatNode(buf, f, node.Bounds.StartBound)
+		buf.WriteString(" AND ")
+		FormatNode(buf, f, node.Bounds.EndBound)
+	} else {
+		FormatNode(buf, f, node.Bounds.StartBound)
+	}
+}
@@ -218,6 +218,28 @@ func (node *ShowCreateView) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, &node.View)
 }
 
+// ShowCreateSequence represents a SHOW CREATE SEQUENCE statement.
+type ShowCreateSequence struct {
+	Sequence NormalizableTableName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowCreateSequence) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW CREATE SEQUENCE ")
+	FormatNode(buf, f, &node.Sequence)
+}
+
+// ShowTableStats represents a SHOW STATISTICS FOR TABLE statement.
+type ShowTableStats struct {
+	Table NormalizableTableName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowTableStats) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW STATISTICS FOR TABLE ")
+	FormatNode(buf, f, &node.Table)
+}
+
 // ShowTransactionStatus represents a SHOW TRANSACTION STATUS statement.
 type ShowTransactionStatus struct {
 }
@@ -236,6 +258,15 @@ func (node *ShowUsers) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("SHOW USERS")
 }
 
+// ShowRoles represents a SHOW ROLES statement.
+type ShowRoles struct {
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowRoles) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW ROLES")
+}
+
 // ShowRanges represents a SHOW TESTING_RANGES statement.
 // Only one of Table and Index can be set.
 type ShowRanges struct {
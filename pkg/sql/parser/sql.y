@@ -91,6 +91,9 @@ type sqlSymUnion struct {
 func (u *sqlSymUnion) numVal() *NumVal {
     return u.val.(*NumVal)
 }
+func (u *sqlSymUnion) int64() int64 {
+    return u.val.(int64)
+}
 func (u *sqlSymUnion) strVal() *StrVal {
     if stmt, ok := u.val.(*StrVal); ok {
         return stmt
@@ -269,9 +272,6 @@ func (u *sqlSymUnion) targetList() TargetList {
 func (u *sqlSymUnion) targetListPtr() *TargetList {
     return u.val.(*TargetList)
 }
-func (u *sqlSymUnion) privilegeType() privilege.Kind {
-    return u.val.(privilege.Kind)
-}
 func (u *sqlSymUnion) privilegeList() privilege.List {
     return u.val.(privilege.List)
 }
@@ -338,9 +338,27 @@ func (u *sqlSymUnion) tuples() []*Tuple {
 func (u *sqlSymUnion) windowDef() *WindowDef {
     return u.val.(*WindowDef)
 }
+func (u *sqlSymUnion) windowFrame() *WindowFrame {
+    return u.val.(*WindowFrame)
+}
+func (u *sqlSymUnion) windowFrameBounds() WindowFrameBounds {
+    return u.val.(WindowFrameBounds)
+}
+func (u *sqlSymUnion) windowFrameBound() *WindowFrameBound {
+    return u.val.(*WindowFrameBound)
+}
 func (u *sqlSymUnion) window() Window {
     return u.val.(Window)
 }
+func (u *sqlSymUnion) with() *With {
+    return u.val.(*With)
+}
+func (u *sqlSymUnion) cte() *CTE {
+    return u.val.(*CTE)
+}
+func (u *sqlSymUnion) ctes() []*CTE {
+    return u.val.([]*CTE)
+}
 func (u *sqlSymUnion) op() operator {
     return u.val.(operator)
 }
@@ -377,6 +395,14 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
     return u.val.(ScrubOption)
 }
 
+func (u *sqlSymUnion) seqOpt() SequenceOption {
+    return u.val.(SequenceOption)
+}
+
+func (u *sqlSymUnion) seqOpts() []SequenceOption {
+    return u.val.([]SequenceOption)
+}
+
 %}
 
 // NB: the %token definitions must come before the %type definitions in this
@@ -396,14 +422,14 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str>   ACTION ADD
+%token <str>   ACTION ADD ADMIN
 %token <str>   ALL ALL_EXISTENCE ALTER ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str>   ASYMMETRIC AT
 
 %token <str>   BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
 %token <str>   BLOB BOOL BOOLEAN BOTH BY BYTEA BYTES
 
-%token <str>   CANCEL CASCADE CASE CAST CHAR
+%token <str>   CACHE CANCEL CASCADE CASE CAST CHAR
 %token <str>   CHARACTER CHARACTERISTICS CHECK
 %token <str>   CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMIT
 %token <str>   COMMITTED CONCAT CONFIGURATION CONFIGURATIONS CONFIGURE
@@ -425,9 +451,9 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 
 %token <str>   GRANT GRANTS GREATEST GROUP GROUPING
 
-%token <str>   HAVING HELP HIGH HOUR
+%token <str>   HASH HAVING HELP HIGH HOUR
 
-%token <str>   IMPORT INCREMENTAL IF IFNULL ILIKE IN INET INTERLEAVE
+%token <str>   IMPORT INCREMENT INCREMENTAL IF IFNULL ILIKE IN INET INTERLEAVE
 %token <str>   INDEX INDEXES INITIALLY
 %token <str>   INNER INSERT INT INT2VECTOR INT2 INT4 INT8 INT64 INTEGER
 %token <str>   INTERSECT INTERVAL INTO IS ISOLATION
@@ -438,15 +464,15 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 
 %token <str>   LATERAL LC_CTYPE LC_COLLATE
 %token <str>   LEADING LEAST LEFT LESS LEVEL LIKE LIMIT LIST LOCAL
-%token <str>   LOCALTIME LOCALTIMESTAMP LOW LSHIFT
+%token <str>   LOCALTIME LOCALTIMESTAMP LOOKUP LOW LSHIFT
 
-%token <str>   MATCH MAXVALUE MINUTE MONTH
+%token <str>   MATCH MATERIALIZED MAXVALUE MERGE MINUTE MINVALUE MONTH
 
 %token <str>   NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
 %token <str>   NOT NOTHING NULL NULLIF
 %token <str>   NULLS NUMERIC
 
-%token <str>   OF OFF OFFSET OID ON ONLY OPTIONS OR
+%token <str>   OF OFF OFFSET OID ON ONLY OPTION OPTIONS OR
 %token <str>   ORDER ORDINALITY OUT OUTER OVER OVERLAPS OVERLAY
 
 %token <str>   PARENT PARTIAL PARTITION PASSWORD PAUSE PHYSICAL PLACING
@@ -454,16 +480,16 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 
 %token <str>   QUERIES QUERY
 
-%token <str>   RANGE READ REAL RECURSIVE REF REFERENCES
+%token <str>   RANGE READ REAL RECURSIVE REF REFERENCES REFRESH
 %token <str>   REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
 %token <str>   REMOVE_PATH RENAME REPEATABLE
 %token <str>   RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
-%token <str>   ROLLBACK ROLLUP ROW ROWS RSHIFT
+%token <str>   ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT
 
-%token <str>   SAVEPOINT SCATTER SCRUB SEARCH SECOND SELECT SEQUENCES
+%token <str>   SAVEPOINT SCATTER SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str>   SERIAL SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
 %token <str>   SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SOME_EXISTENCE SPLIT SQL
-%token <str>   START STATUS STDIN STRICT STRING STORE STORING SUBSTRING
+%token <str>   START STATISTICS STATUS STDIN STRICT STRING STORE STORING SUBSTRING
 %token <str>   SYMMETRIC SYSTEM
 
 %token <str>   TABLE TABLES TEMP TEMPLATE TEMPORARY TESTING_RANGES TESTING_RELOCATE TEXT THAN THEN
@@ -508,6 +534,7 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <Statement> alter_table_stmt
 %type <Statement> alter_index_stmt
 %type <Statement> alter_view_stmt
+%type <Statement> alter_sequence_stmt
 %type <Statement> alter_database_stmt
 %type <Statement> alter_user_stmt
 %type <Statement> alter_range_stmt
@@ -540,6 +567,10 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 // ALTER VIEW
 %type <Statement> alter_rename_view_stmt
 
+// ALTER SEQUENCE
+%type <Statement> alter_rename_sequence_stmt
+%type <Statement> alter_sequence_options_stmt
+
 %type <Statement> backup_stmt
 %type <Statement> begin_stmt
 
@@ -562,8 +593,11 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <Statement> create_index_stmt
 %type <Statement> create_table_stmt
 %type <Statement> create_table_as_stmt
+%type <Statement> create_role_stmt
 %type <Statement> create_user_stmt
 %type <Statement> create_view_stmt
+%type <Statement> create_sequence_stmt
+%type <Statement> create_stats_stmt
 %type <Statement> delete_stmt
 %type <Statement> discard_stmt
 
@@ -572,8 +606,10 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <Statement> drop_database_stmt
 %type <Statement> drop_index_stmt
 %type <Statement> drop_table_stmt
+%type <Statement> drop_role_stmt
 %type <Statement> drop_user_stmt
 %type <Statement> drop_view_stmt
+%type <Statement> drop_sequence_stmt
 
 %type <Statement> explain_stmt
 %type <Statement> prepare_stmt
@@ -585,6 +621,7 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <Statement> insert_stmt
 %type <Statement> import_stmt
 %type <Statement> pause_stmt
+%type <Statement> refresh_stmt
 %type <Statement> release_stmt
 %type <Statement> reset_stmt reset_session_stmt reset_csetting_stmt
 %type <Statement> resume_stmt
@@ -609,6 +646,7 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <Statement> show_constraints_stmt
 %type <Statement> show_create_table_stmt
 %type <Statement> show_create_view_stmt
+%type <Statement> show_create_sequence_stmt
 %type <Statement> show_csettings_stmt
 %type <Statement> show_databases_stmt
 %type <Statement> show_grants_stmt
@@ -617,10 +655,12 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <Statement> show_queries_stmt
 %type <Statement> show_session_stmt
 %type <Statement> show_sessions_stmt
+%type <Statement> show_stats_stmt
 %type <Statement> show_tables_stmt
 %type <Statement> show_testing_stmt
 %type <Statement> show_trace_stmt
 %type <Statement> show_transaction_stmt
+%type <Statement> show_roles_stmt
 %type <Statement> show_users_stmt
 %type <Statement> show_zone_stmt
 
@@ -635,13 +675,15 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <[]string> opt_incremental
 %type <KVOption> kv_option
 %type <[]KVOption> kv_option_list opt_with_options
+%type <[]SequenceOption> sequence_option_list opt_sequence_option_list
+%type <SequenceOption> sequence_option_elem
 %type <str> import_data_format
 
 %type <*Select> select_no_parens
 %type <SelectStatement> select_clause select_with_parens simple_select values_clause table_clause simple_select_clause
 %type <SelectStatement> set_operation
 
-%type <empty> alter_using
+%type <Expr> alter_using
 %type <Expr> alter_column_default
 %type <Direction> opt_asc_desc
 
@@ -721,6 +763,7 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <empty> join_outer
 %type <JoinCond> join_qual
 %type <str> join_type
+%type <str> opt_join_hint
 
 %type <Exprs> extract_list
 %type <Exprs> overlay_list
@@ -732,6 +775,7 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <Expr> overlay_placing
 
 %type <bool> opt_unique opt_column
+%type <bool> opt_temp
 
 %type <empty> opt_set_data
 
@@ -803,6 +847,7 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <empty> opt_varying
 
 %type <*NumVal>  signed_iconst
+%type <int64>  signed_iconst64
 %type <Expr>  var_value
 %type <Exprs> var_list
 %type <UnresolvedName> var_name
@@ -828,9 +873,10 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 
 %type <Expr>  func_application func_expr_common_subexpr
 %type <Expr>  func_expr func_expr_windowless
-%type <empty> common_table_expr
-%type <empty> with_clause opt_with opt_with_clause
-%type <empty> cte_list
+%type <*CTE> common_table_expr
+%type <*With> with_clause opt_with_clause
+%type <[]*CTE> cte_list
+%type <empty> opt_with
 
 %type <empty> within_group_clause
 %type <Expr> filter_clause
@@ -838,15 +884,19 @@ func (u *sqlSymUnion) scrubOption() ScrubOption {
 %type <Window> window_clause window_definition_list
 %type <*WindowDef> window_definition over_clause window_specification
 %type <str> opt_existing_window_name
-%type <empty> opt_frame_clause frame_extent frame_bound
+%type <*WindowFrame> opt_frame_clause
+%type <WindowFrameBounds> frame_extent
+%type <*WindowFrameBound> frame_bound
 
 %type <[]ColumnID> opt_tableref_col_list tableref_col_list
 
 %type <TargetList>    targets
 %type <*TargetList> on_privilege_target_clause
 %type <NameList>       grantee_list for_grantee_clause
-%type <privilege.List> privileges privilege_list
-%type <privilege.Kind> privilege
+%type <privilege.List> privileges
+%type <NameList>       privilege_list
+%type <str>            privilege
+%type <bool>           opt_with_admin_option
 
 // Precedence: lowest to highest
 %nonassoc  VALUES              // see value_clause
@@ -963,6 +1013,7 @@ stmt:
   {
     $$.val = $1.slct()
   }
+| refresh_stmt     // EXTEND WITH HELP: REFRESH MATERIALIZED VIEW
 | release_stmt     // EXTEND WITH HELP: RELEASE
 | reset_stmt       // help texts in sub-rule
 | set_stmt         // help texts in sub-rule
@@ -978,7 +1029,7 @@ stmt:
 
 // %Help: ALTER
 // %Category: Group
-// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER DATABASE, ALTER USER
+// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE, ALTER USER
 alter_stmt:
   alter_ddl_stmt      // help texts in sub-rule
 | alter_user_stmt     // EXTEND WITH HELP: ALTER USER
@@ -988,6 +1039,7 @@ alter_ddl_stmt:
   alter_table_stmt    // EXTEND WITH HELP: ALTER TABLE
 | alter_index_stmt    // EXTEND WITH HELP: ALTER INDEX
 | alter_view_stmt     // EXTEND WITH HELP: ALTER VIEW
+| alter_sequence_stmt // EXTEND WITH HELP: ALTER SEQUENCE
 | alter_database_stmt // EXTEND WITH HELP: ALTER DATABASE
 | alter_range_stmt
 
@@ -1002,7 +1054,8 @@ alter_ddl_stmt:
 //   ALTER TABLE ... DROP [COLUMN] [IF EXISTS] <colname> [RESTRICT | CASCADE]
 //   ALTER TABLE ... DROP CONSTRAINT [IF EXISTS] <constraintname> [RESTRICT | CASCADE]
 //   ALTER TABLE ... ALTER [COLUMN] <colname> {SET DEFAULT <expr> | DROP DEFAULT}
-//   ALTER TABLE ... ALTER [COLUMN] <colname> DROP NOT NULL
+//   ALTER TABLE ... ALTER [COLUMN] <colname> {SET NOT NULL | DROP NOT NULL}
+//   ALTER TABLE ... ALTER [COLUMN] <colname> [SET DATA] TYPE <type> [USING <expr>]
 //   ALTER TABLE ... RENAME TO <newname>
 //   ALTER TABLE ... RENAME [COLUMN] <colname> TO <newname>
 //   ALTER TABLE ... VALIDATE CONSTRAINT <constraintname>
@@ -1038,6 +1091,32 @@ alter_view_stmt:
 // prefix is spread over multiple non-terminals.
 | ALTER VIEW error // SHOW HELP: ALTER VIEW
 
+// %Help: ALTER SEQUENCE - change the definition of a sequence
+// %Category: DDL
+// %Text:
+// ALTER SEQUENCE [IF EXISTS] <name>
+//   [INCREMENT <increment>]
+//   [MINVALUE <minvalue> | NO MINVALUE]
+//   [MAXVALUE <maxvalue> | NO MAXVALUE]
+//   [START <start>]
+//   [[NO] CYCLE]
+// ALTER SEQUENCE [IF EXISTS] <name> RENAME TO <newname>
+// %SeeAlso: CREATE SEQUENCE, DROP SEQUENCE
+alter_sequence_stmt:
+  alter_rename_sequence_stmt
+| alter_sequence_options_stmt
+| ALTER SEQUENCE error // SHOW HELP: ALTER SEQUENCE
+
+alter_sequence_options_stmt:
+  ALTER SEQUENCE relation_expr sequence_option_list
+  {
+    $$.val = &AlterSequence{Name: $3.normalizableTableName(), Options: $4.seqOpts(), IfExists: false}
+  }
+| ALTER SEQUENCE IF EXISTS relation_expr sequence_option_list
+  {
+    $$.val = &AlterSequence{Name: $5.normalizableTableName(), Options: $6.seqOpts(), IfExists: true}
+  }
+
 // %Help: ALTER USER - change user properties
 // %Category: Priv
 // %Text:
@@ -1227,7 +1306,10 @@ alter_table_cmd:
     $$.val = &AlterTableDropNotNull{columnKeyword: $2.bool(), Column: Name($3)}
   }
   // ALTER TABLE <name> ALTER [COLUMN] <colname> SET NOT NULL
-| ALTER opt_column name SET NOT NULL { return unimplemented(sqllex, "alter set non null") }
+| ALTER opt_column name SET NOT NULL
+  {
+    $$.val = &AlterTableSetNotNull{columnKeyword: $2.bool(), Column: Name($3)}
+  }
   // ALTER TABLE <name> DROP [COLUMN] IF EXISTS <colname> [RESTRICT|CASCADE]
 | DROP opt_column IF EXISTS name opt_drop_behavior
   {
@@ -1250,7 +1332,15 @@ alter_table_cmd:
   }
   // ALTER TABLE <name> ALTER [COLUMN] <colname> [SET DATA] TYPE <typename>
   //     [ USING <expression> ]
-| ALTER opt_column name opt_set_data TYPE typename opt_collate_clause alter_using { return unimplemented(sqllex, "alter set type") }
+| ALTER opt_column name opt_set_data TYPE typename opt_collate_clause alter_using
+  {
+    $$.val = &AlterTableAlterColumnType{
+      columnKeyword: $2.bool(),
+      Column: Name($3),
+      ToType: $6.colType(),
+      Using: $8.expr(),
+    }
+  }
   // ALTER TABLE <name> ADD CONSTRAINT ...
 | ADD table_constraint opt_validate_behavior
   {
@@ -1326,8 +1416,14 @@ opt_collate_clause:
 | /* EMPTY */ {}
 
 alter_using:
-  USING a_expr { return unimplemented(sqllex, "alter using") }
-| /* EMPTY */ {}
+  USING a_expr
+  {
+    $$.val = $2.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
 
 // %Help: BACKUP - back up data to external storage
 // %Category: CCL
@@ -1537,10 +1633,12 @@ cancel_query_stmt:
 // %Category: Group
 // %Text:
 // CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
-// CREATE USER, CREATE VIEW
+// CREATE USER, CREATE ROLE, CREATE VIEW, CREATE SEQUENCE
 create_stmt:
   create_user_stmt     // EXTEND WITH HELP: CREATE USER
+| create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
+| create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
 | CREATE error         // SHOW HELP: CREATE
 
 create_ddl_stmt:
@@ -1549,8 +1647,9 @@ create_ddl_stmt:
 | create_table_stmt    // EXTEND WITH HELP: CREATE TABLE
 | create_table_as_stmt // EXTEND WITH HELP: CREATE TABLE
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
-| CREATE TABLE error   // SHOW HELP: CREATE TABLE
+| CREATE opt_temp TABLE error // SHOW HELP: CREATE TABLE
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
+| create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
 
 
 // %Help: DELETE - delete rows from a table
@@ -1563,6 +1662,7 @@ delete_stmt:
   opt_with_clause DELETE FROM relation_expr_opt_alias where_clause opt_limit_clause returning_clause
   {
     $$.val = &Delete{
+      With: $1.with(),
       Table: $4.tblExpr(),
       Where: newWhere(astWhere, $5.expr()),
       Limit: $6.limit(),
@@ -1573,7 +1673,7 @@ delete_stmt:
 
 // %Help: DISCARD - reset the session to its initial state
 // %Category: Cfg
-// %Text: DISCARD ALL
+// %Text: DISCARD { ALL | TEMP | TEMPORARY }
 discard_stmt:
   DISCARD ALL
   {
@@ -1581,15 +1681,23 @@ discard_stmt:
   }
 | DISCARD PLANS { return unimplemented(sqllex, "discard plans") }
 | DISCARD SEQUENCES { return unimplemented(sqllex, "discard sequences") }
-| DISCARD TEMP { return unimplemented(sqllex, "discard temp") }
-| DISCARD TEMPORARY { return unimplemented(sqllex, "discard temporary") }
+| DISCARD TEMP
+  {
+    $$.val = &Discard{Mode: DiscardModeTemp}
+  }
+| DISCARD TEMPORARY
+  {
+    $$.val = &Discard{Mode: DiscardModeTemp}
+  }
 | DISCARD error // SHOW HELP: DISCARD
 
 // %Help: DROP
 // %Category: Group
-// %Text: DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP USER
+// %Text: DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE, DROP USER,
+// DROP ROLE
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
+| drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
 | DROP error         // SHOW HELP: DROP
 
@@ -1598,10 +1706,11 @@ drop_ddl_stmt:
 | drop_index_stmt    // EXTEND WITH HELP: DROP INDEX
 | drop_table_stmt    // EXTEND WITH HELP: DROP TABLE
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
+| drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
-// %Text: DROP VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
+// %Text: DROP [MATERIALIZED] VIEW [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
 // %SeeAlso: WEBDOCS/drop-index.html
 drop_view_stmt:
   DROP VIEW table_name_list opt_drop_behavior
@@ -1612,7 +1721,31 @@ drop_view_stmt:
   {
     $$.val = &DropView{Names: $5.tableNameReferences(), IfExists: true, DropBehavior: $6.dropBehavior()}
   }
+| DROP MATERIALIZED VIEW table_name_list opt_drop_behavior
+  {
+    $$.val = &DropView{Names: $4.tableNameReferences(), IfExists: false, DropBehavior: $5.dropBehavior(), IsMaterialized: true}
+  }
+| DROP MATERIALIZED VIEW IF EXISTS table_name_list opt_drop_behavior
+  {
+    $$.val = &DropView{Names: $6.tableNameReferences(), IfExists: true, DropBehavior: $7.dropBehavior(), IsMaterialized: true}
+  }
 | DROP VIEW error // SHOW HELP: DROP VIEW
+| DROP MATERIALIZED VIEW error // SHOW HELP: DROP VIEW
+
+// %Help: DROP SEQUENCE - remove a sequence
+// %Category: DDL
+// %Text: DROP SEQUENCE [IF EXISTS] <sequenceName> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: DROP
+drop_sequence_stmt:
+  DROP SEQUENCE table_name_list opt_drop_behavior
+  {
+    $$.val = &DropSequence{Names: $3.tableNameReferences(), IfExists: false, DropBehavior: $4.dropBehavior()}
+  }
+| DROP SEQUENCE IF EXISTS table_name_list opt_drop_behavior
+  {
+    $$.val = &DropSequence{Names: $5.tableNameReferences(), IfExists: true, DropBehavior: $6.dropBehavior()}
+  }
+| DROP SEQUENCE error // SHOW HELP: DROP SEQUENCE
 
 // %Help: DROP TABLE - remove a table
 // %Category: DDL
@@ -1690,6 +1823,21 @@ drop_user_stmt:
   }
 | DROP USER error // SHOW HELP: DROP USER
 
+// %Help: DROP ROLE - remove a role
+// %Category: Priv
+// %Text: DROP ROLE [IF EXISTS] <role> [, ...]
+// %SeeAlso: CREATE ROLE, SHOW ROLES
+drop_role_stmt:
+  DROP ROLE string_or_placeholder_list
+  {
+    $$.val = &DropRole{Names: $3.exprs(), IfExists: false}
+  }
+| DROP ROLE IF EXISTS string_or_placeholder_list
+  {
+    $$.val = &DropRole{Names: $5.exprs(), IfExists: true}
+  }
+| DROP ROLE error // SHOW HELP: DROP ROLE
+
 table_name_list:
   any_name
   {
@@ -1754,8 +1902,10 @@ preparable_stmt:
   alter_user_stmt   // EXTEND WITH HELP: ALTER USER
 | backup_stmt       // EXTEND WITH HELP: BACKUP
 | cancel_stmt       // help texts in sub-rule
+| create_role_stmt  // EXTEND WITH HELP: CREATE ROLE
 | create_user_stmt  // EXTEND WITH HELP: CREATE USER
 | delete_stmt       // EXTEND WITH HELP: DELETE
+| drop_role_stmt    // EXTEND WITH HELP: DROP ROLE
 | drop_user_stmt    // EXTEND WITH HELP: DROP USER
 | import_stmt       // EXTEND WITH HELP: IMPORT
 | insert_stmt       // EXTEND WITH HELP: INSERT
@@ -1868,10 +2018,13 @@ deallocate_stmt:
   }
 | DEALLOCATE error // SHOW HELP: DEALLOCATE
 
-// %Help: GRANT - define access privileges
+// %Help: GRANT - define access privileges and role memberships
 // %Category: Priv
 // %Text:
-// GRANT {ALL | <privileges...> } ON <targets...> TO <grantees...>
+// Grant privileges:
+//   GRANT {ALL | <privileges...> } ON <targets...> TO <grantees...>
+// Grant role membership:
+//   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 //
 // Privileges:
 //   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE
@@ -1886,12 +2039,19 @@ grant_stmt:
   {
     $$.val = &Grant{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| GRANT privilege_list TO grantee_list opt_with_admin_option
+  {
+    $$.val = &GrantRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: $5.bool()}
+  }
 | GRANT error // SHOW HELP: GRANT
 
-// %Help: REVOKE - remove access privileges
+// %Help: REVOKE - remove access privileges and role memberships
 // %Category: Priv
 // %Text:
-// REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
+// Revoke privileges:
+//   REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
+// Revoke role membership:
+//   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 //
 // Privileges:
 //   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE
@@ -1906,6 +2066,14 @@ revoke_stmt:
   {
     $$.val = &Revoke{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| REVOKE privilege_list FROM grantee_list
+  {
+    $$.val = &RevokeRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false}
+  }
+| REVOKE ADMIN OPTION FOR privilege_list FROM grantee_list
+  {
+    $$.val = &RevokeRole{Roles: $5.nameList(), Members: $7.nameList(), AdminOption: true}
+  }
 | REVOKE error // SHOW HELP: REVOKE
 
 targets:
@@ -1928,47 +2096,43 @@ privileges:
   {
     $$.val = privilege.List{privilege.ALL}
   }
-  | privilege_list { }
+| privilege_list
+  {
+    privList, err := privilege.ListFromNames($1.nameList().ToStrings())
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = privList
+  }
 
+// The privileges are parsed as names since the same list can name the
+// roles granted by GRANT <roles...> TO <grantees...>. The privileges which
+// are reserved keywords are listed explicitly.
 privilege_list:
   privilege
   {
-    $$.val = privilege.List{$1.privilegeType()}
+    $$.val = NameList{Name($1)}
   }
-  | privilege_list ',' privilege
+| privilege_list ',' privilege
   {
-    $$.val = append($1.privilegeList(), $3.privilegeType())
+    $$.val = append($1.nameList(), Name($3))
   }
 
-// This list must match the list of privileges in sql/privilege/privilege.go.
 privilege:
-  CREATE
-  {
-    $$.val = privilege.CREATE
-  }
-| DROP
-  {
-    $$.val = privilege.DROP
-  }
+  name
+| CREATE
 | GRANT
-  {
-    $$.val = privilege.GRANT
-  }
 | SELECT
+
+opt_with_admin_option:
+  WITH ADMIN OPTION
   {
-    $$.val = privilege.SELECT
-  }
-| INSERT
-  {
-    $$.val = privilege.INSERT
-  }
-| DELETE
-  {
-    $$.val = privilege.DELETE
+    $$.val = true
   }
-| UPDATE
+| /* EMPTY */
   {
-    $$.val = privilege.UPDATE
+    $$.val = false
   }
 
 // TODO(marc): this should not be 'name', but should instead be a
@@ -2279,7 +2443,8 @@ non_reserved_word_or_sconst:
 // %Category: Group
 // %Text:
 // SHOW SESSION, SHOW CLUSTER SETTING, SHOW DATABASES, SHOW TABLES, SHOW COLUMNS, SHOW INDEXES,
-// SHOW CONSTRAINTS, SHOW CREATE TABLE, SHOW CREATE VIEW, SHOW USERS, SHOW TRANSACTION, SHOW BACKUP,
+// SHOW CONSTRAINTS, SHOW CREATE TABLE, SHOW CREATE VIEW, SHOW CREATE SEQUENCE, SHOW USERS, SHOW ROLES,
+// SHOW TRANSACTION, SHOW BACKUP,
 // SHOW JOBS, SHOW QUERIES, SHOW SESSIONS, SHOW TRACE
 show_stmt:
   show_backup_stmt       // EXTEND WITH HELP: SHOW BACKUP
@@ -2287,14 +2452,17 @@ show_stmt:
 | show_constraints_stmt  // EXTEND WITH HELP: SHOW CONSTRAINTS
 | show_create_table_stmt // EXTEND WITH HELP: SHOW CREATE TABLE
 | show_create_view_stmt  // EXTEND WITH HELP: SHOW CREATE VIEW
+| show_create_sequence_stmt // EXTEND WITH HELP: SHOW CREATE SEQUENCE
 | show_csettings_stmt    // EXTEND WITH HELP: SHOW CLUSTER SETTING
 | show_databases_stmt    // EXTEND WITH HELP: SHOW DATABASES
 | show_grants_stmt       // EXTEND WITH HELP: SHOW GRANTS
 | show_indexes_stmt      // EXTEND WITH HELP: SHOW INDEXES
 | show_jobs_stmt         // EXTEND WITH HELP: SHOW JOBS
 | show_queries_stmt      // EXTEND WITH HELP: SHOW QUERIES
+| show_roles_stmt        // EXTEND WITH HELP: SHOW ROLES
 | show_session_stmt      // EXTEND WITH HELP: SHOW SESSION
 | show_sessions_stmt     // EXTEND WITH HELP: SHOW SESSIONS
+| show_stats_stmt        // EXTEND WITH HELP: SHOW STATISTICS
 | show_tables_stmt       // EXTEND WITH HELP: SHOW TABLES
 | show_testing_stmt
 | show_trace_stmt        // EXTEND WITH HELP: SHOW TRACE
@@ -2563,6 +2731,28 @@ show_create_view_stmt:
   }
 | SHOW CREATE VIEW error // SHOW HELP: SHOW CREATE VIEW
 
+// %Help: SHOW CREATE SEQUENCE - display the CREATE SEQUENCE statement for a sequence
+// %Category: DDL
+// %Text: SHOW CREATE SEQUENCE <seqname>
+// %SeeAlso: CREATE SEQUENCE
+show_create_sequence_stmt:
+  SHOW CREATE SEQUENCE var_name
+  {
+    $$.val = &ShowCreateSequence{Sequence: $4.normalizableTableName()}
+  }
+| SHOW CREATE SEQUENCE error // SHOW HELP: SHOW CREATE SEQUENCE
+
+// %Help: SHOW STATISTICS - display table statistics
+// %Category: Misc
+// %Text: SHOW STATISTICS FOR TABLE <table_name>
+// %SeeAlso: CREATE STATISTICS
+show_stats_stmt:
+  SHOW STATISTICS FOR TABLE qualified_name
+  {
+    $$.val = &ShowTableStats{Table: $5.normalizableTableName()}
+  }
+| SHOW STATISTICS error // SHOW HELP: SHOW STATISTICS
+
 // %Help: SHOW USERS - list defined users
 // %Category: Priv
 // %Text: SHOW USERS
@@ -2574,6 +2764,17 @@ show_users_stmt:
   }
 | SHOW USERS error // SHOW HELP: SHOW USERS
 
+// %Help: SHOW ROLES - list defined roles
+// %Category: Priv
+// %Text: SHOW ROLES
+// %SeeAlso: CREATE ROLE, DROP ROLE, SHOW USERS
+show_roles_stmt:
+  SHOW ROLES
+  {
+    $$.val = &ShowRoles{}
+  }
+| SHOW ROLES error // SHOW HELP: SHOW ROLES
+
 show_zone_stmt:
   EXPERIMENTAL SHOW ZONE CONFIGURATION FOR RANGE unrestricted_name
   {
@@ -2664,26 +2865,26 @@ pause_stmt:
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
 // %Text:
-// CREATE TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>]
-// CREATE TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
+// CREATE [TEMP] TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>]
+// CREATE [TEMP] TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
 //
 // Table elements:
 //    <name> <type> [<qualifiers...>]
 //    [UNIQUE] INDEX [<name>] ( <colname> [ASC | DESC] [, ...] )
-//                            [STORING ( <colnames...> )] [<interleave>]
+//                            [STORING ( <colnames...> )] [<interleave>] [WHERE <predicate>]
 //    FAMILY [<name>] ( <colnames...> )
 //    [CONSTRAINT <name>] <constraint>
 //
 // Table constraints:
 //    PRIMARY KEY ( <colnames...> )
-//    FOREIGN KEY ( <colnames...> ) REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT}] [ON UPDATE {NO ACTION | RESTRICT}]
+//    FOREIGN KEY ( <colnames...> ) REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT | CASCADE | SET NULL | SET DEFAULT}] [ON UPDATE {NO ACTION | RESTRICT | CASCADE | SET NULL | SET DEFAULT}]
 //    UNIQUE ( <colnames... ) [STORING ( <colnames...> )] [<interleave>]
 //    CHECK ( <expr> )
 //
 // Column qualifiers:
 //   [CONSTRAINT <constraintname>] {NULL | NOT NULL | UNIQUE | PRIMARY KEY | CHECK (<expr>) | DEFAULT <expr>}
 //   FAMILY <familyname>, CREATE [IF NOT EXISTS] FAMILY [<familyname>]
-//   REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT}] [ON UPDATE {NO ACTION | RESTRICT}]
+//   REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT | CASCADE | SET NULL | SET DEFAULT}] [ON UPDATE {NO ACTION | RESTRICT | CASCADE | SET NULL | SET DEFAULT}]
 //   COLLATE <collationname>
 //
 // Interleave clause:
@@ -2693,31 +2894,46 @@ pause_stmt:
 // WEBDOCS/create-table.html
 // WEBDOCS/create-table-as.html
 create_table_stmt:
-  CREATE TABLE any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
+  CREATE opt_temp TABLE any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
   {
     $$.val = &CreateTable{
-      Table: $3.normalizableTableName(),
+      Temporary: $2.bool(),
+      Table: $4.normalizableTableName(),
       IfNotExists: false,
-      Interleave: $7.interleave(),
-      Defs: $5.tblDefs(),
+      Interleave: $8.interleave(),
+      Defs: $6.tblDefs(),
       AsSource: nil,
       AsColumnNames: nil,
-      PartitionBy: $8.partitionBy(),
+      PartitionBy: $9.partitionBy(),
     }
   }
-| CREATE TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave
+| CREATE opt_temp TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave
   {
-    $$.val = &CreateTable{Table: $6.normalizableTableName(), IfNotExists: true, Interleave: $10.interleave(), Defs: $8.tblDefs(), AsSource: nil, AsColumnNames: nil}
+    $$.val = &CreateTable{Temporary: $2.bool(), Table: $7.normalizableTableName(), IfNotExists: true, Interleave: $11.interleave(), Defs: $9.tblDefs(), AsSource: nil, AsColumnNames: nil}
   }
 
 create_table_as_stmt:
-  CREATE TABLE any_name opt_column_list AS select_stmt
+  CREATE opt_temp TABLE any_name opt_column_list AS select_stmt
   {
-    $$.val = &CreateTable{Table: $3.normalizableTableName(), IfNotExists: false, Interleave: nil, Defs: nil, AsSource: $6.slct(), AsColumnNames: $4.nameList()}
+    $$.val = &CreateTable{Temporary: $2.bool(), Table: $4.normalizableTableName(), IfNotExists: false, Interleave: nil, Defs: nil, AsSource: $7.slct(), AsColumnNames: $5.nameList()}
   }
-| CREATE TABLE IF NOT EXISTS any_name opt_column_list AS select_stmt
+| CREATE opt_temp TABLE IF NOT EXISTS any_name opt_column_list AS select_stmt
   {
-    $$.val = &CreateTable{Table: $6.normalizableTableName(), IfNotExists: true, Interleave: nil, Defs: nil, AsSource: $9.slct(), AsColumnNames: $7.nameList()}
+    $$.val = &CreateTable{Temporary: $2.bool(), Table: $7.normalizableTableName(), IfNotExists: true, Interleave: nil, Defs: nil, AsSource: $10.slct(), AsColumnNames: $8.nameList()}
+  }
+
+opt_temp:
+  TEMP
+  {
+    $$.val = true
+  }
+| TEMPORARY
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
   }
 
 opt_table_elem_list:
@@ -2987,16 +3203,17 @@ col_qualification_elem:
  }
 
 index_def:
-  INDEX opt_name '(' index_params ')' opt_storing opt_interleave
+  INDEX opt_name '(' index_params ')' opt_storing opt_interleave where_clause
   {
     $$.val = &IndexTableDef{
       Name:    Name($2),
       Columns: $4.idxElems(),
       Storing: $6.nameList(),
       Interleave: $7.interleave(),
+      Predicate: $8.expr(),
     }
   }
-| UNIQUE INDEX opt_name '(' index_params ')' opt_storing opt_interleave
+| UNIQUE INDEX opt_name '(' index_params ')' opt_storing opt_interleave where_clause
   {
     $$.val = &UniqueConstraintTableDef{
       IndexTableDef: IndexTableDef {
@@ -3004,6 +3221,7 @@ index_def:
         Columns: $5.idxElems(),
         Storing: $7.nameList(),
         Interleave: $8.interleave(),
+        Predicate: $9.expr(),
       },
     }
   }
@@ -3208,6 +3426,21 @@ create_user_stmt:
   }
 | CREATE USER error // SHOW HELP: CREATE USER
 
+// %Help: CREATE ROLE - define a new role
+// %Category: Priv
+// %Text: CREATE ROLE [IF NOT EXISTS] <name>
+// %SeeAlso: DROP ROLE, SHOW ROLES, GRANT
+create_role_stmt:
+  CREATE ROLE string_or_placeholder
+  {
+    $$.val = &CreateRole{Name: $3.expr()}
+  }
+| CREATE ROLE IF NOT EXISTS string_or_placeholder
+  {
+    $$.val = &CreateRole{Name: $6.expr(), IfNotExists: true}
+  }
+| CREATE ROLE error // SHOW HELP: CREATE ROLE
+
 opt_password:
   opt_with PASSWORD string_or_placeholder
   {
@@ -3220,8 +3453,8 @@ opt_password:
 
 // %Help: CREATE VIEW - create a new view
 // %Category: DDL
-// %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source>
-// %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, WEBDOCS/create-view.html
+// %Text: CREATE [MATERIALIZED] VIEW <viewname> [( <colnames...> )] AS <source>
+// %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, REFRESH MATERIALIZED VIEW, WEBDOCS/create-view.html
 create_view_stmt:
   CREATE VIEW any_name opt_column_list AS select_stmt
   {
@@ -3231,16 +3464,103 @@ create_view_stmt:
       AsSource: $6.slct(),
     }
   }
+| CREATE MATERIALIZED VIEW any_name opt_column_list AS select_stmt
+  {
+    $$.val = &CreateView{
+      Name: $4.normalizableTableName(),
+      ColumnNames: $5.nameList(),
+      AsSource: $7.slct(),
+      Materialized: true,
+    }
+  }
 | CREATE VIEW error // SHOW HELP: CREATE VIEW
+| CREATE MATERIALIZED VIEW error // SHOW HELP: CREATE VIEW
 
 // TODO(a-robinson): CREATE OR REPLACE VIEW support (#2971).
 
+// %Help: CREATE STATISTICS - create a new table statistic
+// %Category: Misc
+// %Text:
+// CREATE STATISTICS <statisticname>
+//   ON <colname> [, ...]
+//   FROM <tablename>
+// %SeeAlso: SHOW STATISTICS
+create_stats_stmt:
+  CREATE STATISTICS name ON name_list FROM qualified_name
+  {
+    $$.val = &CreateStats{
+      Name: Name($3),
+      ColumnNames: $5.nameList(),
+      Table: $7.normalizableTableName(),
+    }
+  }
+| CREATE STATISTICS error // SHOW HELP: CREATE STATISTICS
+
+// %Help: CREATE SEQUENCE - create a new sequence
+// %Category: DDL
+// %Text:
+// CREATE SEQUENCE [IF NOT EXISTS] <seqname>
+//   [INCREMENT <increment>]
+//   [MINVALUE <minvalue> | NO MINVALUE]
+//   [MAXVALUE <maxvalue> | NO MAXVALUE]
+//   [START [WITH] <start>]
+//   [CACHE <cache>]
+//   [NO CYCLE]
+//
+// %SeeAlso: ALTER SEQUENCE, DROP SEQUENCE, SHOW CREATE SEQUENCE
+create_sequence_stmt:
+  CREATE SEQUENCE any_name opt_sequence_option_list
+  {
+    $$.val = &CreateSequence{Name: $3.normalizableTableName(), Options: $4.seqOpts()}
+  }
+| CREATE SEQUENCE IF NOT EXISTS any_name opt_sequence_option_list
+  {
+    $$.val = &CreateSequence{Name: $6.normalizableTableName(), Options: $7.seqOpts(), IfNotExists: true}
+  }
+| CREATE SEQUENCE error // SHOW HELP: CREATE SEQUENCE
+
+opt_sequence_option_list:
+  sequence_option_list
+| /* EMPTY */          { $$.val = []SequenceOption(nil) }
+
+sequence_option_list:
+  sequence_option_elem                       { $$.val = []SequenceOption{$1.seqOpt()} }
+| sequence_option_list sequence_option_elem  { $$.val = append($1.seqOpts(), $2.seqOpt()) }
+
+sequence_option_elem:
+  AS typename                  { return unimplemented(sqllex, "create sequence AS option") }
+| CYCLE                        { /* SKIP DOC */
+                                 $$.val = SequenceOption{Name: SeqOptCycle} }
+| NO CYCLE                     { $$.val = SequenceOption{Name: SeqOptNoCycle} }
+| CACHE signed_iconst64        { /* SKIP DOC */
+                                 x := $2.int64()
+                                 $$.val = SequenceOption{Name: SeqOptCache, IntVal: &x} }
+| INCREMENT signed_iconst64    { x := $2.int64()
+                                 $$.val = SequenceOption{Name: SeqOptIncrement, IntVal: &x} }
+| INCREMENT BY signed_iconst64 { x := $3.int64()
+                                 $$.val = SequenceOption{Name: SeqOptIncrement, IntVal: &x, OptionalWord: true} }
+| MINVALUE signed_iconst64     { x := $2.int64()
+                                 $$.val = SequenceOption{Name: SeqOptMinValue, IntVal: &x} }
+| NO MINVALUE                  { $$.val = SequenceOption{Name: SeqOptMinValue} }
+| MAXVALUE signed_iconst64     { x := $2.int64()
+                                 $$.val = SequenceOption{Name: SeqOptMaxValue, IntVal: &x} }
+| NO MAXVALUE                  { $$.val = SequenceOption{Name: SeqOptMaxValue} }
+| START signed_iconst64        { x := $2.int64()
+                                 $$.val = SequenceOption{Name: SeqOptStart, IntVal: &x} }
+| START WITH signed_iconst64   { x := $3.int64()
+                                 $$.val = SequenceOption{Name: SeqOptStart, IntVal: &x, OptionalWord: true} }
+
 // %Help: CREATE INDEX - create a new index
 // %Category: DDL
 // %Text:
 // CREATE [UNIQUE] INDEX [IF NOT EXISTS] [<idxname>]
-//        ON <tablename> ( <colname> [ASC | DESC] [, ...] )
-//        [STORING ( <colnames...> )] [<interleave>]
+//        ON <tablename> ( <index_elem> [ASC | DESC] [, ...] )
+//        [STORING ( <colnames...> )] [<interleave>] [WHERE <predicate>]
+//
+// Index elements:
+//    <colname>
+//    <func_name> ( <args...> )
+//    ( <expr> )
 //
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
@@ -3248,7 +3568,7 @@ create_view_stmt:
 // %SeeAlso: CREATE TABLE, SHOW INDEXES, SHOW CREATE INDEX,
 // WEBDOCS/create-index.html
 create_index_stmt:
-  CREATE opt_unique INDEX opt_name ON qualified_name '(' index_params ')' opt_storing opt_interleave
+  CREATE opt_unique INDEX opt_name ON qualified_name '(' index_params ')' opt_storing opt_interleave where_clause
   {
     $$.val = &CreateIndex{
       Name:    Name($4),
@@ -3257,9 +3577,10 @@ create_index_stmt:
       Columns: $8.idxElems(),
       Storing: $10.nameList(),
       Interleave: $11.interleave(),
+      Predicate: $12.expr(),
     }
   }
-| CREATE opt_unique INDEX IF NOT EXISTS name ON qualified_name '(' index_params ')' opt_storing opt_interleave
+| CREATE opt_unique INDEX IF NOT EXISTS name ON qualified_name '(' index_params ')' opt_storing opt_interleave where_clause
   {
     $$.val = &CreateIndex{
       Name:        Name($7),
@@ -3269,6 +3590,7 @@ create_index_stmt:
       Columns:     $11.idxElems(),
       Storing:     $13.nameList(),
       Interleave: $14.interleave(),
+      Predicate:   $15.expr(),
     }
   }
 | CREATE opt_unique INDEX error // SHOW HELP: CREATE INDEX
@@ -3301,8 +3623,14 @@ index_elem:
   {
     $$.val = IndexElem{Column: Name($1), Direction: $3.dir()}
   }
-| func_expr_windowless opt_collate opt_asc_desc { return unimplemented(sqllex, "index_elem func expr") }
-| '(' a_expr ')' opt_collate opt_asc_desc { return unimplemented(sqllex, "index_elem a_expr") }
+| func_expr_windowless opt_collate opt_asc_desc
+  {
+    $$.val = IndexElem{Expr: $1.expr(), Direction: $3.dir()}
+  }
+| '(' a_expr ')' opt_collate opt_asc_desc
+  {
+    $$.val = IndexElem{Expr: $2.expr(), Direction: $5.dir()}
+  }
 
 opt_collate:
   COLLATE unrestricted_name { return unimplementedWithIssue(sqllex, 16619) }
@@ -3371,6 +3699,16 @@ alter_rename_view_stmt:
     $$.val = &RenameTable{Name: $5.normalizableTableName(), NewName: $8.normalizableTableName(), IfExists: true, IsView: true}
   }
 
+alter_rename_sequence_stmt:
+  ALTER SEQUENCE relation_expr RENAME TO qualified_name
+  {
+    $$.val = &RenameTable{Name: $3.normalizableTableName(), NewName: $6.normalizableTableName(), IfExists: false, IsSequence: true}
+  }
+| ALTER SEQUENCE IF EXISTS relation_expr RENAME TO qualified_name
+  {
+    $$.val = &RenameTable{Name: $5.normalizableTableName(), NewName: $8.normalizableTableName(), IfExists: true, IsSequence: true}
+  }
+
 alter_rename_index_stmt:
   ALTER INDEX table_name_with_index RENAME TO unrestricted_name
   {
@@ -3395,10 +3733,23 @@ opt_set_data:
   SET DATA {}
 | /* EMPTY */ {}
 
-// %Help: RELEASE - complete a retryable block
+// %Help: REFRESH MATERIALIZED VIEW - recompute the contents of a materialized view
+// %Category: DDL
+// %Text: REFRESH MATERIALIZED VIEW <viewname>
+// %SeeAlso: CREATE VIEW, SHOW JOBS
+refresh_stmt:
+  REFRESH MATERIALIZED VIEW qualified_name
+  {
+    $$.val = &RefreshMaterializedView{Name: $4.normalizableTableName()}
+  }
+| REFRESH error // SHOW HELP: REFRESH MATERIALIZED VIEW
+
+// %Help: RELEASE - destroy a savepoint or complete a retryable block
 // %Category: Txn
-// %Text: RELEASE [SAVEPOINT] cockroach_restart
-// %SeeAlso: SAVEPOINT, WEBDOCS/savepoint.html
+// %Text:
+// RELEASE [SAVEPOINT] <savepoint name>
+// RELEASE [SAVEPOINT] cockroach_restart
+// %SeeAlso: SAVEPOINT, ROLLBACK, WEBDOCS/savepoint.html
 release_stmt:
   RELEASE savepoint_name
   {
@@ -3417,10 +3768,12 @@ resume_stmt:
   }
 | RESUME error // SHOW HELP: RESUME JOB
 
-// %Help: SAVEPOINT - start a retryable block
+// %Help: SAVEPOINT - define a savepoint or start a retryable block
 // %Category: Txn
-// %Text: SAVEPOINT cockroach_restart
-// %SeeAlso: RELEASE, WEBDOCS/savepoint.html
+// %Text:
+// SAVEPOINT <savepoint name>
+// SAVEPOINT cockroach_restart
+// %SeeAlso: RELEASE, ROLLBACK, WEBDOCS/savepoint.html
 savepoint_stmt:
   SAVEPOINT name
   {
@@ -3477,7 +3830,10 @@ commit_stmt:
 
 // %Help: ROLLBACK - abort the current transaction
 // %Category: Txn
-// %Text: ROLLBACK [TRANSACTION] [TO [SAVEPOINT] cockroach_restart]
+// %Text:
+// ROLLBACK [TRANSACTION]
+// ROLLBACK [TRANSACTION] TO [SAVEPOINT] <savepoint name>
+// ROLLBACK [TRANSACTION] TO [SAVEPOINT] cockroach_restart
 // %SeeAlso: BEGIN, COMMIT, SAVEPOINT, WEBDOCS/rollback-transaction.html
 rollback_stmt:
   ROLLBACK opt_to_savepoint
@@ -3675,12 +4031,14 @@ insert_stmt:
   opt_with_clause INSERT INTO insert_target insert_rest returning_clause
   {
     $$.val = $5.stmt()
+    $$.val.(*Insert).With = $1.with()
     $$.val.(*Insert).Table = $4.tblExpr()
     $$.val.(*Insert).Returning = $6.retClause()
   }
 | opt_with_clause INSERT INTO insert_target insert_rest on_conflict returning_clause
   {
     $$.val = $5.stmt()
+    $$.val.(*Insert).With = $1.with()
     $$.val.(*Insert).Table = $4.tblExpr()
     $$.val.(*Insert).OnConflict = $6.onConflict()
     $$.val.(*Insert).Returning = $7.retClause()
@@ -3698,6 +4056,7 @@ upsert_stmt:
   opt_with_clause UPSERT INTO insert_target insert_rest returning_clause
   {
     $$.val = $5.stmt()
+    $$.val.(*Insert).With = $1.with()
     $$.val.(*Insert).Table = $4.tblExpr()
     $$.val.(*Insert).OnConflict = &OnConflict{}
     $$.val.(*Insert).Returning = $6.retClause()
@@ -3777,7 +4136,13 @@ update_stmt:
   opt_with_clause UPDATE relation_expr_opt_alias
     SET set_clause_list update_from_clause where_clause returning_clause
   {
-    $$.val = &Update{Table: $3.tblExpr(), Exprs: $5.updateExprs(), Where: newWhere(astWhere, $7.expr()), Returning: $8.retClause()}
+    $$.val = &Update{
+      With: $1.with(),
+      Table: $3.tblExpr(),
+      Exprs: $5.updateExprs(),
+      Where: newWhere(astWhere, $7.expr()),
+      Returning: $8.retClause(),
+    }
   }
 | opt_with_clause UPDATE error // SHOW HELP: UPDATE
 
@@ -3893,19 +4258,19 @@ select_no_parens:
   }
 | with_clause select_clause
   {
-    $$.val = &Select{Select: $2.selectStmt()}
+    $$.val = &Select{With: $1.with(), Select: $2.selectStmt()}
   }
 | with_clause select_clause sort_clause
   {
-    $$.val = &Select{Select: $2.selectStmt(), OrderBy: $3.orderBy()}
+    $$.val = &Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy()}
   }
 | with_clause select_clause opt_sort_clause for_locking_clause opt_select_limit
   {
-    $$.val = &Select{Select: $2.selectStmt(), OrderBy: $3.orderBy(), LockForUpdate: $4.bool(), Limit: $5.limit()}
+    $$.val = &Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), LockForUpdate: $4.bool(), Limit: $5.limit()}
   }
 | with_clause select_clause opt_sort_clause select_limit opt_for_locking_clause
   {
-    $$.val = &Select{Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit(), LockForUpdate: $5.bool()}
+    $$.val = &Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit(), LockForUpdate: $5.bool()}
   }
 
 select_clause:
@@ -4050,24 +4415,51 @@ table_clause:
 //
 // Recognizing WITH_LA here allows a CTE to be named TIME or ORDINALITY.
 with_clause:
-  WITH cte_list { return unimplemented(sqllex, "with cte_list") }
-| WITH_LA cte_list { return unimplemented(sqllex, "with cte_list") }
-| WITH RECURSIVE cte_list { return unimplemented(sqllex, "with cte_list") }
+  WITH cte_list
+  {
+    $$.val = &With{CTEList: $2.ctes()}
+  }
+| WITH_LA cte_list
+  {
+    $$.val = &With{CTEList: $2.ctes()}
+  }
+| WITH RECURSIVE cte_list
+  {
+    $$.val = &With{Recursive: true, CTEList: $3.ctes()}
+  }
 
 cte_list:
-  common_table_expr { return unimplemented(sqllex, "cte_list") }
-| cte_list ',' common_table_expr { return unimplemented(sqllex, "cte_list") }
+  common_table_expr
+  {
+    $$.val = []*CTE{$1.cte()}
+  }
+| cte_list ',' common_table_expr
+  {
+    $$.val = append($1.ctes(), $3.cte())
+  }
 
 common_table_expr:
-  name opt_name_list AS '(' preparable_stmt ')' { return unimplemented(sqllex, "cte") }
+  name opt_name_list AS '(' preparable_stmt ')'
+  {
+    $$.val = &CTE{
+      Name: AliasClause{Alias: Name($1), Cols: $2.nameList()},
+      Stmt: $5.stmt(),
+    }
+  }
 
 opt_with:
   WITH {}
 | /* EMPTY */ {}
 
 opt_with_clause:
-  with_clause { return unimplemented(sqllex, "with_clause") }
-| /* EMPTY */ {}
+  with_clause
+  {
+    $$.val = $1.with()
+  }
+| /* EMPTY */
+  {
+    $$.val = (*With)(nil)
+  }
 
 opt_table:
   TABLE {}
@@ -4535,17 +4927,17 @@ joined_table:
   {
     $$.val = &JoinTableExpr{Join: astCrossJoin, Left: $1.tblExpr(), Right: $4.tblExpr()}
   }
-| table_ref join_type JOIN table_ref join_qual
+| table_ref join_type opt_join_hint JOIN table_ref join_qual
   {
-    $$.val = &JoinTableExpr{Join: $2, Left: $1.tblExpr(), Right: $4.tblExpr(), Cond: $5.joinCond()}
+    $$.val = &JoinTableExpr{Join: $2, Hint: $3, Left: $1.tblExpr(), Right: $5.tblExpr(), Cond: $6.joinCond()}
   }
 | table_ref JOIN table_ref join_qual
   {
     $$.val = &JoinTableExpr{Join: astJoin, Left: $1.tblExpr(), Right: $3.tblExpr(), Cond: $4.joinCond()}
   }
-| table_ref NATURAL join_type JOIN table_ref
+| table_ref NATURAL join_type opt_join_hint JOIN table_ref
   {
-    $$.val = &JoinTableExpr{Join: $3, Left: $1.tblExpr(), Right: $5.tblExpr(), Cond: NaturalJoinCond{}}
+    $$.val = &JoinTableExpr{Join: $3, Hint: $4, Left: $1.tblExpr(), Right: $6.tblExpr(), Cond: NaturalJoinCond{}}
   }
 | table_ref NATURAL JOIN table_ref
   {
@@ -4605,6 +4997,26 @@ join_type:
     $$ = astInnerJoin
   }
 
+// A join hint forces the algorithm used to execute the join. Hints are only
+// accepted after an explicit join type, e.g. INNER HASH JOIN.
+opt_join_hint:
+  HASH
+  {
+    $$ = AstHash
+  }
+| MERGE
+  {
+    $$ = AstMerge
+  }
+| LOOKUP
+  {
+    $$ = AstLookup
+  }
+| /* EMPTY */
+  {
+    $$ = ""
+  }
+
 // OUTER is just noise...
 join_outer:
   OUTER {}
@@ -5214,7 +5626,10 @@ a_expr:
   {
     $$.val = &CollateExpr{Expr: $1.expr(), Locale: $3}
   }
-| a_expr AT TIME ZONE a_expr %prec AT { return unimplemented(sqllex, "at tz") }
+| a_expr AT TIME ZONE a_expr %prec AT
+  {
+    $$.val = &FuncExpr{Func: wrapFunction("TIMEZONE"), Exprs: Exprs{$5.expr(), $1.expr()}}
+  }
   // These operators must be called out explicitly in order to make use of
   // bison's automatic operator-precedence handling. All other operator names
   // are handled by the generic productions using "OP", below; and all those
@@ -5792,7 +6207,10 @@ func_expr_common_subexpr:
     $$.val = &FuncExpr{Func: wrapFunction($1)}
   }
 | CURRENT_TIMESTAMP '(' error { return helpWithFunction(sqllex, ResolvableFunctionReference{UnresolvedName{Name($1)}}) }
-| CURRENT_ROLE { return unimplemented(sqllex, "current role") }
+| CURRENT_ROLE
+  {
+    $$.val = &FuncExpr{Func: wrapFunction("current_user")}
+  }
 | CURRENT_USER
   {
     $$.val = &FuncExpr{Func: wrapFunction($1)}
@@ -5952,6 +6370,7 @@ window_specification:
       RefName: Name($2),
       Partitions: $3.exprs(),
       OrderBy: $4.orderBy(),
+      Frame: $5.windowFrame(),
     }
   }
 
@@ -5980,29 +6399,96 @@ opt_partition_clause:
     $$.val = Exprs(nil)
   }
 
-// For frame clauses, we return a WindowDef, but only some fields are used:
-// frameOptions, startOffset, and endOffset.
-//
 // This is only a subset of the full SQL:2008 frame_clause grammar. We don't
 // support <window frame exclusion> yet.
 opt_frame_clause:
-  RANGE frame_extent { return unimplemented(sqllex, "frame range") }
-| ROWS frame_extent { return unimplemented(sqllex, "frame rows") }
-| /* EMPTY */ {}
+  RANGE frame_extent
+  {
+    $$.val = &WindowFrame{
+      Mode: RangeMode,
+      Bounds: $2.windowFrameBounds(),
+    }
+  }
+| ROWS frame_extent
+  {
+    $$.val = &WindowFrame{
+      Mode: RowsMode,
+      Bounds: $2.windowFrameBounds(),
+    }
+  }
+| /* EMPTY */
+  {
+    $$.val = (*WindowFrame)(nil)
+  }
 
 frame_extent:
-  frame_bound { return unimplemented(sqllex, "frame_extent") }
-| BETWEEN frame_bound AND frame_bound { return unimplemented(sqllex, "frame_extent") }
+  frame_bound
+  {
+    startBound := $1.windowFrameBound()
+    switch {
+    case startBound.BoundType == UnboundedFollowing:
+      sqllex.Error("frame start cannot be UNBOUNDED FOLLOWING")
+      return 1
+    case startBound.BoundType == ValueFollowing:
+      sqllex.Error("frame starting from following row cannot end with current row")
+      return 1
+    }
+    $$.val = WindowFrameBounds{StartBound: startBound}
+  }
+| BETWEEN frame_bound AND frame_bound
+  {
+    startBound := $2.windowFrameBound()
+    endBound := $4.windowFrameBound()
+    switch {
+    case startBound.BoundType == UnboundedFollowing:
+      sqllex.Error("frame start cannot be UNBOUNDED FOLLOWING")
+      return 1
+    case endBound.BoundType == UnboundedPreceding:
+      sqllex.Error("frame end cannot be UNBOUNDED PRECEDING")
+      return 1
+    case startBound.BoundType == CurrentRow && endBound.BoundType == ValuePreceding:
+      sqllex.Error("frame starting from current row cannot have preceding rows")
+      return 1
+    case startBound.BoundType == ValueFollowing && endBound.BoundType == ValuePreceding:
+      sqllex.Error("frame starting from following row cannot have preceding rows")
+      return 1
+    case startBound.BoundType == ValueFollowing && endBound.BoundType == CurrentRow:
+      sqllex.Error("frame starting from following row cannot end with current row")
+      return 1
+    }
+    $$.val = WindowFrameBounds{StartBound: startBound, EndBound: endBound}
+  }
 
 // This is used for both frame start and frame end, with output set up on the
 // assumption it's frame start; the frame_extent productions must reject
 // invalid cases.
 frame_bound:
-  UNBOUNDED PRECEDING { return unimplemented(sqllex, "frame_bound") }
-| UNBOUNDED FOLLOWING { return unimplemented(sqllex, "frame_bound") }
-| CURRENT ROW { return unimplemented(sqllex, "frame_bound") }
-| a_expr PRECEDING { return unimplemented(sqllex, "frame_bound") }
-| a_expr FOLLOWING { return unimplemented(sqllex, "frame_bound") }
+  UNBOUNDED PRECEDING
+  {
+    $$.val = &WindowFrameBound{BoundType: UnboundedPreceding}
+  }
+| UNBOUNDED FOLLOWING
+  {
+    $$.val = &WindowFrameBound{BoundType: UnboundedFollowing}
+  }
+| CURRENT ROW
+  {
+    $$.val = &WindowFrameBound{BoundType: CurrentRow}
+  }
+| a_expr PRECEDING
+  {
+    $$.val = &WindowFrameBound{
+      OffsetExpr: $1.expr(),
+      BoundType: ValuePreceding,
+    }
+  }
+| a_expr FOLLOWING
+  {
+    $$.val = &WindowFrameBound{
+      OffsetExpr: $1.expr(),
+      BoundType: ValueFollowing,
+    }
+  }
 
 // Supporting nonterminals for expressions.
 
@@ -6490,7 +6976,10 @@ opt_name_list:
   {
     $$.val = $2.nameList()
   }
-| /* EMPTY */ {}
+| /* EMPTY */
+  {
+    $$.val = NameList(nil)
+  }
 
 // The production for a qualified func_name has to exactly match the production
 // for a qualified name, because we cannot tell which we are parsing until
@@ -6560,6 +7049,16 @@ signed_iconst:
     $$.val = &NumVal{Value: constant.UnaryOp(token.SUB, $2.numVal().Value, 0)}
   }
 
+// signed_iconst64 is a variant of signed_iconst which only accepts
+// (signed) integer literals that fit in an int64.
+signed_iconst64:
+  signed_iconst
+  {
+    val, err := $1.numVal().AsInt64()
+    if err != nil { sqllex.Error(err.Error()); return 1 }
+    $$.val = val
+  }
+
 interval:
   const_interval SCONST opt_interval
   {
@@ -6645,12 +7144,14 @@ unrestricted_name:
 unreserved_keyword:
   ACTION
 | ADD
+| ADMIN
 | ALTER
 | AT
 | BACKUP
 | BEGIN
 | BLOB
 | BY
+| CACHE
 | CANCEL
 | CASCADE
 | CLUSTER
@@ -6687,9 +7188,11 @@ unreserved_keyword:
 | FOLLOWING
 | FORCE_INDEX
 | GRANTS
+| HASH
 | HIGH
 | HOUR
 | IMPORT
+| INCREMENT
 | INCREMENTAL
 | INDEXES
 | INSERT
@@ -6707,9 +7210,13 @@ unreserved_keyword:
 | LEVEL
 | LIST
 | LOCAL
+| LOOKUP
 | LOW
 | MATCH
+| MATERIALIZED
+| MERGE
 | MINUTE
+| MINVALUE
 | MONTH
 | NAMES
 | NAN
@@ -6721,6 +7228,7 @@ unreserved_keyword:
 | OF
 | OFF
 | OID
+| OPTION
 | OPTIONS
 | ORDINALITY
 | OVER
@@ -6740,6 +7248,7 @@ unreserved_keyword:
 | READ
 | RECURSIVE
 | REF
+| REFRESH
 | REGCLASS
 | REGPROC
 | REGPROCEDURE
@@ -6753,11 +7262,14 @@ unreserved_keyword:
 | RESTRICT
 | RESUME
 | REVOKE
+| ROLE
+| ROLES
 | ROLLBACK
 | ROLLUP
 | ROWS
 | SETTING
 | SETTINGS
+| STATISTICS
 | STATUS
 | SAVEPOINT
 | SCATTER
@@ -6765,6 +7277,7 @@ unreserved_keyword:
 | SEARCH
 | SECOND
 | SERIALIZABLE
+| SEQUENCE
 | SEQUENCES
 | SESSION
 | SESSIONS
@@ -24,6 +24,7 @@ import "bytes"
 
 // Update represents an UPDATE statement.
 type Update struct {
+	With      *With
 	Table     TableExpr
 	Exprs     UpdateExprs
 	Where     *Where
@@ -32,6 +33,7 @@ type Update struct {
 
 // Format implements the NodeFormatter interface.
 func (node *Update) Format(buf *bytes.Buffer, f FmtFlags) {
+	FormatNode(buf, f, node.With)
 	buf.WriteString("UPDATE ")
 	FormatNode(buf, f, node.Table)
 	buf.WriteString(" SET ")
@@ -631,6 +631,31 @@ func walkReturningClause(v Visitor, clause ReturningClause) (ReturningClause, bo
 	}
 }
 
+// CopyNode makes a copy of this WITH clause without recursing in any child
+// Statements.
+func (node *With) CopyNode() *With {
+	nodeCopy := *node
+	nodeCopy.CTEList = append([]*CTE(nil), node.CTEList...)
+	return &nodeCopy
+}
+
+func walkWith(v Visitor, with *With) (*With, bool) {
+	if with == nil {
+		return nil, false
+	}
+	ret := with
+	for i, cte := range with.CTEList {
+		s, changed := WalkStmt(v, cte.Stmt)
+		if changed {
+			if ret == with {
+				ret = with.CopyNode()
+			}
+			ret.CTEList[i] = &CTE{Name: cte.Name, Stmt: s}
+		}
+	}
+	return ret, (ret != with)
+}
+
 // CopyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Backup) CopyNode() *Backup {
 	stmtCopy := *stmt
@@ -694,10 +719,16 @@ func (stmt *Delete) CopyNode() *Delete {
 // WalkStmt is part of the WalkableStmt interface.
 func (stmt *Delete) WalkStmt(v Visitor) Statement {
 	ret := stmt
+	if with, changed := walkWith(v, stmt.With); changed {
+		ret = stmt.CopyNode()
+		ret.With = with
+	}
 	if stmt.Where != nil {
 		e, changed := WalkExpr(v, stmt.Where.Expr)
 		if changed {
-			ret = stmt.CopyNode()
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
 			ret.Where.Expr = e
 		}
 	}
@@ -737,10 +768,16 @@ func (stmt *Insert) CopyNode() *Insert {
 // WalkStmt is part of the WalkableStmt interface.
 func (stmt *Insert) WalkStmt(v Visitor) Statement {
 	ret := stmt
+	if with, changed := walkWith(v, stmt.With); changed {
+		ret = stmt.CopyNode()
+		ret.With = with
+	}
 	if stmt.Rows != nil {
 		rows, changed := WalkStmt(v, stmt.Rows)
 		if changed {
-			ret = stmt.CopyNode()
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
 			ret.Rows = rows.(*Select)
 		}
 	}
@@ -884,9 +921,15 @@ func (stmt *Select) CopyNode() *Select {
 // WalkStmt is part of the WalkableStmt interface.
 func (stmt *Select) WalkStmt(v Visitor) Statement {
 	ret := stmt
+	if with, changed := walkWith(v, stmt.With); changed {
+		ret = stmt.CopyNode()
+		ret.With = with
+	}
 	sel, changed := WalkStmt(v, stmt.Select)
 	if changed {
-		ret = stmt.CopyNode()
+		if ret == stmt {
+			ret = stmt.CopyNode()
+		}
 		ret.Select = sel.(SelectStatement)
 	}
 	order, changed := walkOrderBy(v, stmt.OrderBy)
@@ -1075,6 +1118,10 @@ func (stmt *Update) CopyNode() *Update {
 // WalkStmt is part of the WalkableStmt interface.
 func (stmt *Update) WalkStmt(v Visitor) Statement {
 	ret := stmt
+	if with, changed := walkWith(v, stmt.With); changed {
+		ret = stmt.CopyNode()
+		ret.With = with
+	}
 	for i, expr := range stmt.Exprs {
 		e, changed := WalkExpr(v, expr.Expr)
 		if changed {
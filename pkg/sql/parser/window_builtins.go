@@ -16,6 +16,7 @@ package parser
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -49,13 +50,26 @@ type IndexedRow struct {
 	Row Datums
 }
 
-// WindowFrame is a view into a subset of data over which calculations are made.
-type WindowFrame struct {
+// WindowFrameRun contains the runtime state of window frame during calculations.
+type WindowFrameRun struct {
 	// constant for all calls to WindowFunc.Add
 	Rows        []IndexedRow
 	ArgIdxStart int // the index which arguments to the window function begin
 	ArgCount    int // the number of window function arguments
 
+	// Frame is the frame specification of the window, or nil if the
+	// default frame (RANGE UNBOUNDED PRECEDING) is used.
+	Frame            *WindowFrame
+	StartBoundOffset Datum // the evaluated offset of the frame start, if any
+	EndBoundOffset   Datum // the evaluated offset of the frame end, if any
+
+	// OrdColIdx is the index in each row of the column the partition is
+	// ordered by, and OrdDescending its direction. They are only used
+	// in RANGE mode with an offset, which requires a single ORDER BY
+	// column.
+	OrdColIdx     int
+	OrdDescending bool
+
 	// changes for each row (each call to WindowFunc.Add)
 	RowIdx int // the current row index
 
@@ -64,34 +78,182 @@ type WindowFrame struct {
 	PeerRowCount int // the number of rows in the current peer group
 }
 
-func (wf WindowFrame) rank() int {
+func (wf WindowFrameRun) rank() int {
 	return wf.RowIdx + 1
 }
 
-func (wf WindowFrame) rowCount() int {
+func (wf WindowFrameRun) rowCount() int {
 	return len(wf.Rows)
 }
 
-// TODO(nvanbenschoten): This definition only holds while we don't support
-// frame specification (RANGE or ROWS) in the OVER clause.
-func (wf WindowFrame) frameSize() int {
+// peerEndIdx returns the index of the first row after the current
+// row's peer group.
+func (wf WindowFrameRun) peerEndIdx() int {
 	return wf.FirstPeerIdx + wf.PeerRowCount
 }
 
+// frameBounds returns the indexes delimiting the window frame of the
+// current row: the frame contains the rows in [start, end).
+func (wf WindowFrameRun) frameBounds(evalCtx *EvalContext) (start, end int, err error) {
+	if start, err = wf.FrameStartIdx(evalCtx); err != nil {
+		return 0, 0, err
+	}
+	if end, err = wf.FrameEndIdx(evalCtx); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// FrameStartIdx returns the index of the first row in the window frame
+// of the current row.
+func (wf WindowFrameRun) FrameStartIdx(evalCtx *EvalContext) (int, error) {
+	if wf.Frame == nil {
+		return 0, nil
+	}
+	return wf.boundIdx(evalCtx, wf.Frame.Bounds.StartBound, wf.StartBoundOffset, true /* isStart */)
+}
+
+// FrameEndIdx returns the index of the first row after the window frame
+// of the current row. The frame is empty if FrameEndIdx is not greater
+// than FrameStartIdx.
+func (wf WindowFrameRun) FrameEndIdx(evalCtx *EvalContext) (int, error) {
+	if wf.Frame == nil {
+		return wf.peerEndIdx(), nil
+	}
+	endBound := wf.Frame.Bounds.EndBound
+	if endBound == nil {
+		// A frame specified with a single bound ends at the current row.
+		endBound = &WindowFrameBound{BoundType: CurrentRow}
+	}
+	return wf.boundIdx(evalCtx, endBound, wf.EndBoundOffset, false /* isStart */)
+}
+
+// boundIdx computes the position of a frame bound for the current row.
+// For a start bound, this is the index of the first row in the frame;
+// for an end bound, the index of the first row after the frame.
+func (wf WindowFrameRun) boundIdx(
+	evalCtx *EvalContext, bound *WindowFrameBound, offset Datum, isStart bool,
+) (int, error) {
+	switch bound.BoundType {
+	case UnboundedPreceding:
+		return 0, nil
+	case UnboundedFollowing:
+		return wf.rowCount(), nil
+	}
+
+	if wf.Frame.Mode == RowsMode {
+		idx := int64(wf.RowIdx)
+		switch bound.BoundType {
+		case ValuePreceding:
+			idx -= int64(MustBeDInt(offset))
+		case ValueFollowing:
+			idx += int64(MustBeDInt(offset))
+		}
+		if !isStart {
+			idx++
+		}
+		if idx < 0 {
+			return 0, nil
+		}
+		if idx > int64(wf.rowCount()) {
+			return wf.rowCount(), nil
+		}
+		return int(idx), nil
+	}
+
+	// RANGE mode.
+	if bound.BoundType == CurrentRow {
+		if isStart {
+			return wf.FirstPeerIdx, nil
+		}
+		return wf.peerEndIdx(), nil
+	}
+	cur := wf.Rows[wf.RowIdx].Row[wf.OrdColIdx]
+	if cur == DNull {
+		// NULL values are only peers of one another: the frame of a row with
+		// a NULL value is its peer group.
+		if isStart {
+			return wf.FirstPeerIdx, nil
+		}
+		return wf.peerEndIdx(), nil
+	}
+
+	// Compute the value delimiting the frame. When the partition is in
+	// descending order, the preceding rows have greater values.
+	op := Minus
+	if (bound.BoundType == ValueFollowing) != wf.OrdDescending {
+		op = Plus
+	}
+	binOp, ok := BinOps[op].lookupImpl(cur.ResolvedType(), offset.ResolvedType())
+	if !ok {
+		return 0, pgerror.NewErrorf(pgerror.CodeInternalError,
+			"unsupported RANGE offset type %s for ordering column type %s",
+			offset.ResolvedType(), cur.ResolvedType())
+	}
+	target, err := binOp.fn(evalCtx, cur, offset)
+	if err != nil {
+		return 0, err
+	}
+
+	// The rows are sorted on the ordering column, so the bound can be found
+	// by binary search: the frame starts at the first row that does not
+	// come before target and ends before the first row that comes after it.
+	return sort.Search(wf.rowCount(), func(i int) bool {
+		c := wf.compareToOrdValue(evalCtx, i, target)
+		if isStart {
+			return c >= 0
+		}
+		return c > 0
+	}), nil
+}
+
+// compareToOrdValue compares the value of the ordering column of the
+// i-th row to the given value, in the ordering of the partition.
+func (wf WindowFrameRun) compareToOrdValue(evalCtx *EvalContext, i int, val Datum) int {
+	d := wf.Rows[i].Row[wf.OrdColIdx]
+	if d == DNull {
+		// NULLs sort first in ascending order and last in descending order.
+		if wf.OrdDescending {
+			return 1
+		}
+		return -1
+	}
+	c := d.Compare(evalCtx, val)
+	if wf.OrdDescending {
+		return -c
+	}
+	return c
+}
+
 // firstInPeerGroup returns if the current row is the first in its peer group.
-func (wf WindowFrame) firstInPeerGroup() bool {
+func (wf WindowFrameRun) firstInPeerGroup() bool {
 	return wf.RowIdx == wf.FirstPeerIdx
 }
 
-func (wf WindowFrame) args() Datums {
+func (wf WindowFrameRun) args() Datums {
 	return wf.argsWithRowOffset(0)
 }
 
-func (wf WindowFrame) argsWithRowOffset(offset int) Datums {
-	return wf.Rows[wf.RowIdx+offset].Row[wf.ArgIdxStart : wf.ArgIdxStart+wf.ArgCount]
+func (wf WindowFrameRun) argsWithRowOffset(offset int) Datums {
+	return wf.argsAt(wf.RowIdx + offset)
 }
 
-// WindowFunc performs a computation on each row using data from a provided WindowFrame.
+func (wf WindowFrameRun) argsAt(idx int) Datums {
+	return wf.Rows[idx].Row[wf.ArgIdxStart : wf.ArgIdxStart+wf.ArgCount]
+}
+
+// aggValue returns the value to accumulate for the row at the given index
+// when computing an aggregate function over the window frame.
+func (wf WindowFrameRun) aggValue(idx int) Datum {
+	args := wf.argsAt(idx)
+	// COUNT_ROWS takes no arguments.
+	if len(args) > 0 {
+		return args[0]
+	}
+	return nil
+}
+
+// WindowFunc performs a computation on each row using data from a provided WindowFrameRun.
 type WindowFunc interface {
 	// Compute computes the window function for the provided window frame, given the
 	// current state of WindowFunc. The method should be called sequentially for every
@@ -99,7 +261,7 @@ type WindowFunc interface {
 	// because there is an implicit carried dependency between each row and all those
 	// that have come before it (like in an AggregateFunc). As such, this approach does
 	// not present any exploitable associativity/commutativity for optimization.
-	Compute(context.Context, *EvalContext, WindowFrame) (Datum, error)
+	Compute(context.Context, *EvalContext, WindowFrameRun) (Datum, error)
 
 	// Close allows the window function to free any memory it requested during execution,
 	// such as during the execution of an aggregation like CONCAT_AGG or ARRAY_AGG.
@@ -210,15 +372,30 @@ var _ WindowFunc = &nthValueWindow{}
 type aggregateWindowFunc struct {
 	agg     AggregateFunc
 	peerRes Datum
+
+	// The fields below are only used when the window has a frame
+	// specification (see computeFramed).
+
+	// newAgg creates a new instance of the aggregate function.
+	newAgg func() AggregateFunc
+	// sliding, if set, maintains the aggregate as the frame slides.
+	sliding slidingAggregate
+	// frameStart and frameEnd delimit the rows that have been accumulated.
+	frameStart, frameEnd int
+	computed             bool
 }
 
-func newAggregateWindow(agg AggregateFunc) WindowFunc {
-	return &aggregateWindowFunc{agg: agg}
+func newAggregateWindow(newAgg func() AggregateFunc) WindowFunc {
+	return &aggregateWindowFunc{agg: newAgg(), newAgg: newAgg}
 }
 
 func (w *aggregateWindowFunc) Compute(
-	ctx context.Context, evalCtx *EvalContext, wf WindowFrame,
+	ctx context.Context, evalCtx *EvalContext, wf WindowFrameRun,
 ) (Datum, error) {
+	if wf.Frame != nil {
+		return w.computeFramed(ctx, evalCtx, wf)
+	}
+
 	if !wf.firstInPeerGroup() {
 		return w.peerRes, nil
 	}
@@ -226,13 +403,7 @@ func (w *aggregateWindowFunc) Compute(
 	// Accumulate all values in the peer group at the same time, as these
 	// must return the same value.
 	for i := 0; i < wf.PeerRowCount; i++ {
-		args := wf.argsWithRowOffset(i)
-		var value Datum
-		// COUNT_ROWS takes no arguments.
-		if len(args) > 0 {
-			value = args[0]
-		}
-		if err := w.agg.Add(ctx, value); err != nil {
+		if err := w.agg.Add(ctx, wf.aggValue(wf.RowIdx+i)); err != nil {
 			return nil, err
 		}
 	}
@@ -259,7 +430,7 @@ func newRowNumberWindow([]types.T, *EvalContext) WindowFunc {
 	return &rowNumberWindow{}
 }
 
-func (rowNumberWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
+func (rowNumberWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
 	return NewDInt(DInt(wf.RowIdx + 1 /* one-indexed */)), nil
 }
 
@@ -274,7 +445,7 @@ func newRankWindow([]types.T, *EvalContext) WindowFunc {
 	return &rankWindow{}
 }
 
-func (w *rankWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
+func (w *rankWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
 	if wf.firstInPeerGroup() {
 		w.peerRes = NewDInt(DInt(wf.rank()))
 	}
@@ -294,7 +465,7 @@ func newDenseRankWindow([]types.T, *EvalContext) WindowFunc {
 }
 
 func (w *denseRankWindow) Compute(
-	_ context.Context, _ *EvalContext, wf WindowFrame,
+	_ context.Context, _ *EvalContext, wf WindowFrameRun,
 ) (Datum, error) {
 	if wf.firstInPeerGroup() {
 		w.denseRank++
@@ -318,7 +489,7 @@ func newPercentRankWindow([]types.T, *EvalContext) WindowFunc {
 var dfloatZero = NewDFloat(0)
 
 func (w *percentRankWindow) Compute(
-	_ context.Context, _ *EvalContext, wf WindowFrame,
+	_ context.Context, _ *EvalContext, wf WindowFrameRun,
 ) (Datum, error) {
 	// Return zero if there's only one row, per spec.
 	if wf.rowCount() <= 1 {
@@ -345,11 +516,11 @@ func newCumulativeDistWindow([]types.T, *EvalContext) WindowFunc {
 }
 
 func (w *cumulativeDistWindow) Compute(
-	_ context.Context, _ *EvalContext, wf WindowFrame,
+	_ context.Context, _ *EvalContext, wf WindowFrameRun,
 ) (Datum, error) {
 	if wf.firstInPeerGroup() {
 		// (number of rows preceding or peer with current row) / (total rows)
-		w.peerRes = NewDFloat(DFloat(wf.frameSize()) / DFloat(wf.rowCount()))
+		w.peerRes = NewDFloat(DFloat(wf.peerEndIdx()) / DFloat(wf.rowCount()))
 	}
 	return w.peerRes, nil
 }
@@ -371,7 +542,7 @@ func newNtileWindow([]types.T, *EvalContext) WindowFunc {
 
 var errInvalidArgumentForNtile = pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "argument of ntile() must be greater than zero")
 
-func (w *ntileWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
+func (w *ntileWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
 	if w.ntile == nil {
 		// If this is the first call to ntileWindow.Compute, set up the buckets.
 		total := wf.rowCount()
@@ -439,7 +610,7 @@ func makeLeadLagWindowConstructor(
 	}
 }
 
-func (w *leadLagWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
+func (w *leadLagWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrameRun) (Datum, error) {
 	offset := 1
 	if w.withOffset {
 		offsetArg := wf.args()[1]
@@ -473,8 +644,18 @@ func newFirstValueWindow([]types.T, *EvalContext) WindowFunc {
 	return &firstValueWindow{}
 }
 
-func (firstValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
-	return wf.Rows[0].Row[wf.ArgIdxStart], nil
+func (firstValueWindow) Compute(
+	_ context.Context, evalCtx *EvalContext, wf WindowFrameRun,
+) (Datum, error) {
+	start, end, err := wf.frameBounds(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	if start >= end {
+		// The window frame is empty.
+		return DNull, nil
+	}
+	return wf.Rows[start].Row[wf.ArgIdxStart], nil
 }
 
 func (firstValueWindow) Close(context.Context, *EvalContext) {}
@@ -486,8 +667,18 @@ func newLastValueWindow([]types.T, *EvalContext) WindowFunc {
 	return &lastValueWindow{}
 }
 
-func (lastValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
-	return wf.Rows[wf.frameSize()-1].Row[wf.ArgIdxStart], nil
+func (lastValueWindow) Compute(
+	_ context.Context, evalCtx *EvalContext, wf WindowFrameRun,
+) (Datum, error) {
+	start, end, err := wf.frameBounds(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	if start >= end {
+		// The window frame is empty.
+		return DNull, nil
+	}
+	return wf.Rows[end-1].Row[wf.ArgIdxStart], nil
 }
 
 func (lastValueWindow) Close(context.Context, *EvalContext) {}
@@ -502,7 +693,9 @@ func newNthValueWindow([]types.T, *EvalContext) WindowFunc {
 
 var errInvalidArgumentForNthValue = pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "argument of nth_value() must be greater than zero")
 
-func (nthValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame) (Datum, error) {
+func (nthValueWindow) Compute(
+	_ context.Context, evalCtx *EvalContext, wf WindowFrameRun,
+) (Datum, error) {
 	arg := wf.args()[1]
 	if arg == DNull {
 		return DNull, nil
@@ -515,10 +708,14 @@ func (nthValueWindow) Compute(_ context.Context, _ *EvalContext, wf WindowFrame)
 
 	// per spec: Only consider the rows within the "window frame", which by default contains
 	// the rows from the start of the partition through the last peer of the current row.
-	if nth > wf.frameSize() {
+	start, end, err := wf.frameBounds(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	if nth > end-start {
 		return DNull, nil
 	}
-	return wf.Rows[nth-1].Row[wf.ArgIdxStart], nil
+	return wf.Rows[start+nth-1].Row[wf.ArgIdxStart], nil
 }
 
 func (nthValueWindow) Close(context.Context, *EvalContext) {}
@@ -0,0 +1,275 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"github.com/cockroachdb/apd"
+	"golang.org/x/net/context"
+)
+
+// computeFramed computes the aggregate over the window frame of the current
+// row. Both the start and the end of the frame only ever move forward as the
+// partition is traversed, so the rows accumulated for the previous row are
+// reused when possible:
+// - if the frame start cannot move, new rows are simply added to the
+//   aggregate;
+// - if the aggregate supports removing rows (see slidingAggregate), rows
+//   that left the frame are removed and rows that entered it are added;
+// - otherwise the aggregate is recomputed over the whole frame.
+// In all cases, the result is reused if the frame did not change.
+func (w *aggregateWindowFunc) computeFramed(
+	ctx context.Context, evalCtx *EvalContext, wf WindowFrameRun,
+) (Datum, error) {
+	start, end, err := wf.frameBounds(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	if end < start {
+		end = start
+	}
+	if w.computed && start == w.frameStart && end == w.frameEnd {
+		return w.peerRes, nil
+	}
+	if !w.computed && wf.Frame.Bounds.StartBound.BoundType != UnboundedPreceding {
+		w.sliding = newSlidingAggregate(evalCtx, w.agg)
+	}
+
+	var res Datum
+	switch {
+	case w.sliding != nil:
+		for i := w.frameStart; i < start && i < w.frameEnd; i++ {
+			if err := w.sliding.remove(i, wf.aggValue(i)); err != nil {
+				return nil, err
+			}
+		}
+		addStart := w.frameEnd
+		if addStart < start {
+			addStart = start
+		}
+		for i := addStart; i < end; i++ {
+			if err := w.sliding.add(i, wf.aggValue(i)); err != nil {
+				return nil, err
+			}
+		}
+		res, err = w.sliding.result()
+
+	default:
+		if start != w.frameStart {
+			// The aggregate cannot forget about the rows that left the frame;
+			// start over.
+			w.agg.Close(ctx)
+			w.agg = w.newAgg()
+			w.frameEnd = start
+		}
+		for i := w.frameEnd; i < end; i++ {
+			if err := w.agg.Add(ctx, wf.aggValue(i)); err != nil {
+				return nil, err
+			}
+		}
+		res, err = w.agg.Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w.frameStart, w.frameEnd = start, end
+	w.computed = true
+	w.peerRes = res
+	return res, nil
+}
+
+// slidingAggregate is an aggregate over a window frame that can be
+// maintained as the frame slides, that is, which supports removing the
+// values of rows that left the frame. Rows are always added and removed in
+// increasing order of their index in the partition.
+type slidingAggregate interface {
+	add(idx int, d Datum) error
+	remove(idx int, d Datum) error
+	result() (Datum, error)
+}
+
+// newSlidingAggregate returns a slidingAggregate equivalent to the given
+// aggregate function, or nil if it cannot be maintained incrementally.
+// Floating point and interval sums are not supported, as removing values
+// from them is not exact.
+func newSlidingAggregate(evalCtx *EvalContext, agg AggregateFunc) slidingAggregate {
+	switch t := agg.(type) {
+	case *countAggregate:
+		return &slidingCount{}
+	case *countRowsAggregate:
+		return &slidingCount{countRows: true}
+	case *intSumAggregate, *decimalSumAggregate:
+		return newSlidingSum(false /* avg */)
+	case *avgAggregate:
+		switch t.agg.(type) {
+		case *intSumAggregate, *decimalSumAggregate:
+			return newSlidingSum(true /* avg */)
+		}
+	case *MinAggregate:
+		return &slidingExtremum{evalCtx: evalCtx}
+	case *MaxAggregate:
+		return &slidingExtremum{evalCtx: evalCtx, max: true}
+	}
+	return nil
+}
+
+// slidingCount implements COUNT and COUNT_ROWS over a sliding frame.
+type slidingCount struct {
+	countRows bool
+	count     int
+}
+
+func (a *slidingCount) add(_ int, d Datum) error {
+	if a.countRows || d != DNull {
+		a.count++
+	}
+	return nil
+}
+
+func (a *slidingCount) remove(_ int, d Datum) error {
+	if a.countRows || d != DNull {
+		a.count--
+	}
+	return nil
+}
+
+func (a *slidingCount) result() (Datum, error) {
+	return NewDInt(DInt(a.count)), nil
+}
+
+// slidingSum implements SUM and AVG of integers and decimals over a sliding
+// frame.
+type slidingSum struct {
+	avg   bool
+	sum   apd.Decimal
+	tmp   apd.Decimal
+	count int
+	// exponents counts the values in the frame by exponent. The sum of
+	// decimals has the smallest exponent of its operands; this is used to
+	// drop the trailing zeros left by values that have since been removed,
+	// so that the result is the same as if it was computed from scratch.
+	exponents map[int32]int
+}
+
+func newSlidingSum(avg bool) *slidingSum {
+	return &slidingSum{avg: avg, exponents: make(map[int32]int)}
+}
+
+func (a *slidingSum) decimal(d Datum) *apd.Decimal {
+	if t, ok := d.(*DDecimal); ok {
+		return &t.Decimal
+	}
+	a.tmp.SetCoefficient(int64(MustBeDInt(d)))
+	return &a.tmp
+}
+
+func (a *slidingSum) add(_ int, d Datum) error {
+	if d == DNull {
+		return nil
+	}
+	dec := a.decimal(d)
+	if _, err := ExactCtx.Add(&a.sum, &a.sum, dec); err != nil {
+		return err
+	}
+	a.exponents[dec.Exponent]++
+	a.count++
+	return nil
+}
+
+func (a *slidingSum) remove(_ int, d Datum) error {
+	if d == DNull {
+		return nil
+	}
+	dec := a.decimal(d)
+	if _, err := ExactCtx.Sub(&a.sum, &a.sum, dec); err != nil {
+		return err
+	}
+	if a.exponents[dec.Exponent]--; a.exponents[dec.Exponent] == 0 {
+		delete(a.exponents, dec.Exponent)
+	}
+	a.count--
+	return nil
+}
+
+func (a *slidingSum) result() (Datum, error) {
+	if a.count == 0 {
+		return DNull, nil
+	}
+	dd := &DDecimal{}
+	dd.Set(&a.sum)
+	first := true
+	var minExp int32
+	for e := range a.exponents {
+		if first || e < minExp {
+			minExp, first = e, false
+		}
+	}
+	if dd.Exponent < minExp {
+		if _, err := ExactCtx.Quantize(&dd.Decimal, &dd.Decimal, minExp); err != nil {
+			return nil, err
+		}
+	}
+	if a.avg {
+		count := apd.New(int64(a.count), 0)
+		if _, err := DecimalCtx.Quo(&dd.Decimal, &dd.Decimal, count); err != nil {
+			return nil, err
+		}
+	}
+	return dd, nil
+}
+
+// slidingExtremum implements MIN and MAX over a sliding frame. It maintains
+// the candidate values in a deque ordered by row index, in which each value
+// is strictly better than the values that follow it: a value that is not
+// better than a later value can never be the extremum again.
+type slidingExtremum struct {
+	evalCtx *EvalContext
+	max     bool
+	deque   []indexedDatum
+}
+
+type indexedDatum struct {
+	idx int
+	d   Datum
+}
+
+func (a *slidingExtremum) add(idx int, d Datum) error {
+	if d == DNull {
+		return nil
+	}
+	for len(a.deque) > 0 {
+		c := a.deque[len(a.deque)-1].d.Compare(a.evalCtx, d)
+		if (a.max && c > 0) || (!a.max && c < 0) {
+			break
+		}
+		a.deque = a.deque[:len(a.deque)-1]
+	}
+	a.deque = append(a.deque, indexedDatum{idx: idx, d: d})
+	return nil
+}
+
+func (a *slidingExtremum) remove(idx int, _ Datum) error {
+	if len(a.deque) > 0 && a.deque[0].idx == idx {
+		a.deque = a.deque[1:]
+	}
+	return nil
+}
+
+func (a *slidingExtremum) result() (Datum, error) {
+	if len(a.deque) == 0 {
+		return DNull, nil
+	}
+	return a.deque[0].d, nil
+}
@@ -220,9 +220,11 @@ CREATE TABLE pg_catalog.pg_attribute (
 }
 
 var (
-	relKindTable = parser.NewDString("r")
-	relKindIndex = parser.NewDString("i")
-	relKindView  = parser.NewDString("v")
+	relKindTable            = parser.NewDString("r")
+	relKindIndex            = parser.NewDString("i")
+	relKindView             = parser.NewDString("v")
+	relKindMaterializedView = parser.NewDString("m")
+	relKindSequence         = parser.NewDString("S")
 
 	relPersistencePermanent = parser.NewDString("p")
 )
@@ -265,10 +267,16 @@ CREATE TABLE pg_catalog.pg_class (
 		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
 			// Table.
 			relKind := relKindTable
-			if table.IsView() {
+			if table.IsMaterializedView {
+				relKind = relKindMaterializedView
+			} else if table.IsView() {
 				// The only difference between tables and views is the relkind column.
 				relKind = relKindView
+			} else if table.IsSequence() {
+				relKind = relKindSequence
 			}
+			// The primary index of a sequence is an implementation detail.
+			hasIndexes := table.IsPhysicalTable() && !table.IsSequence()
 			if err := addRow(
 				h.TableOid(db, table),       // oid
 				parser.NewDName(table.Name), // relname
@@ -282,7 +290,7 @@ CREATE TABLE pg_catalog.pg_class (
 				parser.DNull,                // reltuples
 				zeroVal,                     // relallvisible
 				oidZero,                     // reltoastrelid
-				parser.MakeDBool(parser.DBool(table.IsPhysicalTable())), // relhasindex
+				parser.MakeDBool(parser.DBool(hasIndexes)),              // relhasindex
 				parser.MakeDBool(false),                                 // relisshared
 				relPersistencePermanent,                                 // relPersistence
 				parser.MakeDBool(false),                                 // relistemp
@@ -290,7 +298,7 @@ CREATE TABLE pg_catalog.pg_class (
 				parser.NewDInt(parser.DInt(len(table.Columns))),         // relnatts
 				parser.NewDInt(parser.DInt(len(table.Checks))),          // relchecks
 				parser.MakeDBool(false),                                 // relhasoids
-				parser.MakeDBool(parser.DBool(table.IsPhysicalTable())), // relhaspkey
+				parser.MakeDBool(parser.DBool(hasIndexes)),              // relhaspkey
 				parser.MakeDBool(false),                                 // relhasrules
 				parser.MakeDBool(false),                                 // relhastriggers
 				parser.MakeDBool(false),                                 // relhassubclass
@@ -390,11 +398,13 @@ var (
 	fkActionSetNull    = parser.NewDString("n")
 	fkActionSetDefault = parser.NewDString("d")
 
-	// Avoid unused warning for constants.
-	_ = fkActionRestrict
-	_ = fkActionCascade
-	_ = fkActionSetNull
-	_ = fkActionSetDefault
+	fkActionTypes = map[sqlbase.ForeignKeyReference_Action]parser.Datum{
+		sqlbase.ForeignKeyReference_NO_ACTION:   fkActionNone,
+		sqlbase.ForeignKeyReference_RESTRICT:    fkActionRestrict,
+		sqlbase.ForeignKeyReference_CASCADE:     fkActionCascade,
+		sqlbase.ForeignKeyReference_SET_NULL:    fkActionSetNull,
+		sqlbase.ForeignKeyReference_SET_DEFAULT: fkActionSetDefault,
+	}
 
 	fkMatchTypeFull    = parser.NewDString("f")
 	fkMatchTypePartial = parser.NewDString("p")
@@ -483,8 +493,8 @@ CREATE TABLE pg_catalog.pg_constraint (
 					contype = conTypeFK
 					conindid = h.IndexOid(referencedDB, c.ReferencedTable, c.ReferencedIndex)
 					confrelid = h.TableOid(referencedDB, c.ReferencedTable)
-					confupdtype = fkActionNone
-					confdeltype = fkActionNone
+					confupdtype = fkActionTypes[c.FK.OnUpdate]
+					confdeltype = fkActionTypes[c.FK.OnDelete]
 					confmatchtype = fkMatchTypeSimple
 					var err error
 					conkey, err = colIDArrayToDatum(c.Index.ColumnIDs)
@@ -943,11 +953,25 @@ func indexDefFromDescriptor(
 		if index.ColumnDirections[i] == sqlbase.IndexDescriptor_DESC {
 			elem.Direction = parser.Descending
 		}
+		if i < len(index.ColumnExpressions) && index.ColumnExpressions[i] != "" {
+			expr, err := parser.ParseExpr(index.ColumnExpressions[i])
+			if err != nil {
+				return "", err
+			}
+			elem.Expr = expr
+		}
 		indexDef.Columns[i] = elem
 	}
 	for i, name := range index.StoreColumnNames {
 		indexDef.Storing[i] = parser.Name(name)
 	}
+	if index.IsPartial() {
+		pred, err := parser.ParseExpr(index.Predicate)
+		if err != nil {
+			return "", err
+		}
+		indexDef.Predicate = pred
+	}
 	if len(index.Interleave.Ancestors) > 0 {
 		intl := index.Interleave
 		parentTable, err := sqlbase.GetTableDescFromID(ctx, p.txn, intl.Ancestors[len(intl.Ancestors)-1].TableID)
@@ -1225,17 +1249,20 @@ CREATE TABLE pg_catalog.pg_roles (
 		// include sensitive information such as password hashes.
 		h := makeOidHasher()
 		return forEachUser(ctx, p,
-			func(username string) error {
+			func(username string, isRole bool) error {
 				isRoot := parser.DBool(username == security.RootUser)
+				// Roles cannot log in; their privileges are inherited by
+				// their members.
+				canLogin := parser.DBool(!isRole)
 				return addRow(
 					h.UserOid(username),           // oid
 					parser.NewDName(username),     // rolname
 					parser.MakeDBool(isRoot),      // rolsuper
-					parser.MakeDBool(false),       // rolinherit
+					parser.MakeDBool(true),        // rolinherit
 					parser.MakeDBool(isRoot),      // rolcreaterole
 					parser.MakeDBool(isRoot),      // rolcreatedb
 					parser.MakeDBool(false),       // rolcatupdate
-					parser.MakeDBool(true),        // rolcanlogin
+					parser.MakeDBool(canLogin),    // rolcanlogin
 					negOneVal,                     // rolconnlimit
 					parser.NewDString("********"), // rolpassword
 					parser.DNull,                  // rolvaliduntil
@@ -1320,7 +1347,7 @@ CREATE TABLE pg_catalog.pg_tables (
 `,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...parser.Datum) error) error {
 		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
-			if table.IsView() {
+			if !table.IsTable() {
 				return nil
 			}
 			return addRow(
@@ -0,0 +1,208 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+)
+
+// hbaConfSetting holds the host-based authentication rules, in a subset of
+// the pg_hba.conf format. Each line is a rule:
+//
+//   host <databases> <users> <address> <method>
+//
+// where databases and users are comma-separated lists or "all", address is
+// an IP address, a CIDR network or "all", and method is one of cert,
+// password, scram-sha-256, trust or reject. The first rule matching a
+// connection decides how it is authenticated; connections which match no
+// rule are rejected.
+// Text after a # is a comment.
+var hbaConfSetting = settings.RegisterValidatedStringSetting(
+	"server.host_based_authentication.configuration",
+	"host-based authentication rules for SQL connections, one per line in the "+
+		"format 'host <databases> <users> <address> <method>' (method: cert, password, "+
+		"scram-sha-256, trust or reject); if empty, clients authenticate with a certificate or a password",
+	"",
+	func(s string) error {
+		_, err := parseHBAConf(s)
+		return err
+	},
+)
+
+// hbaMethod is the authentication method of a host-based authentication
+// rule.
+type hbaMethod string
+
+const (
+	// hbaDefault authenticates clients with a certificate if they present
+	// one, and with a password otherwise. It is used when no rules are
+	// configured.
+	hbaDefault hbaMethod = ""
+	// hbaCert requires a client certificate for the user.
+	hbaCert hbaMethod = "cert"
+	// hbaPassword requires the password of the user, sent in cleartext.
+	hbaPassword hbaMethod = "password"
+	// hbaScram requires the password of the user, verified with a
+	// SCRAM-SHA-256 exchange which does not reveal it to the server.
+	hbaScram hbaMethod = "scram-sha-256"
+	// hbaTrust lets the client connect as any existing user.
+	hbaTrust hbaMethod = "trust"
+	// hbaReject refuses the connection.
+	hbaReject hbaMethod = "reject"
+)
+
+// hbaRule is a host-based authentication rule.
+type hbaRule struct {
+	// databases and users are nil if the rule matches all of them.
+	databases []string
+	users     []string
+	// network is nil if the rule matches all addresses.
+	network *net.IPNet
+	method  hbaMethod
+}
+
+// hbaConf is a list of host-based authentication rules, in order of
+// precedence.
+type hbaConf struct {
+	rules []hbaRule
+}
+
+// parseHBAConf parses host-based authentication rules.
+func parseHBAConf(s string) (hbaConf, error) {
+	var conf hbaConf
+	for i, line := range strings.Split(s, "\n") {
+		if pos := strings.IndexByte(line, '#'); pos >= 0 {
+			line = line[:pos]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		rule, err := parseHBARule(fields)
+		if err != nil {
+			return hbaConf{}, errors.Wrapf(err, "line %d", i+1)
+		}
+		conf.rules = append(conf.rules, rule)
+	}
+	return conf, nil
+}
+
+func parseHBARule(fields []string) (hbaRule, error) {
+	if len(fields) != 5 {
+		return hbaRule{}, errors.Errorf(
+			"expected 5 fields (host <databases> <users> <address> <method>), found %d", len(fields))
+	}
+	if fields[0] != "host" {
+		return hbaRule{}, errors.Errorf("unsupported connection type %q", fields[0])
+	}
+
+	var rule hbaRule
+	rule.databases = parseHBANames(fields[1])
+	rule.users = parseHBANames(fields[2])
+
+	if addr := fields[3]; addr != "all" {
+		if !strings.Contains(addr, "/") {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return hbaRule{}, errors.Errorf("invalid address %q", addr)
+			}
+			if ip.To4() != nil {
+				addr += "/32"
+			} else {
+				addr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(addr)
+		if err != nil {
+			return hbaRule{}, errors.Errorf("invalid address %q", fields[3])
+		}
+		rule.network = network
+	}
+
+	switch method := hbaMethod(strings.ToLower(fields[4])); method {
+	case hbaCert, hbaPassword, hbaScram, hbaTrust, hbaReject:
+		rule.method = method
+	default:
+		return hbaRule{}, errors.Errorf("unsupported authentication method %q", fields[4])
+	}
+	return rule, nil
+}
+
+// parseHBANames parses a comma-separated list of database or user names,
+// which are normalized like SQL identifiers. It returns nil for "all".
+func parseHBANames(s string) []string {
+	if s == "all" {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name != "" {
+			names = append(names, parser.Name(name).Normalize())
+		}
+	}
+	return names
+}
+
+// lookup returns the authentication method of the first rule matching the
+// user, the database and the address of a connection. It returns false if
+// no rule matches.
+func (c hbaConf) lookup(user, database string, addr net.Addr) (hbaMethod, bool) {
+	ip := addrIP(addr)
+	database = parser.Name(database).Normalize()
+	for _, rule := range c.rules {
+		if !hbaNamesContain(rule.databases, database) || !hbaNamesContain(rule.users, user) {
+			continue
+		}
+		if rule.network != nil && (ip == nil || !rule.network.Contains(ip)) {
+			continue
+		}
+		return rule.method, true
+	}
+	return "", false
+}
+
+func hbaNamesContain(names []string, name string) bool {
+	if names == nil {
+		return true
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addrIP returns the IP address of a connection, or nil if it does not
+// have one.
+func addrIP(addr net.Addr) net.IP {
+	if addr == nil {
+		return nil
+	}
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		return tcpAddr.IP
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
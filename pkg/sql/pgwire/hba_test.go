@@ -0,0 +1,97 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseHBAConf(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		conf     string
+		numRules int
+		err      string
+	}{
+		{``, 0, ``},
+		{"# comment only\n\n", 0, ``},
+		{`host all root 127.0.0.1/32 cert`, 1, ``},
+		{"host all all all reject # trailing comment\nhost db1,db2 u1,u2 ::1 trust", 2, ``},
+		{`host all all 10.0.0.0/8 PASSWORD`, 1, ``},
+		{`host all all 10.0.0.0/8 scram-sha-256`, 1, ``},
+		{`host all all`, 0, `line 1: expected 5 fields`},
+		{"\nlocal all all all trust", 0, `line 2: unsupported connection type "local"`},
+		{`host all all 10.0.0/8 cert`, 0, `invalid address "10.0.0/8"`},
+		{`host all all localhost cert`, 0, `invalid address "localhost"`},
+		{`host all all all md5`, 0, `unsupported authentication method "md5"`},
+	}
+	for _, d := range testData {
+		conf, err := parseHBAConf(d.conf)
+		if !testutils.IsError(err, d.err) {
+			t.Errorf("%q: expected error %q, got %v", d.conf, d.err, err)
+			continue
+		}
+		if err == nil && len(conf.rules) != d.numRules {
+			t.Errorf("%q: expected %d rules, got %d", d.conf, d.numRules, len(conf.rules))
+		}
+	}
+}
+
+func TestHBAConfLookup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	conf, err := parseHBAConf(`
+# TYPE DATABASE USER     ADDRESS      METHOD
+host   all      root     127.0.0.1    cert
+host   all      root     all          reject
+host   bank     app,App2 10.1.0.0/16  password
+host   all      all      ::1/128      trust
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcpAddr := func(ip string) net.Addr {
+		return &net.TCPAddr{IP: net.ParseIP(ip), Port: 26257}
+	}
+	testData := []struct {
+		user     string
+		database string
+		addr     net.Addr
+		method   hbaMethod
+		ok       bool
+	}{
+		{"root", "", tcpAddr("127.0.0.1"), hbaCert, true},
+		{"root", "bank", tcpAddr("10.1.2.3"), hbaReject, true},
+		{"app", "bank", tcpAddr("10.1.2.3"), hbaPassword, true},
+		{"app2", "BANK", tcpAddr("10.1.2.3"), hbaPassword, true},
+		{"app", "other", tcpAddr("10.1.2.3"), "", false},
+		{"app", "bank", tcpAddr("10.2.0.1"), "", false},
+		{"app", "other", tcpAddr("::1"), hbaTrust, true},
+		{"app", "bank", &net.UnixAddr{Name: "/tmp/sock", Net: "unix"}, "", false},
+	}
+	for _, d := range testData {
+		method, ok := conf.lookup(d.user, d.database, d.addr)
+		if method != d.method || ok != d.ok {
+			t.Errorf("%s@%s from %s: expected (%q, %t), got (%q, %t)",
+				d.user, d.database, d.addr, d.method, d.ok, method, ok)
+		}
+	}
+}
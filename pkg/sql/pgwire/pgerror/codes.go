@@ -88,6 +88,7 @@ const (
 	CodeNonstandardUseOfEscapeCharacterError       = "22P06"
 	CodeInvalidIndicatorParameterValueError        = "22010"
 	CodeInvalidParameterValueError                 = "22023"
+	CodeInvalidPrecedingOrFollowingSizeError       = "22013"
 	CodeInvalidRegularExpressionError              = "2201B"
 	CodeInvalidRowCountInLimitClauseError          = "2201W"
 	CodeInvalidRowCountInResultOffsetClauseError   = "2201X"
@@ -97,6 +98,7 @@ const (
 	CodeNullValueNotAllowedError                   = "22004"
 	CodeNullValueNoIndicatorParameterError         = "22002"
 	CodeNumericValueOutOfRangeError                = "22003"
+	CodeSequenceGeneratorLimitExceededError        = "2200H"
 	CodeStringDataLengthMismatchError              = "22026"
 	CodeStringDataRightTruncationError             = "22001"
 	CodeSubstringError                             = "22011"
@@ -135,6 +137,7 @@ const (
 	CodeSchemaAndDataStatementMixingNotSupportedError        = "25007"
 	CodeNoActiveSQLTransactionError                          = "25P01"
 	CodeInFailedSQLTransactionError                          = "25P02"
+	CodeIdleInTransactionSessionTimeoutError                 = "25P03"
 	// Class 26 - Invalid SQL Statement Name
 	CodeInvalidSQLStatementNameError = "26000"
 	// Class 27 - Triggered Data Change Violation
@@ -1787,3 +1787,58 @@ func TestPGWireTooManyArguments(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestPGWireIdleInTransactionSessionTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(`
+CREATE DATABASE d;
+CREATE TABLE d.t (k INT PRIMARY KEY);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`SET idle_in_transaction_session_timeout = '100ms'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO d.t VALUES (1)`); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second)
+
+	// The session was terminated, and its transaction aborted.
+	_, err = tx.Exec(`SELECT 1`)
+	if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != "25P03" {
+		t.Fatalf("expected idle-in-transaction timeout error, got %v", err)
+	}
+	_ = tx.Rollback()
+
+	var count int
+	if err := db.QueryRow(`SELECT count(*) FROM d.t`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected the transaction to be aborted, found %d rows", count)
+	}
+
+	// Sessions which are idle outside of a transaction are not terminated.
+	conn, err := db.Conn(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(context.TODO(), `SET idle_in_transaction_session_timeout = 100`); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second)
+	if _, err := conn.ExecContext(context.TODO(), `SELECT 1`); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -59,8 +61,9 @@ var (
 )
 
 const (
-	version30  = 196608
-	versionSSL = 80877103
+	version30     = 196608
+	versionCancel = 80877102
+	versionSSL    = 80877103
 )
 
 const (
@@ -93,6 +96,7 @@ type cancelChanMap map[chan struct{}]context.CancelFunc
 type Server struct {
 	AmbientCtx log.AmbientContext
 	cfg        *base.Config
+	st         *cluster.Settings
 	executor   *sql.Executor
 
 	metrics ServerMetrics
@@ -149,6 +153,7 @@ var noteworthyConnMemoryUsageBytes = envutil.EnvOrDefaultInt64("COCKROACH_NOTEWO
 func MakeServer(
 	ambientCtx log.AmbientContext,
 	cfg *base.Config,
+	st *cluster.Settings,
 	executor *sql.Executor,
 	internalMemMetrics *sql.MemoryMetrics,
 	parentMemoryMonitor *mon.BytesMonitor,
@@ -157,6 +162,7 @@ func MakeServer(
 	server := &Server{
 		AmbientCtx: ambientCtx,
 		cfg:        cfg,
+		st:         st,
 		executor:   executor,
 		metrics:    makeServerMetrics(internalMemMetrics, histogramWindow),
 	}
@@ -192,7 +198,7 @@ func Match(rd io.Reader) bool {
 	if err != nil {
 		return false
 	}
-	return version == version30 || version == versionSSL
+	return version == version30 || version == versionSSL || version == versionCancel
 }
 
 // IsDraining returns true if the server is not currently accepting
@@ -381,6 +387,14 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 		errSSLRequired = true
 	}
 
+	if version == versionCancel {
+		// A CancelRequest is not authenticated other than by its secret key,
+		// and gets no response, even if it fails: the client cannot tell
+		// whether it had any effect.
+		s.handleCancelRequest(ctx, &buf)
+		return nil
+	}
+
 	if version == version30 {
 		// We make a connection before anything. If there is an error
 		// parsing the connection arguments, the connection will only be
@@ -400,7 +414,12 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 		}
 
 		v3conn.sessionArgs.User = parser.Name(v3conn.sessionArgs.User).Normalize()
-		if err := v3conn.handleAuthentication(ctx, s.cfg.Insecure); err != nil {
+		// The rules were validated when the setting was changed.
+		hba, err := parseHBAConf(hbaConfSetting.Get(&s.st.SV))
+		if err != nil {
+			return v3conn.sendError(err)
+		}
+		if err := v3conn.handleAuthentication(ctx, s.cfg.Insecure, hba); err != nil {
 			return v3conn.sendError(pgerror.NewError(pgerror.CodeInvalidPasswordError, err.Error()))
 		}
 
@@ -419,10 +438,13 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 				baseSQLMemoryBudget, err)
 		}
 
-		err := v3conn.serve(ctx, s.IsDraining, acc)
+		err = v3conn.serve(ctx, s.IsDraining, acc)
 		// If the error that closed the connection is related to an
-		// administrative shutdown, relay that information to the client.
-		if pgErr, ok := pgerror.GetPGCause(err); ok && pgErr.Code == pgerror.CodeAdminShutdownError {
+		// administrative shutdown or to an idle-in-transaction timeout, relay
+		// that information to the client.
+		if pgErr, ok := pgerror.GetPGCause(err); ok &&
+			(pgErr.Code == pgerror.CodeAdminShutdownError ||
+				pgErr.Code == pgerror.CodeIdleInTransactionSessionTimeoutError) {
 			return v3conn.sendError(err)
 		}
 		return err
@@ -430,3 +452,25 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 
 	return errors.Errorf("unknown protocol version %d", version)
 }
+
+// handleCancelRequest cancels the queries of the session identified by the
+// process ID and secret key of a CancelRequest, which the session sent to
+// its client in a BackendKeyData message. The process ID is the ID of the
+// node of the session, which may not be this node.
+func (s *Server) handleCancelRequest(ctx context.Context, buf *readBuffer) {
+	nodeID, err := buf.getUint32()
+	if err != nil {
+		log.Warningf(ctx, "malformed CancelRequest: %v", err)
+		return
+	}
+	cancelKey, err := buf.getUint32()
+	if err != nil {
+		log.Warningf(ctx, "malformed CancelRequest: %v", err)
+		return
+	}
+	if err := s.executor.CancelQueryByKey(
+		ctx, roachpb.NodeID(nodeID), int32(cancelKey),
+	); err != nil {
+		log.Infof(ctx, "unable to process CancelRequest for node %d: %v", nodeID, err)
+	}
+}
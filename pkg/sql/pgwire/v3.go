@@ -66,6 +66,7 @@ const (
 	clientMsgTerminate   clientMessageType = 'X'
 
 	serverMsgAuth                 serverMessageType = 'R'
+	serverMsgBackendKeyData       serverMessageType = 'K'
 	serverMsgBindComplete         serverMessageType = '2'
 	serverMsgCommandComplete      serverMessageType = 'C'
 	serverMsgCloseComplete        serverMessageType = '3'
@@ -107,6 +108,9 @@ const (
 const (
 	authOK                int32 = 0
 	authCleartextPassword int32 = 3
+	authSASL              int32 = 10
+	authSASLContinue      int32 = 11
+	authSASLFinal         int32 = 12
 )
 
 // connResultsBufferSizeBytes refers to the size of the result set which we
@@ -334,7 +338,25 @@ var statusReportParams = map[string]string{
 // name, if different from the one given initially. Note: at this
 // point the sql.Session does not exist yet! If need exists to access the
 // database to look up authentication data, use the internal executor.
-func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool) error {
+//
+// If host-based authentication rules are configured, the first rule
+// matching the connection decides the authentication method.
+func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool, hba hbaConf) error {
+	method := hbaDefault
+	if len(hba.rules) > 0 {
+		var ok bool
+		method, ok = hba.lookup(c.sessionArgs.User, c.sessionArgs.Database, c.conn.RemoteAddr())
+		if !ok {
+			return c.sendError(errors.Errorf(
+				"no host-based authentication rule for user %s, database %q and address %s",
+				c.sessionArgs.User, c.sessionArgs.Database, c.conn.RemoteAddr()))
+		}
+	}
+	if method == hbaReject {
+		return c.sendError(errors.Errorf(
+			"connection of user %s rejected by host-based authentication rules", c.sessionArgs.User))
+	}
+
 	if tlsConn, ok := c.conn.(*tls.Conn); ok {
 		var authenticationHook security.UserAuthHook
 
@@ -351,17 +373,49 @@ func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool) error
 		}
 
 		tlsState := tlsConn.ConnectionState()
-		// If no certificates are provided, default to password
-		// authentication.
-		if len(tlsState.PeerCertificates) == 0 {
+		switch {
+		case method == hbaTrust:
+			return c.sendAuthOK()
+		case method == hbaCert && len(tlsState.PeerCertificates) == 0:
+			return c.sendError(errors.Errorf(
+				"user %s must use certificate authentication", c.sessionArgs.User))
+		case method == hbaScram:
+			scramVerifier, err := sql.GetUserScramVerifier(
+				ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User,
+			)
+			if err != nil {
+				return c.sendError(err)
+			}
+			if len(scramVerifier) == 0 {
+				return c.sendError(errors.Errorf(
+					"user %s has no %s verifier: log in with password authentication "+
+						"or set the password again to create one", c.sessionArgs.User, security.ScramSHA256))
+			}
+			if err := c.handleScramAuthentication(scramVerifier); err != nil {
+				return c.sendError(err)
+			}
+			return c.sendAuthOK()
+		case method == hbaPassword || len(tlsState.PeerCertificates) == 0:
+			// If no certificates are provided, default to password
+			// authentication.
 			password, err := c.sendAuthPasswordRequest()
 			if err != nil {
 				return c.sendError(err)
 			}
-			authenticationHook = security.UserAuthPasswordHook(
+			if err := security.UserAuthPasswordHook(
 				insecure, password, hashedPassword,
-			)
-		} else {
+			)(c.sessionArgs.User, true /* public */); err != nil {
+				return c.sendError(err)
+			}
+			// Users whose password was set before SCRAM verifiers were
+			// stored get one on their first successful login, so that they
+			// can then use SCRAM authentication.
+			if err := c.maybeAddScramVerifier(ctx, password); err != nil {
+				log.Warningf(ctx, "unable to store the %s verifier of user %s: %v",
+					security.ScramSHA256, c.sessionArgs.User, err)
+			}
+			return c.sendAuthOK()
+		default:
 			// Normalize the username contained in the certificate.
 			tlsState.PeerCertificates[0].Subject.CommonName = parser.Name(
 				tlsState.PeerCertificates[0].Subject.CommonName,
@@ -378,6 +432,10 @@ func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool) error
 		}
 	}
 
+	return c.sendAuthOK()
+}
+
+func (c *v3Conn) sendAuthOK() error {
 	c.writeBuf.initMsg(serverMsgAuth)
 	c.writeBuf.putInt32(authOK)
 	return c.writeBuf.finishMsg(c.wr)
@@ -391,6 +449,16 @@ func (c *v3Conn) setupSession(ctx context.Context, reserved mon.BoundAccount) er
 	return nil
 }
 
+// sendBackendKeyData sends the identifiers with which the client can cancel
+// the queries of the session with a CancelRequest.
+func (c *v3Conn) sendBackendKeyData() error {
+	nodeID, cancelKey := c.session.BackendKeyData()
+	c.writeBuf.initMsg(serverMsgBackendKeyData)
+	c.writeBuf.putInt32(int32(nodeID))
+	c.writeBuf.putInt32(cancelKey)
+	return c.writeBuf.finishMsg(c.wr)
+}
+
 func (c *v3Conn) closeSession(ctx context.Context) {
 	c.session.Finish(c.executor)
 	c.session = nil
@@ -413,6 +481,9 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 	if err := c.setupSession(ctx, reserved); err != nil {
 		return err
 	}
+	if err := c.sendBackendKeyData(); err != nil {
+		return err
+	}
 	// Now that a Session has been set up, further operations done on behalf of
 	// this session use Session.Ctx() (which may diverge from this method's ctx).
 
@@ -430,6 +501,9 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 	// a conn that exits if the session's context is cancelled or if the server
 	// is draining and the session does not have an ongoing transaction.
 	c.conn = newReadTimeoutConn(c.conn, func() error {
+		if err := c.session.IdleInTxnTimeoutErr(); err != nil {
+			return err
+		}
 		if err := func() error {
 			if draining() && c.session.TxnState.State() == sql.NoTxn {
 				return errors.New(ErrDraining)
@@ -478,7 +552,9 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 			}
 		}
 		c.doNotSendReadyForQuery = false
+		c.session.StartIdleInTxnTimer()
 		typ, n, err := c.readBuf.readTypedMsg(c.rd)
+		c.session.StopIdleInTxnTimer()
 		c.metrics.BytesInCount.Inc(int64(n))
 		if err != nil {
 			return err
@@ -578,24 +654,112 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 func (c *v3Conn) sendAuthPasswordRequest() (string, error) {
 	c.writeBuf.initMsg(serverMsgAuth)
 	c.writeBuf.putInt32(authCleartextPassword)
-	if err := c.writeBuf.finishMsg(c.wr); err != nil {
+	if err := c.sendAuthRequest(); err != nil {
 		return "", err
 	}
+	return c.readBuf.getString()
+}
+
+// sendAuthRequest sends the authentication request in the write buffer and
+// reads the response of the client into the read buffer.
+func (c *v3Conn) sendAuthRequest() error {
+	if err := c.writeBuf.finishMsg(c.wr); err != nil {
+		return err
+	}
 	if err := c.wr.Flush(); err != nil {
-		return "", err
+		return err
 	}
 
 	typ, n, err := c.readBuf.readTypedMsg(c.rd)
 	c.metrics.BytesInCount.Inc(int64(n))
 	if err != nil {
-		return "", err
+		return err
 	}
 
+	// Passwords and SASL responses share the same message type.
 	if typ != clientMsgPassword {
-		return "", errors.Errorf("invalid response to authentication request: %s", typ)
+		return errors.Errorf("invalid response to authentication request: %s", typ)
 	}
+	return nil
+}
 
-	return c.readBuf.getString()
+// handleScramAuthentication authenticates the user with a SCRAM-SHA-256
+// SASL exchange. See:
+// https://www.postgresql.org/docs/10/static/sasl-authentication.html
+func (c *v3Conn) handleScramAuthentication(scramVerifier []byte) error {
+	verifier, err := security.DecodeScramVerifier(scramVerifier)
+	if err != nil {
+		return err
+	}
+	scram := security.NewScramServer(verifier)
+
+	// The list of supported mechanisms is terminated by an empty name.
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(authSASL)
+	c.writeBuf.writeTerminatedString(security.ScramSHA256)
+	c.writeBuf.nullTerminate()
+	if err := c.sendAuthRequest(); err != nil {
+		return err
+	}
+	// SASLInitialResponse: the chosen mechanism and the client-first
+	// message.
+	mechanism, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	if mechanism != security.ScramSHA256 {
+		return errors.Errorf("unsupported SASL mechanism %q", mechanism)
+	}
+	n, err := c.readBuf.getUint32()
+	if err != nil {
+		return err
+	}
+	if int32(n) < 0 {
+		return errors.Errorf("missing %s client-first message", security.ScramSHA256)
+	}
+	clientFirst, err := c.readBuf.getBytes(int(n))
+	if err != nil {
+		return err
+	}
+	serverFirst, err := scram.ServerFirst(clientFirst)
+	if err != nil {
+		return err
+	}
+
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(authSASLContinue)
+	c.writeBuf.write(serverFirst)
+	if err := c.sendAuthRequest(); err != nil {
+		return err
+	}
+	// SASLResponse: the client-final message.
+	serverFinal, err := scram.ServerFinal(c.readBuf.msg)
+	if err != nil {
+		return err
+	}
+
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(authSASLFinal)
+	c.writeBuf.write(serverFinal)
+	return c.writeBuf.finishMsg(c.wr)
+}
+
+// maybeAddScramVerifier stores the SCRAM verifier of the password of the
+// user, if the user has none.
+func (c *v3Conn) maybeAddScramVerifier(ctx context.Context, password string) error {
+	scramVerifier, err := sql.GetUserScramVerifier(
+		ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User,
+	)
+	if err != nil || len(scramVerifier) > 0 {
+		return err
+	}
+	verifier, err := security.MakeScramVerifier(password)
+	if err != nil {
+		return err
+	}
+	return sql.SetUserScramVerifier(
+		ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User, verifier.Encode(),
+	)
 }
 
 func (c *v3Conn) handleSimpleQuery(buf *readBuffer) error {
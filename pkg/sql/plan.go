@@ -141,10 +141,13 @@ type planNodeFastPath interface {
 	FastPathResults() (int, bool)
 }
 
+var _ planNode = &alterSequenceNode{}
 var _ planNode = &alterTableNode{}
 var _ planNode = &copyNode{}
 var _ planNode = &createDatabaseNode{}
 var _ planNode = &createIndexNode{}
+var _ planNode = &createSequenceNode{}
+var _ planNode = &createStatsNode{}
 var _ planNode = &createTableNode{}
 var _ planNode = &createViewNode{}
 var _ planNode = &delayedNode{}
@@ -152,6 +155,7 @@ var _ planNode = &deleteNode{}
 var _ planNode = &distinctNode{}
 var _ planNode = &dropDatabaseNode{}
 var _ planNode = &dropIndexNode{}
+var _ planNode = &dropSequenceNode{}
 var _ planNode = &dropTableNode{}
 var _ planNode = &dropViewNode{}
 var _ planNode = &zeroNode{}
@@ -168,9 +172,12 @@ var _ planNode = &joinNode{}
 var _ planNode = &limitNode{}
 var _ planNode = &ordinalityNode{}
 var _ planNode = &testingRelocateNode{}
+var _ planNode = &recursiveCTENode{}
+var _ planNode = &refreshMaterializedViewNode{}
 var _ planNode = &renderNode{}
 var _ planNode = &scanNode{}
 var _ planNode = &scatterNode{}
+var _ planNode = &sequenceSelectNode{}
 var _ planNode = &showRangesNode{}
 var _ planNode = &showFingerprintsNode{}
 var _ planNode = &sortNode{}
@@ -188,6 +195,7 @@ var _ planNodeFastPath = &dropUserNode{}
 
 // makePlan implements the Planner interface.
 func (p *planner) makePlan(ctx context.Context, stmt Statement) (planNode, error) {
+	p.pinnedHints = p.session.statementHints.pinnedHints(stmt.AST)
 	plan, err := p.newPlan(ctx, stmt.AST, nil)
 	if err != nil {
 		return nil, err
@@ -330,6 +338,8 @@ func (p *planner) newPlan(
 	switch n := stmt.(type) {
 	case *parser.AlterTable:
 		return p.AlterTable(ctx, n)
+	case *parser.AlterSequence:
+		return p.AlterSequence(ctx, n)
 	case *parser.AlterUserSetPassword:
 		return p.AlterUserSetPassword(ctx, n)
 	case *parser.BeginTransaction:
@@ -350,10 +360,16 @@ func (p *planner) newPlan(
 		return p.CreateIndex(ctx, n)
 	case *parser.CreateTable:
 		return p.CreateTable(ctx, n)
+	case *parser.CreateRole:
+		return p.CreateRole(ctx, n)
 	case *parser.CreateUser:
 		return p.CreateUser(ctx, n)
 	case *parser.CreateView:
 		return p.CreateView(ctx, n)
+	case *parser.CreateSequence:
+		return p.CreateSequence(ctx, n)
+	case *parser.CreateStats:
+		return p.CreateStats(ctx, n)
 	case *parser.Deallocate:
 		return p.Deallocate(ctx, n)
 	case *parser.Delete:
@@ -368,6 +384,10 @@ func (p *planner) newPlan(
 		return p.DropTable(ctx, n)
 	case *parser.DropView:
 		return p.DropView(ctx, n)
+	case *parser.DropSequence:
+		return p.DropSequence(ctx, n)
+	case *parser.DropRole:
+		return p.DropRole(ctx, n)
 	case *parser.DropUser:
 		return p.DropUser(ctx, n)
 	case *parser.Execute:
@@ -376,6 +396,8 @@ func (p *planner) newPlan(
 		return p.Explain(ctx, n)
 	case *parser.Grant:
 		return p.Grant(ctx, n)
+	case *parser.GrantRole:
+		return p.GrantRole(ctx, n)
 	case *parser.Insert:
 		return p.Insert(ctx, n, desiredTypes)
 	case *parser.ParenSelect:
@@ -384,6 +406,8 @@ func (p *planner) newPlan(
 		return p.PauseJob(ctx, n)
 	case *parser.TestingRelocate:
 		return p.TestingRelocate(ctx, n)
+	case *parser.RefreshMaterializedView:
+		return p.RefreshMaterializedView(ctx, n)
 	case *parser.RenameColumn:
 		return p.RenameColumn(ctx, n)
 	case *parser.RenameDatabase:
@@ -396,6 +420,8 @@ func (p *planner) newPlan(
 		return p.ResumeJob(ctx, n)
 	case *parser.Revoke:
 		return p.Revoke(ctx, n)
+	case *parser.RevokeRole:
+		return p.RevokeRole(ctx, n)
 	case *parser.Scatter:
 		return p.Scatter(ctx, n)
 	case *parser.Select:
@@ -425,6 +451,8 @@ func (p *planner) newPlan(
 		return p.ShowCreateTable(ctx, n)
 	case *parser.ShowCreateView:
 		return p.ShowCreateView(ctx, n)
+	case *parser.ShowCreateSequence:
+		return p.ShowCreateSequence(ctx, n)
 	case *parser.ShowDatabases:
 		return p.ShowDatabases(ctx, n)
 	case *parser.ShowGrants:
@@ -439,10 +467,14 @@ func (p *planner) newPlan(
 		return p.ShowSessions(ctx, n)
 	case *parser.ShowTables:
 		return p.ShowTables(ctx, n)
+	case *parser.ShowTableStats:
+		return p.ShowTableStats(ctx, n)
 	case *parser.ShowTrace:
 		return p.ShowTrace(ctx, n)
 	case *parser.ShowTransactionStatus:
 		return p.ShowTransactionStatus(ctx)
+	case *parser.ShowRoles:
+		return p.ShowRoles(ctx, n)
 	case *parser.ShowUsers:
 		return p.ShowUsers(ctx, n)
 	case *parser.ShowZoneConfig:
@@ -486,10 +518,14 @@ func (p *planner) prepare(ctx context.Context, stmt parser.Statement) (planNode,
 		return p.CancelQuery(ctx, n)
 	case *parser.CancelJob:
 		return p.CancelJob(ctx, n)
+	case *parser.CreateRole:
+		return p.CreateRole(ctx, n)
 	case *parser.CreateUser:
 		return p.CreateUser(ctx, n)
 	case *parser.Delete:
 		return p.Delete(ctx, n, nil)
+	case *parser.DropRole:
+		return p.DropRole(ctx, n)
 	case *parser.DropUser:
 		return p.DropUser(ctx, n)
 	case *parser.Explain:
@@ -517,6 +553,8 @@ func (p *planner) prepare(ctx context.Context, stmt parser.Statement) (planNode,
 		return p.ShowCreateTable(ctx, n)
 	case *parser.ShowCreateView:
 		return p.ShowCreateView(ctx, n)
+	case *parser.ShowCreateSequence:
+		return p.ShowCreateSequence(ctx, n)
 	case *parser.ShowColumns:
 		return p.ShowColumns(ctx, n)
 	case *parser.ShowDatabases:
@@ -535,8 +573,12 @@ func (p *planner) prepare(ctx context.Context, stmt parser.Statement) (planNode,
 		return p.ShowSessions(ctx, n)
 	case *parser.ShowTables:
 		return p.ShowTables(ctx, n)
+	case *parser.ShowTableStats:
+		return p.ShowTableStats(ctx, n)
 	case *parser.ShowTrace:
 		return p.ShowTrace(ctx, n)
+	case *parser.ShowRoles:
+		return p.ShowRoles(ctx, n)
 	case *parser.ShowUsers:
 		return p.ShowUsers(ctx, n)
 	case *parser.ShowTransactionStatus:
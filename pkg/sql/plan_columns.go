@@ -71,6 +71,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.columns
 	case *valuesNode:
 		return n.columns
+	case *recursiveCTENode:
+		return n.columns
 	case *explainPlanNode:
 		return n.results.columns
 	case *windowNode:
@@ -95,6 +97,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.getColumns(mut, showFingerprintsColumns)
 	case *splitNode:
 		return n.getColumns(mut, splitNodeColumns)
+	case *sequenceSelectNode:
+		return n.getColumns(mut, sequenceSelectColumns)
 
 		// Nodes using the RETURNING helper.
 	case *deleteNode:
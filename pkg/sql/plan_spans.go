@@ -41,6 +41,8 @@ func collectSpans(params runParams, plan planNode) (reads, writes roachpb.Spans,
 
 	case *scanNode:
 		return n.spans, nil, nil
+	case *sequenceSelectNode:
+		return sequenceSelectNodeSpans(n), nil, nil
 
 	case *updateNode:
 		return editNodeSpans(params, &n.run.editNodeRun)
@@ -87,6 +89,8 @@ func collectSpans(params runParams, plan planNode) (reads, writes roachpb.Spans,
 		return concatSpans(params, n.left.plan, n.right.plan)
 	case *unionNode:
 		return concatSpans(params, n.left, n.right)
+	case *recursiveCTENode:
+		return concatSpans(params, n.initial, n.recursive)
 	}
 
 	panic(fmt.Sprintf("don't know how to collect spans for node %T", plan))
@@ -125,7 +129,9 @@ func tableWriterSpans(params runParams, tw tableWriter) (reads, writes roachpb.S
 	// conservative and assume anything in the table might change.
 	tableSpans := tw.tableDesc().AllIndexSpans()
 	fkReads := tw.fkSpanCollector().CollectSpans()
-	return fkReads, tableSpans
+	// Cascading foreign key actions read and write the referencing tables.
+	cascadeSpans := tw.fkSpanCollector().CollectCascadeSpans()
+	return append(fkReads, cascadeSpans...), append(tableSpans, cascadeSpans...)
 }
 
 // insertNodeWithValuesSpans is a special case of editNodeSpans. It tightens the
@@ -175,7 +181,9 @@ func insertNodeWithValuesSpans(
 		}
 		addWriteKey(primaryKey)
 		for _, secondaryKey := range secondaryKeys {
-			addWriteKey(secondaryKey.Key)
+			if secondaryKey.Key != nil {
+				addWriteKey(secondaryKey.Key)
+			}
 		}
 
 		// Determine the table spans that foreign key constraints will require
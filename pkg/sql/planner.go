@@ -69,6 +69,10 @@ type planner struct {
 	// initializing plans to read from a table. This should be used with care.
 	skipSelectPrivilegeChecks bool
 
+	// If set, the planner allows writes to materialized views. This is
+	// used by CREATE and REFRESH MATERIALIZED VIEW to populate them.
+	populatingMaterializedView bool
+
 	// autoCommit indicates whether we're planning for a spontaneous transaction.
 	// If autoCommit is true, the plan is allowed (but not required) to
 	// commit the transaction along with other KV operations.
@@ -103,6 +107,15 @@ type planner struct {
 	// plannedExecute is true if this planner has planned an EXECUTE statement.
 	plannedExecute bool
 
+	// pinnedHints, if non-nil, contains the planner hints pinned to the
+	// statement being planned in system.statement_hints. They override the
+	// hints specified in the statement.
+	pinnedHints *parser.PinnedHints
+
+	// cteEnv contains the names of the common table expressions (WITH
+	// clauses) in scope during logical planning.
+	cteEnv cteNameEnvironment
+
 	// Avoid allocations by embedding commonly used objects and visitors.
 	parser                parser.Parser
 	txCtx                 parser.ExprTransformContext
@@ -315,8 +328,17 @@ func (p *planner) exec(ctx context.Context, sql string, args ...interface{}) (in
 	return countRowsAffected(params, plan)
 }
 
+// fillFKTableMap fills the descriptors of the tables in m. The tables whose
+// rows may be written by cascading foreign key actions are added to m along
+// with the tables needed to write them.
 func (p *planner) fillFKTableMap(ctx context.Context, m sqlbase.TableLookupsByID) error {
+	queue := make([]sqlbase.ID, 0, len(m))
 	for tableID := range m {
+		queue = append(queue, tableID)
+	}
+	for len(queue) > 0 {
+		tableID := queue[0]
+		queue = queue[1:]
 		table, err := p.session.tables.getTableVersionByID(ctx, p.txn, tableID)
 		if err == errTableAdding {
 			m[tableID] = sqlbase.TableLookup{IsAdding: true}
@@ -326,6 +348,12 @@ func (p *planner) fillFKTableMap(ctx context.Context, m sqlbase.TableLookupsByID
 			return err
 		}
 		m[tableID] = sqlbase.TableLookup{Table: table}
+		for id := range sqlbase.TablesNeededForCascades(*table) {
+			if _, ok := m[id]; !ok {
+				m[id] = sqlbase.TableLookup{}
+				queue = append(queue, id)
+			}
+		}
 	}
 	return nil
 }
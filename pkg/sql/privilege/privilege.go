@@ -57,6 +57,15 @@ var ByValue = [...]Kind{
 	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE,
 }
 
+// ByName is a map of privilege names to privilege kinds.
+var ByName = func() map[string]Kind {
+	m := make(map[string]Kind, len(ByValue))
+	for _, p := range ByValue {
+		m[p.String()] = p
+	}
+	return m
+}()
+
 // List is a list of privileges.
 type List []Kind
 
@@ -138,6 +147,20 @@ func ListFromBitField(m uint32) List {
 	return ret
 }
 
+// ListFromNames takes a list of privilege names (case insensitive) and
+// returns a list of privileges, in the same order.
+func ListFromNames(names []string) (List, error) {
+	ret := make(List, len(names))
+	for i, name := range names {
+		p, ok := ByName[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("unrecognized privilege type %q", name)
+		}
+		ret[i] = p
+	}
+	return ret, nil
+}
+
 // Lists is a list of privilege lists
 type Lists []List
 
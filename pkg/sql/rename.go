@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -47,6 +48,15 @@ func (p *planner) RenameDatabase(ctx context.Context, n *parser.RenameDatabase)
 		return nil, err
 	}
 
+	if isTempDatabaseName(string(n.Name)) {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot rename temporary database %s", n.Name)
+	}
+	if isTempDatabaseName(string(n.NewName)) {
+		return nil, pgerror.NewErrorf(pgerror.CodeReservedNameError,
+			"database names starting with %q are reserved for temporary tables", tempDatabasePrefix)
+	}
+
 	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), string(n.Name))
 	if err != nil {
 		return nil, err
@@ -111,58 +121,59 @@ func (p *planner) RenameDatabase(ctx context.Context, n *parser.RenameDatabase)
 //          mysql requires ALTER, DROP on the original table, and CREATE, INSERT
 //          on the new table (and does not copy privileges over).
 func (p *planner) RenameTable(ctx context.Context, n *parser.RenameTable) (planNode, error) {
-	oldTn, err := n.Name.NormalizeWithDatabaseName(p.session.Database)
+	oldTn, err := p.normalizeTableName(ctx, &n.Name)
 	if err != nil {
 		return nil, err
 	}
-	newTn, err := n.NewName.NormalizeWithDatabaseName(p.session.Database)
+	newTn, err := n.NewName.Normalize()
 	if err != nil {
 		return nil, err
 	}
+	if isTempDatabaseName(oldTn.Database()) {
+		// Renamed temporary tables stay in the temporary database.
+		if err := newTn.QualifyWithDatabase(oldTn.Database()); err != nil {
+			return nil, err
+		}
+	} else if err := newTn.QualifyWithDatabase(p.session.Database); err != nil {
+		return nil, err
+	}
+	if isTempDatabaseName(oldTn.Database()) != isTempDatabaseName(newTn.Database()) {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot move %s between temporary and permanent databases", oldTn)
+	}
 
 	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), oldTn.Database())
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if source table or view exists.
+	// Check if source table, view or sequence exists.
 	// Note that Postgres's behavior here is a little lenient - it'll let you
 	// modify views by running ALTER TABLE, but won't let you modify tables
 	// by running ALTER VIEW. Our behavior is strict for now, but can be
 	// made more lenient down the road if needed.
 	var tableDesc *sqlbase.TableDescriptor
-	if n.IsView {
+	switch {
+	case n.IsView:
 		tableDesc, err = getViewDesc(ctx, p.txn, p.getVirtualTabler(), oldTn)
-		if err != nil {
-			return nil, err
-		}
-		if tableDesc == nil {
-			if n.IfExists {
-				// Noop.
-				return &zeroNode{}, nil
-			}
-			// Key does not exist, but we want it to: error out.
-			return nil, sqlbase.NewUndefinedRelationError(oldTn)
-		}
-		if tableDesc.State != sqlbase.TableDescriptor_PUBLIC {
-			return nil, sqlbase.NewUndefinedRelationError(oldTn)
-		}
-	} else {
+	case n.IsSequence:
+		tableDesc, err = getSequenceDesc(ctx, p.txn, p.getVirtualTabler(), oldTn)
+	default:
 		tableDesc, err = getTableDesc(ctx, p.txn, p.getVirtualTabler(), oldTn)
-		if err != nil {
-			return nil, err
-		}
-		if tableDesc == nil {
-			if n.IfExists {
-				// Noop.
-				return &zeroNode{}, nil
-			}
-			// Key does not exist, but we want it to: error out.
-			return nil, sqlbase.NewUndefinedRelationError(oldTn)
-		}
-		if tableDesc.State != sqlbase.TableDescriptor_PUBLIC {
-			return nil, sqlbase.NewUndefinedRelationError(oldTn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		if n.IfExists {
+			// Noop.
+			return &zeroNode{}, nil
 		}
+		// Key does not exist, but we want it to: error out.
+		return nil, sqlbase.NewUndefinedRelationError(oldTn)
+	}
+	if tableDesc.State != sqlbase.TableDescriptor_PUBLIC {
+		return nil, sqlbase.NewUndefinedRelationError(oldTn)
 	}
 
 	if err := p.CheckPrivilege(tableDesc, privilege.DROP); err != nil {
@@ -174,8 +185,8 @@ func (p *planner) RenameTable(ctx context.Context, n *parser.RenameTable) (planN
 	// of everything they depend on. Rather than trying to rewrite the view's
 	// query with the new name, we simply disallow such renames for now.
 	if len(tableDesc.DependedOnBy) > 0 {
-		return nil, p.dependentViewRenameError(
-			ctx, tableDesc.TypeName(), oldTn.String(), tableDesc.ParentID, tableDesc.DependedOnBy[0].ID)
+		return nil, p.dependentViewError(
+			ctx, "rename", tableDesc.TypeName(), oldTn.String(), tableDesc.ParentID, tableDesc.DependedOnBy[0].ID)
 	}
 
 	// Check if target database exists.
@@ -280,8 +291,8 @@ func (p *planner) RenameIndex(ctx context.Context, n *parser.RenameIndex) (planN
 		if tableRef.IndexID != idx.ID {
 			continue
 		}
-		return nil, p.dependentViewRenameError(
-			ctx, "index", n.Index.Index.String(), tableDesc.ParentID, tableRef.ID)
+		return nil, p.dependentViewError(
+			ctx, "rename", "index", n.Index.Index.String(), tableDesc.ParentID, tableRef.ID)
 	}
 
 	if n.NewName == "" {
@@ -319,7 +330,7 @@ func (p *planner) RenameIndex(ctx context.Context, n *parser.RenameIndex) (planN
 //          mysql requires ALTER, CREATE, INSERT on the table.
 func (p *planner) RenameColumn(ctx context.Context, n *parser.RenameColumn) (planNode, error) {
 	// Check if table exists.
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -358,8 +369,8 @@ func (p *planner) RenameColumn(ctx context.Context, n *parser.RenameColumn) (pla
 			}
 		}
 		if found {
-			return nil, p.dependentViewRenameError(
-				ctx, "column", n.Name.String(), tableDesc.ParentID, tableRef.ID)
+			return nil, p.dependentViewError(
+				ctx, "rename", "column", n.Name.String(), tableDesc.ParentID, tableRef.ID)
 		}
 	}
 
@@ -406,6 +417,48 @@ func (p *planner) RenameColumn(ctx context.Context, n *parser.RenameColumn) (pla
 			tableDesc.Checks[i].Expr = after
 		}
 	}
+	// Rename the column in the indexed expressions and in the predicates of
+	// partial indexes.
+	renameInExpression := func(exprString string) (string, error) {
+		expr, err := parser.ParseExpr(exprString)
+		if err != nil {
+			return "", err
+		}
+		if expr, err = parser.SimpleVisit(expr, preFn); err != nil {
+			return "", err
+		}
+		return parser.Serialize(expr), nil
+	}
+	renameInExpressions := func(idx *sqlbase.IndexDescriptor) error {
+		for i, exprString := range idx.ColumnExpressions {
+			if exprString == "" {
+				continue
+			}
+			var err error
+			if idx.ColumnExpressions[i], err = renameInExpression(exprString); err != nil {
+				return err
+			}
+		}
+		if idx.IsPartial() {
+			var err error
+			if idx.Predicate, err = renameInExpression(idx.Predicate); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i := range tableDesc.Indexes {
+		if err := renameInExpressions(&tableDesc.Indexes[i]); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range tableDesc.Mutations {
+		if idx := m.GetIndex(); idx != nil {
+			if err := renameInExpressions(idx); err != nil {
+				return nil, err
+			}
+		}
+	}
 	// Rename the column in the indexes.
 	tableDesc.RenameColumnDescriptor(col, string(n.NewName))
 
@@ -424,10 +477,13 @@ func (p *planner) RenameColumn(ctx context.Context, n *parser.RenameColumn) (pla
 	return &zeroNode{}, nil
 }
 
+// dependentViewError returns the error for an operation, e.g. "rename",
+// which can't be applied to an object because a view depends on it.
+//
 // TODO(a-robinson): Support renaming objects depended on by views once we have
 // a better encoding for view queries (#10083).
-func (p *planner) dependentViewRenameError(
-	ctx context.Context, typeName, objName string, parentID, viewID sqlbase.ID,
+func (p *planner) dependentViewError(
+	ctx context.Context, op, typeName, objName string, parentID, viewID sqlbase.ID,
 ) error {
 	viewDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, viewID)
 	if err != nil {
@@ -439,13 +495,13 @@ func (p *planner) dependentViewRenameError(
 		viewName, err = p.getQualifiedTableName(ctx, viewDesc)
 		if err != nil {
 			log.Warningf(ctx, "unable to retrieve name of view %d: %v", viewID, err)
-			msg := fmt.Sprintf("cannot rename %s %q because a view depends on it",
-				typeName, objName)
+			msg := fmt.Sprintf("cannot %s %s %q because a view depends on it",
+				op, typeName, objName)
 			return sqlbase.NewDependentObjectError(msg)
 		}
 	}
-	msg := fmt.Sprintf("cannot rename %s %q because view %q depends on it",
-		typeName, objName, viewName)
+	msg := fmt.Sprintf("cannot %s %s %q because view %q depends on it",
+		op, typeName, objName, viewName)
 	hint := fmt.Sprintf("you can drop %s instead.", viewName)
 	return sqlbase.NewDependentObjectErrorWithHint(msg, hint)
 }
@@ -139,13 +139,33 @@ func (r *renderNode) IndexedVarFormat(buf *bytes.Buffer, f parser.FmtFlags, idx
 func (p *planner) Select(
 	ctx context.Context, n *parser.Select, desiredTypes []types.T,
 ) (planNode, error) {
+	resetter, err := p.initWith(ctx, n.With)
+	if err != nil {
+		return nil, err
+	}
+	if resetter != nil {
+		defer resetter(p)
+	}
+
 	wrapped := n.Select
 	limit := n.Limit
 	orderBy := n.OrderBy
 	lockForUpdate := n.LockForUpdate
+	with := n.With
 
 	for s, ok := wrapped.(*parser.ParenSelect); ok; s, ok = wrapped.(*parser.ParenSelect) {
 		wrapped = s.Select.Select
+		if s.Select.With != nil {
+			if with != nil {
+				return nil, fmt.Errorf("multiple WITH clauses not allowed")
+			}
+			with = s.Select.With
+			resetter, err := p.initWith(ctx, with)
+			if err != nil {
+				return nil, err
+			}
+			defer resetter(p)
+		}
 		if s.Select.OrderBy != nil {
 			if orderBy != nil {
 				return nil, fmt.Errorf("multiple ORDER BY clauses not allowed")
@@ -473,7 +493,7 @@ func (r *renderNode) rewriteSRFs(
 
 	if !isUnarySource(r.source) {
 		// The FROM clause specifies something. Replace with a cross-join.
-		src, err = r.planner.makeJoin(ctx, "CROSS JOIN", r.source, src, nil)
+		src, err = r.planner.makeJoin(ctx, "CROSS JOIN", "" /* hint */, r.source, src, nil)
 		if err != nil {
 			return target, err
 		}
@@ -0,0 +1,320 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// roleMembershipCache caches the roles which users and roles belong to,
+// directly or through other roles.
+//
+// Roles are principals which cannot log in, and whose privileges are
+// inherited by their members. The memberships are stored in
+// system.role_members, which is part of the system config: the cache is
+// cleared whenever the system config changes, like the database cache.
+// Only memberships read at or above the timestamp of the latest system
+// config are cached: older reads, e.g. by AS OF SYSTEM TIME queries or long
+// running transactions, may not see the latest changes.
+type roleMembershipCache struct {
+	syncutil.Mutex
+	// memberOf maps members to the roles they belong to, and whether they
+	// hold the admin option on them.
+	memberOf map[string]map[string]bool
+	// generation is incremented whenever the cache is cleared, so that the
+	// memberships read before are not added back.
+	generation int64
+	// timestamp is the timestamp of the latest system config, below which
+	// the memberships read are not cached.
+	timestamp hlc.Timestamp
+}
+
+func newRoleMembershipCache() *roleMembershipCache {
+	return &roleMembershipCache{memberOf: make(map[string]map[string]bool)}
+}
+
+// get returns the cached roles of the member and the current generation of
+// the cache. The cache can be nil, e.g. for internal planners.
+func (c *roleMembershipCache) get(member string) (map[string]bool, int64, bool) {
+	if c == nil {
+		return nil, 0, false
+	}
+	c.Lock()
+	defer c.Unlock()
+	roles, ok := c.memberOf[member]
+	return roles, c.generation, ok
+}
+
+// add caches the roles of the member read at the given timestamp, unless
+// the cache was cleared since the given generation or the roles were read
+// below the timestamp of the latest system config.
+func (c *roleMembershipCache) add(
+	member string, roles map[string]bool, generation int64, readTS hlc.Timestamp,
+) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.generation == generation && !readTS.Less(c.timestamp) {
+		c.memberOf[member] = roles
+	}
+}
+
+// clear empties the cache. The memberships read below ts are no longer
+// cached afterwards.
+func (c *roleMembershipCache) clear(ts hlc.Timestamp) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.memberOf = make(map[string]map[string]bool)
+	c.timestamp.Forward(ts)
+	c.generation++
+}
+
+// systemConfigTimestamp returns the timestamp of the latest write to the
+// system config.
+func systemConfigTimestamp(cfg config.SystemConfig) hlc.Timestamp {
+	var ts hlc.Timestamp
+	for _, kv := range cfg.Values {
+		ts.Forward(kv.Value.Timestamp)
+	}
+	return ts
+}
+
+// memberOf returns the roles which the member belongs to, directly or
+// through other roles, and whether it holds the admin option on them.
+// The memberships read by transactions which have written are not cached,
+// since they may not be committed, and neither are those read below the
+// latest system config.
+func (p *planner) memberOf(ctx context.Context, member string) (map[string]bool, error) {
+	if p.txn == nil {
+		return nil, nil
+	}
+	cache := p.session.roleMembers
+	roles, generation, ok := cache.get(member)
+	if ok {
+		return roles, nil
+	}
+	roles, err := resolveMemberOf(ctx, InternalExecutor{LeaseManager: p.LeaseMgr()}, p.txn, member)
+	if err != nil {
+		return nil, err
+	}
+	if !p.txn.Proto().Writing {
+		cache.add(member, roles, generation, p.txn.OrigTimestamp())
+	}
+	return roles, nil
+}
+
+// resolveMemberOf reads the roles which the member belongs to from
+// system.role_members, following the memberships of the roles.
+//
+// A member holds the admin option on a role if it was granted the role
+// with the admin option, or if it belongs to a role which was.
+func resolveMemberOf(
+	ctx context.Context, ie InternalExecutor, txn *client.Txn, member string,
+) (map[string]bool, error) {
+	roles := make(map[string]bool)
+	toVisit := []string{member}
+	for len(toVisit) > 0 {
+		m := toVisit[0]
+		toVisit = toVisit[1:]
+		rows, err := ie.QueryRowsInTransaction(
+			ctx, "expand-roles", txn,
+			`SELECT role, "isAdmin" FROM system.role_members WHERE member = $1`, m,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			role := string(parser.MustBeDString(row[0]))
+			isAdmin := *row[1].(*parser.DBool)
+			if _, ok := roles[role]; !ok {
+				toVisit = append(toVisit, role)
+			}
+			roles[role] = roles[role] || bool(isAdmin)
+		}
+	}
+	return roles, nil
+}
+
+// anyRole returns whether fn returns true for any of the roles of the
+// session user.
+func (p *planner) anyRole(fn func(role string) bool) (bool, error) {
+	roles, err := p.memberOf(p.session.Ctx(), p.session.User)
+	if err != nil {
+		return false, err
+	}
+	for role := range roles {
+		if fn(role) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkRoleAdmin verifies that the session user is allowed to change the
+// members of the roles: it must be a superuser or hold the admin option on
+// the roles.
+func (p *planner) checkRoleAdmin(ctx context.Context, roles []string) error {
+	if p.session.User == security.RootUser || p.session.User == security.NodeUser {
+		return nil
+	}
+	memberOf, err := p.memberOf(ctx, p.session.User)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if !memberOf[role] {
+			return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+				"user %s must have admin option on role %s", p.session.User, role)
+		}
+	}
+	return nil
+}
+
+// normalizeRoleMembers normalizes the names of the roles and members of a
+// GRANT or REVOKE statement, and checks that they exist.
+func (p *planner) normalizeRoleMembers(
+	ctx context.Context, roleNames, memberNames parser.NameList,
+) (roles []string, members []string, _ error) {
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	// lookup returns whether the principal exists and whether it is a role.
+	lookup := func(name string) (exists bool, isRole bool, _ error) {
+		if name == security.RootUser {
+			return true, false, nil
+		}
+		row, err := ie.QueryRowInTransaction(
+			ctx, "check-role", p.txn,
+			`SELECT "isRole" FROM system.users WHERE username = $1`, name,
+		)
+		if err != nil || row == nil {
+			return false, false, err
+		}
+		return true, bool(*row[0].(*parser.DBool)), nil
+	}
+
+	for _, name := range roleNames {
+		role, err := NormalizeAndValidateUsername(string(name))
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, isRole, err := lookup(role); err != nil {
+			return nil, nil, err
+		} else if !isRole {
+			return nil, nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+				"role %s does not exist", role)
+		}
+		roles = append(roles, role)
+	}
+	for _, name := range memberNames {
+		member, err := NormalizeAndValidateUsername(string(name))
+		if err != nil {
+			return nil, nil, err
+		}
+		if exists, _, err := lookup(member); err != nil {
+			return nil, nil, err
+		} else if !exists {
+			return nil, nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+				"user or role %s does not exist", member)
+		}
+		members = append(members, member)
+	}
+	return roles, members, nil
+}
+
+// GrantRole adds members to roles.
+// Privileges: admin option on the roles, or superuser.
+func (p *planner) GrantRole(ctx context.Context, n *parser.GrantRole) (planNode, error) {
+	roles, members, err := p.normalizeRoleMembers(ctx, n.Roles, n.Members)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkRoleAdmin(ctx, roles); err != nil {
+		return nil, err
+	}
+
+	// Granting a role without the admin option leaves the admin option of
+	// an existing membership in place.
+	stmt := `INSERT INTO system.role_members VALUES ($1, $2, false) ON CONFLICT (role, member) DO NOTHING`
+	if n.AdminOption {
+		stmt = `UPSERT INTO system.role_members VALUES ($1, $2, true)`
+	}
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	for _, role := range roles {
+		for _, member := range members {
+			if member == role {
+				return nil, pgerror.NewErrorf(pgerror.CodeInvalidGrantOperationError,
+					"%s cannot be a member of itself", role)
+			}
+			// The roles of the role are read from the transaction, which
+			// includes the memberships granted by the previous iterations.
+			memberOf, err := resolveMemberOf(ctx, ie, p.txn, role)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := memberOf[member]; ok {
+				return nil, pgerror.NewErrorf(pgerror.CodeInvalidGrantOperationError,
+					"making %s a member of %s would create a cycle", member, role)
+			}
+			if _, err := ie.ExecuteStatementInTransaction(
+				ctx, "grant-role", p.txn, stmt, role, member,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	p.session.roleMembers.clear(p.txn.OrigTimestamp())
+	return &zeroNode{}, nil
+}
+
+// RevokeRole removes members from roles, or only their admin option.
+// Privileges: admin option on the roles, or superuser.
+func (p *planner) RevokeRole(ctx context.Context, n *parser.RevokeRole) (planNode, error) {
+	roles, members, err := p.normalizeRoleMembers(ctx, n.Roles, n.Members)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkRoleAdmin(ctx, roles); err != nil {
+		return nil, err
+	}
+
+	stmt := `DELETE FROM system.role_members WHERE role = $1 AND member = $2`
+	if n.AdminOption {
+		stmt = `UPDATE system.role_members SET "isAdmin" = false WHERE role = $1 AND member = $2`
+	}
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	for _, role := range roles {
+		for _, member := range members {
+			if _, err := ie.ExecuteStatementInTransaction(
+				ctx, "revoke-role", p.txn, stmt, role, member,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	p.session.roleMembers.clear(p.txn.OrigTimestamp())
+	return &zeroNode{}, nil
+}
@@ -16,6 +16,7 @@ package sql
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
@@ -23,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 )
@@ -200,3 +202,29 @@ func (p *planner) CancelQuery(ctx context.Context, n *parser.CancelQuery) (planN
 		queryID: typedQueryID,
 	}, nil
 }
+
+// statementTimer cancels a query once the statement timeout of its session
+// elapses.
+type statementTimer struct {
+	timer *time.Timer
+}
+
+// startStatementTimer arms a statementTimer for the query, if the statement
+// timeout is set.
+func startStatementTimer(timeout time.Duration, q *queryMeta) statementTimer {
+	if timeout == 0 {
+		return statementTimer{}
+	}
+	return statementTimer{timer: time.AfterFunc(timeout, q.cancel)}
+}
+
+// stop disarms the timer. If the timer fired, it replaces the error of the
+// query with a statement timeout error: even if the query completed, the
+// timer cancelled its transaction before it could be disarmed.
+func (t statementTimer) stop(err error) error {
+	if t.timer == nil || t.timer.Stop() {
+		return err
+	}
+	return pgerror.NewError(pgerror.CodeQueryCanceledError,
+		"query execution canceled due to statement timeout")
+}
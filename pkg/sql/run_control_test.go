@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -223,3 +224,106 @@ func TestCancelDistSQLQuery(t *testing.T) {
 		t.Fatal("didn't get an error from query that should have been cancelled")
 	}
 }
+
+func TestCancelQueryWithCancelRequest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// The query would run practically forever if it were not cancelled.
+	const queryToCancel = "SELECT * FROM generate_series(1,1000000000000)"
+	const countQueries = "SELECT count(*) FROM [SHOW CLUSTER QUERIES] WHERE node_id = 2"
+
+	tc := serverutils.StartTestCluster(t, 2, /* numNodes */
+		base.TestClusterArgs{
+			ReplicationMode: base.ReplicationManual,
+		})
+	defer tc.Stopper().Stop(context.TODO())
+
+	conn1 := tc.ServerConn(0)
+	conn2 := tc.ServerConn(1)
+
+	// lib/pq sends a CancelRequest, with the key of the BackendKeyData message
+	// it received, when the context of a query is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() {
+		rows, err := conn2.QueryContext(ctx, queryToCancel)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		for rows.Next() {
+		}
+		errChan <- rows.Err()
+	}()
+
+	waitForQueries := func(expected int) {
+		testutils.SucceedsSoon(t, func() error {
+			var count int
+			if err := conn1.QueryRow(countQueries).Scan(&count); err != nil {
+				return err
+			}
+			if count != expected {
+				return errors.New("unexpected number of queries on node 2")
+			}
+			return nil
+		})
+	}
+	waitForQueries(1)
+	cancel()
+	if err := <-errChan; err == nil {
+		t.Fatal("no error received from query supposed to be cancelled")
+	}
+	// The query only stops running if the server processed the CancelRequest.
+	waitForQueries(0)
+}
+
+func TestStatementTimeout(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	conn, err := db.Conn(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(context.TODO(), `SET statement_timeout = '500ms'`); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := conn.QueryContext(context.TODO(), "SELECT * FROM generate_series(1,20000000)")
+	if err == nil {
+		for rows.Next() {
+		}
+		err = rows.Err()
+		rows.Close()
+	}
+	if !testutils.IsError(err, "query execution canceled due to statement timeout") {
+		t.Fatalf("expected statement timeout error, got %v", err)
+	}
+
+	// The session can run statements which complete in time.
+	if _, err := conn.ExecContext(context.TODO(), `SELECT 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Statements parallelized with RETURNING NOTHING are subject to the
+	// timeout too. Their error is reported by the next statement which
+	// waits for them.
+	for _, stmt := range []string{
+		`CREATE DATABASE test`,
+		`CREATE TABLE test.t (x INT)`,
+		`BEGIN`,
+		`INSERT INTO test.t SELECT * FROM generate_series(1,20000000) RETURNING NOTHING`,
+	} {
+		if _, err := conn.ExecContext(context.TODO(), stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = conn.ExecContext(context.TODO(), `COMMIT`)
+	if !testutils.IsError(err, "query execution canceled due to statement timeout") {
+		t.Fatalf("expected statement timeout error, got %v", err)
+	}
+}
@@ -101,6 +101,13 @@ type scanNode struct {
 	// "hint". If hardLimit is set (non-zero), softLimit must be unset (zero).
 	softLimit int64
 
+	// estimatedRowCount and estimatedCost are the number of rows and the
+	// cost of the scan as estimated from the table statistics during index
+	// selection. They are only valid if hasEstimate is set.
+	hasEstimate       bool
+	estimatedRowCount float64
+	estimatedCost     float64
+
 	disableBatchLimits bool
 
 	scanVisibility scanVisibility
@@ -114,6 +121,14 @@ type scanNode struct {
 	noCopy util.NoCopy
 }
 
+// setEstimate records the row count and cost estimated for the given index
+// in the scanNode.
+func (n *scanNode) setEstimate(c *indexInfo) {
+	n.hasEstimate = true
+	n.estimatedRowCount = c.estimatedRows
+	n.estimatedCost = c.cost
+}
+
 func (p *planner) Scan() *scanNode {
 	n := scanNodePool.Get().(*scanNode)
 	n.p = p
@@ -66,6 +66,10 @@ type SchemaChanger struct {
 	distSQLPlanner *DistSQLPlanner
 	jobRegistry    *jobs.Registry
 	job            *jobs.Job
+	// The mutations queued by the completion of the schema change, if any,
+	// and their job.
+	followupMutationID sqlbase.MutationID
+	followupJob        *jobs.Job
 	// Caches updated by DistSQL.
 	rangeDescriptorCache *kv.RangeDescriptorCache
 	leaseHolderCache     *kv.LeaseHolderCache
@@ -448,6 +452,23 @@ func (sc *SchemaChanger) exec(
 	// Run through mutation state machine and backfill.
 	err = sc.runStateMachineAndBackfill(ctx, &lease, evalCtx, false /* isRollback */)
 
+	// Run the mutations queued by the completion of the schema change right
+	// away rather than leaving them to the SchemaChangeManager, unless other
+	// schema changes are queued before them.
+	if err == nil && sc.followupMutationID != sqlbase.InvalidMutationID {
+		sc.mutationID, sc.job = sc.followupMutationID, sc.followupJob
+		sc.followupMutationID, sc.followupJob = sqlbase.InvalidMutationID, nil
+		if notFirst, err := sc.notFirstInLine(ctx); err != nil || notFirst {
+			return err
+		}
+		if err := sc.job.Started(ctx); err != nil {
+			if log.V(2) {
+				log.Infof(ctx, "Failed to mark job %d as started: %v", *sc.job.ID(), err)
+			}
+		}
+		err = sc.runStateMachineAndBackfill(ctx, &lease, evalCtx, false /* isRollback */)
+	}
+
 	// Purge the mutations if the application of the mutations failed due to
 	// a permanent error. All other errors are transient errors that are
 	// resolved by retrying the backfill.
@@ -541,6 +562,11 @@ func (sc *SchemaChanger) RunStateMachineBeforeBackfill(ctx context.Context) erro
 
 				case sqlbase.DescriptorMutation_DELETE_AND_WRITE_ONLY:
 					desc.Mutations[i].State = sqlbase.DescriptorMutation_DELETE_ONLY
+					if col := mutation.GetColumn(); col != nil {
+						// All the nodes use the version in which the column
+						// was replaced, if it was.
+						desc.ClearReplacedColumnConversion(col.ID)
+					}
 					modified = true
 				}
 			}
@@ -582,7 +608,12 @@ func (sc *SchemaChanger) waitToUpdateLeases(ctx context.Context, tableID sqlbase
 // schema.
 // Returns the updated of the descriptor.
 func (sc *SchemaChanger) done(ctx context.Context, isRollback bool) (*sqlbase.Descriptor, error) {
-	return sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
+	var followupMutationID sqlbase.MutationID
+	var followupJob *jobs.Job
+	var followupDesc *sqlbase.TableDescriptor
+	desc, err := sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
+		followupMutationID, followupJob, followupDesc = sqlbase.InvalidMutationID, nil, nil
+		nextMutationID := desc.NextMutationID
 		i := 0
 		for _, mutation := range desc.Mutations {
 			if mutation.MutationID != sc.mutationID {
@@ -608,8 +639,45 @@ func (sc *SchemaChanger) done(ctx context.Context, isRollback bool) (*sqlbase.De
 				break
 			}
 		}
+
+		// Completing a mutation can queue new ones, such as the drop of the
+		// column replaced by an ALTER COLUMN TYPE, which get their own job.
+		var spanList []jobs.ResumeSpanList
+		for _, mutation := range desc.Mutations {
+			if mutation.MutationID == nextMutationID {
+				spanList = append(spanList, jobs.ResumeSpanList{
+					ResumeSpans: []roachpb.Span{desc.PrimaryIndexSpan()},
+				})
+			}
+		}
+		if len(spanList) > 0 {
+			desc.NextMutationID++
+			record := sc.job.Record
+			record.Description = "CLEAN UP " + record.Description
+			record.Details = jobs.SchemaChangeDetails{ResumeSpanList: spanList}
+			followupMutationID = nextMutationID
+			followupJob = sc.jobRegistry.NewJob(record)
+			followupDesc = desc
+		}
 		return nil
 	}, func(txn *client.Txn) error {
+		if followupJob != nil {
+			// The job of the queued mutations is created in the transaction
+			// publishing them, so that it isn't orphaned if the transaction is
+			// retried or fails. Its ID is only known once it has been created,
+			// so the descriptor is written again.
+			if err := followupJob.WithTxn(txn).Created(ctx, jobs.WithoutCancel); err != nil {
+				return err
+			}
+			followupDesc.MutationJobs = append(followupDesc.MutationJobs, sqlbase.TableDescriptor_MutationJob{
+				MutationID: followupMutationID, JobID: *followupJob.ID()})
+			if err := txn.Put(
+				ctx, sqlbase.MakeDescMetadataKey(sc.tableID), sqlbase.WrapDescriptor(followupDesc),
+			); err != nil {
+				return err
+			}
+		}
+
 		if err := sc.job.WithTxn(txn).Succeeded(ctx); err != nil {
 			log.Warningf(ctx, "schema change ignoring error while marking job %d as successful: %+v",
 				*sc.job.ID(), err)
@@ -635,6 +703,10 @@ func (sc *SchemaChanger) done(ctx context.Context, isRollback bool) (*sqlbase.De
 			}{uint32(sc.mutationID)},
 		)
 	})
+	if err == nil {
+		sc.followupMutationID, sc.followupJob = followupMutationID, followupJob
+	}
+	return desc, err
 }
 
 // notFirstInLine returns true whenever the schema change has been queued
@@ -412,9 +412,15 @@ func indexesToCheck(
 	indexNames parser.NameList, tableDesc *sqlbase.TableDescriptor,
 ) (results []sqlbase.IndexDescriptor, err error) {
 	if indexNames == nil {
-		// Populate results with all secondary indexes of the
-		// table.
-		return tableDesc.Indexes, nil
+		// Populate results with all secondary indexes of the table. Partial
+		// indexes are skipped, as they lack the entries of the rows which
+		// don't satisfy their predicate.
+		for _, idx := range tableDesc.Indexes {
+			if !idx.IsPartial() {
+				results = append(results, idx)
+			}
+		}
+		return results, nil
 	}
 
 	// Find the indexes corresponding to the user input index names.
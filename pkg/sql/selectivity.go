@@ -0,0 +1,248 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// The selectivities assumed for constraints on columns without statistics.
+const (
+	defaultEqSelectivity    = 0.01
+	defaultRangeSelectivity = 1.0 / 3
+	defaultNullSelectivity  = 0.01
+)
+
+// tableStats contains the statistics of a table used during planning.
+type tableStats struct {
+	// rowCount is the row count of the most recent statistic.
+	rowCount float64
+	// cols contains the most recent single-column statistic for each column
+	// that has one.
+	cols map[sqlbase.ColumnID]*stats.TableStatistic
+}
+
+// getTableStats returns the statistics of the given table, or nil if the
+// table has no statistics or they are not available.
+func (p *planner) getTableStats(ctx context.Context, desc *sqlbase.TableDescriptor) *tableStats {
+	execCfg := p.ExecCfg()
+	if execCfg == nil || execCfg.TableStatsCache == nil {
+		return nil
+	}
+	// System tables never have statistics; looking them up would recurse
+	// when planning the query on system.table_statistics.
+	if desc.IsVirtualTable() || desc.ID <= keys.MaxReservedDescID {
+		return nil
+	}
+	tableStatistics, err := execCfg.TableStatsCache.GetTableStats(ctx, desc.ID)
+	if err != nil {
+		log.Warningf(ctx, "failed to read statistics for table %d: %v", desc.ID, err)
+		return nil
+	}
+	if len(tableStatistics) == 0 {
+		return nil
+	}
+	ts := &tableStats{
+		rowCount: float64(tableStatistics[0].RowCount),
+		cols:     make(map[sqlbase.ColumnID]*stats.TableStatistic),
+	}
+	// The statistics are ordered most recent first.
+	for _, s := range tableStatistics {
+		if len(s.ColumnIDs) != 1 {
+			continue
+		}
+		if _, ok := ts.cols[s.ColumnIDs[0]]; !ok {
+			ts.cols[s.ColumnIDs[0]] = s
+		}
+	}
+	return ts
+}
+
+// selectivity returns the estimated fraction of the rows of the table which
+// satisfy the index constraints.
+func (v *indexInfo) selectivity(ts *tableStats) float64 {
+	if len(v.constraints) == 0 {
+		return 1
+	}
+	// The disjunctions can overlap, so their sum is an upper bound.
+	var sel float64
+	for _, cset := range v.constraints {
+		colIdx := 0
+		conjSel := 1.0
+		for _, c := range cset {
+			conjSel *= ts.constraintSelectivity(v.index, colIdx, c)
+			colIdx += c.numColumns()
+		}
+		sel += conjSel
+	}
+	return math.Min(sel, 1)
+}
+
+// constraintSelectivity returns the estimated selectivity of a constraint on
+// the index columns starting at colIdx. Constraints on different columns are
+// assumed to be independent.
+func (ts *tableStats) constraintSelectivity(
+	index *sqlbase.IndexDescriptor, colIdx int, c indexConstraint,
+) float64 {
+	for _, e := range [...]*parser.ComparisonExpr{c.start, c.end} {
+		if e != nil && (e.Operator == parser.EQ || e.Operator == parser.In) {
+			return ts.eqExprSelectivity(index, colIdx, e, c.tupleMap)
+		}
+	}
+	if c.tupleMap != nil {
+		return defaultRangeSelectivity
+	}
+
+	colID := index.ColumnIDs[colIdx]
+	var lower, upper *parser.ComparisonExpr
+	for _, e := range [...]*parser.ComparisonExpr{c.start, c.end} {
+		if e == nil {
+			continue
+		}
+		switch e.Operator {
+		case parser.Is:
+			return ts.nullSelectivity(colID)
+		case parser.GE, parser.GT:
+			lower = e
+		case parser.LE, parser.LT:
+			upper = e
+		}
+	}
+	return ts.rangeSelectivity(colID, lower, upper)
+}
+
+// eqExprSelectivity returns the estimated selectivity of an equality or IN
+// constraint, which can be on a tuple of index columns.
+func (ts *tableStats) eqExprSelectivity(
+	index *sqlbase.IndexDescriptor, colIdx int, e *parser.ComparisonExpr, tupleMap []int,
+) float64 {
+	tupleSelectivity := func(t *parser.DTuple) float64 {
+		sel := 1.0
+		for j, tupleIdx := range tupleMap {
+			sel *= ts.eqSelectivity(index.ColumnIDs[colIdx+j], t.D[tupleIdx])
+		}
+		return sel
+	}
+	valSelectivity := func(d parser.Datum) float64 {
+		if tupleMap != nil {
+			return tupleSelectivity(d.(*parser.DTuple))
+		}
+		return ts.eqSelectivity(index.ColumnIDs[colIdx], d)
+	}
+
+	if e.Operator == parser.EQ {
+		return valSelectivity(e.Right.(parser.Datum))
+	}
+	var sel float64
+	for _, d := range e.Right.(*parser.DTuple).D {
+		sel += valSelectivity(d)
+	}
+	return math.Min(sel, 1)
+}
+
+// eqSelectivity returns the estimated fraction of rows in which the column
+// is equal to the given value.
+func (ts *tableStats) eqSelectivity(colID sqlbase.ColumnID, d parser.Datum) float64 {
+	s, ok := ts.cols[colID]
+	if !ok || s.RowCount == 0 {
+		return defaultEqSelectivity
+	}
+	if d == parser.DNull {
+		return 0
+	}
+	rowCount := float64(s.RowCount)
+	distinct := math.Max(float64(s.DistinctCount), 1)
+	if h := s.Histogram; h != nil && len(h.Buckets) > 0 {
+		if key, err := sqlbase.EncodeTableKey(nil, d, encoding.Ascending); err == nil {
+			// Each bucket upper bound accounts for one of the distinct values;
+			// the rest are spread evenly over the bucket ranges.
+			numBuckets := float64(len(h.Buckets))
+			rows, _ := h.ValueCount(key, (distinct-numBuckets)/numBuckets)
+			return math.Min(rows/rowCount, 1)
+		}
+	}
+	return ts.notNullFraction(s) / distinct
+}
+
+// nullSelectivity returns the estimated fraction of rows in which the column
+// is NULL.
+func (ts *tableStats) nullSelectivity(colID sqlbase.ColumnID) float64 {
+	s, ok := ts.cols[colID]
+	if !ok || s.RowCount == 0 {
+		return defaultNullSelectivity
+	}
+	return 1 - ts.notNullFraction(s)
+}
+
+// rangeSelectivity returns the estimated fraction of rows in which the column
+// is not NULL and is within the bounds given by the lower (> or >=) and upper
+// (< or <=) comparisons, either of which can be nil.
+func (ts *tableStats) rangeSelectivity(
+	colID sqlbase.ColumnID, lower, upper *parser.ComparisonExpr,
+) float64 {
+	s, ok := ts.cols[colID]
+	if !ok || s.RowCount == 0 {
+		if lower == nil && upper == nil {
+			return 1 - defaultNullSelectivity
+		}
+		return defaultRangeSelectivity
+	}
+	notNull := ts.notNullFraction(s)
+	if lower == nil && upper == nil {
+		return notNull
+	}
+	h := s.Histogram
+	if h == nil || h.TotalRows() == 0 {
+		return notNull * defaultRangeSelectivity
+	}
+
+	low, high := 0.0, h.TotalRows()
+	if lower != nil {
+		key, err := sqlbase.EncodeTableKey(nil, lower.Right.(parser.Datum), encoding.Ascending)
+		if err != nil {
+			return notNull * defaultRangeSelectivity
+		}
+		low = h.RowsLessThan(key, lower.Operator == parser.GT)
+	}
+	if upper != nil {
+		key, err := sqlbase.EncodeTableKey(nil, upper.Right.(parser.Datum), encoding.Ascending)
+		if err != nil {
+			return notNull * defaultRangeSelectivity
+		}
+		high = h.RowsLessThan(key, upper.Operator == parser.LE)
+	}
+	if high <= low {
+		return 0
+	}
+	return notNull * (high - low) / h.TotalRows()
+}
+
+// notNullFraction returns the fraction of rows in which the column of the
+// statistic is not NULL.
+func (ts *tableStats) notNullFraction(s *stats.TableStatistic) float64 {
+	if s.NullCount >= s.RowCount {
+		return 0
+	}
+	return float64(s.RowCount-s.NullCount) / float64(s.RowCount)
+}
@@ -0,0 +1,760 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// sequenceState stores the session-local state of the sequences, i.e.
+// the values returned by currval() and lastval().
+type sequenceState struct {
+	mu syncutil.Mutex
+	// latestValues stores the last value obtained by nextval() in this
+	// session, by sequence ID.
+	latestValues map[sqlbase.ID]int64
+	// lastSequenceIncremented is the ID of the sequence most recently
+	// incremented in this session. It is only valid if latestValues is
+	// non-empty.
+	lastSequenceIncremented sqlbase.ID
+}
+
+func (ss *sequenceState) recordValue(seqID sqlbase.ID, value int64) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.latestValues == nil {
+		ss.latestValues = make(map[sqlbase.ID]int64)
+	}
+	ss.lastSequenceIncremented = seqID
+	ss.latestValues[seqID] = value
+}
+
+func (ss *sequenceState) getLastValue() (int64, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if len(ss.latestValues) == 0 {
+		return 0, false
+	}
+	return ss.latestValues[ss.lastSequenceIncremented], true
+}
+
+func (ss *sequenceState) getLastValueByID(seqID sqlbase.ID) (int64, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	val, ok := ss.latestValues[seqID]
+	return val, ok
+}
+
+// getSequenceDesc returns the descriptor of the sequence with the given
+// name, or an error if the name does not designate a sequence.
+func (p *planner) getSequenceDesc(
+	ctx context.Context, seqName *parser.TableName,
+) (*sqlbase.TableDescriptor, error) {
+	desc, err := p.getTableDesc(ctx, seqName)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.IsSequence() {
+		return nil, sqlbase.NewWrongObjectTypeError(seqName, "sequence")
+	}
+	return desc, nil
+}
+
+// IncrementSequence implements the parser.SequenceOperators interface.
+// Privileges: UPDATE on sequence.
+//   Notes: postgres requires USAGE or UPDATE on the sequence.
+func (p *planner) IncrementSequence(ctx context.Context, seqName *parser.TableName) (int64, error) {
+	descriptor, err := p.getSequenceDesc(ctx, seqName)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.CheckPrivilege(descriptor, privilege.UPDATE); err != nil {
+		return 0, err
+	}
+
+	seqOpts := descriptor.SequenceOpts
+	seqValueKey := keys.MakeSequenceKey(uint32(descriptor.ID))
+	// The increment is not transactional: the sequence value stays
+	// incremented even if the current transaction aborts, like in
+	// PostgreSQL. This avoids turning the sequence key into a point of
+	// contention between all the transactions using the sequence.
+	val, err := client.IncrementValRetryable(
+		ctx, p.session.execCfg.DB, seqValueKey, seqOpts.Increment)
+	if err != nil {
+		return 0, err
+	}
+
+	if val > seqOpts.MaxValue || val < seqOpts.MinValue {
+		// Bring the value back to the bound it went past, so that repeated
+		// calls don't keep moving it away from the range of the sequence
+		// (e.g. it can be used again once the bound is raised). This fails
+		// harmlessly if the value was changed concurrently.
+		bound, pastBound := seqOpts.MaxValue, val > seqOpts.MaxValue
+		if seqOpts.Increment < 0 {
+			bound, pastBound = seqOpts.MinValue, val < seqOpts.MinValue
+		}
+		if pastBound {
+			if err := p.session.execCfg.DB.CPut(ctx, seqValueKey, bound, val); err != nil {
+				if _, ok := err.(*roachpb.ConditionFailedError); !ok {
+					return 0, err
+				}
+			}
+		}
+		return 0, boundsExceededError(descriptor, val)
+	}
+
+	p.session.sequenceState.recordValue(descriptor.ID, val)
+	return val, nil
+}
+
+func boundsExceededError(descriptor *sqlbase.TableDescriptor, val int64) error {
+	seqOpts := descriptor.SequenceOpts
+	isAscending := seqOpts.Increment > 0
+
+	var word string
+	var value int64
+	if isAscending {
+		word = "maximum"
+		value = seqOpts.MaxValue
+	} else {
+		word = "minimum"
+		value = seqOpts.MinValue
+	}
+	return pgerror.NewErrorf(
+		pgerror.CodeSequenceGeneratorLimitExceededError,
+		`reached %s value of sequence %q (%d)`, word, descriptor.Name, value)
+}
+
+// GetLatestValueInSessionForSequence implements the
+// parser.SequenceOperators interface.
+// Privileges: SELECT on sequence.
+//   Notes: postgres requires USAGE or SELECT on the sequence.
+func (p *planner) GetLatestValueInSessionForSequence(
+	ctx context.Context, seqName *parser.TableName,
+) (int64, error) {
+	descriptor, err := p.getSequenceDesc(ctx, seqName)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.CheckPrivilege(descriptor, privilege.SELECT); err != nil {
+		return 0, err
+	}
+
+	val, ok := p.session.sequenceState.getLastValueByID(descriptor.ID)
+	if !ok {
+		return 0, pgerror.NewErrorf(
+			pgerror.CodeObjectNotInPrerequisiteStateError,
+			`currval of sequence %q is not yet defined in this session`, parser.ErrString(seqName))
+	}
+	return val, nil
+}
+
+// GetLastSequenceValueInSession implements the parser.SequenceOperators
+// interface.
+func (p *planner) GetLastSequenceValueInSession() (int64, bool) {
+	return p.session.sequenceState.getLastValue()
+}
+
+// SetSequenceValue implements the parser.SequenceOperators interface.
+// Privileges: UPDATE on sequence.
+//   Notes: postgres requires UPDATE on the sequence.
+func (p *planner) SetSequenceValue(
+	ctx context.Context, seqName *parser.TableName, newVal int64, isCalled bool,
+) error {
+	descriptor, err := p.getSequenceDesc(ctx, seqName)
+	if err != nil {
+		return err
+	}
+	if err := p.CheckPrivilege(descriptor, privilege.UPDATE); err != nil {
+		return err
+	}
+
+	seqOpts := descriptor.SequenceOpts
+	if newVal > seqOpts.MaxValue || newVal < seqOpts.MinValue {
+		return pgerror.NewErrorf(
+			pgerror.CodeNumericValueOutOfRangeError,
+			`value %d is out of bounds for sequence %q (%d..%d)`,
+			newVal, descriptor.Name, seqOpts.MinValue, seqOpts.MaxValue)
+	}
+
+	// The value stored is the one last returned by nextval(). If the
+	// sequence is marked as not called, store the value preceding newVal so
+	// that the next call to nextval() returns newVal.
+	storedVal := newVal
+	if !isCalled {
+		storedVal = newVal - seqOpts.Increment
+	}
+
+	// Like nextval(), setval() is not transactional.
+	seqValueKey := keys.MakeSequenceKey(uint32(descriptor.ID))
+	if err := p.session.execCfg.DB.Put(ctx, seqValueKey, storedVal); err != nil {
+		return err
+	}
+	if isCalled {
+		p.session.sequenceState.recordValue(descriptor.ID, newVal)
+	}
+	return nil
+}
+
+// assignSequenceOptions moves options from the AST node to the sequence
+// options descriptor, starting with defaults and overriding them with
+// user-provided options if setDefaults is set.
+func assignSequenceOptions(
+	opts *sqlbase.TableDescriptor_SequenceOpts, optsNode parser.SequenceOptions, setDefaults bool,
+) error {
+	// The increment determines the defaults of the other options, so it
+	// must be processed first.
+	for _, option := range optsNode {
+		if option.Name == parser.SeqOptIncrement {
+			opts.Increment = *option.IntVal
+		}
+	}
+	if opts.Increment == 0 {
+		return pgerror.NewError(
+			pgerror.CodeInvalidParameterValueError, "INCREMENT must not be zero")
+	}
+	isAscending := opts.Increment > 0
+
+	// Set the defaults; they depend on the direction of the sequence.
+	if setDefaults {
+		if isAscending {
+			opts.MinValue = 1
+			opts.MaxValue = math.MaxInt64
+			opts.Start = opts.MinValue
+		} else {
+			opts.MinValue = math.MinInt64
+			opts.MaxValue = -1
+			opts.Start = opts.MaxValue
+		}
+	}
+
+	// Fill in all other options.
+	optionsSeen := map[string]bool{}
+	for _, option := range optsNode {
+		// Error on duplicate options.
+		name := option.Name
+		if name == parser.SeqOptNoCycle {
+			name = parser.SeqOptCycle
+		}
+		if optionsSeen[name] {
+			return sqlbase.NewSyntaxError("conflicting or redundant options")
+		}
+		optionsSeen[name] = true
+
+		switch option.Name {
+		case parser.SeqOptCycle:
+			return pgerror.Unimplemented("seq-cycle", "CYCLE option is not supported")
+		case parser.SeqOptNoCycle:
+			// Do nothing; this is the default.
+		case parser.SeqOptCache:
+			if v := *option.IntVal; v < 1 {
+				return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+					"CACHE (%d) must be greater than zero", v)
+			} else if v > 1 {
+				return pgerror.Unimplemented("seq-cache", "CACHE values larger than 1 are not supported")
+			}
+		case parser.SeqOptIncrement:
+			// Do nothing; this has already been set.
+		case parser.SeqOptMinValue:
+			// A value of nil represents the user explicitly saying `NO MINVALUE`.
+			if option.IntVal != nil {
+				opts.MinValue = *option.IntVal
+			} else if isAscending {
+				opts.MinValue = 1
+			} else {
+				opts.MinValue = math.MinInt64
+			}
+		case parser.SeqOptMaxValue:
+			// A value of nil represents the user explicitly saying `NO MAXVALUE`.
+			if option.IntVal != nil {
+				opts.MaxValue = *option.IntVal
+			} else if isAscending {
+				opts.MaxValue = math.MaxInt64
+			} else {
+				opts.MaxValue = -1
+			}
+		case parser.SeqOptStart:
+			opts.Start = *option.IntVal
+		}
+	}
+
+	// If the start value was not specified when creating the sequence, it
+	// follows the (possibly user-provided) bound in the direction of the
+	// sequence.
+	if setDefaults && !optionsSeen[parser.SeqOptStart] {
+		if isAscending {
+			opts.Start = opts.MinValue
+		} else {
+			opts.Start = opts.MaxValue
+		}
+	}
+
+	if opts.MinValue >= opts.MaxValue {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"MINVALUE (%d) must be less than MAXVALUE (%d)", opts.MinValue, opts.MaxValue)
+	}
+	if opts.Start < opts.MinValue {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"START value (%d) cannot be less than MINVALUE (%d)", opts.Start, opts.MinValue)
+	}
+	if opts.Start > opts.MaxValue {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"START value (%d) cannot be greater than MAXVALUE (%d)", opts.Start, opts.MaxValue)
+	}
+	return nil
+}
+
+// sequenceColumnName is the name of the single column of a sequence,
+// which holds its value.
+const sequenceColumnName = "value"
+
+// makeSequenceTableDesc returns the table descriptor for a new sequence.
+//
+// Like views, sequences are created directly in the PUBLIC state: there
+// is no data to backfill.
+func makeSequenceTableDesc(
+	sequenceName string,
+	sequenceOptions parser.SequenceOptions,
+	parentID sqlbase.ID,
+	id sqlbase.ID,
+	creationTime hlc.Timestamp,
+	privileges *sqlbase.PrivilegeDescriptor,
+) (sqlbase.TableDescriptor, error) {
+	desc := initTableDescriptor(id, parentID, sequenceName, creationTime, privileges)
+
+	// Mimic a table with a single column, whose value is stored under
+	// keys.MakeSequenceKey().
+	desc.AddColumn(sqlbase.ColumnDescriptor{
+		Name: sequenceColumnName,
+		Type: sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT},
+	})
+	if err := desc.AddIndex(sqlbase.IndexDescriptor{
+		Name:             sqlbase.PrimaryKeyIndexName,
+		Unique:           true,
+		ColumnNames:      []string{sequenceColumnName},
+		ColumnDirections: []sqlbase.IndexDescriptor_Direction{sqlbase.IndexDescriptor_ASC},
+	}, true /* primary */); err != nil {
+		return desc, err
+	}
+
+	opts := &sqlbase.TableDescriptor_SequenceOpts{Increment: 1}
+	if err := assignSequenceOptions(opts, sequenceOptions, true /* setDefaults */); err != nil {
+		return desc, err
+	}
+	desc.SequenceOpts = opts
+
+	return desc, desc.AllocateIDs()
+}
+
+// createSequenceNode represents a CREATE SEQUENCE statement.
+type createSequenceNode struct {
+	n      *parser.CreateSequence
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+// CreateSequence creates a sequence.
+// Privileges: CREATE on database.
+//   Notes: postgres requires CREATE on database.
+func (p *planner) CreateSequence(ctx context.Context, n *parser.CreateSequence) (planNode, error) {
+	name, err := n.Name.NormalizeWithDatabaseName(p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), name.Database())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &createSequenceNode{
+		n:      n,
+		dbDesc: dbDesc,
+	}, nil
+}
+
+func (n *createSequenceNode) Start(params runParams) error {
+	seqName := n.n.Name.TableName().Table()
+	tKey := tableKey{parentID: n.dbDesc.ID, name: seqName}
+	key := tKey.Key()
+	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
+		if n.n.IfNotExists {
+			// If the sequence exists but the user specified IF NOT EXISTS, return without doing anything.
+			return nil
+		}
+		return sqlbase.NewRelationAlreadyExistsError(tKey.Name())
+	} else if err != nil {
+		return err
+	}
+
+	id, err := GenerateUniqueDescID(params.ctx, params.p.session.execCfg.DB)
+	if err != nil {
+		return err
+	}
+
+	// Inherit permissions from the database descriptor.
+	privs := n.dbDesc.GetPrivileges()
+
+	desc, err := makeSequenceTableDesc(
+		seqName, n.n.Options, n.dbDesc.ID, id, params.p.txn.OrigTimestamp(), privs)
+	if err != nil {
+		return err
+	}
+
+	if err = desc.ValidateTable(); err != nil {
+		return err
+	}
+
+	if err = params.p.createDescriptorWithID(params.ctx, key, id, &desc); err != nil {
+		return err
+	}
+
+	// Initialize the sequence value so that the first call to nextval()
+	// returns the start value. Like nextval(), this is not transactional:
+	// writing the value in the transaction would make nextval() calls made
+	// later in the same transaction conflict with it. The value is left
+	// behind if the transaction aborts, but the ID it is stored under is
+	// never used again.
+	seqValueKey := keys.MakeSequenceKey(uint32(id))
+	if err := params.p.session.execCfg.DB.Put(
+		params.ctx, seqValueKey, desc.SequenceOpts.Start-desc.SequenceOpts.Increment,
+	); err != nil {
+		return err
+	}
+
+	if err := desc.Validate(params.ctx, params.p.txn); err != nil {
+		return err
+	}
+
+	// Log Create Sequence event. This is an auditable log event and is
+	// recorded in the same transaction as the table descriptor update.
+	return MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogCreateSequence,
+		int32(desc.ID),
+		int32(params.p.evalCtx.NodeID),
+		struct {
+			SequenceName string
+			Statement    string
+			User         string
+		}{n.n.Name.String(), n.n.String(), params.p.session.User},
+	)
+}
+
+func (*createSequenceNode) Next(runParams) (bool, error) { return false, nil }
+func (*createSequenceNode) Close(context.Context)        {}
+func (*createSequenceNode) Values() parser.Datums        { return parser.Datums{} }
+
+// alterSequenceNode represents an ALTER SEQUENCE statement.
+type alterSequenceNode struct {
+	n       *parser.AlterSequence
+	seqDesc *sqlbase.TableDescriptor
+}
+
+// AlterSequence changes the options of a sequence.
+// Privileges: CREATE on sequence.
+//   Notes: postgres requires the sequence owner.
+func (p *planner) AlterSequence(ctx context.Context, n *parser.AlterSequence) (planNode, error) {
+	tn, err := p.normalizeTableName(ctx, &n.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	seqDesc, err := getSequenceDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	if err != nil {
+		return nil, err
+	}
+	if seqDesc == nil {
+		if n.IfExists {
+			return &zeroNode{}, nil
+		}
+		return nil, sqlbase.NewUndefinedRelationError(tn)
+	}
+
+	if err := p.CheckPrivilege(seqDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &alterSequenceNode{n: n, seqDesc: seqDesc}, nil
+}
+
+func (n *alterSequenceNode) Start(params runParams) error {
+	desc := n.seqDesc
+
+	// Work on a copy of the options, so that a failed validation leaves the
+	// descriptor untouched.
+	opts := *desc.SequenceOpts
+	if err := assignSequenceOptions(&opts, n.n.Options, false /* setDefaults */); err != nil {
+		return err
+	}
+	desc.SequenceOpts = &opts
+
+	if err := params.p.saveNonmutationAndNotify(params.ctx, desc); err != nil {
+		return err
+	}
+
+	// Log Alter Sequence event. This is an auditable log event and is
+	// recorded in the same transaction as the table descriptor update.
+	return MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogAlterSequence,
+		int32(desc.ID),
+		int32(params.p.evalCtx.NodeID),
+		struct {
+			SequenceName string
+			Statement    string
+			User         string
+		}{n.n.Name.String(), n.n.String(), params.p.session.User},
+	)
+}
+
+func (*alterSequenceNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterSequenceNode) Close(context.Context)        {}
+func (*alterSequenceNode) Values() parser.Datums        { return parser.Datums{} }
+
+// sequenceSelectNode is the plan for SELECT * FROM <sequence>.
+type sequenceSelectNode struct {
+	optColumnsSlot
+
+	desc *sqlbase.TableDescriptor
+
+	val  int64
+	done bool
+}
+
+var sequenceSelectColumns = sqlbase.ResultColumns{
+	{Name: `last_value`, Typ: types.Int},
+	{Name: `log_cnt`, Typ: types.Int},
+	{Name: `is_called`, Typ: types.Bool},
+}
+
+// getSequenceSource returns the data source that reads the current value
+// of a sequence.
+// Privileges: SELECT on sequence.
+func (p *planner) getSequenceSource(
+	tn parser.TableName, desc *sqlbase.TableDescriptor,
+) (planDataSource, error) {
+	if err := p.CheckPrivilege(desc, privilege.SELECT); err != nil {
+		return planDataSource{}, err
+	}
+	node := &sequenceSelectNode{desc: desc}
+	return planDataSource{
+		plan: node,
+		info: newSourceInfoForSingleTable(tn, sequenceSelectColumns),
+	}, nil
+}
+
+func (ss *sequenceSelectNode) Start(params runParams) error {
+	seqValueKey := keys.MakeSequenceKey(uint32(ss.desc.ID))
+	val, err := params.p.txn.Get(params.ctx, seqValueKey)
+	if err != nil {
+		return err
+	}
+	ss.val = val.ValueInt()
+	return nil
+}
+
+func (ss *sequenceSelectNode) Next(params runParams) (bool, error) {
+	if ss.done {
+		return false, nil
+	}
+	ss.done = true
+	return true, nil
+}
+
+func (ss *sequenceSelectNode) Values() parser.Datums {
+	valDatum := parser.NewDInt(parser.DInt(ss.val))
+	// log_cnt is only meaningful for PostgreSQL's sequence caching, and
+	// is_called cannot be determined from the value stored; report the
+	// values of a sequence on which nextval() has been called.
+	return []parser.Datum{
+		valDatum,
+		parser.NewDInt(0),
+		parser.MakeDBool(true),
+	}
+}
+
+func (ss *sequenceSelectNode) Close(ctx context.Context) {}
+
+// sequenceSelectNodeSpans returns the span read by a sequenceSelectNode.
+func sequenceSelectNodeSpans(n *sequenceSelectNode) roachpb.Spans {
+	seqValueKey := roachpb.Key(keys.MakeSequenceKey(uint32(n.desc.ID)))
+	return roachpb.Spans{{Key: seqValueKey, EndKey: seqValueKey.Next()}}
+}
+
+// sequenceNameVisitor collects the names of the sequences used by an
+// expression, that is, the constant arguments of the calls to the sequence
+// builtins.
+type sequenceNameVisitor struct {
+	searchPath parser.SearchPath
+	names      []string
+	err        error
+}
+
+var _ parser.Visitor = &sequenceNameVisitor{}
+
+func (v *sequenceNameVisitor) VisitPre(expr parser.Expr) (recurse bool, newExpr parser.Expr) {
+	if v.err != nil {
+		return false, expr
+	}
+	if f, ok := expr.(*parser.FuncExpr); ok && len(f.Exprs) > 0 {
+		def, err := f.Func.Resolve(v.searchPath)
+		if err != nil {
+			v.err = err
+			return false, expr
+		}
+		switch def.Name {
+		case "nextval", "currval", "setval":
+			if s, ok := f.Exprs[0].(*parser.DString); ok {
+				v.names = append(v.names, string(*s))
+			}
+		}
+	}
+	return true, expr
+}
+
+func (*sequenceNameVisitor) VisitPost(expr parser.Expr) parser.Expr { return expr }
+
+// resolveColumnSequences stores in the column descriptor the IDs of the
+// sequences used by its default expression. The names of the sequences are
+// resolved against the current database.
+func (p *planner) resolveColumnSequences(ctx context.Context, col *sqlbase.ColumnDescriptor) error {
+	col.UsesSequenceIds = nil
+	if col.DefaultExpr == nil {
+		return nil
+	}
+	expr, err := parser.ParseExpr(*col.DefaultExpr)
+	if err != nil {
+		return err
+	}
+	typedExpr, err := parser.TypeCheck(expr, &p.semaCtx, types.Any)
+	if err != nil {
+		return err
+	}
+	v := sequenceNameVisitor{searchPath: p.session.SearchPath}
+	parser.WalkExprConst(&v, typedExpr)
+	if v.err != nil {
+		return v.err
+	}
+	for _, name := range v.names {
+		tn, err := parser.ParseTableName(name)
+		if err != nil {
+			return err
+		}
+		seqName, err := p.QualifyWithDatabase(ctx, tn)
+		if err != nil {
+			return err
+		}
+		seqDesc, err := p.getSequenceDesc(ctx, seqName)
+		if err != nil {
+			return err
+		}
+		if !containsID(col.UsesSequenceIds, seqDesc.ID) {
+			col.UsesSequenceIds = append(col.UsesSequenceIds, seqDesc.ID)
+		}
+	}
+	return nil
+}
+
+// updateSequenceDependents updates the back-references of the sequences
+// which the table started or stopped using since it used the sequences with
+// the IDs in prevSeqIDs.
+func (p *planner) updateSequenceDependents(
+	ctx context.Context, tableDesc *sqlbase.TableDescriptor, prevSeqIDs []sqlbase.ID,
+) error {
+	seqIDs := tableDesc.UsedSequenceIDs()
+	for _, id := range seqIDs {
+		if !containsID(prevSeqIDs, id) {
+			if err := p.addSequenceDependent(ctx, id, tableDesc); err != nil {
+				return err
+			}
+		}
+	}
+	for _, id := range prevSeqIDs {
+		if !containsID(seqIDs, id) {
+			if err := p.removeSequenceDependent(ctx, id, tableDesc.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addSequenceDependent records that the table uses the sequence with ID
+// seqID.
+func (p *planner) addSequenceDependent(
+	ctx context.Context, seqID sqlbase.ID, tableDesc *sqlbase.TableDescriptor,
+) error {
+	seqDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, seqID)
+	if err != nil {
+		return err
+	}
+	if err := p.checkTempSequenceDependency(ctx, tableDesc, seqDesc); err != nil {
+		return err
+	}
+	if containsID(seqDesc.SequenceDependents, tableDesc.ID) {
+		return nil
+	}
+	seqDesc.SequenceDependents = append(seqDesc.SequenceDependents, tableDesc.ID)
+	return p.saveNonmutationAndNotify(ctx, seqDesc)
+}
+
+// removeSequenceDependent removes the back-reference from the sequence with
+// ID seqID to the table with ID tableID.
+func (p *planner) removeSequenceDependent(ctx context.Context, seqID, tableID sqlbase.ID) error {
+	seqDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, seqID)
+	if err != nil {
+		return err
+	}
+	if seqDesc.Dropped() {
+		// The sequence is being dropped. No need to modify it further.
+		return nil
+	}
+	dependents := seqDesc.SequenceDependents[:0]
+	for _, id := range seqDesc.SequenceDependents {
+		if id != tableID {
+			dependents = append(dependents, id)
+		}
+	}
+	seqDesc.SequenceDependents = dependents
+	return p.saveNonmutationAndNotify(ctx, seqDesc)
+}
+
+func containsID(ids []sqlbase.ID, id sqlbase.ID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
@@ -15,6 +15,8 @@
 package sql
 
 import (
+	"crypto/rand"
+	"encoding/binary"
 	"fmt"
 	"net"
 	"strings"
@@ -35,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -79,6 +82,20 @@ var traceSessionEventLogEnabled = settings.RegisterBoolSetting(
 	"set to true to enable session tracing", false,
 )
 
+// statementTimeout is the cluster default for the statement_timeout session
+// variable.
+var statementTimeout = settings.RegisterNonNegativeDurationSetting(
+	"sql.defaults.statement_timeout",
+	"default duration after which statements are cancelled (set to 0 to disable)", 0,
+)
+
+// idleInTxnSessionTimeout is the cluster default for the
+// idle_in_transaction_session_timeout session variable.
+var idleInTxnSessionTimeout = settings.RegisterNonNegativeDurationSetting(
+	"sql.defaults.idle_in_transaction_session_timeout",
+	"default duration after which sessions idle in an open transaction are terminated (set to 0 to disable)", 0,
+)
+
 // DistSQLClusterExecMode controls the cluster default for when DistSQL is used.
 var DistSQLClusterExecMode = settings.RegisterEnumSetting(
 	"sql.defaults.distsql",
@@ -211,6 +228,17 @@ type Session struct {
 	// SafeUpdates causes errors when the client
 	// sends syntax that may have unwanted side effects.
 	SafeUpdates bool
+	// ReorderJoinsLimit is the maximum number of data sources in a tree of
+	// inner joins for which an exhaustive join order search is performed;
+	// larger trees are ordered greedily. Zero disables join reordering.
+	ReorderJoinsLimit int
+	// StatementTimeout is the duration after which statements are cancelled.
+	// Zero disables the timeout.
+	StatementTimeout time.Duration
+	// IdleInTxnSessionTimeout is the duration after which the session is
+	// terminated if it stays idle in an open transaction. Zero disables the
+	// timeout.
+	IdleInTxnSessionTimeout time.Duration
 
 	//
 	// Session parameters, non-user-configurable.
@@ -222,6 +250,23 @@ type Session struct {
 
 	// ClientAddr is the client's IP address and port.
 	ClientAddr string
+	// idleInTxnTimer is armed by StartIdleInTxnTimer while the session waits
+	// for its client in an open transaction.
+	idleInTxnTimer *time.Timer
+	// idleInTxnTimedOut is set atomically to 1 when idleInTxnTimer fires.
+	idleInTxnTimedOut int32
+
+	// cancelKey is the secret key with which pgwire CancelRequests identify
+	// the session. It is assigned by the SessionRegistry and is unique among
+	// the sessions of this node.
+	cancelKey int32
+
+	// tempDatabaseName is the name of the database holding the temporary
+	// tables of the session. See temp_tables.go.
+	tempDatabaseName string
+	// hasTempTables is set once the session has created its temporary
+	// database.
+	hasTempTables bool
 
 	//
 	// State structures for the logical SQL session.
@@ -240,6 +285,14 @@ type Session struct {
 	// TODO(knz): place this in an executionContext parameter-passing
 	// structure.
 	virtualSchemas virtualSchemaHolder
+	// statementHints is a copy of Executor.statementHints, refreshed
+	// before each batch of statements.
+	statementHints *statementHintsCache
+	// roleMembers aliases Executor.roleMembers.
+	roleMembers *roleMembershipCache
+	// sequenceState stores the values most recently obtained by nextval()
+	// in this session, for use by currval() and lastval().
+	sequenceState sequenceState
 
 	// planner is the "default planner" on a session, to save planner allocations
 	// during serial execution. Since planners are not threadsafe, this is only
@@ -385,26 +438,79 @@ type SessionArgs struct {
 type SessionRegistry struct {
 	syncutil.Mutex
 	store map[*Session]struct{}
+	// cancelKeys maps the cancellation keys of the sessions to the sessions.
+	cancelKeys map[int32]*Session
 }
 
 // MakeSessionRegistry creates a new SessionRegistry with an empty set
 // of sessions.
 func MakeSessionRegistry() *SessionRegistry {
-	return &SessionRegistry{store: make(map[*Session]struct{})}
+	return &SessionRegistry{
+		store:      make(map[*Session]struct{}),
+		cancelKeys: make(map[int32]*Session),
+	}
 }
 
 func (r *SessionRegistry) register(s *Session) {
 	r.Lock()
 	r.store[s] = struct{}{}
+	for {
+		// The key is the only credential of a CancelRequest, so it must not be
+		// guessable. Zero is reserved for "no key".
+		var buf [4]byte
+		if _, err := rand.Read(buf[:]); err != nil {
+			panic(err)
+		}
+		key := int32(binary.BigEndian.Uint32(buf[:]))
+		if _, ok := r.cancelKeys[key]; key != 0 && !ok {
+			s.cancelKey = key
+			r.cancelKeys[key] = s
+			break
+		}
+	}
 	r.Unlock()
 }
 
 func (r *SessionRegistry) deregister(s *Session) {
 	r.Lock()
 	delete(r.store, s)
+	delete(r.cancelKeys, s.cancelKey)
 	r.Unlock()
 }
 
+// hasTempDatabase returns true if one of the registered sessions owns the
+// temporary database with the given name.
+func (r *SessionRegistry) hasTempDatabase(name string) bool {
+	r.Lock()
+	defer r.Unlock()
+	for s := range r.store {
+		if s.tempDatabaseName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CancelQueryByKey cancels the active queries of the session with the given
+// cancellation key. It returns false if there is no such session, or if the
+// session had no active queries.
+func (r *SessionRegistry) CancelQueryByKey(cancelKey int32) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	session, ok := r.cancelKeys[cancelKey]
+	if !ok || cancelKey == 0 {
+		return false, fmt.Errorf("session with cancel key %d not found", cancelKey)
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	for _, queryMeta := range session.mu.ActiveQueries {
+		queryMeta.cancel()
+	}
+	return len(session.mu.ActiveQueries) > 0, nil
+}
+
 // CancelQuery looks up the associated query in the session registry and cancels it.
 func (r *SessionRegistry) CancelQuery(queryIDStr string, username string) (bool, error) {
 	queryID, err := uint128.FromString(queryIDStr)
@@ -457,17 +563,22 @@ func NewSession(
 	distSQLMode := DistSQLExecMode(DistSQLClusterExecMode.Get(&e.cfg.Settings.SV))
 
 	s := &Session{
-		Database:         args.Database,
-		DistSQLMode:      distSQLMode,
-		SearchPath:       sqlbase.DefaultSearchPath,
-		Location:         time.UTC,
-		User:             args.User,
-		virtualSchemas:   e.virtualSchemas,
-		execCfg:          &e.cfg,
-		distSQLPlanner:   e.distSQLPlanner,
-		parallelizeQueue: MakeParallelizeQueue(NewSpanBasedDependencyAnalyzer()),
-		memMetrics:       memMetrics,
-		sqlStats:         &e.sqlStats,
+		Database:                args.Database,
+		DistSQLMode:             distSQLMode,
+		SearchPath:              sqlbase.DefaultSearchPath,
+		Location:                time.UTC,
+		User:                    args.User,
+		ReorderJoinsLimit:       defaultReorderJoinsLimit,
+		StatementTimeout:        statementTimeout.Get(&e.cfg.Settings.SV),
+		IdleInTxnSessionTimeout: idleInTxnSessionTimeout.Get(&e.cfg.Settings.SV),
+		virtualSchemas:          e.virtualSchemas,
+		statementHints:          e.getStatementHints(),
+		roleMembers:             e.roleMembers,
+		execCfg:                 &e.cfg,
+		distSQLPlanner:          e.distSQLPlanner,
+		parallelizeQueue:        MakeParallelizeQueue(NewSpanBasedDependencyAnalyzer()),
+		memMetrics:              memMetrics,
+		sqlStats:                &e.sqlStats,
 		defaults: sessionDefaults{
 			applicationName: args.ApplicationName,
 			database:        args.Database,
@@ -490,6 +601,7 @@ func NewSession(
 		remoteStr = remote.String()
 	}
 	s.ClientAddr = remoteStr
+	s.tempDatabaseName = makeTempDatabaseName(e.cfg.NodeID.Get(), e.cfg.Clock.Now())
 
 	if traceSessionEventLogEnabled.Get(&e.cfg.Settings.SV) {
 		s.eventLog = trace.NewEventLog(fmt.Sprintf("sql [%s]", args.User), remoteStr)
@@ -501,6 +613,13 @@ func NewSession(
 	return s
 }
 
+// BackendKeyData returns the identifiers with which a pgwire CancelRequest
+// can cancel the queries of the session: the ID of the node of the session
+// and its secret cancellation key.
+func (s *Session) BackendKeyData() (roachpb.NodeID, int32) {
+	return s.execCfg.NodeID.Get(), s.cancelKey
+}
+
 // Finish releases resources held by the Session. It is called by the Session's
 // main goroutine, so no synchronous queries will be in-flight during the
 // method's execution. However, it could be called when asynchronous queries are
@@ -541,10 +660,19 @@ func (s *Session) Finish(e *Executor) {
 	if s.TxnState.State().kvTxnIsOpen() {
 		_ = s.TxnState.updateStateAndCleanupOnErr(fmt.Errorf("session closing"), e)
 	}
+	s.TxnState.cleanupRetainedTxn(fmt.Errorf("session closing"), e)
 	if s.TxnState.State() != NoTxn {
 		s.TxnState.finishSQLTxn(s)
 	}
 
+	// Drop the session's temporary tables. If this fails, the sweeper will
+	// eventually drop them.
+	if s.hasTempTables {
+		if err := e.dropTempDatabase(s.context, s.tempDatabaseName); err != nil {
+			log.Warningf(s.context, "error dropping temporary tables: %s", err)
+		}
+	}
+
 	// We might have unreleased tables if we're finishing the
 	// session abruptly in the middle of a transaction, or, until #7648 is
 	// addressed, there might be leases accumulated by preparing statements.
@@ -575,6 +703,40 @@ func (s *Session) Finish(e *Executor) {
 	s.cancel()
 }
 
+// StartIdleInTxnTimer is called when the session starts waiting for input
+// from its client. If the session is in an open transaction, it arms a timer
+// which marks the session as timed out once IdleInTxnSessionTimeout elapses;
+// the client connection is then expected to check IdleInTxnTimeoutErr and
+// close the session, which rolls back the transaction.
+func (s *Session) StartIdleInTxnTimer() {
+	if s.IdleInTxnSessionTimeout == 0 || s.TxnState.State() == NoTxn {
+		return
+	}
+	s.idleInTxnTimer = time.AfterFunc(s.IdleInTxnSessionTimeout, func() {
+		atomic.StoreInt32(&s.idleInTxnTimedOut, 1)
+	})
+}
+
+// StopIdleInTxnTimer is called when the session receives input from its
+// client. It stops the timer armed by StartIdleInTxnTimer, if any.
+func (s *Session) StopIdleInTxnTimer() {
+	if s.idleInTxnTimer != nil {
+		s.idleInTxnTimer.Stop()
+		s.idleInTxnTimer = nil
+	}
+}
+
+// IdleInTxnTimeoutErr returns an error if the session stayed idle in an open
+// transaction for longer than IdleInTxnSessionTimeout. It can be called
+// concurrently with the session's main goroutine.
+func (s *Session) IdleInTxnTimeoutErr() error {
+	if atomic.LoadInt32(&s.idleInTxnTimedOut) == 0 {
+		return nil
+	}
+	return pgerror.NewError(pgerror.CodeIdleInTransactionSessionTimeoutError,
+		"terminating connection due to idle-in-transaction timeout")
+}
+
 // EmergencyClose is a simplified replacement for Finish() which is
 // less picky about the current state of the Session. In particular
 // this can be used to tidy up after a session even in the middle of a
@@ -634,6 +796,7 @@ func (s *Session) resetPlanner(p *planner, e *Executor, txn *client.Txn) {
 
 	p.evalCtx = s.evalCtx()
 	p.evalCtx.Planner = p
+	p.evalCtx.Sequence = p
 	if e != nil {
 		p.evalCtx.ClusterID = e.cfg.ClusterID()
 		p.evalCtx.NodeID = e.cfg.NodeID.Get()
@@ -692,6 +855,7 @@ func (s *Session) resetForBatch(e *Executor) {
 	// Update the database cache to a more recent copy, so that we can use tables
 	// that we created in previous batches of the same transaction.
 	s.tables.databaseCache = e.getDatabaseCache()
+	s.statementHints = e.getStatementHints()
 	s.TxnState.schemaChangers.curGroupNum++
 }
 
@@ -973,6 +1137,17 @@ type txnState struct {
 	// errors. The txn will enter a RestartWait state in case of such errors.
 	retryIntent bool
 
+	// savepoints is the stack of the savepoints established by the user,
+	// other than the restart savepoint, in the order in which they were
+	// established.
+	savepoints []sqlSavepoint
+
+	// schemaChangeCount is the number of schema change statements executed in
+	// the txn. The in-memory state of schema changes, such as the uncommitted
+	// descriptors and the queued schema changers, can't be rolled back to a
+	// savepoint, so rolling back across a schema change is rejected.
+	schemaChangeCount int
+
 	// A COMMIT statement has been processed. Useful for allowing the txn to
 	// survive retriable errors if it will be auto-retried (BEGIN; ... COMMIT; in
 	// the same batch), but not if the error needs to be reported to the user.
@@ -1000,6 +1175,15 @@ type txnState struct {
 	mon mon.BytesMonitor
 }
 
+// sqlSavepoint is a savepoint established by a SAVEPOINT statement.
+type sqlSavepoint struct {
+	name string
+	sp   client.Savepoint
+	// schemaChangeCount is the txn's schemaChangeCount when the savepoint was
+	// established.
+	schemaChangeCount int
+}
+
 // State returns the current state of the session.
 func (ts *txnState) State() TxnStateEnum {
 	return TxnStateEnum(atomic.LoadInt64((*int64)(&ts.state)))
@@ -1044,6 +1228,8 @@ func (ts *txnState) resetForNewSQLTxn(
 
 	ts.retryIntent = retryIntent
 	// Reset state vars to defaults.
+	ts.savepoints = nil
+	ts.schemaChangeCount = 0
 	ts.commitSeen = false
 	ts.sqlTimestamp = sqlTimestamp
 	ts.implicitTxn = implicitTxn
@@ -1142,11 +1328,78 @@ func (ts *txnState) resetStateAndTxn(state TxnStateEnum) {
 				"(finalized: false)", state, ts.mu.txn.Proto().Status))
 	}
 	ts.SetState(state)
+	ts.savepoints = nil
 	ts.mu.Lock()
 	ts.mu.txn = nil
 	ts.mu.Unlock()
 }
 
+// findSavepoint returns the index of the most recently established savepoint
+// with the given name, or -1 if there's no such savepoint.
+func (ts *txnState) findSavepoint(name string) int {
+	for i := len(ts.savepoints) - 1; i >= 0; i-- {
+		if ts.savepoints[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func errSavepointDoesNotExist(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeInvalidSavepointSpecificationError,
+		"savepoint %s does not exist", name)
+}
+
+// releaseSavepoint destroys the named savepoint and all the savepoints
+// established after it. The writes performed since are kept.
+func (ts *txnState) releaseSavepoint(name string) error {
+	idx := ts.findSavepoint(name)
+	if idx < 0 {
+		return errSavepointDoesNotExist(name)
+	}
+	ts.savepoints = ts.savepoints[:idx]
+	return nil
+}
+
+// rollbackToSavepoint undoes the writes performed since the named savepoint
+// was established and destroys all the savepoints established after it. Like
+// in Postgres, the savepoint itself remains valid.
+func (ts *txnState) rollbackToSavepoint(name string) error {
+	idx := ts.findSavepoint(name)
+	if idx < 0 {
+		return errSavepointDoesNotExist(name)
+	}
+	if ts.schemaChangeCount != ts.savepoints[idx].schemaChangeCount {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot roll back to savepoint %s across a schema change", name)
+	}
+	if err := ts.mu.txn.RollbackToSavepoint(ts.Ctx, ts.savepoints[idx].sp); err != nil {
+		return err
+	}
+	ts.savepoints = ts.savepoints[:idx+1]
+	return nil
+}
+
+// canRetainTxnOnErr returns true if the KV txn should survive a non-retryable
+// error, because the client can roll back to one of its savepoints and keep
+// using it.
+func (ts *txnState) canRetainTxnOnErr() bool {
+	return len(ts.savepoints) > 0 && !ts.commitSeen &&
+		!ts.mu.txn.IsFinalized() && ts.mu.txn.Proto().Status == roachpb.PENDING
+}
+
+// cleanupRetainedTxn rolls back the KV txn that was kept around in the
+// Aborted state for the benefit of ROLLBACK TO SAVEPOINT, if any. err is the
+// reason for the rollback.
+func (ts *txnState) cleanupRetainedTxn(err error, e *Executor) {
+	if ts.State() != Aborted || ts.mu.txn == nil {
+		return
+	}
+	e.TxnAbortCount.Inc(1)
+	ts.mu.txn.CleanupOnError(ts.Ctx, err)
+	ts.resetStateAndTxn(Aborted)
+}
+
 // finishSQLTxn finalizes a transaction's results and closes the root span for
 // the current SQL txn. This needs to be called before resetForNewSQLTxn() is
 // called for starting another SQL txn.
@@ -1193,7 +1446,9 @@ func (ts *txnState) finishSQLTxn(s *Session) {
 // the txn (we're either in the AutoRetry state, meaning that we can do
 // auto-retries, or the client is doing client-directed retries), then the state
 // moves to RestartWait. Otherwise, the state moves to Aborted and the KV txn is
-// cleaned up.
+// cleaned up, unless the error is not retriable and the client has
+// established savepoints it might roll back to; in that case, the KV txn is
+// kept until the client decides what to do with it.
 // Note that even if we move to RestartWait here, this doesn't automatically
 // mean that we're going to auto-retry. It might be the case, for example, that
 // we've already streamed results to the client and so we can't auto-retry for
@@ -1212,6 +1467,10 @@ func (ts *txnState) updateStateAndCleanupOnErr(err error, e *Executor) error {
 			"updateStateAndCleanupOnErr called in state with no KV txn. State: %s",
 			ts.State()))
 	}
+	if _, ok := err.(*roachpb.HandledRetryableTxnError); !ok && ts.canRetainTxnOnErr() {
+		ts.SetState(Aborted)
+		return err
+	}
 	if retErr, ok := err.(*roachpb.HandledRetryableTxnError); !ok ||
 		!ts.willBeRetried() ||
 		!ts.mu.txn.IsRetryableErrMeantForTxn(*retErr) ||
@@ -1247,6 +1506,8 @@ func (ts *txnState) updateStateAndCleanupOnErr(err error, e *Executor) error {
 		// in this case cleanup for the txn has been done for us under the hood.
 		ts.SetState(RestartWait)
 		ts.mu.txn.ResetDeadline()
+		// The savepoints don't survive the restart of the txn.
+		ts.savepoints = nil
 	}
 	return err
 }
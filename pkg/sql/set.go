@@ -27,7 +27,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
-	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -400,16 +399,9 @@ func setTimeZone(_ context.Context, session *Session, values []parser.TypedExpr)
 	switch v := parser.UnwrapDatum(&evalCtx, d).(type) {
 	case *parser.DString:
 		location := string(*v)
-		loc, err = timeutil.LoadLocation(location)
+		loc, err = timeutil.TimeZoneStringToLocation(location)
 		if err != nil {
-			var err1 error
-			loc, err1 = timeutil.LoadLocation(strings.ToUpper(location))
-			if err1 != nil {
-				loc, err1 = timeutil.LoadLocation(strings.ToTitle(location))
-				if err1 != nil {
-					return fmt.Errorf("cannot find time zone %q: %v", location, err)
-				}
-			}
+			return fmt.Errorf("cannot find time zone %q: %v", location, err)
 		}
 
 	case *parser.DInterval:
@@ -438,7 +430,7 @@ func setTimeZone(_ context.Context, session *Session, values []parser.TypedExpr)
 		return fmt.Errorf("bad time zone value: %s", d.String())
 	}
 	if loc == nil {
-		loc = sqlbase.FixedOffsetTimeZoneToLocation(int(offset), d.String())
+		loc = timeutil.FixedOffsetTimeZoneToLocation(int(offset), d.String())
 	}
 	session.Location = loc
 	return nil
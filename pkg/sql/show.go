@@ -211,7 +211,7 @@ func (p *planner) ShowColumns(ctx context.Context, n *parser.ShowColumns) (planN
 func (p *planner) showTableDetails(
 	ctx context.Context, showType string, t parser.NormalizableTableName, query string,
 ) (planNode, error) {
-	tn, err := t.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &t)
 	if err != nil {
 		return nil, err
 	}
@@ -279,6 +279,25 @@ func (p *planner) ShowCreateView(ctx context.Context, n *parser.ShowCreateView)
 	return p.showTableDetails(ctx, "SHOW CREATE VIEW", n.View, showCreateViewQuery)
 }
 
+// ShowCreateSequence returns a CREATE SEQUENCE statement for the specified
+// sequence.
+// Privileges: Any privilege on sequence.
+func (p *planner) ShowCreateSequence(
+	ctx context.Context, n *parser.ShowCreateSequence,
+) (planNode, error) {
+	const showCreateSequenceQuery = `
+     SELECT %[3]s AS "Sequence",
+            IFNULL(create_statement,
+                   crdb_internal.force_error('` + pgerror.CodeUndefinedTableError + `',
+                                             %[1]s || '.' || %[2]s || ' is not a sequence')::string
+            ) AS "CreateSequence"
+       FROM (SELECT create_statement FROM %[4]s.crdb_internal.create_statements
+              WHERE database_name = %[1]s AND descriptor_name = %[2]s AND descriptor_type = 'sequence'
+              UNION ALL VALUES (NULL) ORDER BY 1 DESC) LIMIT 1
+  `
+	return p.showTableDetails(ctx, "SHOW CREATE SEQUENCE", n.Sequence, showCreateSequenceQuery)
+}
+
 // ShowTrace shows the current stored session trace.
 // Privileges: None.
 func (p *planner) ShowTrace(ctx context.Context, n *parser.ShowTrace) (planNode, error) {
@@ -536,7 +555,7 @@ func (p *planner) ShowIndex(ctx context.Context, n *parser.ShowIndex) (planNode,
 func (p *planner) ShowConstraints(
 	ctx context.Context, n *parser.ShowConstraints,
 ) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -671,5 +690,12 @@ func (p *planner) ShowTransactionStatus(ctx context.Context) (planNode, error) {
 // Privileges: SELECT on system.users.
 func (p *planner) ShowUsers(ctx context.Context, n *parser.ShowUsers) (planNode, error) {
 	return p.delegateQuery(ctx, "SHOW USERS",
-		`SELECT username FROM system.users ORDER BY 1`, nil, nil)
+		`SELECT username FROM system.users WHERE "isRole" = false ORDER BY 1`, nil, nil)
+}
+
+// ShowRoles returns all the roles.
+// Privileges: SELECT on system.users.
+func (p *planner) ShowRoles(ctx context.Context, n *parser.ShowRoles) (planNode, error) {
+	return p.delegateQuery(ctx, "SHOW ROLES",
+		`SELECT username AS role FROM system.users WHERE "isRole" = true ORDER BY 1`, nil, nil)
 }
@@ -30,10 +30,15 @@ func (p *planner) showCreateView(
 	ctx context.Context, tn parser.Name, desc *sqlbase.TableDescriptor,
 ) (string, error) {
 	var buf bytes.Buffer
-	buf.WriteString("CREATE VIEW ")
+	buf.WriteString("CREATE ")
+	if desc.IsMaterializedView {
+		buf.WriteString("MATERIALIZED ")
+	}
+	buf.WriteString("VIEW ")
 	tn.Format(&buf, parser.FmtSimple)
 	buf.WriteString(" (")
-	for i, col := range desc.Columns {
+	// Materialized views have a hidden rowid column for their primary key.
+	for i, col := range desc.VisibleColumns() {
 		if i > 0 {
 			buf.WriteString(", ")
 		}
@@ -43,6 +48,22 @@ func (p *planner) showCreateView(
 	return buf.String(), nil
 }
 
+// showCreateSequence returns a valid SQL representation of the
+// CREATE SEQUENCE statement used to create the given sequence.
+func (p *planner) showCreateSequence(
+	ctx context.Context, tn parser.Name, desc *sqlbase.TableDescriptor,
+) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("CREATE SEQUENCE ")
+	tn.Format(&buf, parser.FmtSimple)
+	opts := desc.SequenceOpts
+	fmt.Fprintf(&buf, " MINVALUE %d", opts.MinValue)
+	fmt.Fprintf(&buf, " MAXVALUE %d", opts.MaxValue)
+	fmt.Fprintf(&buf, " INCREMENT %d", opts.Increment)
+	fmt.Fprintf(&buf, " START %d", opts.Start)
+	return buf.String(), nil
+}
+
 // showCreateTable returns a valid SQL representation of the CREATE
 // TABLE statement used to create the given table.
 //
@@ -98,10 +119,10 @@ func (p *planner) showCreateTable(
 				quoteNames(fkIdx.ColumnNames...),
 			)
 			if fk.OnDelete != sqlbase.ForeignKeyReference_NO_ACTION {
-				fmt.Fprintf(&buf, " ON DELETE %s", fk.OnDelete.String())
+				fmt.Fprintf(&buf, " ON DELETE %s", sqlbase.ForeignKeyReferenceActionType[fk.OnDelete])
 			}
 			if fk.OnUpdate != sqlbase.ForeignKeyReference_NO_ACTION {
-				fmt.Fprintf(&buf, " ON UPDATE %s", fk.OnUpdate.String())
+				fmt.Fprintf(&buf, " ON UPDATE %s", sqlbase.ForeignKeyReferenceActionType[fk.OnUpdate])
 			}
 		}
 		if idx.ID != desc.PrimaryIndex.ID {
@@ -111,12 +132,20 @@ func (p *planner) showCreateTable(
 			if err := p.showCreateInterleave(ctx, &idx, &buf, dbPrefix); err != nil {
 				return "", err
 			}
+			if idx.IsPartial() {
+				fmt.Fprintf(&buf, " WHERE %s", idx.Predicate)
+			}
 		}
 	}
 
 	for _, fam := range desc.Families {
 		activeColumnNames := make([]string, 0, len(fam.ColumnNames))
 		for i, colID := range fam.ColumnIDs {
+			// The columns of index expressions are created along with their
+			// indexes.
+			if desc.IsIndexExpressionColumn(colID) {
+				continue
+			}
 			if _, err := desc.FindActiveColumnByID(colID); err == nil {
 				activeColumnNames = append(activeColumnNames, fam.ColumnNames[i])
 			}
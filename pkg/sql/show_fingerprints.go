@@ -46,7 +46,7 @@ import (
 func (p *planner) ShowFingerprints(
 	ctx context.Context, n *parser.ShowFingerprints,
 ) (planNode, error) {
-	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,119 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+var showTableStatsColumns = sqlbase.ResultColumns{
+	{Name: "statistics_name", Typ: types.String},
+	{Name: "column_names", Typ: types.TArray{Typ: types.String}},
+	{Name: "created", Typ: types.Timestamp},
+	{Name: "row_count", Typ: types.Int},
+	{Name: "distinct_count", Typ: types.Int},
+	{Name: "null_count", Typ: types.Int},
+	{Name: "histogram_id", Typ: types.Int},
+}
+
+// ShowTableStats returns the statistics collected for a table.
+// Privileges: Any privilege on table.
+func (p *planner) ShowTableStats(ctx context.Context, n *parser.ShowTableStats) (planNode, error) {
+	tn, err := p.normalizeTableName(ctx, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := MustGetTableDesc(ctx, p.txn, p.getVirtualTabler(), tn, true /*allowAdding*/)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.anyPrivilege(desc); err != nil {
+		return nil, err
+	}
+
+	columns := showTableStatsColumns
+	return &delayedNode{
+		name:    n.String(),
+		columns: columns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			// The statistics table is only readable by root, so it is
+			// queried with the internal executor.
+			internalExecutor := InternalExecutor{LeaseManager: p.LeaseMgr()}
+			rows, err := internalExecutor.QueryRowsInTransaction(
+				ctx,
+				"read-table-stats",
+				p.txn,
+				`SELECT "statisticID", name, "columnIDs", "createdAt", "rowCount",
+				        "distinctCount", "nullCount", histogram
+				   FROM system.table_statistics
+				  WHERE "tableID" = $1
+				  ORDER BY "createdAt", "statisticID"`,
+				parser.NewDInt(parser.DInt(desc.ID)),
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			v := p.newContainerValuesNode(columns, 0)
+			for _, r := range rows {
+				const (
+					statIDIdx = iota
+					nameIdx
+					columnIDsIdx
+					createdAtIdx
+					rowCountIdx
+					distinctCountIdx
+					nullCountIdx
+					histogramIdx
+				)
+				columnNames := parser.NewDArray(types.String)
+				for _, d := range parser.MustBeDArray(r[columnIDsIdx]).Array {
+					id := sqlbase.ColumnID(parser.MustBeDInt(d))
+					// Columns can be dropped after statistics were collected.
+					name := "<unknown>"
+					if col, err := desc.FindColumnByID(id); err == nil {
+						name = col.Name
+					}
+					if err := columnNames.Append(parser.NewDString(name)); err != nil {
+						v.Close(ctx)
+						return nil, err
+					}
+				}
+				histogramID := parser.DNull
+				if r[histogramIdx] != parser.DNull {
+					histogramID = r[statIDIdx]
+				}
+				newRow := parser.Datums{
+					r[nameIdx],
+					columnNames,
+					r[createdAtIdx],
+					r[rowCountIdx],
+					r[distinctCountIdx],
+					r[nullCountIdx],
+					histogramID,
+				}
+				if _, err := v.rows.AddRow(ctx, newRow); err != nil {
+					v.Close(ctx)
+					return nil, err
+				}
+			}
+			return v, nil
+		},
+	}, nil
+}
@@ -72,7 +72,9 @@ func MakeDefaultExprs(
 }
 
 // ProcessDefaultColumns adds columns with DEFAULT to cols if not present
-// and returns the defaultExprs for cols.
+// and returns the defaultExprs for cols. Columns being added by ALTER COLUMN
+// TYPE and the columns of index expressions are also added, since their
+// values are computed on every insert.
 func ProcessDefaultColumns(
 	cols []ColumnDescriptor,
 	tableDesc *TableDescriptor,
@@ -86,7 +88,7 @@ func ProcessDefaultColumns(
 
 	// Add the column if it has a DEFAULT expression.
 	addIfDefault := func(col ColumnDescriptor) {
-		if col.DefaultExpr != nil {
+		if col.DefaultExpr != nil || tableDesc.IsComputedColumn(col) {
 			if _, ok := colIDSet[col.ID]; !ok {
 				colIDSet[col.ID] = struct{}{}
 				cols = append(cols, col)
@@ -99,7 +101,7 @@ func ProcessDefaultColumns(
 		addIfDefault(col)
 	}
 	// Also add any column in a mutation that is DELETE_AND_WRITE_ONLY and has
-	// a DEFAULT expression.
+	// a DEFAULT expression or computed values.
 	for _, m := range tableDesc.Mutations {
 		if col := m.GetColumn(); col != nil &&
 			m.State == DescriptorMutation_DELETE_AND_WRITE_ONLY {
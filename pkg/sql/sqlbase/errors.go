@@ -104,7 +104,8 @@ func NewInvalidSchemaDefinitionError(err error) error {
 func IsPermanentSchemaChangeError(err error) bool {
 	return errHasCode(err, pgerror.CodeNotNullViolationError) ||
 		errHasCode(err, pgerror.CodeUniqueViolationError) ||
-		errHasCode(err, pgerror.CodeInvalidSchemaDefinitionError)
+		errHasCode(err, pgerror.CodeInvalidSchemaDefinitionError) ||
+		errHasCode(err, pgerror.CodeDatatypeMismatchError)
 }
 
 // NewUndefinedDatabaseError creates an error that represents a missing database.
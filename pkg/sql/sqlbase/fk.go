@@ -277,23 +277,43 @@ func (h fkInsertHelper) CollectSpansForValues(values parser.Datums) (roachpb.Spa
 	return collectSpansForValuesWithFKMap(h.fks, values)
 }
 
+// CollectCascadeSpans implements the FkSpanCollector interface.
+func (h fkInsertHelper) CollectCascadeSpans() roachpb.Spans {
+	return nil
+}
+
 type fkDeleteHelper struct {
 	fks map[IndexID][]baseFKHelper
+	// cascades holds the foreign keys referencing each index whose action is
+	// performed on the referencing rows instead of checking that there are
+	// none.
+	cascades map[IndexID][]*fkCascade
+	// cascadeState is set for the writers of the rows modified by cascading
+	// actions, to the state of the chain of cascades they are part of.
+	cascadeState *fkCascadeState
+	table        *TableDescriptor
+	colMap       map[ColumnID]int
 
 	checker *fkBatchChecker
 }
 
+// makeFKDeleteHelper creates the helper checking the foreign keys
+// referencing the rows being deleted, or updated if forUpdate is true, and
+// performing their cascading actions.
 func makeFKDeleteHelper(
 	txn *client.Txn,
 	table TableDescriptor,
 	otherTables TableLookupsByID,
 	colMap map[ColumnID]int,
 	alloc *DatumAlloc,
+	forUpdate bool,
 ) (fkDeleteHelper, error) {
 	h := fkDeleteHelper{
 		checker: &fkBatchChecker{
 			txn: txn,
 		},
+		table:  &table,
+		colMap: colMap,
 	}
 	for _, idx := range table.AllNonDropIndexes() {
 		for _, ref := range idx.ReferencedBy {
@@ -302,6 +322,22 @@ func makeFKDeleteHelper(
 				// and thus does not need to be checked for FK violations.
 				continue
 			}
+			if action, err := referencingAction(otherTables, ref, forUpdate); err != nil {
+				return h, err
+			} else if action.IsCascading() {
+				c, err := makeFKCascade(txn, otherTables, idx, ref, colMap, alloc, action)
+				if err == errSkipUnusedFK {
+					continue
+				}
+				if err != nil {
+					return h, err
+				}
+				if h.cascades == nil {
+					h.cascades = make(map[IndexID][]*fkCascade)
+				}
+				h.cascades[idx.ID] = append(h.cascades[idx.ID], c)
+				continue
+			}
 			fk, err := makeBaseFKHelper(txn, otherTables, idx, ref, colMap, alloc, CheckDeletes)
 			if err == errSkipUnusedFK {
 				continue
@@ -318,8 +354,28 @@ func makeFKDeleteHelper(
 	return h, nil
 }
 
+// referencingAction returns the action of the foreign key of the backward
+// reference ref when the referenced rows are updated if forUpdate is true, or
+// deleted otherwise.
+func referencingAction(
+	otherTables TableLookupsByID, ref ForeignKeyReference, forUpdate bool,
+) (ForeignKeyReference_Action, error) {
+	table := otherTables[ref.Table].Table
+	if table == nil {
+		return 0, errors.Errorf("referencing table %d not in provided table map %+v", ref.Table, otherTables)
+	}
+	idx, err := table.FindIndexByID(ref.Index)
+	if err != nil {
+		return 0, err
+	}
+	if forUpdate {
+		return idx.ForeignKey.OnUpdate, nil
+	}
+	return idx.ForeignKey.OnDelete, nil
+}
+
 func (h fkDeleteHelper) checkAll(ctx context.Context, row parser.Datums) error {
-	if len(h.fks) == 0 {
+	if len(h.fks) == 0 && len(h.cascades) == 0 {
 		return nil
 	}
 	for idx := range h.fks {
@@ -327,9 +383,44 @@ func (h fkDeleteHelper) checkAll(ctx context.Context, row parser.Datums) error {
 			return err
 		}
 	}
+	for idx := range h.cascades {
+		if err := h.cascadeIdx(ctx, idx, row, nil /* newRow */); err != nil {
+			return err
+		}
+	}
 	return h.checker.runCheck(ctx, row, nil /* newRow */)
 }
 
+// cascadeIdx performs the cascading actions of the foreign keys referencing
+// the index idx for the write of a row. newRow is nil when the row is
+// deleted.
+func (h fkDeleteHelper) cascadeIdx(
+	ctx context.Context, idx IndexID, oldRow, newRow parser.Datums,
+) error {
+	cascades := h.cascades[idx]
+	if len(cascades) == 0 {
+		return nil
+	}
+	state := h.cascadeState
+	if state == nil {
+		// The row starts a new chain of cascades, which must not come back
+		// to it.
+		state = &fkCascadeState{visited: make(map[cascadedRow]struct{})}
+		key, _, err := EncodeIndexKey(h.table, &h.table.PrimaryIndex, h.colMap, oldRow,
+			MakeIndexKeyPrefix(h.table, h.table.PrimaryIndex.ID))
+		if err != nil {
+			return err
+		}
+		state.visited[cascadedRow{key: string(key)}] = struct{}{}
+	}
+	for _, c := range cascades {
+		if err := c.run(ctx, state, oldRow, newRow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (h fkDeleteHelper) checkIdx(ctx context.Context, idx IndexID, row parser.Datums) error {
 	for i := range h.fks[idx] {
 		if err := h.checker.addCheck(row, &h.fks[idx][i]); err != nil {
@@ -349,6 +440,26 @@ func (h fkDeleteHelper) CollectSpansForValues(values parser.Datums) (roachpb.Spa
 	return collectSpansForValuesWithFKMap(h.fks, values)
 }
 
+// CollectCascadeSpans implements the FkSpanCollector interface. The chains
+// of cascades can write to any of the tables they were given, which include
+// all the tables reachable through cascading actions and the tables needed
+// to check the foreign keys of the rows they write, so all the spans of
+// these tables are returned.
+func (h fkDeleteHelper) CollectCascadeSpans() roachpb.Spans {
+	for _, cascades := range h.cascades {
+		for _, c := range cascades {
+			var spans roachpb.Spans
+			for _, lookup := range c.otherTables {
+				if lookup.Table != nil {
+					spans = append(spans, lookup.Table.AllIndexSpans()...)
+				}
+			}
+			return spans
+		}
+	}
+	return nil
+}
+
 type fkUpdateHelper struct {
 	inbound  fkDeleteHelper // Check old values are not referenced.
 	outbound fkInsertHelper // Check rows referenced by new values still exist.
@@ -365,7 +476,7 @@ func makeFKUpdateHelper(
 ) (fkUpdateHelper, error) {
 	ret := fkUpdateHelper{}
 	var err error
-	if ret.inbound, err = makeFKDeleteHelper(txn, table, otherTables, colMap, alloc); err != nil {
+	if ret.inbound, err = makeFKDeleteHelper(txn, table, otherTables, colMap, alloc, true /* forUpdate */); err != nil {
 		return ret, err
 	}
 	ret.outbound, err = makeFKInsertHelper(txn, table, otherTables, colMap, alloc)
@@ -380,6 +491,9 @@ func (fks fkUpdateHelper) checkIdx(
 	if err := fks.inbound.checkIdx(ctx, idx, oldValues); err != nil {
 		return err
 	}
+	if err := fks.inbound.cascadeIdx(ctx, idx, oldValues, newValues); err != nil {
+		return err
+	}
 	return fks.outbound.checkIdx(ctx, idx, newValues)
 }
 
@@ -403,6 +517,11 @@ func (fks fkUpdateHelper) CollectSpansForValues(values parser.Datums) (roachpb.S
 	return append(inboundReads, outboundReads...), nil
 }
 
+// CollectCascadeSpans implements the FkSpanCollector interface.
+func (fks fkUpdateHelper) CollectCascadeSpans() roachpb.Spans {
+	return fks.inbound.CollectCascadeSpans()
+}
+
 type baseFKHelper struct {
 	txn          *client.Txn
 	rf           RowFetcher
@@ -487,6 +606,9 @@ func (f baseFKHelper) span() roachpb.Span {
 type FkSpanCollector interface {
 	CollectSpans() roachpb.Spans
 	CollectSpansForValues(values parser.Datums) (roachpb.Spans, error)
+	// CollectCascadeSpans returns the spans which may be both read and
+	// written by cascading foreign key actions.
+	CollectCascadeSpans() roachpb.Spans
 }
 
 var _ FkSpanCollector = fkInsertHelper{}
@@ -0,0 +1,395 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// MaxCascadeDepth is the maximum length of the chains of cascading foreign
+// key actions triggered by the write of a single row. Reaching it usually
+// means the cascades loop through a cycle of foreign keys.
+const MaxCascadeDepth = 32
+
+// IsCascading returns whether the action modifies the referencing rows
+// instead of preventing the write of the referenced rows.
+func (a ForeignKeyReference_Action) IsCascading() bool {
+	switch a {
+	case ForeignKeyReference_CASCADE, ForeignKeyReference_SET_NULL, ForeignKeyReference_SET_DEFAULT:
+		return true
+	}
+	return false
+}
+
+// TablesNeededForCascades calculates the IDs of the additional
+// TableDescriptors that will be needed to write to table when its rows are
+// deleted or updated by the cascading actions of its foreign keys. It returns
+// nil if none of its foreign keys has a cascading action. As with
+// TablesNeededForFKs, the returned map's values are not set.
+func TablesNeededForCascades(table TableDescriptor) TableLookupsByID {
+	for _, idx := range table.AllNonDropIndexes() {
+		fk := idx.ForeignKey
+		if fk.IsSet() && (fk.OnDelete.IsCascading() || fk.OnUpdate.IsCascading()) {
+			return TablesNeededForFKs(table, CheckUpdates)
+		}
+	}
+	return nil
+}
+
+// fkCascadeState is shared by the writers of the rows modified by the chain of
+// cascading actions triggered by the write of a row.
+type fkCascadeState struct {
+	depth int
+	// visited contains the rows written in the chain. A row is only deleted
+	// once, and only updated once by the action of each foreign key, which
+	// stops the cascades going around cycles of foreign keys. The row which
+	// started the chain is never written again.
+	visited map[cascadedRow]struct{}
+}
+
+// cascadedRow identifies a row written by a chain of cascading actions.
+type cascadedRow struct {
+	// fkIndex is the referencing index of the foreign key whose action updated
+	// the row, or 0 if the row was deleted or started the chain.
+	fkIndex IndexID
+	// key is the primary key of the row.
+	key string
+}
+
+// fkCascade performs the action of a foreign key with ON DELETE or ON UPDATE
+// CASCADE, SET NULL or SET DEFAULT on the rows referencing the rows written
+// to the referenced index.
+type fkCascade struct {
+	txn         *client.Txn
+	otherTables TableLookupsByID
+	alloc       *DatumAlloc
+	action      ForeignKeyReference_Action
+
+	// table and index are the referencing table and its index holding the
+	// foreign key.
+	table     *TableDescriptor
+	index     *IndexDescriptor
+	prefixLen int
+	// refCols are the positions, in the rows written to the referenced
+	// table, of the values of the referenced columns.
+	refCols      []int
+	searchPrefix []byte
+
+	// The writers of the referencing rows, created on first use since they
+	// may in turn cascade to the referenced table.
+	rd *RowDeleter
+	ru *RowUpdater
+}
+
+func makeFKCascade(
+	txn *client.Txn,
+	otherTables TableLookupsByID,
+	writeIdx IndexDescriptor,
+	ref ForeignKeyReference,
+	colMap map[ColumnID]int,
+	alloc *DatumAlloc,
+	action ForeignKeyReference_Action,
+) (*fkCascade, error) {
+	c := &fkCascade{
+		txn:         txn,
+		otherTables: otherTables,
+		alloc:       alloc,
+		action:      action,
+		table:       otherTables[ref.Table].Table,
+	}
+	var err error
+	if c.index, err = c.table.FindIndexByID(ref.Index); err != nil {
+		return nil, err
+	}
+	c.searchPrefix = MakeIndexKeyPrefix(c.table, c.index.ID)
+	c.prefixLen = len(c.index.ColumnIDs)
+	if len(writeIdx.ColumnIDs) < c.prefixLen {
+		c.prefixLen = len(writeIdx.ColumnIDs)
+	}
+	nulls := true
+	for i, writeColID := range writeIdx.ColumnIDs[:c.prefixLen] {
+		if found, ok := colMap[writeColID]; ok {
+			c.refCols = append(c.refCols, found)
+			nulls = false
+		} else if !nulls {
+			return nil, errors.Errorf("missing value for column %q in multi-part foreign key", writeIdx.ColumnNames[i])
+		}
+	}
+	if nulls {
+		return nil, errSkipUnusedFK
+	}
+	return c, nil
+}
+
+// referencedValues returns the values of the referenced columns in row, or
+// nil if one of them is NULL, in which case no row references it.
+func (c *fkCascade) referencedValues(row parser.Datums) parser.Datums {
+	values := make(parser.Datums, c.prefixLen)
+	for i, idx := range c.refCols {
+		if row[idx] == parser.DNull {
+			return nil
+		}
+		values[i] = row[idx]
+	}
+	return values
+}
+
+// run performs the action of the foreign key for the write of a referenced
+// row. newRow is nil when the row is deleted.
+func (c *fkCascade) run(
+	ctx context.Context, state *fkCascadeState, oldRow, newRow parser.Datums,
+) error {
+	values := c.referencedValues(oldRow)
+	if values == nil {
+		return nil
+	}
+	var newValues parser.Datums
+	if newRow != nil {
+		newValues = make(parser.Datums, c.prefixLen)
+		changed := false
+		for i, idx := range c.refCols {
+			newValues[i] = newRow[idx]
+			changed = changed || newValues[i].Compare(&parser.EvalContext{}, values[i]) != 0
+		}
+		if !changed {
+			return nil
+		}
+	}
+	childState := &fkCascadeState{depth: state.depth + 1, visited: state.visited}
+
+	deleting := newRow == nil && c.action == ForeignKeyReference_CASCADE
+	var fetchCols []ColumnDescriptor
+	var colMap map[ColumnID]int
+	var updateValues parser.Datums
+	if deleting {
+		if c.rd == nil {
+			rd, err := MakeRowDeleter(c.txn, c.table, c.otherTables, nil /* requestedCols */, CheckFKs, c.alloc)
+			if err != nil {
+				return err
+			}
+			c.rd = &rd
+		}
+		c.rd.Fks.cascadeState = childState
+		fetchCols, colMap = c.rd.FetchCols, c.rd.FetchColIDtoRowIndex
+	} else {
+		if c.ru == nil {
+			if err := c.initUpdater(); err != nil {
+				return err
+			}
+		}
+		c.ru.Fks.inbound.cascadeState = childState
+		fetchCols, colMap = c.ru.FetchCols, c.ru.FetchColIDtoRowIndex
+		var err error
+		if updateValues, err = c.updateValues(newValues); err != nil {
+			return err
+		}
+	}
+
+	rows, err := c.referencingRows(ctx, values, fetchCols, colMap)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if state.depth >= MaxCascadeDepth {
+		return pgerror.NewErrorf(pgerror.CodeTriggeredActionExceptionError,
+			"foreign key cascade on table %q exceeds the maximum depth of %d",
+			c.table.Name, MaxCascadeDepth)
+	}
+	b := c.txn.NewBatch()
+	pkPrefix := MakeIndexKeyPrefix(c.table, c.table.PrimaryIndex.ID)
+	for _, row := range rows {
+		key, _, err := EncodeIndexKey(c.table, &c.table.PrimaryIndex, colMap, row, pkPrefix)
+		if err != nil {
+			return err
+		}
+		visitedRow := cascadedRow{key: string(key)}
+		if _, ok := state.visited[visitedRow]; ok {
+			continue
+		}
+		if !deleting {
+			// The foreign keys referencing different columns of a row each
+			// update it once.
+			visitedRow.fkIndex = c.index.ID
+			if _, ok := state.visited[visitedRow]; ok {
+				continue
+			}
+		}
+		state.visited[visitedRow] = struct{}{}
+		if deleting {
+			err = c.rd.DeleteRow(ctx, b, row, false /* traceKV */)
+		} else {
+			_, err = c.ru.UpdateRow(ctx, b, row, updateValues, false /* traceKV */)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := c.txn.Run(ctx, b); err != nil {
+		return ConvertBatchError(ctx, c.table, b)
+	}
+	return nil
+}
+
+// initUpdater creates the writer updating the referencing columns of the
+// referencing rows.
+func (c *fkCascade) initUpdater() error {
+	updateCols := make([]ColumnDescriptor, c.prefixLen)
+	for i, colID := range c.index.ColumnIDs[:c.prefixLen] {
+		col, err := c.table.FindColumnByID(colID)
+		if err != nil {
+			return err
+		}
+		updateCols[i] = *col
+	}
+	ru, err := MakeRowUpdater(c.txn, c.table, c.otherTables, updateCols,
+		nil /* requestedCols */, RowUpdaterDefault, c.alloc)
+	if err != nil {
+		return err
+	}
+	if c.action == ForeignKeyReference_CASCADE {
+		// The referencing rows are updated to reference the new values of the
+		// referenced row, which is written along with them.
+		delete(ru.Fks.outbound.fks, c.index.ID)
+	}
+	c.ru = &ru
+	return nil
+}
+
+// updateValues returns the values the referencing columns are set to.
+// newValues are the new values of the referenced columns, nil if the
+// referenced row is deleted.
+func (c *fkCascade) updateValues(newValues parser.Datums) (parser.Datums, error) {
+	updateCols := c.ru.UpdateCols
+	values := make(parser.Datums, len(updateCols))
+	switch c.action {
+	case ForeignKeyReference_CASCADE:
+		copy(values, newValues)
+	case ForeignKeyReference_SET_NULL:
+		for i := range values {
+			values[i] = parser.DNull
+		}
+	case ForeignKeyReference_SET_DEFAULT:
+		var evalCtx parser.EvalContext
+		// Defaults are evaluated as of the transaction performing the write.
+		ts := c.txn.OrigTimestamp().GoTime()
+		evalCtx.SetTxnTimestamp(ts)
+		evalCtx.SetStmtTimestamp(ts)
+		defaultExprs, err := MakeDefaultExprs(updateCols, &parser.ExprTransformContext{}, &evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		for i := range values {
+			values[i] = parser.DNull
+			if defaultExprs != nil {
+				if values[i], err = defaultExprs[i].Eval(&evalCtx); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for i := range updateCols {
+		if col := &updateCols[i]; values[i] == parser.DNull && c.table.EnforcesNotNull(col) {
+			return nil, NewNonNullViolationError(col.Name)
+		}
+	}
+	return values, nil
+}
+
+// referencingRows returns the rows of the referencing table which reference
+// the given values, with the columns in cols mapped by colMap, which must
+// contain the columns of the primary key.
+func (c *fkCascade) referencingRows(
+	ctx context.Context, values parser.Datums, cols []ColumnDescriptor, colMap map[ColumnID]int,
+) ([]parser.Datums, error) {
+	valuesMap := make(map[ColumnID]int, c.prefixLen)
+	for i, colID := range c.index.ColumnIDs[:c.prefixLen] {
+		valuesMap[colID] = i
+	}
+	key, _, err := EncodePartialIndexKey(
+		c.table, c.index, c.prefixLen, valuesMap, values, c.searchPrefix)
+	if err != nil {
+		return nil, err
+	}
+	spans := roachpb.Spans{{Key: key, EndKey: roachpb.Key(key).PrefixEnd()}}
+
+	valNeededForCol := make([]bool, len(cols))
+	for i := range valNeededForCol {
+		valNeededForCol[i] = true
+	}
+	if c.index.ID != c.table.PrimaryIndex.ID {
+		// Find the primary keys of the rows in the referencing index, then
+		// fetch the rows from the primary index.
+		pkNeeded := make([]bool, len(cols))
+		for _, colID := range c.table.PrimaryIndex.ColumnIDs {
+			pkNeeded[colMap[colID]] = true
+		}
+		var rf RowFetcher
+		if err := rf.Init(c.table, colMap, c.index, false /* reverse */, false, /* lockForUpdate */
+			true /* isSecondaryIndex */, cols, pkNeeded, false /* returnRangeInfo */, c.alloc); err != nil {
+			return nil, err
+		}
+		if err := rf.StartScan(ctx, c.txn, spans, false /* limitBatches */, 0, false /* traceKV */); err != nil {
+			return nil, err
+		}
+		pkPrefix := MakeIndexKeyPrefix(c.table, c.table.PrimaryIndex.ID)
+		spans = nil
+		for {
+			row, err := rf.NextRowDecoded(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if row == nil {
+				break
+			}
+			pk, _, err := EncodeIndexKey(c.table, &c.table.PrimaryIndex, colMap, row, pkPrefix)
+			if err != nil {
+				return nil, err
+			}
+			spans = append(spans, roachpb.Span{Key: pk, EndKey: roachpb.Key(pk).PrefixEnd()})
+		}
+		if len(spans) == 0 {
+			return nil, nil
+		}
+	}
+
+	var rf RowFetcher
+	if err := rf.Init(c.table, colMap, &c.table.PrimaryIndex, false /* reverse */, false, /* lockForUpdate */
+		false /* isSecondaryIndex */, cols, valNeededForCol, false /* returnRangeInfo */, c.alloc); err != nil {
+		return nil, err
+	}
+	if err := rf.StartScan(ctx, c.txn, spans, false /* limitBatches */, 0, false /* traceKV */); err != nil {
+		return nil, err
+	}
+	var rows []parser.Datums
+	for {
+		row, err := rf.NextRowDecoded(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			break
+		}
+		rows = append(rows, append(parser.Datums(nil), row...))
+	}
+	return rows, nil
+}
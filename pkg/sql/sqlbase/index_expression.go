@@ -0,0 +1,352 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// indexExpressionColumnName is the prefix of the names of the hidden columns
+// holding the values of the expressions of expression indexes.
+const indexExpressionColumnName = "crdb_internal_idx_expr"
+
+// indexExpression evaluates an expression of an expression index, which
+// computes the value of the hidden column indexed in its place. It is the
+// IndexedVarContainer of the expression, whose variables are the columns of
+// the table it refers to.
+type indexExpression struct {
+	// colID is the ID of the hidden column computed by the expression.
+	colID ColumnID
+	expr  parser.TypedExpr
+
+	srcCols []ColumnDescriptor
+	// srcIdx and dstIdx are the positions of the source columns and of the
+	// computed column in the rows passed to Convert. The elements of srcIdx
+	// are -1 for the columns the rows don't contain, which are considered
+	// NULL.
+	srcIdx []int
+	dstIdx int
+
+	srcValues parser.Datums
+}
+
+var _ parser.IndexedVarContainer = &indexExpression{}
+
+// IndexedVarEval implements the parser.IndexedVarContainer interface.
+func (e *indexExpression) IndexedVarEval(idx int, ctx *parser.EvalContext) (parser.Datum, error) {
+	return e.srcValues[idx].Eval(ctx)
+}
+
+// IndexedVarResolvedType implements the parser.IndexedVarContainer interface.
+func (e *indexExpression) IndexedVarResolvedType(idx int) types.T {
+	return e.srcCols[idx].Type.ToDatumType()
+}
+
+// IndexedVarFormat implements the parser.IndexedVarContainer interface.
+func (e *indexExpression) IndexedVarFormat(buf *bytes.Buffer, f parser.FmtFlags, idx int) {
+	parser.FormatNode(buf, f, parser.Name(e.srcCols[idx].Name))
+}
+
+// init parses expr, resolves the columns of tableDesc it refers to and type
+// checks it.
+func (e *indexExpression) init(tableDesc *TableDescriptor, expr string) error {
+	if err := e.initWithContext(tableDesc, expr, types.Any, "index expressions"); err != nil {
+		return err
+	}
+	return e.checkTimeZoneIndependent("index expressions")
+}
+
+// initWithContext is like init, for an expression of the desired type used
+// in the given context, which is named in error messages.
+func (e *indexExpression) initWithContext(
+	tableDesc *TableDescriptor, expr string, desired types.T, context string,
+) error {
+	parsed, err := parser.ParseExpr(expr)
+	if err != nil {
+		return err
+	}
+	parsed, err = parser.SimpleVisit(parsed, func(expr parser.Expr) (error, bool, parser.Expr) {
+		switch t := expr.(type) {
+		case *parser.Subquery:
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"subqueries are not allowed in %s", context), false, nil
+		case *parser.IndexedVar:
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"ordinal column references are not allowed in %s", context), false, nil
+		case parser.VarName:
+			v, err := t.NormalizeVarName()
+			if err != nil {
+				return err, false, nil
+			}
+			c, ok := v.(*parser.ColumnItem)
+			if !ok {
+				return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"invalid column reference in %s: %s", context, v), false, nil
+			}
+			col, _, err := tableDesc.FindColumnByName(c.ColumnName)
+			if err != nil {
+				return err, false, nil
+			}
+			// The columns of tables being created don't have IDs yet.
+			for i := range e.srcCols {
+				if e.srcCols[i].Name == col.Name {
+					return nil, false, parser.NewOrdinalReference(i)
+				}
+			}
+			e.srcCols = append(e.srcCols, col)
+			return nil, false, parser.NewOrdinalReference(len(e.srcCols) - 1)
+		}
+		return nil, true, expr
+	})
+	if err != nil {
+		return err
+	}
+	h := parser.MakeIndexedVarHelper(e, len(e.srcCols))
+	parsed, err = parser.SimpleVisit(parsed, func(expr parser.Expr) (error, bool, parser.Expr) {
+		if ivar, ok := expr.(*parser.IndexedVar); ok {
+			newVar, err := h.BindIfUnbound(ivar)
+			return err, false, newVar
+		}
+		return nil, true, expr
+	})
+	if err != nil {
+		return err
+	}
+	if e.expr, err = parser.TypeCheck(parsed, nil, desired); err != nil {
+		return err
+	}
+	// The expression must be a pure function of the row, since the value
+	// stored in the index has to be recomputed identically by every write.
+	_, err = parser.SimpleVisit(e.expr, func(expr parser.Expr) (error, bool, parser.Expr) {
+		if f, ok := expr.(*parser.FuncExpr); ok {
+			if f.IsWindowFunctionApplication() {
+				return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+					"window functions are not allowed in %s: %s", context, f), false, expr
+			}
+			if f.GetAggregateConstructor() != nil {
+				return pgerror.NewErrorf(pgerror.CodeGroupingError,
+					"aggregate functions are not allowed in %s: %s", context, f), false, expr
+			}
+			if f.IsImpure() {
+				return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+					"impure functions are not allowed in %s: %s", context, f), false, expr
+			}
+		}
+		return nil, true, expr
+	})
+	return err
+}
+
+// checkTimeZoneIndependent returns an error if the expression depends on the
+// time zone of the session evaluating it. The values stored in an index can't
+// depend on the session writing the row.
+func (e *indexExpression) checkTimeZoneIndependent(context string) error {
+	_, err := parser.SimpleVisit(e.expr, func(expr parser.Expr) (error, bool, parser.Expr) {
+		if dependsOnTimeZone(expr) {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"expressions depending on the session time zone are not allowed in %s: %s",
+				context, expr), false, expr
+		}
+		return nil, true, expr
+	})
+	return err
+}
+
+// dependsOnTimeZone returns whether the result of expr, a type checked
+// expression, depends on the time zone of the session evaluating it. Only
+// the root of expr is checked.
+func dependsOnTimeZone(expr parser.Expr) bool {
+	switch t := expr.(type) {
+	case *parser.FuncExpr:
+		// Dates and timestamps with time zones are truncated and decomposed in
+		// the session time zone.
+		def, err := t.Func.Resolve(parser.SearchPath{})
+		if err != nil {
+			return false
+		}
+		switch def.Name {
+		case "date_trunc", "extract":
+			for _, arg := range t.Exprs {
+				if typ := arg.(parser.TypedExpr).ResolvedType(); typ == types.TimestampTZ || typ == types.Date {
+					return true
+				}
+			}
+		}
+	case *parser.CastExpr:
+		// Conversions between dates, timestamps with and without time zones,
+		// and parsing dates and timestamps with time zones from strings, are
+		// performed in the session time zone.
+		from := t.Expr.(parser.TypedExpr).ResolvedType()
+		if from == types.Int || from == types.Null {
+			return false
+		}
+		switch to := t.ResolvedType(); to {
+		case types.Date:
+			return from != types.Date && from != types.Timestamp
+		case types.TimestampTZ:
+			return from != types.TimestampTZ
+		case types.Timestamp:
+			return from == types.TimestampTZ
+		}
+	case *parser.BinaryExpr:
+		// Intervals are added to dates in the session time zone.
+		return t.ResolvedType() == types.TimestampTZ &&
+			(t.TypedLeft().ResolvedType() == types.Date || t.TypedRight().ResolvedType() == types.Date)
+	case *parser.ComparisonExpr:
+		// Dates are compared to timestamps at midnight in the session time
+		// zone.
+		l, r := t.TypedLeft().ResolvedType(), t.TypedRight().ResolvedType()
+		isTimestamp := func(typ types.T) bool { return typ == types.Timestamp || typ == types.TimestampTZ }
+		return (l == types.Date && isTimestamp(r)) || (r == types.Date && isTimestamp(l))
+	}
+	return false
+}
+
+// IsIndexExpressionColumn returns whether the column with the given ID holds
+// the values of an expression of an expression index, including the indexes
+// being added or dropped.
+func (desc *TableDescriptor) IsIndexExpressionColumn(colID ColumnID) bool {
+	_, ok := desc.indexExpressions()[colID]
+	return ok
+}
+
+// IsComputedColumn returns whether the values of col are computed from the
+// other columns of the table whenever a row is written: this is the case of
+// the columns being added by ALTER COLUMN TYPE and of the columns holding
+// the values of index expressions.
+func (desc *TableDescriptor) IsComputedColumn(col ColumnDescriptor) bool {
+	return col.TypeConversion != nil || desc.IsIndexExpressionColumn(col.ID)
+}
+
+// indexExpressions returns the expressions of the expression indexes of the
+// table, including the indexes being added or dropped, keyed by the ID of the
+// hidden columns they compute.
+func (desc *TableDescriptor) indexExpressions() map[ColumnID]string {
+	var exprs map[ColumnID]string
+	addIndex := func(index *IndexDescriptor) {
+		for i, expr := range index.ColumnExpressions {
+			if expr == "" || i >= len(index.ColumnIDs) {
+				continue
+			}
+			if exprs == nil {
+				exprs = make(map[ColumnID]string)
+			}
+			exprs[index.ColumnIDs[i]] = expr
+		}
+	}
+	for i := range desc.Indexes {
+		addIndex(&desc.Indexes[i])
+	}
+	for _, m := range desc.Mutations {
+		if index := m.GetIndex(); index != nil {
+			addIndex(index)
+		}
+	}
+	return exprs
+}
+
+// IndexExpressionColumnIDs returns the IDs of the columns referred to by
+// expr, an expression of an expression index of the table.
+func (desc *TableDescriptor) IndexExpressionColumnIDs(expr string) ([]ColumnID, error) {
+	var e indexExpression
+	if err := e.init(desc, expr); err != nil {
+		return nil, err
+	}
+	ids := make([]ColumnID, len(e.srcCols))
+	for i := range e.srcCols {
+		ids[i] = e.srcCols[i].ID
+	}
+	return ids, nil
+}
+
+// recomputedIndexExpressionColumns returns the set of the columns holding the
+// values of index expressions which refer to at least one of the given
+// columns, and thus change when they are updated.
+func (desc *TableDescriptor) recomputedIndexExpressionColumns(
+	updated map[ColumnID]int,
+) (map[ColumnID]struct{}, error) {
+	var recomputed map[ColumnID]struct{}
+	for colID, expr := range desc.indexExpressions() {
+		srcIDs, err := desc.IndexExpressionColumnIDs(expr)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range srcIDs {
+			if _, ok := updated[id]; ok {
+				if recomputed == nil {
+					recomputed = make(map[ColumnID]struct{})
+				}
+				recomputed[colID] = struct{}{}
+				break
+			}
+		}
+	}
+	return recomputed, nil
+}
+
+// MakeIndexExpressionColumns checks the expressions of index, which must
+// belong to desc, and returns the hidden columns holding their values, whose
+// names it fills in index. The columns are not added to desc. It returns nil
+// if index doesn't have any expressions.
+func (desc *TableDescriptor) MakeIndexExpressionColumns(
+	index *IndexDescriptor,
+) ([]ColumnDescriptor, error) {
+	var cols []ColumnDescriptor
+	for i, expr := range index.ColumnExpressions {
+		if expr == "" {
+			continue
+		}
+		var e indexExpression
+		if err := e.init(desc, expr); err != nil {
+			return nil, err
+		}
+		colType, err := DatumTypeToColumnType(e.expr.ResolvedType())
+		if err != nil {
+			return nil, pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"index expression %s has invalid type %s", expr, e.expr.ResolvedType())
+		}
+		if !columnTypeIsIndexable(colType) {
+			return nil, pgerror.UnimplementedWithIssueErrorf(17154,
+				"index expression %s is of type %s and thus is not indexable",
+				expr, colType.SemanticType)
+		}
+		name := indexExpressionColumnName
+		for n := 1; ; n++ {
+			if _, _, err := desc.FindColumnByName(parser.Name(name)); err != nil {
+				isUsed := false
+				for _, c := range cols {
+					isUsed = isUsed || c.Name == name
+				}
+				if !isUsed {
+					break
+				}
+			}
+			name = fmt.Sprintf("%s_%d", indexExpressionColumnName, n)
+		}
+		cols = append(cols, ColumnDescriptor{
+			Name:     name,
+			Type:     colType,
+			Nullable: true,
+			Hidden:   true,
+		})
+		index.ColumnNames[i] = name
+	}
+	return cols, nil
+}
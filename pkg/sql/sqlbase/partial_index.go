@@ -0,0 +1,138 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// IsPartial returns whether the index only contains the rows of its table
+// which satisfy its predicate.
+func (desc *IndexDescriptor) IsPartial() bool {
+	return desc.Predicate != ""
+}
+
+// makeIndexPredicate parses and type checks the predicate of a partial index
+// of the table.
+func (desc *TableDescriptor) makeIndexPredicate(pred string) (*indexExpression, error) {
+	e := &indexExpression{}
+	if err := e.initWithContext(desc, pred, types.Bool, "index predicates"); err != nil {
+		return nil, err
+	}
+	// The rows satisfying the predicate can't depend on the session writing
+	// them.
+	if err := e.checkTimeZoneIndependent("index predicates"); err != nil {
+		return nil, err
+	}
+	if typ := e.expr.ResolvedType(); typ != types.Bool && typ != types.Null {
+		return nil, pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+			"index predicate %s must be of type bool, not %s", pred, typ)
+	}
+	return e, nil
+}
+
+// ValidateIndexPredicate checks that pred is a valid predicate for a partial
+// index of the table.
+func (desc *TableDescriptor) ValidateIndexPredicate(pred string) error {
+	_, err := desc.makeIndexPredicate(pred)
+	return err
+}
+
+// IndexPredicateColumnIDs returns the IDs of the columns referred to by the
+// predicate of index, which must belong to the table. It returns nil for the
+// indexes which are not partial.
+func (desc *TableDescriptor) IndexPredicateColumnIDs(index *IndexDescriptor) ([]ColumnID, error) {
+	if !index.IsPartial() {
+		return nil, nil
+	}
+	e, err := desc.makeIndexPredicate(index.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]ColumnID, len(e.srcCols))
+	for i := range e.srcCols {
+		ids[i] = e.srcCols[i].ID
+	}
+	return ids, nil
+}
+
+// PartialIndexFilter removes, from the entries of the secondary indexes
+// encoded for a row, the ones of the partial indexes whose predicate the row
+// doesn't satisfy.
+type PartialIndexFilter struct {
+	// predicates are the predicates of the indexes the filter was made for,
+	// nil for the indexes containing all the rows.
+	predicates []*indexExpression
+
+	// Predicates are pure functions of the row, like index expressions.
+	evalCtx parser.EvalContext
+}
+
+// MakePartialIndexFilter returns a PartialIndexFilter for the entries of the
+// given indexes of the table.
+func MakePartialIndexFilter(
+	tableDesc *TableDescriptor, indexes []IndexDescriptor,
+) (PartialIndexFilter, error) {
+	var f PartialIndexFilter
+	for i := range indexes {
+		if !indexes[i].IsPartial() {
+			continue
+		}
+		if f.predicates == nil {
+			f.predicates = make([]*indexExpression, len(indexes))
+		}
+		e, err := tableDesc.makeIndexPredicate(indexes[i].Predicate)
+		if err != nil {
+			return PartialIndexFilter{}, err
+		}
+		e.srcValues = make(parser.Datums, len(e.srcCols))
+		f.predicates[i] = e
+	}
+	return f, nil
+}
+
+// Empty returns true if none of the indexes are partial.
+func (f *PartialIndexFilter) Empty() bool {
+	return f.predicates == nil
+}
+
+// Filter clears the keys of the entries, encoded for the row of values whose
+// columns are mapped by colMap, of the indexes which don't contain the row.
+// The columns of the predicates missing from the row are considered NULL.
+func (f *PartialIndexFilter) Filter(
+	colMap map[ColumnID]int, values []parser.Datum, entries []IndexEntry,
+) error {
+	for i, e := range f.predicates {
+		if e == nil {
+			continue
+		}
+		for j := range e.srcCols {
+			e.srcValues[j] = parser.DNull
+			if idx, ok := colMap[e.srcCols[j].ID]; ok {
+				e.srcValues[j] = values[idx]
+			}
+		}
+		d, err := e.expr.Eval(&f.evalCtx)
+		if err != nil {
+			return err
+		}
+		if b, ok := d.(*parser.DBool); !ok || !bool(*b) {
+			entries[i] = IndexEntry{}
+		}
+	}
+	return nil
+}
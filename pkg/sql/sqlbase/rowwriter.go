@@ -47,6 +47,7 @@ type rowHelper struct {
 	primaryIndexKeyPrefix []byte
 	primaryIndexCols      map[ColumnID]struct{}
 	sortedColumnFamilies  map[FamilyID][]ColumnID
+	partialIndexes        *PartialIndexFilter
 }
 
 // encodeIndexes encodes the primary and secondary index keys. The
@@ -71,7 +72,8 @@ func (rh *rowHelper) encodeIndexes(
 	return primaryIndexKey, secondaryIndexEntries, nil
 }
 
-// encodeSecondaryIndexes encodes the secondary index keys. The
+// encodeSecondaryIndexes encodes the secondary index keys. The entries of the
+// partial indexes which don't contain the row have a nil Key. The
 // secondaryIndexEntries are only valid until the next call to encodeIndexes or
 // encodeSecondaryIndexes.
 func (rh *rowHelper) encodeSecondaryIndexes(
@@ -85,6 +87,18 @@ func (rh *rowHelper) encodeSecondaryIndexes(
 	if err != nil {
 		return nil, err
 	}
+	if rh.partialIndexes == nil {
+		f, err := MakePartialIndexFilter(rh.TableDesc, rh.Indexes)
+		if err != nil {
+			return nil, err
+		}
+		rh.partialIndexes = &f
+	}
+	if !rh.partialIndexes.Empty() {
+		if err := rh.partialIndexes.Filter(colIDtoRowIndex, values, rh.indexEntries); err != nil {
+			return nil, err
+		}
+	}
 	return rh.indexEntries, nil
 }
 
@@ -144,8 +158,11 @@ type RowInserter struct {
 	InsertColIDtoRowIndex map[ColumnID]int
 	Fks                   fkInsertHelper
 
+	converter ColumnConverter
+
 	// For allocation avoidance.
 	marshalled []roachpb.Value
+	converted  []parser.Datum
 	key        roachpb.Key
 	valueBuf   []byte
 	scratch    []byte
@@ -187,8 +204,12 @@ func MakeRowInserter(
 		}
 	}
 
+	var err error
+	if ri.converter, err = MakeColumnConverter(tableDesc, insertCols); err != nil {
+		return RowInserter{}, err
+	}
+
 	if checkFKs {
-		var err error
 		if ri.Fks, err = makeFKInsertHelper(txn, *tableDesc, fkTables,
 			ri.InsertColIDtoRowIndex, alloc); err != nil {
 			return ri, err
@@ -239,6 +260,16 @@ func (ri *RowInserter) InsertRow(
 		putFn = insertPutFn
 	}
 
+	if !ri.converter.Empty() {
+		// Compute the columns being converted by ALTER COLUMN TYPE without
+		// modifying the caller's values.
+		ri.converted = append(ri.converted[:0], values...)
+		if err := ri.converter.Convert(ri.converted); err != nil {
+			return err
+		}
+		values = ri.converted
+	}
+
 	// Encode the values to the expected column type. This needs to
 	// happen before index encoding because certain datum types (i.e. tuple)
 	// cannot be used as index values.
@@ -337,6 +368,10 @@ func (ri *RowInserter) InsertRow(
 
 	for i := range secondaryIndexEntries {
 		e := &secondaryIndexEntries[i]
+		if e.Key == nil {
+			// The row isn't contained in this partial index.
+			continue
+		}
 		putFn(ctx, b, &e.Key, &e.Value, traceKV)
 	}
 
@@ -361,6 +396,9 @@ type RowUpdater struct {
 	updateColIDtoRowIndex map[ColumnID]int
 	deleteOnlyIndex       map[int]struct{}
 	primaryKeyColChange   bool
+	// recomputedCols are the columns of index expressions which refer to
+	// updated columns: they are updated too, with their new computed values.
+	recomputedCols map[ColumnID]struct{}
 
 	// rd and ri are used when the update this RowUpdater is created for modifies
 	// the primary key of the table. In that case, rows must be deleted and
@@ -370,6 +408,8 @@ type RowUpdater struct {
 
 	Fks fkUpdateHelper
 
+	converter ColumnConverter
+
 	// For allocation avoidance.
 	marshalled      []roachpb.Value
 	newValues       []parser.Datum
@@ -423,6 +463,50 @@ func MakeRowUpdater(
 		}
 	}
 
+	recomputedCols, err := tableDesc.recomputedIndexExpressionColumns(updateColIDtoRowIndex)
+	if err != nil {
+		return RowUpdater{}, err
+	}
+	isUpdated := func(colID ColumnID) bool {
+		if _, ok := updateColIDtoRowIndex[colID]; ok {
+			return true
+		}
+		_, ok := recomputedCols[colID]
+		return ok
+	}
+
+	// Rows move in and out of partial indexes when the columns of their
+	// predicates are updated.
+	var predicateUpdated map[IndexID]struct{}
+	checkPredicate := func(index *IndexDescriptor) error {
+		predCols, err := tableDesc.IndexPredicateColumnIDs(index)
+		if err != nil {
+			return err
+		}
+		for _, colID := range predCols {
+			if isUpdated(colID) {
+				if predicateUpdated == nil {
+					predicateUpdated = make(map[IndexID]struct{})
+				}
+				predicateUpdated[index.ID] = struct{}{}
+				break
+			}
+		}
+		return nil
+	}
+	for i := range tableDesc.Indexes {
+		if err := checkPredicate(&tableDesc.Indexes[i]); err != nil {
+			return RowUpdater{}, err
+		}
+	}
+	for _, m := range tableDesc.Mutations {
+		if index := m.GetIndex(); index != nil {
+			if err := checkPredicate(index); err != nil {
+				return RowUpdater{}, err
+			}
+		}
+	}
+
 	// Secondary indexes needing updating.
 	needsUpdate := func(index IndexDescriptor) bool {
 		if updateType == RowUpdaterOnlyColumns {
@@ -433,8 +517,11 @@ func MakeRowUpdater(
 		if primaryKeyColChange {
 			return true
 		}
+		if _, ok := predicateUpdated[index.ID]; ok {
+			return true
+		}
 		return index.RunOverAllColumns(func(id ColumnID) error {
-			if _, ok := updateColIDtoRowIndex[id]; ok {
+			if isUpdated(id) {
 				return returnTruePseudoError
 			}
 			return nil
@@ -483,6 +570,7 @@ func MakeRowUpdater(
 		updateColIDtoRowIndex: updateColIDtoRowIndex,
 		deleteOnlyIndex:       deleteOnlyIndex,
 		primaryKeyColChange:   primaryKeyColChange,
+		recomputedCols:        recomputedCols,
 		marshalled:            make([]roachpb.Value, len(updateCols)),
 		newValues:             make([]parser.Datum, len(tableCols)),
 	}
@@ -525,7 +613,7 @@ func MakeRowUpdater(
 		for _, fam := range tableDesc.Families {
 			familyBeingUpdated := false
 			for _, colID := range fam.ColumnIDs {
-				if _, ok := ru.updateColIDtoRowIndex[colID]; ok {
+				if isUpdated(colID) {
 					familyBeingUpdated = true
 					break
 				}
@@ -542,10 +630,43 @@ func MakeRowUpdater(
 			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
 				return RowUpdater{}, err
 			}
+			predCols, err := tableDesc.IndexPredicateColumnIDs(&index)
+			if err != nil {
+				return RowUpdater{}, err
+			}
+			for _, colID := range predCols {
+				if err := maybeAddCol(colID); err != nil {
+					return RowUpdater{}, err
+				}
+			}
+		}
+		// Columns being added by ALTER COLUMN TYPE are recomputed from the
+		// columns they replace whenever they are written, and the columns of
+		// index expressions from the columns the expressions refer to.
+		exprs := tableDesc.indexExpressions()
+		for _, col := range ru.FetchCols {
+			if col.TypeConversion != nil {
+				if err := maybeAddCol(col.TypeConversion.SourceColumnID); err != nil {
+					return RowUpdater{}, err
+				}
+			}
+			if expr, ok := exprs[col.ID]; ok {
+				srcIDs, err := tableDesc.IndexExpressionColumnIDs(expr)
+				if err != nil {
+					return RowUpdater{}, err
+				}
+				for _, id := range srcIDs {
+					if err := maybeAddCol(id); err != nil {
+						return RowUpdater{}, err
+					}
+				}
+			}
 		}
 	}
 
-	var err error
+	if ru.converter, err = MakeColumnConverter(tableDesc, ru.FetchCols); err != nil {
+		return RowUpdater{}, err
+	}
 	if ru.Fks, err = makeFKUpdateHelper(txn, *tableDesc, fkTables,
 		ru.FetchColIDtoRowIndex, alloc); err != nil {
 		return RowUpdater{}, err
@@ -599,6 +720,9 @@ func (ru *RowUpdater) UpdateRow(
 	for i, updateCol := range ru.UpdateCols {
 		ru.newValues[ru.FetchColIDtoRowIndex[updateCol.ID]] = updateValues[i]
 	}
+	if err := ru.converter.Convert(ru.newValues); err != nil {
+		return nil, err
+	}
 
 	rowPrimaryKeyChanged := false
 	var newSecondaryIndexEntries []IndexEntry
@@ -657,6 +781,10 @@ func (ru *RowUpdater) UpdateRow(
 				update = true
 				break
 			}
+			if _, ok := ru.recomputedCols[colID]; ok {
+				update = true
+				break
+			}
 		}
 		if !update {
 			continue
@@ -752,10 +880,16 @@ func (ru *RowUpdater) UpdateRow(
 				return nil, err
 			}
 
-			if traceKV {
-				log.VEventf(ctx, 2, "Del %s", secondaryIndexEntry.Key)
+			// The old or the new row may not be contained in a partial index.
+			if secondaryIndexEntry.Key != nil {
+				if traceKV {
+					log.VEventf(ctx, 2, "Del %s", secondaryIndexEntry.Key)
+				}
+				b.Del(secondaryIndexEntry.Key)
+			}
+			if newSecondaryIndexEntry.Key == nil {
+				continue
 			}
-			b.Del(secondaryIndexEntry.Key)
 		} else if !bytes.Equal(newSecondaryIndexEntry.Value.RawBytes, secondaryIndexEntry.Value.RawBytes) {
 			expValue = &secondaryIndexEntry.Value
 		} else {
@@ -850,6 +984,17 @@ func MakeRowDeleter(
 				return RowDeleter{}, err
 			}
 		}
+		// The columns of the predicates of partial indexes determine whether
+		// the row has entries to delete in them.
+		predCols, err := tableDesc.IndexPredicateColumnIDs(&index)
+		if err != nil {
+			return RowDeleter{}, err
+		}
+		for _, colID := range predCols {
+			if err := maybeAddCol(colID); err != nil {
+				return RowDeleter{}, err
+			}
+		}
 	}
 
 	rd := RowDeleter{
@@ -860,7 +1005,7 @@ func MakeRowDeleter(
 	if checkFKs {
 		var err error
 		if rd.Fks, err = makeFKDeleteHelper(txn, *tableDesc, fkTables,
-			fetchColIDtoRowIndex, alloc); err != nil {
+			fetchColIDtoRowIndex, alloc, false /* forUpdate */); err != nil {
 			return RowDeleter{}, err
 		}
 	}
@@ -883,6 +1028,9 @@ func (rd *RowDeleter) DeleteRow(
 	}
 
 	for _, secondaryIndexEntry := range secondaryIndexEntries {
+		if secondaryIndexEntry.Key == nil {
+			continue
+		}
 		if traceKV {
 			log.VEventf(ctx, 2, "Del %s", secondaryIndexEntry.Key)
 		}
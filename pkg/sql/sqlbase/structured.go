@@ -190,7 +190,18 @@ func (desc *IndexDescriptor) RunOverAllColumns(fn func(id ColumnID) error) error
 func (desc *IndexDescriptor) allocateName(tableDesc *TableDescriptor) {
 	segments := make([]string, 0, len(desc.ColumnNames)+2)
 	segments = append(segments, tableDesc.Name)
-	segments = append(segments, desc.ColumnNames...)
+	for i, name := range desc.ColumnNames {
+		if i < len(desc.ColumnExpressions) && desc.ColumnExpressions[i] != "" {
+			// As in PostgreSQL, expressions are named after their function.
+			name = "expr"
+			if expr, err := parser.ParseExpr(desc.ColumnExpressions[i]); err == nil {
+				if f, ok := expr.(*parser.FuncExpr); ok {
+					name = f.Func.String()
+				}
+			}
+		}
+		segments = append(segments, name)
+	}
 	if desc.Unique {
 		segments = append(segments, "key")
 	} else {
@@ -211,12 +222,31 @@ func (desc *IndexDescriptor) allocateName(tableDesc *TableDescriptor) {
 	desc.Name = name
 }
 
-// FillColumns sets the column names and directions in desc.
+// FillColumns sets the column names and directions in desc. The expressions
+// of the elements indexing expressions are stored in desc.ColumnExpressions,
+// and their column names are left empty until MakeIndexExpressionColumns.
 func (desc *IndexDescriptor) FillColumns(elems parser.IndexElemList) error {
 	desc.ColumnNames = make([]string, 0, len(elems))
 	desc.ColumnDirections = make([]IndexDescriptor_Direction, 0, len(elems))
-	for _, c := range elems {
+	desc.ColumnExpressions = nil
+	for i, c := range elems {
+		var expr parser.Expr
+		if c.Expr != nil {
+			expr = parser.StripParens(c.Expr)
+			// Parenthesized column names are indexed as columns.
+			if name, ok := expr.(parser.UnresolvedName); ok && len(name) == 1 {
+				if col, ok := name[0].(parser.Name); ok {
+					c.Column, expr = col, nil
+				}
+			}
+		}
 		desc.ColumnNames = append(desc.ColumnNames, string(c.Column))
+		if expr != nil {
+			if desc.ColumnExpressions == nil {
+				desc.ColumnExpressions = make([]string, len(elems))
+			}
+			desc.ColumnExpressions[i] = parser.Serialize(expr)
+		}
 		switch c.Direction {
 		case parser.Ascending, parser.DefaultDirection:
 			desc.ColumnDirections = append(desc.ColumnDirections, IndexDescriptor_ASC)
@@ -281,11 +311,29 @@ func (desc *IndexDescriptor) ColNamesString() string {
 		if i > 0 {
 			buf.WriteString(", ")
 		}
+		if expr := desc.ColumnExpression(i); expr != "" {
+			fmt.Fprintf(&buf, "%s %s", expr, desc.ColumnDirections[i])
+			continue
+		}
 		fmt.Fprintf(&buf, "%s %s", parser.Name(name), desc.ColumnDirections[i])
 	}
 	return buf.String()
 }
 
+// ColumnExpression returns the expression indexed by the i-th column of the
+// index, formatted as in an index definition, or the empty string if the
+// column is indexed directly.
+func (desc *IndexDescriptor) ColumnExpression(i int) string {
+	if i >= len(desc.ColumnExpressions) || desc.ColumnExpressions[i] == "" {
+		return ""
+	}
+	expr, err := parser.ParseExpr(desc.ColumnExpressions[i])
+	if err != nil {
+		return desc.ColumnExpressions[i]
+	}
+	return parser.AsString(parser.IndexElem{Expr: expr, Direction: parser.DefaultDirection})
+}
+
 var isUnique = map[bool]string{true: "UNIQUE "}
 
 // SQLString returns the SQL string describing this index. If non-empty,
@@ -336,15 +384,21 @@ func (desc *TableDescriptor) IsEmpty() bool {
 // IsTable returns true if the TableDescriptor actually describes a
 // Table resource, as opposed to a different resource (like a View).
 func (desc *TableDescriptor) IsTable() bool {
-	return !desc.IsView()
+	return !desc.IsView() && !desc.IsSequence()
 }
 
 // IsView returns true if the TableDescriptor actually describes a
-// View resource rather than a Table.
+// View resource rather than a Table. This includes materialized views.
 func (desc *TableDescriptor) IsView() bool {
 	return desc.ViewQuery != ""
 }
 
+// IsSequence returns true if the TableDescriptor actually describes a
+// Sequence resource rather than a Table.
+func (desc *TableDescriptor) IsSequence() bool {
+	return desc.SequenceOpts != nil
+}
+
 // IsVirtualTable returns true if the TableDescriptor describes a
 // virtual Table (like the information_schema tables) and thus doesn't
 // need to be physically stored.
@@ -356,8 +410,11 @@ func (desc *TableDescriptor) IsVirtualTable() bool {
 // physical Table that needs to be stored in the kv layer, as opposed to a
 // different resource like a view or a virtual table. Physical tables have
 // primary keys, column families, and indexes (unlike virtual tables).
+// Sequences and materialized views count as physical tables because their
+// values are stored in the KV layer.
 func (desc *TableDescriptor) IsPhysicalTable() bool {
-	return desc.IsTable() && !desc.IsVirtualTable()
+	return desc.IsSequence() || desc.IsMaterializedView ||
+		(desc.IsTable() && !desc.IsVirtualTable())
 }
 
 // KeysPerRow returns the maximum number of keys used to encode a row for the
@@ -1065,6 +1122,14 @@ func (desc *TableDescriptor) ValidateTable() error {
 				idx := desc.Index
 				return errors.Errorf("mutation in state %s, direction %s, index %s, id %v", m.State, m.Direction, idx.Name, idx.ID)
 			}
+		case *DescriptorMutation_Constraint:
+			colID := desc.Constraint.NotNullColumn
+			if unSetEnums {
+				return errors.Errorf("mutation in state %s, direction %s, NOT NULL constraint on column id %v", m.State, m.Direction, colID)
+			}
+			if _, ok := columnIDs[colID]; !ok {
+				return errors.Errorf("NOT NULL constraint on unknown column id %v", colID)
+			}
 		default:
 			return errors.Errorf("mutation in state %s, direction %s, and no column/index descriptor", m.State, m.Direction)
 		}
@@ -1207,6 +1272,10 @@ func (desc *TableDescriptor) validateTableIndexes(
 			return fmt.Errorf("mismatched column IDs (%d) and directions (%d)",
 				len(index.ColumnIDs), len(index.ColumnDirections))
 		}
+		if len(index.ColumnExpressions) > 0 && len(index.ColumnIDs) != len(index.ColumnExpressions) {
+			return fmt.Errorf("mismatched column IDs (%d) and expressions (%d)",
+				len(index.ColumnIDs), len(index.ColumnExpressions))
+		}
 
 		if len(index.ColumnIDs) == 0 {
 			return fmt.Errorf("index %q must contain at least 1 column", index.Name)
@@ -1660,6 +1729,10 @@ func (desc *TableDescriptor) AddIndex(idx IndexDescriptor, primary bool) error {
 	if err := checkColumnsValidForIndex(desc, idx.ColumnNames); err != nil {
 		return err
 	}
+	if primary && len(idx.ColumnExpressions) > 0 {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"expressions are not allowed in primary keys")
+	}
 	if primary {
 		// PrimaryIndex is unset.
 		if desc.PrimaryIndex.Name == "" {
@@ -1956,12 +2029,23 @@ func (desc *TableDescriptor) MakeMutationComplete(m DescriptorMutation) {
 	case DescriptorMutation_ADD:
 		switch t := m.Descriptor_.(type) {
 		case *DescriptorMutation_Column:
-			desc.AddColumn(*t.Column)
+			if t.Column.TypeConversion != nil {
+				desc.replaceConvertedColumn(*t.Column)
+			} else {
+				desc.AddColumn(*t.Column)
+			}
 
 		case *DescriptorMutation_Index:
 			if err := desc.AddIndex(*t.Index, false); err != nil {
 				panic(err)
 			}
+
+		case *DescriptorMutation_Constraint:
+			for i := range desc.Columns {
+				if desc.Columns[i].ID == t.Constraint.NotNullColumn {
+					desc.Columns[i].Nullable = false
+				}
+			}
 		}
 
 	case DescriptorMutation_DROP:
@@ -1970,7 +2054,56 @@ func (desc *TableDescriptor) MakeMutationComplete(m DescriptorMutation) {
 			desc.RemoveColumnFromFamily(t.Column.ID)
 		}
 		// Nothing else to be done. The column/index was already removed from the
-		// set of column/index descriptors at mutation creation time.
+		// set of column/index descriptors at mutation creation time, and a
+		// constraint being dropped was never part of the table.
+	}
+}
+
+// replaceConvertedColumn makes col, a column added by ALTER COLUMN TYPE, take
+// the place and the name of the column it was converted from. The replaced
+// column is queued up to be dropped by a new mutation, which the caller must
+// finalize.
+//
+// Nodes still using the previous version of the descriptor read the replaced
+// column, which nodes using this version would no longer write to. col keeps
+// its TypeConversion to block writes to the table until the replaced column
+// moves to the DELETE_ONLY state, see ClearReplacedColumnConversion.
+func (desc *TableDescriptor) replaceConvertedColumn(col ColumnDescriptor) {
+	srcID := col.TypeConversion.SourceColumnID
+	for i := range desc.Columns {
+		if desc.Columns[i].ID != srcID {
+			continue
+		}
+		// The two columns swap names.
+		src := desc.Columns[i]
+		src.Name, col.Name = col.Name, src.Name
+		desc.Columns[i] = col
+		for j := range desc.Families {
+			for k, id := range desc.Families[j].ColumnIDs {
+				switch id {
+				case col.ID:
+					desc.Families[j].ColumnNames[k] = col.Name
+				case src.ID:
+					desc.Families[j].ColumnNames[k] = src.Name
+				}
+			}
+		}
+		desc.AddColumnMutation(src, DescriptorMutation_DROP)
+		return
+	}
+	// The column being converted doesn't exist anymore.
+	col.TypeConversion = nil
+	desc.AddColumn(col)
+}
+
+// ClearReplacedColumnConversion unblocks writes to the column that replaced
+// the column with the given ID by ALTER COLUMN TYPE, once the replaced column
+// is no longer read by any node.
+func (desc *TableDescriptor) ClearReplacedColumnConversion(replacedID ColumnID) {
+	for i := range desc.Columns {
+		if c := desc.Columns[i].TypeConversion; c != nil && c.SourceColumnID == replacedID {
+			desc.Columns[i].TypeConversion = nil
+		}
 	}
 }
 
@@ -1994,6 +2127,68 @@ func (desc *TableDescriptor) AddIndexMutation(
 	return nil
 }
 
+// AddNotNullMutation adds a mutation to desc.Mutations making the column
+// with the given ID NOT NULL.
+func (desc *TableDescriptor) AddNotNullMutation(colID ColumnID) {
+	m := DescriptorMutation{
+		Descriptor_: &DescriptorMutation_Constraint{Constraint: &ConstraintToValidate{NotNullColumn: colID}},
+		Direction:   DescriptorMutation_ADD,
+	}
+	desc.addMutation(m)
+}
+
+// HasNotNullMutation returns true if a mutation is making the column with
+// the given ID NOT NULL.
+func (desc *TableDescriptor) HasNotNullMutation(colID ColumnID) bool {
+	for _, m := range desc.Mutations {
+		if c := m.GetConstraint(); c != nil && c.NotNullColumn == colID {
+			return true
+		}
+	}
+	return false
+}
+
+// EnforcesNotNull returns true if NULL values can't be written to col, either
+// because it is NOT NULL or because it is being made NOT NULL by a mutation
+// in the DELETE_AND_WRITE_ONLY state.
+func (desc *TableDescriptor) EnforcesNotNull(col *ColumnDescriptor) bool {
+	if !col.Nullable {
+		return true
+	}
+	for _, m := range desc.Mutations {
+		if c := m.GetConstraint(); c != nil && c.NotNullColumn == col.ID &&
+			m.State == DescriptorMutation_DELETE_AND_WRITE_ONLY {
+			return true
+		}
+	}
+	return false
+}
+
+// UsedSequenceIDs returns the IDs of the sequences used by the default
+// expressions of the columns of the table, excluding the columns being
+// dropped.
+func (desc *TableDescriptor) UsedSequenceIDs() []ID {
+	var ids []ID
+	seen := make(map[ID]struct{})
+	addColumn := func(col *ColumnDescriptor) {
+		for _, id := range col.UsesSequenceIds {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+	for i := range desc.Columns {
+		addColumn(&desc.Columns[i])
+	}
+	for _, m := range desc.Mutations {
+		if col := m.GetColumn(); col != nil && m.Direction == DescriptorMutation_ADD {
+			addColumn(col)
+		}
+	}
+	return ids
+}
+
 func (desc *TableDescriptor) addMutation(m DescriptorMutation) {
 	switch m.Direction {
 	case DescriptorMutation_ADD:
@@ -2442,3 +2637,13 @@ var ForeignKeyReferenceActionValue = [...]ForeignKeyReference_Action{
 	parser.SetNull:    ForeignKeyReference_SET_NULL,
 	parser.Cascade:    ForeignKeyReference_CASCADE,
 }
+
+// ForeignKeyReferenceActionType allows the conversion between a
+// ForeignKeyReference_Action and a parser.ReferenceAction.
+var ForeignKeyReferenceActionType = [...]parser.ReferenceAction{
+	ForeignKeyReference_NO_ACTION:   parser.NoAction,
+	ForeignKeyReference_RESTRICT:    parser.Restrict,
+	ForeignKeyReference_SET_DEFAULT: parser.SetDefault,
+	ForeignKeyReference_SET_NULL:    parser.SetNull,
+	ForeignKeyReference_CASCADE:     parser.Cascade,
+}
@@ -1301,6 +1301,61 @@ func TestUnvalidateConstraints(t *testing.T) {
 	}
 }
 
+func TestReplaceConvertedColumn(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	intType := ColumnType{SemanticType: ColumnType_INT}
+	desc := TableDescriptor{
+		Name:     "test",
+		ParentID: ID(1),
+		Columns: []ColumnDescriptor{
+			{ID: 1, Name: "k", Type: intType},
+			{ID: 2, Name: "a", Type: intType, Nullable: true},
+		},
+		NextColumnID:  4,
+		FormatVersion: FamilyFormatVersion,
+		Families: []ColumnFamilyDescriptor{
+			{ID: 0, Name: "primary", ColumnIDs: []ColumnID{1, 2, 3}, ColumnNames: []string{"k", "a", "a_conv"}},
+		},
+	}
+	desc.AddColumnMutation(ColumnDescriptor{
+		ID:       3,
+		Name:     "a_conv",
+		Type:     ColumnType{SemanticType: ColumnType_STRING},
+		Nullable: true,
+		TypeConversion: &ColumnDescriptor_TypeConversion{
+			SourceColumnID: 2,
+			Expr:           "@1::STRING",
+		},
+	}, DescriptorMutation_ADD)
+	desc.Mutations[0].State = DescriptorMutation_DELETE_AND_WRITE_ONLY
+	if _, err := MakeColumnConverter(&desc, desc.Columns); err != nil {
+		t.Fatal(err)
+	}
+
+	m := desc.Mutations[0]
+	desc.Mutations = nil
+	desc.MakeMutationComplete(m)
+	if col := desc.Columns[1]; col.ID != 3 || col.Name != "a" {
+		t.Fatalf("expected column 3 to replace column \"a\", found %d %q", col.ID, col.Name)
+	}
+	if len(desc.Mutations) != 1 || desc.Mutations[0].Direction != DescriptorMutation_DROP ||
+		desc.Mutations[0].GetColumn().ID != 2 {
+		t.Fatalf("expected column 2 to be dropped, found %v", desc.Mutations)
+	}
+
+	// Writes are blocked until no node reads the replaced column anymore.
+	if _, err := MakeColumnConverter(&desc, desc.Columns); !testutils.IsError(
+		err, `column "a" is being converted to a new type, try again later`,
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	desc.ClearReplacedColumnConversion(2)
+	if _, err := MakeColumnConverter(&desc, desc.Columns); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestKeysPerRow(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
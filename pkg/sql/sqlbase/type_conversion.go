@@ -0,0 +1,271 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// typeConversion evaluates the conversion expression of a column being added
+// by ALTER COLUMN TYPE. It is the IndexedVarContainer of the expression, whose
+// only variable @1 is the value of the source column.
+type typeConversion struct {
+	// col is the column being added, renamed after the source column so
+	// that errors refer to the name the user knows.
+	col  ColumnDescriptor
+	expr parser.TypedExpr
+
+	srcType types.T
+	// srcIdx and dstIdx are the positions of the source and converted
+	// columns in the rows passed to Convert. srcIdx is -1 if the rows don't
+	// contain the source column, in which case it is considered NULL.
+	srcIdx, dstIdx int
+
+	srcValue parser.Datum
+}
+
+var _ parser.IndexedVarContainer = &typeConversion{}
+
+// IndexedVarEval implements the parser.IndexedVarContainer interface.
+func (c *typeConversion) IndexedVarEval(idx int, ctx *parser.EvalContext) (parser.Datum, error) {
+	return c.srcValue.Eval(ctx)
+}
+
+// IndexedVarResolvedType implements the parser.IndexedVarContainer interface.
+func (c *typeConversion) IndexedVarResolvedType(idx int) types.T {
+	return c.srcType
+}
+
+// IndexedVarFormat implements the parser.IndexedVarContainer interface.
+func (c *typeConversion) IndexedVarFormat(buf *bytes.Buffer, f parser.FmtFlags, idx int) {
+	fmt.Fprintf(buf, "@%d", idx+1)
+}
+
+// init parses and type checks the conversion expression of c.col.
+func (c *typeConversion) init() error {
+	expr, err := parser.ParseExpr(c.col.TypeConversion.Expr)
+	if err != nil {
+		return err
+	}
+	h := parser.MakeIndexedVarHelper(c, 1)
+	expr, err = parser.SimpleVisit(expr, func(e parser.Expr) (error, bool, parser.Expr) {
+		if ivar, ok := e.(*parser.IndexedVar); ok {
+			newVar, err := h.BindIfUnbound(ivar)
+			return err, false, newVar
+		}
+		return nil, true, e
+	})
+	if err != nil {
+		return err
+	}
+	typ := c.col.Type.ToDatumType()
+	if c.expr, err = parser.TypeCheck(expr, nil, typ); err != nil {
+		return err
+	}
+	if resolved := c.expr.ResolvedType(); !typ.Equivalent(resolved) && c.expr != parser.DNull {
+		return incompatibleExprTypeError("USING", typ, resolved)
+	}
+	// The expression must be a pure function of the source column, since it
+	// is evaluated independently by every write to the table.
+	_, err = parser.SimpleVisit(c.expr, func(e parser.Expr) (error, bool, parser.Expr) {
+		if f, ok := e.(*parser.FuncExpr); ok && f.IsImpure() {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"impure functions are not allowed in USING expressions: %s", f), false, e
+		}
+		return nil, true, e
+	})
+	return err
+}
+
+// makeTypeConversion returns the typeConversion computing col, which must
+// have a TypeConversion, from its source column in tableDesc.
+func makeTypeConversion(tableDesc *TableDescriptor, col ColumnDescriptor) (typeConversion, error) {
+	src, err := tableDesc.FindColumnByID(col.TypeConversion.SourceColumnID)
+	if err != nil {
+		return typeConversion{}, err
+	}
+	col.Name = src.Name
+	return typeConversion{col: col, srcType: src.Type.ToDatumType(), srcIdx: -1}, nil
+}
+
+// ValidateTypeConversion checks that the conversion expression of col, a
+// column being added by ALTER COLUMN TYPE to tableDesc, is valid.
+func ValidateTypeConversion(tableDesc *TableDescriptor, col ColumnDescriptor) error {
+	c, err := makeTypeConversion(tableDesc, col)
+	if err != nil {
+		return err
+	}
+	return c.init()
+}
+
+// NewTypeConversionError creates an error for a value that can't be
+// converted to the new type of a column by ALTER COLUMN TYPE.
+func NewTypeConversionError(col string, err error) error {
+	return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+		"cannot convert column %q: %v", col, err)
+}
+
+// ColumnConverter computes the values of the columns being added by ALTER
+// COLUMN TYPE schema changes from the values of the columns they replace, so
+// that both columns stay consistent while the schema change is in progress.
+// It also computes the values of the hidden columns indexed by expression
+// indexes from the columns their expressions refer to.
+type ColumnConverter struct {
+	conversions []typeConversion
+	expressions []indexExpression
+
+	// Conversion expressions are pure functions of the source column, so
+	// they are evaluated in an empty context rather than in the one of the
+	// statement performing the write.
+	evalCtx parser.EvalContext
+}
+
+// MakeColumnConverter returns a ColumnConverter for rows holding the values
+// of cols. Only the public columns and the columns being changed in the
+// DELETE_AND_WRITE_ONLY state are computed: columns in the DELETE_ONLY state
+// must not be written to.
+//
+// An error is returned while a column that replaced another one by ALTER
+// COLUMN TYPE still has its TypeConversion: nodes using older versions of the
+// descriptor may still read the replaced column, which is no longer written.
+func MakeColumnConverter(tableDesc *TableDescriptor, cols []ColumnDescriptor) (ColumnConverter, error) {
+	for _, col := range tableDesc.Columns {
+		if col.TypeConversion != nil {
+			return ColumnConverter{}, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+				"column %q is being converted to a new type, try again later", col.Name)
+		}
+	}
+	var c ColumnConverter
+	for _, m := range tableDesc.Mutations {
+		col := m.GetColumn()
+		if col == nil || col.TypeConversion == nil ||
+			m.Direction != DescriptorMutation_ADD || m.State != DescriptorMutation_DELETE_AND_WRITE_ONLY {
+			continue
+		}
+		conv, err := makeTypeConversion(tableDesc, *col)
+		if err != nil {
+			return ColumnConverter{}, err
+		}
+		conv.dstIdx = -1
+		for i := range cols {
+			switch cols[i].ID {
+			case col.ID:
+				conv.dstIdx = i
+			case col.TypeConversion.SourceColumnID:
+				conv.srcIdx = i
+			}
+		}
+		if conv.dstIdx != -1 {
+			c.conversions = append(c.conversions, conv)
+		}
+	}
+	writable := make(map[ColumnID]struct{}, len(tableDesc.Columns)+len(tableDesc.Mutations))
+	for _, col := range tableDesc.Columns {
+		writable[col.ID] = struct{}{}
+	}
+	for _, m := range tableDesc.Mutations {
+		if col := m.GetColumn(); col != nil && m.State == DescriptorMutation_DELETE_AND_WRITE_ONLY {
+			writable[col.ID] = struct{}{}
+		}
+	}
+	var exprs []string
+	for colID, expr := range tableDesc.indexExpressions() {
+		if _, ok := writable[colID]; !ok {
+			continue
+		}
+		e := indexExpression{colID: colID, dstIdx: -1}
+		for i := range cols {
+			if cols[i].ID == colID {
+				e.dstIdx = i
+			}
+		}
+		if e.dstIdx != -1 {
+			c.expressions = append(c.expressions, e)
+			exprs = append(exprs, expr)
+		}
+	}
+	// The expressions are bound to the elements of c.conversions and
+	// c.expressions, which must not move anymore.
+	for i := range c.conversions {
+		if err := c.conversions[i].init(); err != nil {
+			return ColumnConverter{}, err
+		}
+	}
+	for i := range c.expressions {
+		e := &c.expressions[i]
+		if err := e.init(tableDesc, exprs[i]); err != nil {
+			return ColumnConverter{}, err
+		}
+		e.srcValues = make(parser.Datums, len(e.srcCols))
+		e.srcIdx = make([]int, len(e.srcCols))
+		for j := range e.srcCols {
+			e.srcIdx[j] = -1
+			for k := range cols {
+				if cols[k].ID == e.srcCols[j].ID {
+					e.srcIdx[j] = k
+				}
+			}
+		}
+	}
+	return c, nil
+}
+
+// Empty returns true if there are no columns to convert.
+func (c *ColumnConverter) Empty() bool {
+	return len(c.conversions) == 0 && len(c.expressions) == 0
+}
+
+// Convert overwrites, in row, the values of the columns being converted with
+// the result of their conversion expressions.
+func (c *ColumnConverter) Convert(row parser.Datums) error {
+	for i := range c.conversions {
+		conv := &c.conversions[i]
+		conv.srcValue = parser.DNull
+		if conv.srcIdx != -1 {
+			conv.srcValue = row[conv.srcIdx]
+		}
+		d, err := conv.expr.Eval(&c.evalCtx)
+		if err != nil {
+			return NewTypeConversionError(conv.col.Name, err)
+		}
+		if d == parser.DNull && !conv.col.Nullable {
+			return NewNonNullViolationError(conv.col.Name)
+		}
+		if err := CheckValueWidth(conv.col, d); err != nil {
+			return NewTypeConversionError(conv.col.Name, err)
+		}
+		row[conv.dstIdx] = d
+	}
+	for i := range c.expressions {
+		e := &c.expressions[i]
+		for j, idx := range e.srcIdx {
+			e.srcValues[j] = parser.DNull
+			if idx != -1 {
+				e.srcValues[j] = row[idx]
+			}
+		}
+		d, err := e.expr.Eval(&c.evalCtx)
+		if err != nil {
+			return err
+		}
+		row[e.dstIdx] = d
+	}
+	return nil
+}
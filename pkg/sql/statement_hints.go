@@ -0,0 +1,157 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// statementHintsCache holds the planner hints pinned to statement
+// fingerprints in system.statement_hints.
+//
+// A pinned statement is a statement with the same fingerprint as the
+// queries it applies to (see parser.StatementFingerprint), carrying the
+// join and index hints to use when planning them. This makes it possible to
+// fix the plan of a query without changing the application that issues it,
+// e.g.:
+//
+//   INSERT INTO system.statement_hints (fingerprint, statement)
+//     SELECT crdb_internal.statement_fingerprint(s), s
+//     FROM (VALUES ('SELECT * FROM a INNER MERGE JOIN b USING (k)')) AS v(s)
+//
+// The table is part of the system config, so the cache is rebuilt from
+// gossip whenever it changes, like the database cache.
+type statementHintsCache struct {
+	// statements maps fingerprints to the pinned statements.
+	statements map[string]parser.Statement
+}
+
+// newStatementHintsCache builds a statementHintsCache from the rows of
+// system.statement_hints found in the given system config. Invalid rows are
+// logged and ignored.
+func newStatementHintsCache(ctx context.Context, cfg config.SystemConfig) *statementHintsCache {
+	c := &statementHintsCache{}
+	tbl := &sqlbase.StatementHintsTable
+	prefix := keys.MakeTablePrefix(uint32(tbl.ID))
+	var a sqlbase.DatumAlloc
+	for _, kv := range cfg.Values {
+		if !bytes.HasPrefix(kv.Key, prefix) {
+			continue
+		}
+		fingerprint, sql, err := decodeStatementHintsRow(&a, tbl, kv)
+		if err != nil {
+			log.Warningf(ctx, "error decoding statement hints: %+v", err)
+			continue
+		}
+		stmt, err := parser.ParseOne(sql)
+		if err != nil {
+			log.Warningf(ctx, "invalid pinned statement %q: %v", sql, err)
+			continue
+		}
+		if f := parser.StatementFingerprint(stmt); f != fingerprint {
+			log.Warningf(ctx, "pinned statement %q has fingerprint %q, not %q", sql, f, fingerprint)
+			continue
+		}
+		if c.statements == nil {
+			c.statements = make(map[string]parser.Statement)
+		}
+		c.statements[fingerprint] = stmt
+	}
+	return c
+}
+
+// decodeStatementHintsRow decodes the fingerprint and statement columns of
+// a row of system.statement_hints.
+func decodeStatementHintsRow(
+	a *sqlbase.DatumAlloc, tbl *sqlbase.TableDescriptor, kv roachpb.KeyValue,
+) (fingerprint string, sql string, _ error) {
+	// The fingerprint is the primary key.
+	types := []sqlbase.ColumnType{tbl.Columns[0].Type}
+	keyRow := make([]sqlbase.EncDatum, 1)
+	_, matches, err := sqlbase.DecodeIndexKey(tbl, &tbl.PrimaryIndex, types, keyRow, nil, kv.Key)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to decode key")
+	}
+	if !matches {
+		return "", "", errors.Errorf("unexpected KV with statement hints prefix: %v", kv.Key)
+	}
+	if err := keyRow[0].EnsureDecoded(&types[0], a); err != nil {
+		return "", "", err
+	}
+	fingerprint = string(parser.MustBeDString(keyRow[0].Datum))
+
+	// The other columns are stored in a single family.
+	b, err := kv.Value.GetTuple()
+	if err != nil {
+		return "", "", err
+	}
+	var lastColID sqlbase.ColumnID
+	for len(b) > 0 {
+		_, _, colIDDiff, _, err := encoding.DecodeValueTag(b)
+		if err != nil {
+			return "", "", err
+		}
+		colID := lastColID + sqlbase.ColumnID(colIDDiff)
+		lastColID = colID
+		col, err := tbl.FindColumnByID(colID)
+		if err != nil {
+			return "", "", err
+		}
+		var d parser.Datum
+		d, b, err = sqlbase.DecodeTableValue(a, col.Type.ToDatumType(), b)
+		if err != nil {
+			return "", "", err
+		}
+		if colID == tbl.Columns[1].ID {
+			sql = string(parser.MustBeDString(d))
+		}
+	}
+	if sql == "" {
+		return "", "", errors.Errorf("missing statement for fingerprint %q", fingerprint)
+	}
+	return fingerprint, sql, nil
+}
+
+// pinnedHints returns the hints pinned to the fingerprint of the given
+// statement, or nil if there are none. The hints pinned to an EXPLAINed
+// statement are also used for the EXPLAIN statement.
+func (c *statementHintsCache) pinnedHints(stmt parser.Statement) *parser.PinnedHints {
+	if c == nil || len(c.statements) == 0 {
+		return nil
+	}
+	if e, ok := stmt.(*parser.Explain); ok {
+		stmt = e.Statement
+	}
+	pinned, ok := c.statements[parser.StatementFingerprint(stmt)]
+	if !ok {
+		return nil
+	}
+	hints, ok := parser.MakePinnedHints(stmt, pinned)
+	if !ok {
+		return nil
+	}
+	return &hints
+}
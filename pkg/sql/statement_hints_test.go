@@ -0,0 +1,104 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestPinnedStatementHints verifies that the hints of a statement inserted
+// in system.statement_hints are used to plan the queries with the same
+// fingerprint.
+func TestPinnedStatementHints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := createTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+	sqlDB := sqlutils.MakeSQLRunner(t, db)
+
+	sqlDB.Exec(`CREATE DATABASE t`)
+	sqlDB.Exec(`CREATE TABLE t.l (a INT PRIMARY KEY, b INT)`)
+	sqlDB.Exec(`CREATE TABLE t.r (c INT PRIMARY KEY, d INT, INDEX d_idx (d))`)
+
+	// joinHint returns the hint of the join in the plan of the query.
+	joinHint := func(query string) (string, error) {
+		rows := sqlDB.QueryStr(`SELECT "Description" FROM [EXPLAIN ` + query + `] WHERE "Field" = 'hint'`)
+		switch len(rows) {
+		case 0:
+			return "", nil
+		case 1:
+			return rows[0][0], nil
+		default:
+			return "", errors.Errorf("unexpected hints %v", rows)
+		}
+	}
+
+	const query = `SELECT * FROM t.l JOIN t.r ON b = d WHERE a > 5`
+	if h, err := joinHint(query); err != nil {
+		t.Fatal(err)
+	} else if h != "" {
+		t.Fatalf("expected no hint before pinning, got %q", h)
+	}
+
+	sqlDB.Exec(`INSERT INTO system.statement_hints (fingerprint, statement)
+	  SELECT crdb_internal.statement_fingerprint(s), s
+	  FROM (VALUES ('SELECT * FROM t.l INNER LOOKUP JOIN t.r ON b = d WHERE a > 0')) AS v(s)`)
+
+	// The hints are propagated through gossip.
+	testutils.SucceedsSoon(t, func() error {
+		h, err := joinHint(query)
+		if err != nil {
+			return err
+		}
+		if h != "lookup" {
+			return errors.Errorf("expected the pinned hint, got %q", h)
+		}
+		return nil
+	})
+
+	// Queries with other constants use the pinned hints too; other queries
+	// are not affected.
+	if h, err := joinHint(`SELECT * FROM t.l JOIN t.r ON b = d WHERE a > 100`); err != nil {
+		t.Fatal(err)
+	} else if h != "lookup" {
+		t.Fatalf("expected the pinned hint, got %q", h)
+	}
+	if h, err := joinHint(`SELECT * FROM t.l JOIN t.r ON a = c`); err != nil {
+		t.Fatal(err)
+	} else if h != "" {
+		t.Fatalf("expected no hint, got %q", h)
+	}
+
+	sqlDB.Exec(`DELETE FROM system.statement_hints`)
+	testutils.SucceedsSoon(t, func() error {
+		h, err := joinHint(query)
+		if err != nil {
+			return err
+		}
+		if h != "" {
+			return errors.Errorf("expected no hint after unpinning, got %q", h)
+		}
+		return nil
+	})
+}
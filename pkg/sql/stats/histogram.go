@@ -0,0 +1,151 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// EquiDepthHistogram creates a histogram where each bucket contains roughly
+// the same number of samples (though it can vary when a boundary value has
+// high frequency). The samples must not contain NULLs; they are sorted in
+// place.
+//
+// numRows is the total number of rows from which the values were sampled,
+// excluding rows with NULL values. The bucket counts are scaled accordingly.
+func EquiDepthHistogram(
+	evalCtx *parser.EvalContext, samples parser.Datums, numRows int64, maxBuckets int,
+) (HistogramData, error) {
+	numSamples := len(samples)
+	if numSamples == 0 {
+		return HistogramData{}, nil
+	}
+	if maxBuckets < 2 {
+		return HistogramData{}, errors.Errorf("histogram requires at least two buckets")
+	}
+	if numRows < int64(numSamples) {
+		return HistogramData{}, errors.Errorf("more samples than rows")
+	}
+	for _, d := range samples {
+		if d == parser.DNull {
+			return HistogramData{}, errors.Errorf("NULL values not allowed in histogram")
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Compare(evalCtx, samples[j]) < 0
+	})
+
+	numBuckets := maxBuckets
+	if maxBuckets > numSamples {
+		numBuckets = numSamples
+	}
+	h := HistogramData{
+		Buckets: make([]HistogramData_Bucket, 0, numBuckets),
+	}
+	// i keeps track of the current sample and advances as we form buckets.
+	for i, b := 0, 0; b < numBuckets && i < numSamples; b++ {
+		// num is the number of samples in this bucket.
+		num := (numSamples - i) / (numBuckets - b)
+		if num < 1 {
+			num = 1
+		}
+		upper := samples[i+num-1]
+		// numLess is the number of samples less than upper (in this bucket).
+		numLess := 0
+		for ; numLess < num-1; numLess++ {
+			if samples[i+numLess].Compare(evalCtx, upper) == 0 {
+				break
+			}
+		}
+		// Advance the boundary of the bucket to cover all samples equal to
+		// upper.
+		for ; i+num < numSamples; num++ {
+			if samples[i+num].Compare(evalCtx, upper) != 0 {
+				break
+			}
+		}
+		encoded, err := sqlbase.EncodeTableKey(nil, upper, encoding.Ascending)
+		if err != nil {
+			return HistogramData{}, err
+		}
+		h.Buckets = append(h.Buckets, HistogramData_Bucket{
+			NumEq:      int64(num-numLess) * numRows / int64(numSamples),
+			NumRange:   int64(numLess) * numRows / int64(numSamples),
+			UpperBound: encoded,
+		})
+		i += num
+	}
+	return h, nil
+}
+
+// ValueCount returns the estimated number of rows equal to the value with
+// the given ascending key encoding, and whether the value is one of the
+// bucket boundaries. If it is not, the estimate is the average number of
+// rows per distinct value in the bucket range containing the value, given
+// distinctPerBucket distinct values per bucket range.
+func (h *HistogramData) ValueCount(key []byte, distinctPerBucket float64) (float64, bool) {
+	for _, b := range h.Buckets {
+		switch c := bytes.Compare(key, b.UpperBound); {
+		case c == 0:
+			return float64(b.NumEq), true
+		case c < 0:
+			if distinctPerBucket < 1 {
+				distinctPerBucket = 1
+			}
+			return float64(b.NumRange) / distinctPerBucket, false
+		}
+	}
+	// The value is larger than all the values in the histogram.
+	return 0, false
+}
+
+// RowsLessThan returns the estimated number of rows that are smaller than
+// the value with the given ascending key encoding (or smaller than or equal
+// to it, if inclusive is set). Values which fall inside a bucket range are
+// assumed to split the range in half.
+func (h *HistogramData) RowsLessThan(key []byte, inclusive bool) float64 {
+	var rows float64
+	for _, b := range h.Buckets {
+		switch c := bytes.Compare(key, b.UpperBound); {
+		case c > 0:
+			rows += float64(b.NumRange + b.NumEq)
+		case c == 0:
+			rows += float64(b.NumRange)
+			if inclusive {
+				rows += float64(b.NumEq)
+			}
+			return rows
+		default:
+			return rows + float64(b.NumRange)/2
+		}
+	}
+	return rows
+}
+
+// TotalRows returns the number of rows covered by the histogram.
+func (h *HistogramData) TotalRows() float64 {
+	var rows float64
+	for _, b := range h.Buckets {
+		rows += float64(b.NumRange + b.NumEq)
+	}
+	return rows
+}
@@ -0,0 +1,190 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+type expBucket struct {
+	upper    int
+	numEq    int64
+	numRange int64
+}
+
+func TestEquiDepthHistogram(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		samples    []int
+		numRows    int64
+		maxBuckets int
+		buckets    []expBucket
+	}{
+		{
+			samples:    []int{},
+			numRows:    0,
+			maxBuckets: 10,
+			buckets:    nil,
+		},
+		{
+			samples:    []int{1, 2, 4, 5, 5, 9},
+			numRows:    6,
+			maxBuckets: 3,
+			buckets: []expBucket{
+				{upper: 2, numEq: 1, numRange: 1},
+				{upper: 5, numEq: 2, numRange: 1},
+				{upper: 9, numEq: 1, numRange: 0},
+			},
+		},
+		{
+			// Same as above, but the samples are shuffled and each one stands
+			// for ten rows.
+			samples:    []int{5, 9, 1, 4, 2, 5},
+			numRows:    60,
+			maxBuckets: 3,
+			buckets: []expBucket{
+				{upper: 2, numEq: 10, numRange: 10},
+				{upper: 5, numEq: 20, numRange: 10},
+				{upper: 9, numEq: 10, numRange: 0},
+			},
+		},
+		{
+			// A frequent value extends its bucket past the target depth.
+			samples:    []int{1, 1, 1, 1, 2, 2},
+			numRows:    6,
+			maxBuckets: 2,
+			buckets: []expBucket{
+				{upper: 1, numEq: 4, numRange: 0},
+				{upper: 2, numEq: 2, numRange: 0},
+			},
+		},
+		{
+			// More buckets than samples.
+			samples:    []int{3, 1},
+			numRows:    2,
+			maxBuckets: 10,
+			buckets: []expBucket{
+				{upper: 1, numEq: 1, numRange: 0},
+				{upper: 3, numEq: 1, numRange: 0},
+			},
+		},
+	}
+
+	evalCtx := parser.NewTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+
+	for i, tc := range testCases {
+		samples := make(parser.Datums, len(tc.samples))
+		for j := range samples {
+			samples[j] = parser.NewDInt(parser.DInt(tc.samples[j]))
+		}
+		h, err := EquiDepthHistogram(evalCtx, samples, tc.numRows, tc.maxBuckets)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		var buckets []expBucket
+		for _, b := range h.Buckets {
+			var a sqlbase.DatumAlloc
+			datum, _, err := sqlbase.DecodeTableKey(&a, types.Int, b.UpperBound, encoding.Ascending)
+			if err != nil {
+				t.Fatalf("%d: %s", i, err)
+			}
+			buckets = append(buckets, expBucket{
+				upper:    int(*datum.(*parser.DInt)),
+				numEq:    b.NumEq,
+				numRange: b.NumRange,
+			})
+		}
+		if !reflect.DeepEqual(buckets, tc.buckets) {
+			t.Errorf("%d: expected buckets %v, got %v", i, tc.buckets, buckets)
+		}
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		one := parser.Datums{parser.NewDInt(1)}
+		if _, err := EquiDepthHistogram(evalCtx, one, 1, 1); err == nil {
+			t.Error("expected error with a single bucket")
+		}
+		if _, err := EquiDepthHistogram(evalCtx, parser.Datums{parser.NewDInt(1), parser.NewDInt(2)}, 1, 2); err == nil {
+			t.Error("expected error with more samples than rows")
+		}
+		if _, err := EquiDepthHistogram(evalCtx, parser.Datums{parser.DNull}, 1, 2); err == nil {
+			t.Error("expected error with NULL samples")
+		}
+	})
+}
+
+func TestHistogramEstimates(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	encode := func(v int) []byte {
+		key, err := sqlbase.EncodeTableKey(nil, parser.NewDInt(parser.DInt(v)), encoding.Ascending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	// Buckets: (-inf, 2] with 10 rows below 2 and 10 rows equal to 2,
+	// (2, 5] with 10 rows in between and 20 rows equal to 5, and (5, 9]
+	// with 10 rows equal to 9.
+	h := HistogramData{
+		Buckets: []HistogramData_Bucket{
+			{UpperBound: encode(2), NumEq: 10, NumRange: 10},
+			{UpperBound: encode(5), NumEq: 20, NumRange: 10},
+			{UpperBound: encode(9), NumEq: 10, NumRange: 0},
+		},
+	}
+
+	if total := h.TotalRows(); total != 60 {
+		t.Errorf("expected 60 total rows, got %f", total)
+	}
+
+	testCases := []struct {
+		value       int
+		lt, le, cnt float64
+		boundary    bool
+	}{
+		{value: 0, lt: 5, le: 5, cnt: 5},
+		{value: 2, lt: 10, le: 20, cnt: 10, boundary: true},
+		{value: 3, lt: 25, le: 25, cnt: 5},
+		{value: 5, lt: 30, le: 50, cnt: 20, boundary: true},
+		{value: 9, lt: 50, le: 60, cnt: 10, boundary: true},
+		{value: 10, lt: 60, le: 60, cnt: 0},
+	}
+	for _, tc := range testCases {
+		key := encode(tc.value)
+		if lt := h.RowsLessThan(key, false /* inclusive */); lt != tc.lt {
+			t.Errorf("%d: expected %f rows less than, got %f", tc.value, tc.lt, lt)
+		}
+		if le := h.RowsLessThan(key, true /* inclusive */); le != tc.le {
+			t.Errorf("%d: expected %f rows less than or equal, got %f", tc.value, tc.le, le)
+		}
+		cnt, boundary := h.ValueCount(key, 2 /* distinctPerBucket */)
+		if cnt != tc.cnt || boundary != tc.boundary {
+			t.Errorf("%d: expected count %f (boundary %t), got %f (%t)",
+				tc.value, tc.cnt, tc.boundary, cnt, boundary)
+		}
+	}
+}
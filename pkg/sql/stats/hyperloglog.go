@@ -0,0 +1,180 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultHLLPrecision is the number of bits of the hash used to select a
+	// register. 2^14 registers yield a standard error of about 0.8%.
+	DefaultHLLPrecision = 14
+
+	minHLLPrecision = 4
+	maxHLLPrecision = 18
+
+	// hllEncodingVersion is the first byte of the binary encoding of a
+	// HyperLogLog sketch.
+	hllEncodingVersion = 1
+)
+
+// HyperLogLog is a sketch that estimates the number of distinct values in a
+// multiset using a fixed amount of memory. Values are hashed, and each hash
+// updates one of 2^precision registers with the position of its leftmost 1
+// bit; the registers are then combined into an estimate. See "HyperLogLog:
+// the analysis of a near-optimal cardinality estimation algorithm" by
+// Flajolet et al.
+//
+// Two sketches with the same precision can be merged; the result is the
+// sketch of the union of the two multisets. This allows sketches to be built
+// in parallel and combined afterwards.
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog creates an empty sketch with 2^precision registers.
+func NewHyperLogLog(precision uint8) (*HyperLogLog, error) {
+	if precision < minHLLPrecision || precision > maxHLLPrecision {
+		return nil, errors.Errorf(
+			"HyperLogLog precision must be between %d and %d, got %d",
+			minHLLPrecision, maxHLLPrecision, precision,
+		)
+	}
+	return &HyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}, nil
+}
+
+// Insert adds a value, given by its 64-bit hash, to the sketch. The hash must
+// be uniformly distributed across all 64 bits.
+func (h *HyperLogLog) Insert(hash uint64) {
+	idx := hash >> (64 - h.precision)
+	// Set a sentinel bit so that the rank is bounded by 64-precision+1.
+	w := hash<<h.precision | 1<<(h.precision-1)
+	rank := uint8(bits.LeadingZeros64(w)) + 1
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// InsertBytes hashes a value and adds it to the sketch.
+func (h *HyperLogLog) InsertBytes(b []byte) {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write(b)
+	h.Insert(mix64(hasher.Sum64()))
+}
+
+// mix64 is the finalizer of MurmurHash3. It spreads the entropy of the FNV
+// hash, which is weak in the high bits, across the whole word.
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Merge updates the sketch to also account for the values inserted into
+// other.
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if h.precision != other.precision {
+		return errors.Errorf(
+			"cannot merge HyperLogLog sketches of different precisions (%d and %d)",
+			h.precision, other.precision,
+		)
+	}
+	for i, r := range other.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// Estimate returns the estimated number of distinct values that were inserted
+// into the sketch.
+func (h *HyperLogLog) Estimate() uint64 {
+	m := float64(len(h.registers))
+	var sum float64
+	var zeros int
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	est := hllAlpha(len(h.registers)) * m * m / sum
+	// Use linear counting for small cardinalities, where the raw estimate is
+	// known to be biased. No correction is needed for large cardinalities
+	// since the hash has 64 bits.
+	if est <= 2.5*m && zeros > 0 {
+		est = m * math.Log(m/float64(zeros))
+	}
+	return uint64(est + 0.5)
+}
+
+func hllAlpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(m))
+	}
+}
+
+// MarshalBinary encodes the sketch. It implements
+// encoding.BinaryMarshaler.
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 2+len(h.registers))
+	data[0] = hllEncodingVersion
+	data[1] = h.precision
+	copy(data[2:], h.registers)
+	return data, nil
+}
+
+// UnmarshalBinary decodes a sketch encoded with MarshalBinary. It implements
+// encoding.BinaryUnmarshaler.
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errors.Errorf("invalid HyperLogLog encoding: too short")
+	}
+	if data[0] != hllEncodingVersion {
+		return errors.Errorf("unknown HyperLogLog encoding version %d", data[0])
+	}
+	precision := data[1]
+	if precision < minHLLPrecision || precision > maxHLLPrecision {
+		return errors.Errorf("invalid HyperLogLog precision %d", precision)
+	}
+	if len(data)-2 != 1<<precision {
+		return errors.Errorf(
+			"invalid HyperLogLog encoding: expected %d registers, got %d", 1<<precision, len(data)-2,
+		)
+	}
+	h.precision = precision
+	h.registers = append(h.registers[:0], data[2:]...)
+	return nil
+}
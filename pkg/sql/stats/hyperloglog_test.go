@@ -0,0 +1,110 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func insertRange(h *HyperLogLog, start, end uint64) {
+	var buf [8]byte
+	for i := start; i < end; i++ {
+		binary.BigEndian.PutUint64(buf[:], i)
+		h.InsertBytes(buf[:])
+	}
+}
+
+func checkEstimate(t *testing.T, h *HyperLogLog, expected uint64) {
+	t.Helper()
+	est := h.Estimate()
+	// With 2^14 registers the standard error is about 0.8%; allow for a few
+	// standard deviations.
+	if diff := math.Abs(float64(est) - float64(expected)); diff > 0.03*float64(expected) {
+		t.Errorf("expected estimate close to %d, got %d", expected, est)
+	}
+}
+
+func TestHyperLogLog(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, n := range []uint64{0, 1, 10, 1000, 50000, 200000} {
+		h, err := NewHyperLogLog(DefaultHLLPrecision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		insertRange(h, 0, n)
+		// Inserting duplicates must not change the estimate.
+		insertRange(h, 0, n/2)
+		checkEstimate(t, h, n)
+	}
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	a, err := NewHyperLogLog(DefaultHLLPrecision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewHyperLogLog(DefaultHLLPrecision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insertRange(a, 0, 30000)
+	insertRange(b, 20000, 60000)
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	checkEstimate(t, a, 60000)
+
+	c, err := NewHyperLogLog(DefaultHLLPrecision - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Merge(c); err == nil {
+		t.Fatal("expected error merging sketches of different precisions")
+	}
+}
+
+func TestHyperLogLogEncoding(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	h, err := NewHyperLogLog(DefaultHLLPrecision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insertRange(h, 0, 5000)
+	data, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded HyperLogLog
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if a, e := decoded.Estimate(), h.Estimate(); a != e {
+		t.Errorf("expected estimate %d after decoding, got %d", e, a)
+	}
+
+	for _, bad := range [][]byte{nil, {hllEncodingVersion}, {2, DefaultHLLPrecision}, data[:len(data)-1]} {
+		if err := decoded.UnmarshalBinary(bad); err == nil {
+			t.Errorf("expected error decoding %v", bad)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"container/heap"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// SampledRow is a row that was sampled.
+type SampledRow struct {
+	Row  sqlbase.EncDatumRow
+	Rank uint64
+}
+
+// SampleReservoir implements reservoir sampling using random sort. Each row
+// is assigned a rank (which should be a uniformly generated random value),
+// and the rows with the smallest K ranks are retained.
+//
+// This is implemented as a max-heap of the smallest K ranks; a new row can
+// only replace the row with the maximum rank. Heap operations only happen
+// when a row is among the smallest K so far, which for N rows has probability
+// K/N; the overall running time for a stream of N rows is O(N + K log^2 K).
+//
+// Since the ranks are preserved, the same structure can be used to combine
+// sample sets produced in parallel, as long as each of them retained at least
+// as many rows as this reservoir.
+type SampleReservoir struct {
+	samples  []SampledRow
+	size     int
+	colTypes []sqlbase.ColumnType
+	da       sqlbase.DatumAlloc
+}
+
+var _ heap.Interface = &SampleReservoir{}
+
+// Init initializes a SampleReservoir which retains up to numSamples rows of
+// the given column types.
+func (sr *SampleReservoir) Init(numSamples int, colTypes []sqlbase.ColumnType) {
+	sr.samples = make([]SampledRow, 0, numSamples)
+	sr.size = numSamples
+	sr.colTypes = colTypes
+}
+
+// Len is part of heap.Interface.
+func (sr *SampleReservoir) Len() int {
+	return len(sr.samples)
+}
+
+// Less is part of heap.Interface. The comparison is inverted so that the
+// heap top has the largest rank.
+func (sr *SampleReservoir) Less(i, j int) bool {
+	return sr.samples[i].Rank > sr.samples[j].Rank
+}
+
+// Swap is part of heap.Interface.
+func (sr *SampleReservoir) Swap(i, j int) {
+	sr.samples[i], sr.samples[j] = sr.samples[j], sr.samples[i]
+}
+
+// Push is part of heap.Interface, but we're not using it.
+func (sr *SampleReservoir) Push(x interface{}) { panic("unimplemented") }
+
+// Pop is part of heap.Interface, but we're not using it.
+func (sr *SampleReservoir) Pop() interface{} { panic("unimplemented") }
+
+// SampleRow looks at a row and either drops it or adds it to the reservoir.
+// The row is copied if it is retained, so the caller is free to reuse it.
+func (sr *SampleReservoir) SampleRow(row sqlbase.EncDatumRow, rank uint64) error {
+	if len(sr.samples) < sr.size {
+		rowCopy := make(sqlbase.EncDatumRow, len(row))
+		if err := sr.copyRow(rowCopy, row); err != nil {
+			return err
+		}
+		sr.samples = append(sr.samples, SampledRow{Row: rowCopy, Rank: rank})
+		if len(sr.samples) == sr.size {
+			heap.Init(sr)
+		}
+		return nil
+	}
+	// Replace the max rank if ours is smaller.
+	if len(sr.samples) > 0 && rank < sr.samples[0].Rank {
+		if err := sr.copyRow(sr.samples[0].Row, row); err != nil {
+			return err
+		}
+		sr.samples[0].Rank = rank
+		heap.Fix(sr, 0)
+	}
+	return nil
+}
+
+// copyRow copies the decoded values of src into dst. The encoded form of a
+// row can reference memory owned by the producer of the row, so only the
+// datums are retained.
+func (sr *SampleReservoir) copyRow(dst, src sqlbase.EncDatumRow) error {
+	for i := range src {
+		if err := src[i].EnsureDecoded(&sr.colTypes[i], &sr.da); err != nil {
+			return err
+		}
+		dst[i] = sqlbase.DatumToEncDatum(sr.colTypes[i], src[i].Datum)
+	}
+	return nil
+}
+
+// Get returns the sampled rows, in no particular order.
+func (sr *SampleReservoir) Get() []SampledRow {
+	return sr.samples
+}
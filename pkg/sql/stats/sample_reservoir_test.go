@@ -0,0 +1,85 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func runSampleTest(t *testing.T, numSamples int, ranks []uint64) {
+	typeInt := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+	var sr SampleReservoir
+	sr.Init(numSamples, []sqlbase.ColumnType{typeInt})
+	row := make(sqlbase.EncDatumRow, 1)
+	for _, r := range ranks {
+		// The row value is the rank; the reservoir must copy it.
+		row[0] = sqlbase.DatumToEncDatum(typeInt, parser.NewDInt(parser.DInt(r)))
+		if err := sr.SampleRow(row, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	samples := sr.Get()
+	sampledRanks := make([]uint64, len(samples))
+
+	// Verify that the row and the ranks weren't mishandled.
+	for i, s := range samples {
+		if *s.Row[0].Datum.(*parser.DInt) != parser.DInt(s.Rank) {
+			t.Fatalf(
+				"mismatch between row %s and rank %d",
+				s.Row.String([]sqlbase.ColumnType{typeInt}), s.Rank,
+			)
+		}
+		sampledRanks[i] = s.Rank
+	}
+
+	// Verify that we got the top ranks.
+	sorted := append([]uint64(nil), ranks...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	sort.Slice(sampledRanks, func(i, j int) bool { return sampledRanks[i] < sampledRanks[j] })
+	if len(sorted) > numSamples {
+		sorted = sorted[:numSamples]
+	}
+	if len(sorted) != len(sampledRanks) {
+		t.Fatalf("expected %d samples, got %d", len(sorted), len(sampledRanks))
+	}
+	for i := range sorted {
+		if sorted[i] != sampledRanks[i] {
+			t.Fatalf("expected ranks %v, got %v", sorted, sampledRanks)
+		}
+	}
+}
+
+func TestSampleReservoir(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng, _ := randutil.NewPseudoRand()
+	for _, n := range []int{10, 100, 1000, 10000} {
+		for _, k := range []int{1, 5, 10, 100} {
+			ranks := make([]uint64, n)
+			for i := range ranks {
+				ranks[i] = uint64(rng.Int63())
+			}
+			runSampleTest(t, k, ranks)
+		}
+	}
+	// Fewer rows than the reservoir size.
+	runSampleTest(t, 100, []uint64{uint64(rng.Int63()), uint64(rng.Int63())})
+}
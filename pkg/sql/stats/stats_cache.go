@@ -0,0 +1,188 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// TableStatistic is a statistic on a set of columns of a table, as stored
+// in system.table_statistics.
+type TableStatistic struct {
+	TableID       sqlbase.ID
+	StatisticID   uint64
+	Name          string
+	ColumnIDs     []sqlbase.ColumnID
+	CreatedAt     time.Time
+	RowCount      uint64
+	DistinctCount uint64
+	NullCount     uint64
+	// Histogram is nil if no histogram was collected.
+	Histogram *HistogramData
+}
+
+// tableStatsCacheEntryTTL is how long the statistics of a table are cached
+// before they are read again. Statistics created on this node invalidate
+// the cache entry immediately; this bounds how long it takes to observe
+// statistics created on other nodes.
+const tableStatsCacheEntryTTL = time.Minute
+
+type tableStatsCacheEntry struct {
+	stats     []*TableStatistic
+	fetchedAt time.Time
+}
+
+// TableStatisticsCache is a cache of the statistics of recently used tables.
+// It is safe for concurrent use by multiple goroutines.
+type TableStatisticsCache struct {
+	mu struct {
+		syncutil.Mutex
+		cache *cache.UnorderedCache
+	}
+	db       *client.DB
+	executor sqlutil.InternalExecutor
+}
+
+// NewTableStatisticsCache creates a new TableStatisticsCache that retains
+// the statistics of up to cacheSize tables.
+func NewTableStatisticsCache(
+	cacheSize int, db *client.DB, executor sqlutil.InternalExecutor,
+) *TableStatisticsCache {
+	sc := &TableStatisticsCache{db: db, executor: executor}
+	sc.mu.cache = cache.NewUnorderedCache(cache.Config{
+		Policy: cache.CacheLRU,
+		ShouldEvict: func(s int, key, value interface{}) bool {
+			return s > cacheSize
+		},
+	})
+	return sc
+}
+
+// GetTableStats returns the statistics of the given table, most recent
+// first. It returns an empty slice if the table has no statistics.
+func (sc *TableStatisticsCache) GetTableStats(
+	ctx context.Context, tableID sqlbase.ID,
+) ([]*TableStatistic, error) {
+	if stats, ok := sc.lookup(tableID); ok {
+		return stats, nil
+	}
+	var stats []*TableStatistic
+	if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		stats, err = sc.readTableStats(ctx, txn, tableID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.mu.cache.Add(tableID, &tableStatsCacheEntry{stats: stats, fetchedAt: timeutil.Now()})
+	return stats, nil
+}
+
+// InvalidateTableStats removes the statistics of the given table from the
+// cache, so that they are read again on the next access.
+func (sc *TableStatisticsCache) InvalidateTableStats(tableID sqlbase.ID) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.mu.cache.Del(tableID)
+}
+
+func (sc *TableStatisticsCache) lookup(tableID sqlbase.ID) ([]*TableStatistic, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	v, ok := sc.mu.cache.Get(tableID)
+	if !ok {
+		return nil, false
+	}
+	e := v.(*tableStatsCacheEntry)
+	if timeutil.Since(e.fetchedAt) > tableStatsCacheEntryTTL {
+		sc.mu.cache.Del(tableID)
+		return nil, false
+	}
+	return e.stats, true
+}
+
+func (sc *TableStatisticsCache) readTableStats(
+	ctx context.Context, txn *client.Txn, tableID sqlbase.ID,
+) ([]*TableStatistic, error) {
+	rows, err := sc.executor.QueryRowsInTransaction(
+		ctx,
+		"get-table-statistics",
+		txn,
+		`SELECT "statisticID", name, "columnIDs", "createdAt", "rowCount",
+		        "distinctCount", "nullCount", histogram
+		   FROM system.table_statistics
+		  WHERE "tableID" = $1
+		  ORDER BY "createdAt" DESC, "statisticID" DESC`,
+		parser.NewDInt(parser.DInt(tableID)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*TableStatistic, 0, len(rows))
+	for _, r := range rows {
+		const (
+			statIDIdx = iota
+			nameIdx
+			columnIDsIdx
+			createdAtIdx
+			rowCountIdx
+			distinctCountIdx
+			nullCountIdx
+			histogramIdx
+			numCols
+		)
+		if len(r) != numCols {
+			return nil, errors.Errorf("%d values returned from table statistics lookup; expected %d",
+				len(r), numCols)
+		}
+		s := &TableStatistic{
+			TableID:       tableID,
+			StatisticID:   uint64(*r[statIDIdx].(*parser.DInt)),
+			CreatedAt:     r[createdAtIdx].(*parser.DTimestamp).Time,
+			RowCount:      uint64(*r[rowCountIdx].(*parser.DInt)),
+			DistinctCount: uint64(*r[distinctCountIdx].(*parser.DInt)),
+			NullCount:     uint64(*r[nullCountIdx].(*parser.DInt)),
+		}
+		if r[nameIdx] != parser.DNull {
+			s.Name = string(*r[nameIdx].(*parser.DString))
+		}
+		for _, d := range r[columnIDsIdx].(*parser.DArray).Array {
+			s.ColumnIDs = append(s.ColumnIDs, sqlbase.ColumnID(*d.(*parser.DInt)))
+		}
+		if r[histogramIdx] != parser.DNull {
+			s.Histogram = &HistogramData{}
+			if err := s.Histogram.Unmarshal([]byte(*r[histogramIdx].(*parser.DBytes))); err != nil {
+				return nil, err
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
@@ -118,6 +118,9 @@ func TestSystemTableLiterals(t *testing.T) {
 		{keys.JobsTableID, sqlbase.JobsTableSchema, sqlbase.JobsTable},
 		{keys.SettingsTableID, sqlbase.SettingsTableSchema, sqlbase.SettingsTable},
 		{keys.WebSessionsTableID, sqlbase.WebSessionsTableSchema, sqlbase.WebSessionsTable},
+		{keys.StatementHintsTableID, sqlbase.StatementHintsTableSchema, sqlbase.StatementHintsTable},
+		{keys.RoleMembersTableID, sqlbase.RoleMembersTableSchema, sqlbase.RoleMembersTable},
+		{keys.TableStatisticsTableID, sqlbase.TableStatisticsTableSchema, sqlbase.TableStatisticsTable},
 	} {
 		gen, err := sql.CreateTestTableDescriptor(
 			context.TODO(),
@@ -182,6 +182,24 @@ func getViewDesc(
 	return desc, nil
 }
 
+// getSequenceDesc returns a table descriptor for a sequence, or nil if the
+// descriptor is not found.
+//
+// Returns an error if the underlying table descriptor actually
+// represents a table or a view rather than a sequence.
+func getSequenceDesc(
+	ctx context.Context, txn *client.Txn, vt VirtualTabler, tn *parser.TableName,
+) (*sqlbase.TableDescriptor, error) {
+	desc, err := getTableOrViewDesc(ctx, txn, vt, tn)
+	if err != nil {
+		return desc, err
+	}
+	if desc != nil && !desc.IsSequence() {
+		return nil, sqlbase.NewWrongObjectTypeError(tn, "sequence")
+	}
+	return desc, nil
+}
+
 // MustGetTableOrViewDesc returns a table descriptor for either a table or
 // view, or an error if the descriptor is not found. allowAdding when set allows
 // a table descriptor in the ADD state to also be returned.
@@ -562,7 +580,9 @@ func getTableNames(
 	return tableNames, nil
 }
 
-func (p *planner) getAliasedTableName(n parser.TableExpr) (*parser.TableName, error) {
+func (p *planner) getAliasedTableName(
+	ctx context.Context, n parser.TableExpr,
+) (*parser.TableName, error) {
 	if ate, ok := n.(*parser.AliasedTableExpr); ok {
 		n = ate.Expr
 	}
@@ -570,7 +590,7 @@ func (p *planner) getAliasedTableName(n parser.TableExpr) (*parser.TableName, er
 	if !ok {
 		return nil, errors.Errorf("TODO(pmattis): unsupported FROM: %s", n)
 	}
-	return table.NormalizeWithDatabaseName(p.session.Database)
+	return p.normalizeTableName(ctx, table)
 }
 
 // createSchemaChangeJob finalizes the current mutations in the table
@@ -610,6 +630,33 @@ func (p *planner) createSchemaChangeJob(
 	return mutationID, nil
 }
 
+// runStatementJob runs fn, which performs the work of a statement on the
+// given descriptor, under a job tracking it. The work uses the statement's
+// transaction, but the job is updated outside of it: the statements using
+// runStatementJob reject explicit transactions, so that the job does not
+// report success for work that is later rolled back.
+func (p *planner) runStatementJob(
+	ctx context.Context, stmt string, descID sqlbase.ID, details jobs.Details, fn func() error,
+) error {
+	job := p.ExecCfg().JobRegistry.NewJob(jobs.Record{
+		Description:   stmt,
+		Username:      p.User(),
+		DescriptorIDs: sqlbase.IDs{descID},
+		Details:       details,
+	})
+	if err := job.Created(ctx, jobs.WithoutCancel); err != nil {
+		return err
+	}
+	if err := job.Started(ctx); err != nil {
+		return err
+	}
+	fnErr := fn()
+	if err := job.FinishedWith(ctx, fnErr); err != nil {
+		return err
+	}
+	return fnErr
+}
+
 // notifySchemaChange implements the SchemaAccessor interface.
 func (p *planner) notifySchemaChange(
 	tableDesc *sqlbase.TableDescriptor, mutationID sqlbase.MutationID,
@@ -689,6 +736,11 @@ func expandTableGlob(
 // left unchanged otherwise.
 // The table name must not be qualified already.
 func (p *planner) searchAndQualifyDatabase(ctx context.Context, tn *parser.TableName) error {
+	// The session's temporary tables shadow all other tables.
+	if found, err := p.lookupTempTable(ctx, tn); err != nil || found {
+		return err
+	}
+
 	t := *tn
 
 	descFunc := p.session.tables.getTableVersion
@@ -804,7 +856,7 @@ func (p *planner) findTableContainingIndex(
 func (p *planner) expandIndexName(
 	ctx context.Context, index *parser.TableNameWithIndex, requireTable bool,
 ) (*parser.TableName, error) {
-	tn, err := index.Table.NormalizeWithDatabaseName(p.session.Database)
+	tn, err := p.normalizeTableName(ctx, &index.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -854,7 +906,7 @@ func (p *planner) getTableAndIndex(
 	var err error
 	if tableWithIndex == nil {
 		// Variant: ALTER TABLE
-		tn, err = table.NormalizeWithDatabaseName(p.session.Database)
+		tn, err = p.normalizeTableName(ctx, table)
 	} else {
 		// Variant: ALTER INDEX
 		tn, err = p.expandIndexName(ctx, tableWithIndex, true /* requireTable */)
@@ -0,0 +1,392 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// The temporary tables of a session live in a database of their own, which
+// plays the role of Postgres' per-session pg_temp schema. The database is
+// named pg_temp_<node ID>_<session ID>, where the session ID is the HLC
+// timestamp at which the session was created. The name is generated when the
+// session starts, but the database is only created along with the session's
+// first temporary table. It is dropped when the session ends; the databases
+// of sessions which didn't get to drop them (e.g. because their node died)
+// are dropped by a background sweeper.
+const tempDatabasePrefix = "pg_temp_"
+
+// DefaultTempTableSweepInterval is a reasonable interval at which to look for
+// the temporary tables of sessions which are no longer running.
+//
+// DefaultTempTableSweepInterval is mutable for testing. NB: Updates to this
+// value after Executor.StartTempTableSweeper has been called will not have any
+// effect.
+var DefaultTempTableSweepInterval = 5 * time.Minute
+
+var errTempTablesOfOtherSession = pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+	"cannot access temporary tables of other sessions")
+
+func makeTempDatabaseName(nodeID roachpb.NodeID, sessionTS hlc.Timestamp) string {
+	return fmt.Sprintf("%s%d_%d_%d", tempDatabasePrefix, nodeID, sessionTS.WallTime, sessionTS.Logical)
+}
+
+// isTempDatabaseName returns true if name is the name of the temporary
+// database of some session.
+func isTempDatabaseName(name string) bool {
+	return strings.HasPrefix(name, tempDatabasePrefix)
+}
+
+// tempDatabaseNodeID returns the ID of the node of the session that a
+// temporary database belongs to.
+func tempDatabaseNodeID(name string) (roachpb.NodeID, bool) {
+	parts := strings.Split(strings.TrimPrefix(name, tempDatabasePrefix), "_")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	nodeID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+	return roachpb.NodeID(nodeID), true
+}
+
+// canSeeTempDatabase returns false if dbName is the temporary database of
+// another session.
+func (s *Session) canSeeTempDatabase(dbName string) bool {
+	return !isTempDatabaseName(dbName) || dbName == s.tempDatabaseName
+}
+
+// qualifyTableName qualifies a table name like tn.QualifyWithDatabase() does
+// with the session's current database, except that the names of the
+// session's temporary tables are qualified with its temporary database:
+// temporary tables shadow the tables of the current database. Names
+// explicitly qualified with the temporary database of another session are
+// rejected.
+func (p *planner) qualifyTableName(ctx context.Context, tn *parser.TableName) error {
+	if !tn.DBNameOriginallyOmitted {
+		if !p.session.canSeeTempDatabase(tn.Database()) {
+			return errTempTablesOfOtherSession
+		}
+		return nil
+	}
+	if found, err := p.lookupTempTable(ctx, tn); err != nil || found {
+		return err
+	}
+	return tn.QualifyWithDatabase(p.session.Database)
+}
+
+// normalizeTableName normalizes a table name and qualifies it using
+// qualifyTableName().
+func (p *planner) normalizeTableName(
+	ctx context.Context, nt *parser.NormalizableTableName,
+) (*parser.TableName, error) {
+	tn, err := nt.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.qualifyTableName(ctx, tn); err != nil {
+		return nil, err
+	}
+	return tn, nil
+}
+
+// lookupTempTable qualifies an unqualified table name with the session's
+// temporary database if the session has a temporary table, view or sequence
+// by that name. It returns false if there's no such object.
+func (p *planner) lookupTempTable(ctx context.Context, tn *parser.TableName) (bool, error) {
+	if !p.session.hasTempTables {
+		return false, nil
+	}
+	t := *tn
+	t.DatabaseName = parser.Name(p.session.tempDatabaseName)
+	t.DBNameOriginallyOmitted = false
+	desc, err := getTableOrViewDesc(ctx, p.txn, p.getVirtualTabler(), &t)
+	if err != nil && !sqlbase.IsUndefinedRelationError(err) && !sqlbase.IsUndefinedDatabaseError(err) {
+		return false, err
+	}
+	if desc == nil {
+		return false, nil
+	}
+	*tn = t
+	return true, nil
+}
+
+// qualifyTempTableName qualifies the name of a temporary table being created
+// with the session's temporary database. Like in Postgres, temporary tables
+// can't be created in any other database.
+func (p *planner) qualifyTempTableName(tn *parser.TableName) error {
+	if !tn.DBNameOriginallyOmitted && tn.Database() != p.session.tempDatabaseName {
+		return pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+			"cannot create temporary table %s in database %s", tn.Table(), tn.Database())
+	}
+	tn.DatabaseName = parser.Name(p.session.tempDatabaseName)
+	tn.DBNameOriginallyOmitted = false
+	return nil
+}
+
+// getOrCreateTempDatabase returns the descriptor of the session's temporary
+// database, creating the database if it doesn't exist yet. The session's user
+// is granted all privileges on the database.
+func (p *planner) getOrCreateTempDatabase(
+	ctx context.Context,
+) (*sqlbase.DatabaseDescriptor, error) {
+	name := p.session.tempDatabaseName
+	desc, err := getDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), name)
+	if err != nil {
+		return nil, err
+	}
+	if desc == nil {
+		desc = &sqlbase.DatabaseDescriptor{
+			Name:       name,
+			Privileges: sqlbase.NewDefaultPrivilegeDescriptor(),
+		}
+		desc.Privileges.Grant(p.session.User, privilege.List{privilege.ALL})
+		if _, err := p.createDatabase(ctx, desc, false /* ifNotExists */); err != nil {
+			return nil, err
+		}
+		p.session.tables.addUncommittedDatabase(desc.Name, desc.ID, false /* dropped */)
+	}
+	p.session.hasTempTables = true
+	return desc, nil
+}
+
+// checkTempTableReferences verifies that the tables referenced by the foreign
+// keys and interleaves of a table being created are temporary if and only if
+// the table is temporary itself: references between temporary and permanent
+// tables would prevent the temporary tables from being dropped along with
+// their session. The referenced table names are qualified in place.
+func (p *planner) checkTempTableReferences(ctx context.Context, n *parser.CreateTable) error {
+	check := func(nt *parser.NormalizableTableName) error {
+		tn, err := p.normalizeTableName(ctx, nt)
+		if err != nil {
+			return err
+		}
+		if isTempDatabaseName(tn.Database()) != n.Temporary {
+			if n.Temporary {
+				return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+					"constraints on temporary tables may reference only temporary tables")
+			}
+			return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"constraints on permanent tables may reference only permanent tables")
+		}
+		return nil
+	}
+	for _, def := range n.Defs {
+		switch t := def.(type) {
+		case *parser.ForeignKeyConstraintTableDef:
+			if err := check(&t.Table); err != nil {
+				return err
+			}
+		case *parser.IndexTableDef:
+			if t.Interleave != nil {
+				if err := check(t.Interleave.Parent); err != nil {
+					return err
+				}
+			}
+		case *parser.UniqueConstraintTableDef:
+			if t.Interleave != nil {
+				if err := check(t.Interleave.Parent); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if n.Interleave != nil {
+		return check(n.Interleave.Parent)
+	}
+	return nil
+}
+
+// sessionTempDatabaseID returns the ID of the session's temporary database,
+// or 0 if the session hasn't created it.
+func (p *planner) sessionTempDatabaseID(ctx context.Context) (sqlbase.ID, error) {
+	if !p.session.hasTempTables {
+		return 0, nil
+	}
+	desc, err := getDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), p.session.tempDatabaseName)
+	if err != nil || desc == nil {
+		return 0, err
+	}
+	return desc.ID, nil
+}
+
+// checkTempViewDependencies verifies that a permanent view being created in
+// the database with ID parentID doesn't depend on temporary tables: the view
+// would be dropped along with the session's temporary database.
+func (p *planner) checkTempViewDependencies(
+	ctx context.Context, parentID sqlbase.ID, planDeps planDependencies,
+) error {
+	tempID, err := p.sessionTempDatabaseID(ctx)
+	if err != nil || tempID == 0 || parentID == tempID {
+		return err
+	}
+	for _, dep := range planDeps {
+		if dep.desc.ParentID == tempID {
+			return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"permanent views may not reference temporary tables")
+		}
+	}
+	return nil
+}
+
+// checkTempSequenceDependency verifies that the default expressions of a
+// permanent table don't use a temporary sequence: the defaults would be
+// removed along with the session's temporary database.
+func (p *planner) checkTempSequenceDependency(
+	ctx context.Context, tableDesc, seqDesc *sqlbase.TableDescriptor,
+) error {
+	if tableDesc.ParentID == seqDesc.ParentID {
+		return nil
+	}
+	tempID, err := p.sessionTempDatabaseID(ctx)
+	if err != nil {
+		return err
+	}
+	if tempID != 0 && seqDesc.ParentID == tempID {
+		return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+			"defaults of permanent tables may use only permanent sequences")
+	}
+	return nil
+}
+
+// checkTempFKReference verifies that the table referenced by a foreign key
+// being added to a table is temporary if and only if the table is temporary
+// itself, like checkTempTableReferences does for the tables being created.
+func (p *planner) checkTempFKReference(
+	ctx context.Context, tableDesc, referencedDesc *sqlbase.TableDescriptor,
+) error {
+	tempID, err := p.sessionTempDatabaseID(ctx)
+	if err != nil || tempID == 0 {
+		return err
+	}
+	if isTemp := tableDesc.ParentID == tempID; isTemp != (referencedDesc.ParentID == tempID) {
+		if isTemp {
+			return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+				"constraints on temporary tables may reference only temporary tables")
+		}
+		return pgerror.NewError(pgerror.CodeInvalidTableDefinitionError,
+			"constraints on permanent tables may reference only permanent tables")
+	}
+	return nil
+}
+
+// dropTempDatabase drops a temporary database along with all its tables.
+func (e *Executor) dropTempDatabase(ctx context.Context, name string) error {
+	ie := InternalExecutor{LeaseManager: e.cfg.LeaseManager}
+	return e.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		_, err := ie.ExecuteStatementInTransaction(ctx, "drop-temp-tables", txn,
+			fmt.Sprintf("DROP DATABASE IF EXISTS %s CASCADE", parser.Name(name).String()))
+		return err
+	})
+}
+
+// nodeLiveness is the subset of storage.NodeLiveness's interface needed by
+// the temporary table sweeper.
+type nodeLiveness interface {
+	GetLivenesses() []storage.Liveness
+}
+
+// StartTempTableSweeper periodically drops the temporary databases of the
+// sessions which are no longer running: the sessions of this node which
+// aren't registered anymore and the sessions of nodes which are dead.
+func (e *Executor) StartTempTableSweeper(
+	stopper *stop.Stopper, nl nodeLiveness, sweepInterval time.Duration,
+) {
+	ctx := e.cfg.AmbientCtx.AnnotateCtx(context.Background())
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		for {
+			select {
+			case <-time.After(sweepInterval):
+				if err := e.sweepTempDatabases(ctx, nl); err != nil {
+					log.Warningf(ctx, "error while dropping orphaned temporary tables: %s", err)
+				}
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+func (e *Executor) sweepTempDatabases(ctx context.Context, nl nodeLiveness) error {
+	var names []string
+	if err := e.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		names = names[:0]
+		dbDescs, err := getAllDatabaseDescs(ctx, txn)
+		if err != nil {
+			return err
+		}
+		for _, db := range dbDescs {
+			if isTempDatabaseName(db.Name) {
+				names = append(names, db.Name)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	// A node which failed to heartbeat its liveness record for a little while
+	// may still be running its sessions. Like the store pool, only consider
+	// a node dead once its liveness record has been expired for longer than
+	// server.time_until_store_dead, or once it has been decommissioned.
+	isDead := make(map[roachpb.NodeID]bool)
+	now, maxOffset := e.cfg.Clock.Now(), e.cfg.Clock.MaxOffset()
+	deadThreshold := storage.TimeUntilStoreDead.Get(&e.cfg.Settings.SV)
+	for _, liveness := range nl.GetLivenesses() {
+		deadAsOf := hlc.Timestamp(liveness.Expiration).GoTime().Add(deadThreshold)
+		isDead[liveness.NodeID] = !now.GoTime().Before(deadAsOf) ||
+			(liveness.Decommissioning && !liveness.IsLive(now, maxOffset))
+	}
+	localNodeID := e.cfg.NodeID.Get()
+	for _, name := range names {
+		nodeID, ok := tempDatabaseNodeID(name)
+		if !ok {
+			continue
+		}
+		if nodeID == localNodeID {
+			if e.cfg.SessionRegistry.hasTempDatabase(name) {
+				continue
+			}
+		} else if !isDead[nodeID] {
+			// The node sweeps its own sessions' temporary databases.
+			continue
+		}
+		log.Infof(ctx, "dropping orphaned temporary database %s", name)
+		if err := e.dropTempDatabase(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
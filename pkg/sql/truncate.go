@@ -46,7 +46,7 @@ func (p *planner) Truncate(ctx context.Context, n *parser.Truncate) (planNode, e
 		if err != nil {
 			return nil, err
 		}
-		if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		if err := p.qualifyTableName(ctx, tn); err != nil {
 			return nil, err
 		}
 
@@ -56,9 +56,11 @@ func (p *planner) Truncate(ctx context.Context, n *parser.Truncate) (planNode, e
 		if err != nil {
 			return nil, err
 		}
-		// We don't support truncation on views, only real tables.
-		if !tableDesc.IsTable() {
+		// We don't support truncation on views or sequences, only real tables.
+		if tableDesc.IsView() {
 			return nil, errors.Errorf("cannot run TRUNCATE on view %q - views are not updateable", tn)
+		} else if tableDesc.IsSequence() {
+			return nil, errors.Errorf("cannot run TRUNCATE on sequence %q", tn)
 		}
 
 		if err := p.CheckPrivilege(tableDesc, privilege.DROP); err != nil {
@@ -256,6 +258,10 @@ func (p *planner) findAllReferences(
 		refs[c.ID] = struct{}{}
 	}
 
+	for _, id := range table.UsedSequenceIDs() {
+		refs[id] = struct{}{}
+	}
+
 	tables := make([]*sqlbase.TableDescriptor, 0, len(refs))
 	for id := range refs {
 		if id == table.ID {
@@ -318,6 +324,11 @@ func reassignReferencedTables(tables []*sqlbase.TableDescriptor, oldID, newID sq
 			}
 			table.DependedOnBy = append(table.DependedOnBy, ref)
 		}
+		for i, id := range table.SequenceDependents {
+			if id == oldID {
+				table.SequenceDependents[i] = newID
+			}
+		}
 	}
 	return nil
 }
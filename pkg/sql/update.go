@@ -53,10 +53,22 @@ func (p *planner) makeEditNode(
 	if err != nil {
 		return editNodeBase{}, err
 	}
-	// We don't support update on views, only real tables.
-	if !tableDesc.IsTable() {
+	if tableDesc.IsMaterializedView && p.populatingMaterializedView {
+		// The statements populating materialized views perform their own
+		// privilege checks.
+		return editNodeBase{
+			p:         p,
+			tableDesc: tableDesc,
+		}, nil
+	}
+
+	// We don't support update on views or sequences, only real tables.
+	if tableDesc.IsView() {
 		return editNodeBase{},
 			errors.Errorf("cannot run %s on view %q - views are not updateable", priv, tn)
+	} else if tableDesc.IsSequence() {
+		return editNodeBase{},
+			errors.Errorf("cannot run %s on sequence %q", priv, tn)
 	}
 
 	if err := p.CheckPrivilege(tableDesc, priv); err != nil {
@@ -212,7 +224,15 @@ func (p *planner) Update(
 
 	tracing.AnnotateTrace()
 
-	tn, err := p.getAliasedTableName(n.Table)
+	resetter, err := p.initWith(ctx, n.With)
+	if err != nil {
+		return nil, err
+	}
+	if resetter != nil {
+		defer resetter(p)
+	}
+
+	tn, err := p.getAliasedTableName(ctx, n.Table)
 	if err != nil {
 		return nil, err
 	}
@@ -446,7 +466,7 @@ func (u *updateNode) Next(params runParams) (bool, error) {
 
 	for i, col := range u.tw.ru.UpdateCols {
 		val := updateValues[i]
-		if !col.Nullable && val == parser.DNull {
+		if val == parser.DNull && u.tableDesc.EnforcesNotNull(&col) {
 			return false, sqlbase.NewNonNullViolationError(col.Name)
 		}
 	}
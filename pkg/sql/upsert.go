@@ -233,7 +233,9 @@ func upsertExprsAndIndex(
 	}
 
 	indexMatch := func(index sqlbase.IndexDescriptor) bool {
-		if !index.Unique {
+		// Partial unique indexes only enforce uniqueness over a subset of the
+		// rows, so conflicts can't be detected with them.
+		if !index.Unique || index.IsPartial() {
 			return false
 		}
 		if len(index.ColumnNames) != len(onConflict.Columns) {
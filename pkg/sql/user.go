@@ -24,7 +24,7 @@ import (
 )
 
 // GetUserHashedPassword returns the hashedPassword for the given username if
-// found in system.users.
+// found in system.users. Roles are not found, since they cannot log in.
 func GetUserHashedPassword(
 	ctx context.Context, executor *Executor, metrics *MemoryMetrics, username string,
 ) (bool, []byte, error) {
@@ -40,7 +40,7 @@ func GetUserHashedPassword(
 		p := makeInternalPlanner("get-pwd", txn, security.RootUser, metrics)
 		defer finishInternalPlanner(p)
 		const getHashedPassword = `SELECT "hashedPassword" FROM system.users ` +
-			`WHERE username=$1`
+			`WHERE username=$1 AND "isRole" = false`
 		values, err := p.QueryRow(ctx, getHashedPassword, normalizedUsername)
 		if err != nil {
 			return errors.Errorf("error looking up user %s", normalizedUsername)
@@ -55,3 +55,48 @@ func GetUserHashedPassword(
 
 	return exists, hashedPassword, err
 }
+
+// GetUserScramVerifier returns the SCRAM-SHA-256 verifier of the password of
+// the given user, or nil if the user has none: the verifiers of the
+// passwords set before verifiers were stored are only added when the users
+// next log in with a password.
+func GetUserScramVerifier(
+	ctx context.Context, executor *Executor, metrics *MemoryMetrics, username string,
+) ([]byte, error) {
+	var scramVerifier []byte
+	err := executor.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		p := makeInternalPlanner("get-scram-verifier", txn, security.RootUser, metrics)
+		defer finishInternalPlanner(p)
+		const getScramVerifier = `SELECT "scramVerifier" FROM system.users ` +
+			`WHERE username=$1 AND "isRole" = false`
+		values, err := p.QueryRow(ctx, getScramVerifier, username)
+		if err != nil {
+			return errors.Errorf("error looking up user %s", username)
+		}
+		if len(values) == 0 || values[0] == parser.DNull {
+			return nil
+		}
+		scramVerifier = []byte(*(values[0].(*parser.DBytes)))
+		return nil
+	})
+	return scramVerifier, err
+}
+
+// SetUserScramVerifier stores the SCRAM-SHA-256 verifier of the password of
+// the given user.
+func SetUserScramVerifier(
+	ctx context.Context,
+	executor *Executor,
+	metrics *MemoryMetrics,
+	username string,
+	scramVerifier []byte,
+) error {
+	return executor.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		p := makeInternalPlanner("set-scram-verifier", txn, security.RootUser, metrics)
+		defer finishInternalPlanner(p)
+		const setScramVerifier = `UPDATE system.users SET "scramVerifier" = $2 ` +
+			`WHERE username=$1 AND "isRole" = false`
+		_, err := p.exec(ctx, setScramVerifier, username, scramVerifier)
+		return err
+	})
+}
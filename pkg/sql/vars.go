@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/pkg/errors"
 )
@@ -222,6 +223,25 @@ var varGen = map[string]sessionVar{
 	// See https://www.postgresql.org/docs/9.6/static/runtime-config-client.html
 	`extra_float_digits`: nopVar,
 
+	`idle_in_transaction_session_timeout`: {
+		// See https://www.postgresql.org/docs/9.6/static/runtime-config-client.html#GUC-IDLE-IN-TRANSACTION-SESSION-TIMEOUT
+		Set: func(_ context.Context, session *Session, values []parser.TypedExpr) error {
+			d, err := getTimeoutVal(`idle_in_transaction_session_timeout`, session, values)
+			if err != nil {
+				return err
+			}
+			session.IdleInTxnSessionTimeout = d
+			return nil
+		},
+		Get: func(session *Session) string {
+			return session.IdleInTxnSessionTimeout.String()
+		},
+		Reset: func(session *Session) error {
+			session.IdleInTxnSessionTimeout = idleInTxnSessionTimeout.Get(&session.execCfg.Settings.SV)
+			return nil
+		},
+	},
+
 	`max_index_keys`: {
 		// Supported for PG compatibility only.
 		Get: func(*Session) string { return "32" },
@@ -231,6 +251,28 @@ var varGen = map[string]sessionVar{
 		Get: func(session *Session) string { return fmt.Sprintf("%d", session.tables.leaseMgr.nodeID.Get()) },
 	},
 
+	`reorder_joins_limit`: {
+		Set: func(_ context.Context, session *Session, values []parser.TypedExpr) error {
+			i, err := getSingleInt(`reorder_joins_limit`, session, values)
+			if err != nil {
+				return err
+			}
+			if i < 0 || i > maxReorderJoinsLimit {
+				return fmt.Errorf("set reorder_joins_limit: value must be between 0 and %d",
+					maxReorderJoinsLimit)
+			}
+			session.ReorderJoinsLimit = int(i)
+			return nil
+		},
+		Get: func(session *Session) string {
+			return strconv.Itoa(session.ReorderJoinsLimit)
+		},
+		Reset: func(session *Session) error {
+			session.ReorderJoinsLimit = defaultReorderJoinsLimit
+			return nil
+		},
+	},
+
 	`sql_safe_updates`: {
 		Get: func(session *Session) string { return strconv.FormatBool(session.SafeUpdates) },
 		Set: func(_ context.Context, session *Session, values []parser.TypedExpr) error {
@@ -302,7 +344,7 @@ var varGen = map[string]sessionVar{
 			// and not a standard name, then we use a magic format in the Location's
 			// name. We attempt to parse that here and retrieve the original offset
 			// specified by the user.
-			_, origRepr, parsed := sqlbase.ParseFixedOffsetTimeZone(session.Location.String())
+			_, origRepr, parsed := timeutil.ParseFixedOffsetTimeZone(session.Location.String())
 			if parsed {
 				return origRepr
 			}
@@ -335,6 +377,25 @@ var varGen = map[string]sessionVar{
 		Get: func(session *Session) string { return getTransactionState(&session.TxnState) },
 	},
 
+	`statement_timeout`: {
+		// See https://www.postgresql.org/docs/9.6/static/runtime-config-client.html#GUC-STATEMENT-TIMEOUT
+		Set: func(_ context.Context, session *Session, values []parser.TypedExpr) error {
+			d, err := getTimeoutVal(`statement_timeout`, session, values)
+			if err != nil {
+				return err
+			}
+			session.StatementTimeout = d
+			return nil
+		},
+		Get: func(session *Session) string {
+			return session.StatementTimeout.String()
+		},
+		Reset: func(session *Session) error {
+			session.StatementTimeout = statementTimeout.Get(&session.execCfg.Settings.SV)
+			return nil
+		},
+	},
+
 	`tracing`: {
 		Get: func(session *Session) string {
 			if session.Tracing.Enabled() {
@@ -428,3 +489,62 @@ func getSingleBool(
 	}
 	return b, nil
 }
+
+func getSingleInt(name string, session *Session, values []parser.TypedExpr) (int64, error) {
+	if len(values) != 1 {
+		return 0, fmt.Errorf("set %s requires a single argument", name)
+	}
+	evalCtx := session.evalCtx()
+	val, err := values[0].Eval(&evalCtx)
+	if err != nil {
+		return 0, err
+	}
+	i, ok := val.(*parser.DInt)
+	if !ok {
+		return 0, fmt.Errorf("set %s requires an integer value: %s is a %s",
+			name, values[0], val.ResolvedType())
+	}
+	return int64(*i), nil
+}
+
+// getTimeoutVal evaluates the value of a timeout session variable, which is
+// either an integer number of milliseconds, as in PostgreSQL, or an
+// interval.
+func getTimeoutVal(
+	name string, session *Session, values []parser.TypedExpr,
+) (time.Duration, error) {
+	if len(values) != 1 {
+		return 0, fmt.Errorf("set %s requires a single argument", name)
+	}
+	evalCtx := session.evalCtx()
+	val, err := values[0].Eval(&evalCtx)
+	if err != nil {
+		return 0, err
+	}
+	var d time.Duration
+	switch v := val.(type) {
+	case *parser.DInt:
+		d = time.Duration(*v) * time.Millisecond
+	case *parser.DString:
+		if ms, err := strconv.ParseInt(string(*v), 10, 64); err == nil {
+			d = time.Duration(ms) * time.Millisecond
+			break
+		}
+		interval, err := parser.ParseDInterval(string(*v))
+		if err != nil {
+			return 0, fmt.Errorf("set %s: invalid duration %s", name, v)
+		}
+		nanos, _, _, err := interval.Duration.Encode()
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(nanos)
+	default:
+		return 0, fmt.Errorf("set %s requires an integer or string value: %s is a %s",
+			name, values[0], val.ResolvedType())
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("set %s: value must be non-negative", name)
+	}
+	return d, nil
+}
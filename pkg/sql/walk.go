@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"golang.org/x/net/context"
 
@@ -150,6 +151,10 @@ func (v *planVisitor) visit(plan planNode) {
 			if n.hardLimit > 0 && isFilterTrue(n.filter) {
 				v.observer.attr(name, "limit", fmt.Sprintf("%d", n.hardLimit))
 			}
+			if n.hasEstimate {
+				v.observer.attr(name, "estimated rows", fmt.Sprintf("%.0f", n.estimatedRowCount))
+				v.observer.attr(name, "cost", fmt.Sprintf("%.2f", n.estimatedCost))
+			}
 		}
 		subplans := v.expr(name, "filter", -1, n.filter, nil)
 		v.subqueries(name, subplans)
@@ -188,6 +193,9 @@ func (v *planVisitor) visit(plan planNode) {
 				jType = "full outer"
 			}
 			v.observer.attr(name, "type", jType)
+			if n.hint != "" {
+				v.observer.attr(name, "hint", strings.ToLower(n.hint))
+			}
 
 			if len(n.pred.leftColNames) > 0 {
 				var buf bytes.Buffer
@@ -287,6 +295,13 @@ func (v *planVisitor) visit(plan planNode) {
 		v.visit(n.left)
 		v.visit(n.right)
 
+	case *recursiveCTENode:
+		if v.observer.attr != nil {
+			v.observer.attr(name, "name", string(n.name.Alias))
+		}
+		v.visit(n.initial)
+		v.visit(n.recursive)
+
 	case *splitNode:
 		v.visit(n.rows)
 
@@ -518,53 +533,60 @@ func nodeName(plan planNode) string {
 // strings are constant and not precomputed so that the type names can
 // be changed without changing the output of "EXPLAIN".
 var planNodeNames = map[reflect.Type]string{
-	reflect.TypeOf(&alterTableNode{}):           "alter table",
-	reflect.TypeOf(&alterUserSetPasswordNode{}): "alter user",
-	reflect.TypeOf(&cancelQueryNode{}):          "cancel query",
-	reflect.TypeOf(&controlJobNode{}):           "control job",
-	reflect.TypeOf(&copyNode{}):                 "copy",
-	reflect.TypeOf(&createDatabaseNode{}):       "create database",
-	reflect.TypeOf(&createIndexNode{}):          "create index",
-	reflect.TypeOf(&createTableNode{}):          "create table",
-	reflect.TypeOf(&createUserNode{}):           "create user",
-	reflect.TypeOf(&createViewNode{}):           "create view",
-	reflect.TypeOf(&delayedNode{}):              "virtual table",
-	reflect.TypeOf(&deleteNode{}):               "delete",
-	reflect.TypeOf(&distinctNode{}):             "distinct",
-	reflect.TypeOf(&dropDatabaseNode{}):         "drop database",
-	reflect.TypeOf(&dropIndexNode{}):            "drop index",
-	reflect.TypeOf(&dropTableNode{}):            "drop table",
-	reflect.TypeOf(&dropViewNode{}):             "drop view",
-	reflect.TypeOf(&dropUserNode{}):             "drop user",
-	reflect.TypeOf(&explainDistSQLNode{}):       "explain dist_sql",
-	reflect.TypeOf(&explainPlanNode{}):          "explain plan",
-	reflect.TypeOf(&traceNode{}):                "show trace for",
-	reflect.TypeOf(&filterNode{}):               "filter",
-	reflect.TypeOf(&groupNode{}):                "group",
-	reflect.TypeOf(&unaryNode{}):                "emptyrow",
-	reflect.TypeOf(&hookFnNode{}):               "plugin",
-	reflect.TypeOf(&indexJoinNode{}):            "index-join",
-	reflect.TypeOf(&insertNode{}):               "insert",
-	reflect.TypeOf(&joinNode{}):                 "join",
-	reflect.TypeOf(&limitNode{}):                "limit",
-	reflect.TypeOf(&ordinalityNode{}):           "ordinality",
-	reflect.TypeOf(&testingRelocateNode{}):      "testingRelocate",
-	reflect.TypeOf(&renderNode{}):               "render",
-	reflect.TypeOf(&scanNode{}):                 "scan",
-	reflect.TypeOf(&scatterNode{}):              "scatter",
-	reflect.TypeOf(&scrubNode{}):                "scrub",
-	reflect.TypeOf(&setNode{}):                  "set",
-	reflect.TypeOf(&setClusterSettingNode{}):    "set cluster setting",
-	reflect.TypeOf(&setZoneConfigNode{}):        "configure zone",
-	reflect.TypeOf(&showZoneConfigNode{}):       "show zone configuration",
-	reflect.TypeOf(&showRangesNode{}):           "showRanges",
-	reflect.TypeOf(&showFingerprintsNode{}):     "showFingerprints",
-	reflect.TypeOf(&sortNode{}):                 "sort",
-	reflect.TypeOf(&splitNode{}):                "split",
-	reflect.TypeOf(&unionNode{}):                "union",
-	reflect.TypeOf(&updateNode{}):               "update",
-	reflect.TypeOf(&valueGenerator{}):           "generator",
-	reflect.TypeOf(&valuesNode{}):               "values",
-	reflect.TypeOf(&windowNode{}):               "window",
-	reflect.TypeOf(&zeroNode{}):                 "norows",
+	reflect.TypeOf(&alterSequenceNode{}):           "alter sequence",
+	reflect.TypeOf(&alterTableNode{}):              "alter table",
+	reflect.TypeOf(&alterUserSetPasswordNode{}):    "alter user",
+	reflect.TypeOf(&cancelQueryNode{}):             "cancel query",
+	reflect.TypeOf(&controlJobNode{}):              "control job",
+	reflect.TypeOf(&copyNode{}):                    "copy",
+	reflect.TypeOf(&createDatabaseNode{}):          "create database",
+	reflect.TypeOf(&createIndexNode{}):             "create index",
+	reflect.TypeOf(&createSequenceNode{}):          "create sequence",
+	reflect.TypeOf(&createStatsNode{}):             "create statistics",
+	reflect.TypeOf(&createTableNode{}):             "create table",
+	reflect.TypeOf(&createUserNode{}):              "create user",
+	reflect.TypeOf(&createViewNode{}):              "create view",
+	reflect.TypeOf(&delayedNode{}):                 "virtual table",
+	reflect.TypeOf(&deleteNode{}):                  "delete",
+	reflect.TypeOf(&distinctNode{}):                "distinct",
+	reflect.TypeOf(&dropDatabaseNode{}):            "drop database",
+	reflect.TypeOf(&dropIndexNode{}):               "drop index",
+	reflect.TypeOf(&dropSequenceNode{}):            "drop sequence",
+	reflect.TypeOf(&dropTableNode{}):               "drop table",
+	reflect.TypeOf(&dropViewNode{}):                "drop view",
+	reflect.TypeOf(&dropUserNode{}):                "drop user",
+	reflect.TypeOf(&explainDistSQLNode{}):          "explain dist_sql",
+	reflect.TypeOf(&explainPlanNode{}):             "explain plan",
+	reflect.TypeOf(&traceNode{}):                   "show trace for",
+	reflect.TypeOf(&filterNode{}):                  "filter",
+	reflect.TypeOf(&groupNode{}):                   "group",
+	reflect.TypeOf(&unaryNode{}):                   "emptyrow",
+	reflect.TypeOf(&hookFnNode{}):                  "plugin",
+	reflect.TypeOf(&indexJoinNode{}):               "index-join",
+	reflect.TypeOf(&insertNode{}):                  "insert",
+	reflect.TypeOf(&joinNode{}):                    "join",
+	reflect.TypeOf(&limitNode{}):                   "limit",
+	reflect.TypeOf(&ordinalityNode{}):              "ordinality",
+	reflect.TypeOf(&testingRelocateNode{}):         "testingRelocate",
+	reflect.TypeOf(&recursiveCTENode{}):            "recursive cte",
+	reflect.TypeOf(&refreshMaterializedViewNode{}): "refresh materialized view",
+	reflect.TypeOf(&renderNode{}):                  "render",
+	reflect.TypeOf(&scanNode{}):                    "scan",
+	reflect.TypeOf(&scatterNode{}):                 "scatter",
+	reflect.TypeOf(&scrubNode{}):                   "scrub",
+	reflect.TypeOf(&sequenceSelectNode{}):          "sequence select",
+	reflect.TypeOf(&setNode{}):                     "set",
+	reflect.TypeOf(&setClusterSettingNode{}):       "set cluster setting",
+	reflect.TypeOf(&setZoneConfigNode{}):           "configure zone",
+	reflect.TypeOf(&showZoneConfigNode{}):          "show zone configuration",
+	reflect.TypeOf(&showRangesNode{}):              "showRanges",
+	reflect.TypeOf(&showFingerprintsNode{}):        "showFingerprints",
+	reflect.TypeOf(&sortNode{}):                    "sort",
+	reflect.TypeOf(&splitNode{}):                   "split",
+	reflect.TypeOf(&unionNode{}):                   "union",
+	reflect.TypeOf(&updateNode{}):                  "update",
+	reflect.TypeOf(&valueGenerator{}):              "generator",
+	reflect.TypeOf(&valuesNode{}):                  "values",
+	reflect.TypeOf(&windowNode{}):                  "window",
+	reflect.TypeOf(&zeroNode{}):                    "norows",
 }
@@ -24,8 +24,10 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 )
 
@@ -33,7 +35,7 @@ import (
 // adjust the render targets in the renderNode as necessary. The use of window functions
 // will run with a space complexity of O(NW) (N = number of rows, W = number of windows)
 // and a time complexity of O(NW) (no ordering), O(W*NlogN) (with ordering), and
-// O(W*N^2) (with constant or variable sized window-frames, which are not yet supported).
+// O(W*N^2) (with window frames whose aggregate must be recomputed for each row).
 //
 // This code uses the following terminology throughout:
 // - window:
@@ -210,10 +212,120 @@ func (n *windowNode) constructWindowDefinitions(
 		}
 
 		windowFn.windowDef = windowDef
+
+		// Validate the frame clause.
+		if err := windowFn.analyzeFrameOffsets(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// analyzeFrameOffsets type checks the offsets of the window frame bounds, if
+// any. In ROWS mode, offsets are integers. In RANGE mode, the window must
+// be ordered by a single column, and offsets are added to or subtracted
+// from its values, so they must be of a compatible type.
+func (w *windowFuncHolder) analyzeFrameOffsets(ctx context.Context, s *renderNode) error {
+	frame := w.windowDef.Frame
+	if frame == nil {
+		return nil
+	}
+	bounds := []struct {
+		bound *parser.WindowFrameBound
+		dst   *parser.TypedExpr
+	}{
+		{frame.Bounds.StartBound, &w.startOffsetExpr},
+		{frame.Bounds.EndBound, &w.endOffsetExpr},
+	}
+
+	name := frame.Mode.String()
+	offsetType := types.T(types.Int)
+	for _, b := range bounds {
+		if b.bound == nil || b.bound.OffsetExpr == nil {
+			continue
+		}
+		if frame.Mode == parser.RangeMode && offsetType == types.Int {
+			if len(w.columnOrdering) != 1 {
+				return pgerror.NewErrorf(pgerror.CodeWindowingError,
+					"RANGE with offset PRECEDING/FOLLOWING requires exactly one ORDER BY column")
+			}
+			ordering := w.columnOrdering[0]
+			ordType := s.columns[ordering.ColIdx].Typ
+			switch ordType {
+			case types.Int, types.Float, types.Decimal:
+				offsetType = ordType
+			case types.Timestamp, types.TimestampTZ, types.Interval:
+				offsetType = types.Interval
+			default:
+				return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+					"RANGE with offset PRECEDING/FOLLOWING is not supported for column type %s", ordType)
+			}
+			w.ordColIdx = ordering.ColIdx
+			w.ordDescending = ordering.Direction == encoding.Descending
+		}
+
+		if err := s.planner.txCtx.AssertNoAggregationOrWindowing(
+			b.bound.OffsetExpr, name, s.planner.session.SearchPath,
+		); err != nil {
+			return err
+		}
+		typedExpr, err := s.planner.analyzeExpr(
+			ctx, b.bound.OffsetExpr, nil, parser.IndexedVarHelper{}, offsetType, true, name,
+		)
+		if err != nil {
+			return err
+		}
+		*b.dst = typedExpr
 	}
 	return nil
 }
 
+// evalFrameOffsets evaluates the offsets of the window frame bounds, if any.
+func (w *windowFuncHolder) evalFrameOffsets(
+	evalCtx *parser.EvalContext,
+) (startOffset, endOffset parser.Datum, err error) {
+	if startOffset, err = evalFrameOffset(evalCtx, w.startOffsetExpr, "starting"); err != nil {
+		return nil, nil, err
+	}
+	if endOffset, err = evalFrameOffset(evalCtx, w.endOffsetExpr, "ending"); err != nil {
+		return nil, nil, err
+	}
+	return startOffset, endOffset, nil
+}
+
+func evalFrameOffset(
+	evalCtx *parser.EvalContext, expr parser.TypedExpr, which string,
+) (parser.Datum, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	d, err := expr.Eval(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	var negative bool
+	switch t := d.(type) {
+	case *parser.DInt:
+		negative = *t < 0
+	case *parser.DFloat:
+		negative = *t < 0
+	case *parser.DDecimal:
+		negative = t.Sign() < 0
+	case *parser.DInterval:
+		negative = t.Duration.Compare(duration.Duration{}) < 0
+	default:
+		if d == parser.DNull {
+			return nil, pgerror.NewErrorf(pgerror.CodeNullValueNotAllowedError,
+				"frame %s offset must not be null", which)
+		}
+	}
+	if negative {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidPrecedingOrFollowingSizeError,
+			"frame %s offset must not be negative", which)
+	}
+	return d, nil
+}
+
 // constructWindowDef constructs a WindowDef using the provided WindowDef value and the
 // set of named window specifications on the current SELECT clause. If the provided
 // WindowDef does not reference a named window spec, then it will simply be returned without
@@ -253,6 +365,12 @@ func constructWindowDef(
 	}
 	def.Partitions = referencedSpec.Partitions
 
+	// A window with a frame clause cannot be modified.
+	if referencedSpec.Frame != nil {
+		return def, pgerror.NewErrorf(pgerror.CodeWindowingError,
+			"cannot copy window %q because it has a frame clause", refName)
+	}
+
 	// referencedSpec.OrderBy is used if set.
 	if len(referencedSpec.OrderBy) > 0 {
 		if len(def.OrderBy) > 0 {
@@ -577,6 +695,11 @@ func (n *windowNode) computeWindows(ctx context.Context) error {
 	var scratchBytes []byte
 	var scratchDatum []parser.Datum
 	for windowIdx, windowFn := range n.funcs {
+		startOffset, endOffset, err := windowFn.evalFrameOffsets(&n.planner.evalCtx)
+		if err != nil {
+			return err
+		}
+
 		partitions := make(map[string][]parser.IndexedRow)
 
 		if len(windowFn.partitionIdxs) == 0 {
@@ -607,8 +730,7 @@ func (n *windowNode) computeWindows(ctx context.Context) error {
 		// See Cao et al. [http://vldb.org/pvldb/vol5/p1244_yucao_vldb2012.pdf]
 		for rowI := 0; rowI < rowCount; rowI++ {
 			row := n.wrappedRenderVals.At(rowI)
-			sourceVals := row[:n.sourceCols]
-			entry := parser.IndexedRow{Idx: rowI, Row: sourceVals}
+			entry := parser.IndexedRow{Idx: rowI, Row: row}
 			if len(windowFn.partitionIdxs) == 0 {
 				// If no partition indexes are included for the window function, all
 				// rows are added to the same partition.
@@ -645,18 +767,17 @@ func (n *windowNode) computeWindows(ctx context.Context) error {
 		//   * Segment Tree
 		// See Leis et al. [http://www.vldb.org/pvldb/vol8/p1058-leis.pdf]
 		for _, partition := range partitions {
-			// TODO(nvanbenschoten): Handle framing here. Right now we only handle the default
-			// framing option of RANGE UNBOUNDED PRECEDING. With ORDER BY, this sets the frame
-			// to be all rows from the partition start up through the current row's last ORDER BY
-			// peer. Without ORDER BY, all rows of the partition are included in the window frame,
-			// since all rows become peers of the current row. Once we add better framing support,
-			// we should flesh this logic out more.
+			// Without a frame clause, the default framing option of RANGE UNBOUNDED
+			// PRECEDING is used. With ORDER BY, this sets the frame to be all rows from
+			// the partition start up through the current row's last ORDER BY peer.
+			// Without ORDER BY, all rows of the partition are included in the window
+			// frame, since all rows become peers of the current row. Frame clauses are
+			// handled by the window functions themselves (see parser.WindowFrameRun).
 			builtin := windowFn.expr.GetWindowConstructor()(&n.planner.evalCtx)
 			defer builtin.Close(ctx, &n.planner.evalCtx)
 
-			// Since we only support two types of window frames (see TODO above), we only
-			// need two possible types of peerGroupChecker's to help determine peer groups
-			// for given tuples.
+			// We only need two possible types of peerGroupChecker's to help determine
+			// peer groups for given tuples.
 			var peerGrouper peerGroupChecker
 			if windowFn.columnOrdering != nil {
 				// If an ORDER BY clause is provided, order the partition and use the
@@ -681,11 +802,16 @@ func (n *windowNode) computeWindows(ctx context.Context) error {
 			}
 
 			// Iterate over peer groups within partition using a window frame.
-			frame := parser.WindowFrame{
-				Rows:        partition,
-				ArgIdxStart: windowFn.argIdxStart,
-				ArgCount:    windowFn.argCount,
-				RowIdx:      0,
+			frame := parser.WindowFrameRun{
+				Rows:             partition,
+				ArgIdxStart:      windowFn.argIdxStart,
+				ArgCount:         windowFn.argCount,
+				Frame:            windowFn.windowDef.Frame,
+				StartBoundOffset: startOffset,
+				EndBoundOffset:   endOffset,
+				OrdColIdx:        windowFn.ordColIdx,
+				OrdDescending:    windowFn.ordDescending,
+				RowIdx:           0,
 			}
 			for frame.RowIdx < len(partition) {
 				// Compute the size of the current peer group.
@@ -904,6 +1030,14 @@ type windowFuncHolder struct {
 	windowDef      parser.WindowDef
 	partitionIdxs  []int
 	columnOrdering sqlbase.ColumnOrdering
+
+	// startOffsetExpr and endOffsetExpr are the offsets of the window frame
+	// bounds, if any. ordColIdx and ordDescending describe the ordering
+	// column used to compute the frame in RANGE mode with an offset.
+	startOffsetExpr parser.TypedExpr
+	endOffsetExpr   parser.TypedExpr
+	ordColIdx       int
+	ordDescending   bool
 }
 
 func (*windowFuncHolder) Variable() {}
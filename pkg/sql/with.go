@@ -0,0 +1,468 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// cteSource describes a name introduced by a WITH clause that is
+// visible to the data sources of the statement it is attached to.
+//
+// Common table expressions are planned like views: every reference to
+// a CTE in a FROM clause re-plans the CTE's query, in the naming
+// environment where the CTE was defined.
+type cteSource struct {
+	// name is the name of the CTE and, optionally, the names of its
+	// columns.
+	name parser.AliasClause
+
+	// stmt is the query of the CTE.
+	stmt *parser.Select
+
+	// recursive is set if the CTE was defined with WITH RECURSIVE.
+	recursive bool
+
+	// env is the naming environment in which stmt must be planned.
+	env cteNameEnvironment
+
+	// planning is set while stmt is being planned. It is used to detect
+	// mutual recursion between the items of a WITH RECURSIVE clause.
+	planning bool
+
+	// invalidRefErr, when set, is the error to report if the name is
+	// referenced. This is used to reject self-references in places
+	// where they are not allowed.
+	invalidRefErr error
+
+	// working, when non-nil, indicates that this name is the
+	// self-reference of a recursive CTE inside its recursive term.
+	working *cteWorkingTable
+}
+
+// cteWorkingTable holds the rows produced by the previous iteration of
+// a recursive CTE. They are the rows visible through the
+// self-reference in the recursive term of the CTE.
+type cteWorkingTable struct {
+	columns sqlbase.ResultColumns
+	// rows is nil during the initial planning of the recursive term.
+	rows *sqlbase.RowContainer
+	// refs counts the number of references to the working table.
+	refs int
+}
+
+// cteNameEnvironment is the set of CTE names visible at some point in
+// a query. Innermost names are at the end of the slice. A
+// cteNameEnvironment is never modified in place: new names are
+// always appended to a copy, so that an environment can be captured
+// cheaply by the CTEs that must be planned in it.
+type cteNameEnvironment []*cteSource
+
+// push returns a new environment extended with the given CTE names.
+func (e cteNameEnvironment) push(srcs ...*cteSource) cteNameEnvironment {
+	return append(e[:len(e):len(e)], srcs...)
+}
+
+// lookup finds the innermost CTE with the given name.
+func (e cteNameEnvironment) lookup(name parser.Name) *cteSource {
+	norm := name.Normalize()
+	for i := len(e) - 1; i >= 0; i-- {
+		if e[i].name.Alias.Normalize() == norm {
+			return e[i]
+		}
+	}
+	return nil
+}
+
+// initWith introduces the names defined by a WITH clause in the
+// planner's naming environment. The returned function, if non-nil,
+// must be called to restore the previous environment once the
+// statement the WITH clause is attached to has been planned.
+func (p *planner) initWith(ctx context.Context, with *parser.With) (func(p *planner), error) {
+	if with == nil {
+		return nil, nil
+	}
+
+	srcs := make([]*cteSource, len(with.CTEList))
+	seen := make(map[string]struct{}, len(with.CTEList))
+	for i, cte := range with.CTEList {
+		name := cte.Name.Alias.Normalize()
+		if _, ok := seen[name]; ok {
+			return nil, pgerror.NewErrorf(pgerror.CodeDuplicateAliasError,
+				"WITH query name %q specified more than once", cte.Name.Alias)
+		}
+		seen[name] = struct{}{}
+
+		sel, ok := cte.Stmt.(*parser.Select)
+		if !ok {
+			return nil, pgerror.Unimplemented("cte-dml",
+				fmt.Sprintf("%s statements are not supported in WITH", cte.Stmt.StatementTag()))
+		}
+		srcs[i] = &cteSource{name: cte.Name, stmt: sel, recursive: with.Recursive}
+	}
+
+	prevEnv := p.cteEnv
+	if with.Recursive {
+		// All the names in a WITH RECURSIVE clause are visible to every
+		// CTE in the clause, including to itself.
+		env := prevEnv.push(srcs...)
+		for _, src := range srcs {
+			src.env = env
+		}
+		p.cteEnv = env
+	} else {
+		// Each CTE in a WITH clause can refer to the CTEs that precede
+		// it, but not to itself nor to those that follow it.
+		env := prevEnv
+		for _, src := range srcs {
+			src.env = env
+			env = env.push(src)
+		}
+		p.cteEnv = env
+	}
+
+	return func(p *planner) { p.cteEnv = prevEnv }, nil
+}
+
+// lookupCTE determines whether the given table name refers to a CTE
+// currently in scope.
+func (p *planner) lookupCTE(t *parser.NormalizableTableName) (*cteSource, error) {
+	if len(p.cteEnv) == 0 {
+		return nil, nil
+	}
+	tn, err := t.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if tn.DatabaseName != "" || tn.PrefixOriginallySpecified {
+		// A qualified name never refers to a CTE.
+		return nil, nil
+	}
+	return p.cteEnv.lookup(tn.TableName), nil
+}
+
+// getCTEDataSource builds a planDataSource for a reference to a CTE.
+func (p *planner) getCTEDataSource(ctx context.Context, src *cteSource) (planDataSource, error) {
+	if src.invalidRefErr != nil {
+		return planDataSource{}, src.invalidRefErr
+	}
+
+	var plan planNode
+	if src.working != nil {
+		src.working.refs++
+		cols := append(sqlbase.ResultColumns(nil), src.working.columns...)
+		v := p.newContainerValuesNode(cols, 0)
+		if src.working.rows != nil {
+			for i := 0; i < src.working.rows.Len(); i++ {
+				if _, err := v.rows.AddRow(ctx, src.working.rows.At(i)); err != nil {
+					v.Close(ctx)
+					return planDataSource{}, err
+				}
+			}
+		}
+		plan = v
+	} else {
+		if src.planning {
+			return planDataSource{}, pgerror.NewErrorf(pgerror.CodeInvalidRecursionError,
+				"mutual recursion between WITH items is not implemented")
+		}
+		src.planning = true
+		defer func() { src.planning = false }()
+
+		defer func(prev cteNameEnvironment) { p.cteEnv = prev }(p.cteEnv)
+		p.cteEnv = src.env
+
+		var err error
+		if src.recursive {
+			plan, err = p.newRecursiveCTEPlan(ctx, src)
+		} else {
+			plan, err = p.newPlan(ctx, src.stmt, nil)
+		}
+		if err != nil {
+			return planDataSource{}, err
+		}
+	}
+
+	tn := parser.TableName{TableName: src.name.Alias, DBNameOriginallyOmitted: true}
+	ds := planDataSource{
+		info: newSourceInfoForSingleTable(tn, planColumns(plan)),
+		plan: plan,
+	}
+	return renameSource(ds, src.name, false)
+}
+
+// newRecursiveCTEPlan plans the query of a CTE defined with WITH
+// RECURSIVE. If the query does not actually refer to itself, it is
+// planned like any other query.
+func (p *planner) newRecursiveCTEPlan(ctx context.Context, src *cteSource) (planNode, error) {
+	union, ok := src.stmt.Select.(*parser.UnionClause)
+	if !ok || union.Type != parser.UnionOp || src.stmt.OrderBy != nil || src.stmt.Limit != nil {
+		// Not of the form <initial> UNION [ALL] <recursive>: any
+		// self-reference is invalid.
+		self := &cteSource{
+			name: src.name,
+			invalidRefErr: pgerror.NewErrorf(pgerror.CodeInvalidRecursionError,
+				"recursive query %q does not have the form non-recursive-term UNION [ALL] recursive-term",
+				src.name.Alias),
+		}
+		p.cteEnv = src.env.push(self)
+		return p.newPlan(ctx, src.stmt, nil)
+	}
+
+	// Plan the initial (non-recursive) term. It cannot refer to the CTE
+	// being defined.
+	p.cteEnv = src.env.push(&cteSource{
+		name: src.name,
+		invalidRefErr: pgerror.NewErrorf(pgerror.CodeInvalidRecursionError,
+			"recursive reference to query %q must not appear within its non-recursive term",
+			src.name.Alias),
+	})
+	initial, err := p.newPlan(ctx, union.Left, nil)
+	if err != nil {
+		return nil, err
+	}
+	columns := planColumns(initial)
+
+	// Plan the recursive term, with the self-reference bound to an
+	// empty working table. This plan is never executed: the recursive
+	// term is planned anew at every iteration, but this checks the
+	// query and determines whether it is actually recursive.
+	working := &cteWorkingTable{columns: columns}
+	p.cteEnv = src.env.push(&cteSource{name: src.name, working: working})
+	recursive, err := p.newPlan(ctx, union.Right, nil)
+	if err != nil {
+		initial.Close(ctx)
+		return nil, err
+	}
+
+	switch working.refs {
+	case 0:
+		// Not actually recursive. Plan the query as a regular UNION.
+		initial.Close(ctx)
+		recursive.Close(ctx)
+		return p.newPlan(ctx, src.stmt, nil)
+	case 1:
+	default:
+		initial.Close(ctx)
+		recursive.Close(ctx)
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidRecursionError,
+			"recursive reference to query %q must not appear more than once", src.name.Alias)
+	}
+
+	recursiveColumns := planColumns(recursive)
+	if len(columns) != len(recursiveColumns) {
+		initial.Close(ctx)
+		recursive.Close(ctx)
+		return nil, fmt.Errorf("each %v query must have the same number of columns: %d vs %d",
+			union.Type, len(columns), len(recursiveColumns))
+	}
+	for i := range columns {
+		l, r := columns[i].Typ, recursiveColumns[i].Typ
+		if !(l.Equivalent(r) || r == types.Null) {
+			initial.Close(ctx)
+			recursive.Close(ctx)
+			return nil, pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"recursive query %q column %d has type %s in non-recursive term but type %s overall",
+				src.name.Alias, i+1, l, r)
+		}
+	}
+
+	return &recursiveCTENode{
+		name:          src.name,
+		env:           src.env,
+		recursiveStmt: union.Right,
+		columns:       columns,
+		initial:       initial,
+		recursive:     recursive,
+		emitAll:       union.All,
+	}, nil
+}
+
+// recursiveCTENode implements WITH RECURSIVE: it emits the rows of the
+// initial term, then repeatedly evaluates the recursive term over the
+// rows produced by the previous iteration until an iteration produces
+// no new rows.
+type recursiveCTENode struct {
+	// name is the name of the CTE.
+	name parser.AliasClause
+	// env is the naming environment in which the recursive term is
+	// planned at every iteration.
+	env cteNameEnvironment
+	// recursiveStmt is the recursive term of the CTE.
+	recursiveStmt *parser.Select
+
+	columns sqlbase.ResultColumns
+
+	// initial is the plan for the non-recursive term.
+	initial planNode
+	// recursive is a plan for the recursive term over an empty working
+	// table. It is not executed and is only kept for introspection
+	// (EXPLAIN, span collection).
+	recursive planNode
+
+	// emitAll is set for UNION ALL. Otherwise, duplicate rows are
+	// discarded and not added to the working table.
+	emitAll bool
+
+	run recursiveCTERun
+}
+
+// recursiveCTERun contains the run-time state of recursiveCTENode
+// during local execution.
+type recursiveCTERun struct {
+	// source is the plan currently producing rows: either the initial
+	// term or the current iteration of the recursive term.
+	source planNode
+	// working contains the rows produced by the previous iteration.
+	working *sqlbase.RowContainer
+	// next accumulates the rows produced by the current iteration.
+	next *sqlbase.RowContainer
+
+	seen    map[string]struct{}
+	scratch []byte
+	row     parser.Datums
+}
+
+func (n *recursiveCTENode) Start(params runParams) error {
+	if err := n.initial.Start(params); err != nil {
+		return err
+	}
+	n.run.source = n.initial
+	n.run.next = sqlbase.NewRowContainer(
+		params.p.session.TxnState.makeBoundAccount(), sqlbase.ColTypeInfoFromResCols(n.columns), 0,
+	)
+	if !n.emitAll {
+		n.run.seen = make(map[string]struct{})
+	}
+	return nil
+}
+
+func (n *recursiveCTENode) Next(params runParams) (bool, error) {
+	for {
+		if err := params.p.cancelChecker.Check(); err != nil {
+			return false, err
+		}
+
+		if n.run.source == nil {
+			if n.run.next.Len() == 0 {
+				// The last iteration did not produce any new rows.
+				return false, nil
+			}
+			// Move the rows produced by the last iteration to the working
+			// table and start a new iteration.
+			n.run.working, n.run.next = n.run.next, n.run.working
+			if n.run.next == nil {
+				n.run.next = sqlbase.NewRowContainer(
+					params.p.session.TxnState.makeBoundAccount(),
+					sqlbase.ColTypeInfoFromResCols(n.columns), 0,
+				)
+			} else {
+				n.run.next.Clear(params.ctx)
+			}
+			plan, err := n.planIteration(params)
+			if err != nil {
+				return false, err
+			}
+			n.run.source = plan
+		}
+
+		next, err := n.run.source.Next(params)
+		if err != nil {
+			return false, err
+		}
+		if !next {
+			if n.run.source != n.initial {
+				n.run.source.Close(params.ctx)
+			}
+			n.run.source = nil
+			continue
+		}
+
+		row := n.run.source.Values()
+		if !n.emitAll {
+			n.run.scratch, err = sqlbase.EncodeDatums(n.run.scratch[:0], row)
+			if err != nil {
+				return false, err
+			}
+			if _, ok := n.run.seen[string(n.run.scratch)]; ok {
+				continue
+			}
+			n.run.seen[string(n.run.scratch)] = struct{}{}
+		}
+		n.run.row, err = n.run.next.AddRow(params.ctx, row)
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+// planIteration plans and starts the recursive term over the current
+// working table.
+func (n *recursiveCTENode) planIteration(params runParams) (planNode, error) {
+	p := params.p
+	defer func(prev cteNameEnvironment) { p.cteEnv = prev }(p.cteEnv)
+	working := &cteWorkingTable{columns: n.columns, rows: n.run.working}
+	p.cteEnv = n.env.push(&cteSource{name: n.name, working: working})
+
+	plan, err := p.newPlan(params.ctx, n.recursiveStmt, nil)
+	if err != nil {
+		return nil, err
+	}
+	plan, err = p.optimizePlan(params.ctx, plan, allColumns(plan))
+	if err != nil {
+		plan.Close(params.ctx)
+		return nil, err
+	}
+	if err := p.startPlan(params.ctx, plan); err != nil {
+		plan.Close(params.ctx)
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (n *recursiveCTENode) Values() parser.Datums {
+	return n.run.row
+}
+
+func (n *recursiveCTENode) Close(ctx context.Context) {
+	if n.run.source != nil && n.run.source != n.initial {
+		n.run.source.Close(ctx)
+	}
+	n.run.source = nil
+	if n.run.working != nil {
+		n.run.working.Close(ctx)
+		n.run.working = nil
+	}
+	if n.run.next != nil {
+		n.run.next.Close(ctx)
+		n.run.next = nil
+	}
+	if n.initial != nil {
+		n.initial.Close(ctx)
+		n.initial = nil
+	}
+	if n.recursive != nil {
+		n.recursive.Close(ctx)
+		n.recursive = nil
+	}
+}
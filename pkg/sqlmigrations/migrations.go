@@ -89,6 +89,34 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		name:   "persist trace.debug.enable = 'false'",
 		workFn: disableNetTrace,
 	},
+	{
+		name:           "create system.statement_hints table",
+		workFn:         createStatementHintsTable,
+		newDescriptors: 1,
+		newRanges:      0, // it lives in gossip range.
+	},
+	{
+		name:           "create system.table_statistics table",
+		workFn:         createTableStatisticsTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
+	{
+		name:   "add system.users isRole column",
+		workFn: addUsersIsRoleColumn,
+	},
+	{
+		name:           "create system.role_members table",
+		workFn:         createRoleMembersTable,
+		newDescriptors: 1,
+		newRanges:      0, // it lives in gossip range.
+	},
+	{
+		// The verifiers of existing users are added when their password is
+		// set or when they next log in with a password.
+		name:   "add system.users scramVerifier column",
+		workFn: addUsersScramVerifierColumn,
+	},
 }
 
 // migrationDescriptor describes a single migration hook that's used to modify
@@ -366,6 +394,28 @@ func createWebSessionsTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, sqlbase.WebSessionsTable)
 }
 
+func createStatementHintsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.StatementHintsTable)
+}
+
+func createTableStatisticsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.TableStatisticsTable)
+}
+
+func addUsersIsRoleColumn(ctx context.Context, r runner) error {
+	const alterStmt = `ALTER TABLE system.users ADD COLUMN IF NOT EXISTS "isRole" BOOL NOT NULL DEFAULT false`
+	return runStmtAsRootWithRetry(ctx, r, alterStmt)
+}
+
+func addUsersScramVerifierColumn(ctx context.Context, r runner) error {
+	const alterStmt = `ALTER TABLE system.users ADD COLUMN IF NOT EXISTS "scramVerifier" BYTES`
+	return runStmtAsRootWithRetry(ctx, r, alterStmt)
+}
+
+func createRoleMembersTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.RoleMembersTable)
+}
+
 func createSystemTable(ctx context.Context, r runner, desc sqlbase.TableDescriptor) error {
 	// We install the table at the KV layer so that we can choose a known ID in
 	// the reserved ID space. (The SQL layer doesn't allow this.)
@@ -576,11 +576,15 @@ func (a Allocator) RebalanceTarget(
 }
 
 func (a *Allocator) scorerOptions(disableStatsBasedRebalancing bool) scorerOptions {
+	statsBasedRebalancing := statsBasedRebalancingEnabled(a.storePool.st, disableStatsBasedRebalancing)
 	return scorerOptions{
 		deterministic:                a.storePool.deterministic,
-		statsBasedRebalancingEnabled: statsBasedRebalancingEnabled(a.storePool.st, disableStatsBasedRebalancing),
+		statsBasedRebalancingEnabled: statsBasedRebalancing,
+		qpsBasedRebalancingEnabled:   statsBasedRebalancing && EnableQPSBasedRebalancing.Get(&a.storePool.st.SV),
 		rangeRebalanceThreshold:      rangeRebalanceThreshold.Get(&a.storePool.st.SV),
 		statRebalanceThreshold:       statRebalanceThreshold.Get(&a.storePool.st.SV),
+		qpsRebalanceThreshold:        qpsRebalanceThreshold.Get(&a.storePool.st.SV),
+		minQPSRebalanceDelta:         minQPSRebalanceDelta.Get(&a.storePool.st.SV),
 	}
 }
 
@@ -629,6 +633,12 @@ func (a *Allocator) TransferLeaseTarget(
 		return roachpb.ReplicaDescriptor{}
 	}
 
+	// Shedding load from a store serving too many queries takes precedence
+	// over the other considerations.
+	if repl := a.qpsLeaseTransferTarget(ctx, sl, source, existing, stats); repl != (roachpb.ReplicaDescriptor{}) {
+		return repl
+	}
+
 	// Try to pick a replica to transfer the lease to while also determining
 	// whether we actually should be transferring the lease. The transfer
 	// decision is only needed if we've been asked to check the source.
@@ -698,6 +708,11 @@ func (a *Allocator) ShouldTransferLease(
 	sl = sl.filter(constraints)
 	log.VEventf(ctx, 3, "ShouldTransferLease (lease-holder=%d):\n%s", leaseStoreID, sl)
 
+	if repl := a.qpsLeaseTransferTarget(ctx, sl, source, existing, stats); repl != (roachpb.ReplicaDescriptor{}) {
+		log.VEventf(ctx, 3, "ShouldTransferLease decision (lease-holder=%d): true (qps)", leaseStoreID)
+		return true
+	}
+
 	transferDec, _ := a.shouldTransferLeaseUsingStats(ctx, sl, source, existing, stats)
 	var result bool
 	switch transferDec {
@@ -813,6 +828,62 @@ func (a Allocator) shouldTransferLeaseUsingStats(
 	return shouldNotTransfer, bestRepl
 }
 
+// qpsLeaseTransferTarget returns the replica to transfer the lease to in order
+// to move the range's queries away from the source store if the source store
+// serves too many queries per second, or an empty descriptor if the lease
+// shouldn't be transferred for that reason. The target is the replica on the
+// least loaded store among those which are underfull and wouldn't become
+// overfull by taking on the range's queries, which keeps the lease from
+// bouncing back and forth.
+func (a Allocator) qpsLeaseTransferTarget(
+	ctx context.Context,
+	sl StoreList,
+	source roachpb.StoreDescriptor,
+	existing []roachpb.ReplicaDescriptor,
+	stats *replicaStats,
+) roachpb.ReplicaDescriptor {
+	options := a.scorerOptions(false /* disableStatsBasedRebalancing */)
+	if !options.qpsBasedRebalancingEnabled || stats == nil {
+		return roachpb.ReplicaDescriptor{}
+	}
+	// Replica stats are reset upon lease transfer, so requiring them to have
+	// been accumulated for a while also limits how often the lease can move.
+	rangeQPS, dur := stats.avgQPS()
+	if dur < MinLeaseTransferStatsDuration || rangeQPS <= 0 {
+		return roachpb.ReplicaDescriptor{}
+	}
+
+	meanQPS := sl.candidateQueriesPerSecond.mean
+	overfullThreshold := overfullQPSThreshold(options, meanQPS)
+	if source.Capacity.QueriesPerSecond <= overfullThreshold {
+		return roachpb.ReplicaDescriptor{}
+	}
+	underfullThreshold := underfullQPSThreshold(options, meanQPS)
+
+	var bestRepl roachpb.ReplicaDescriptor
+	bestQPS := math.MaxFloat64
+	for _, repl := range existing {
+		if repl.StoreID == source.StoreID {
+			continue
+		}
+		storeDesc, ok := a.storePool.getStoreDescriptor(repl.StoreID)
+		if !ok {
+			continue
+		}
+		qps := storeDesc.Capacity.QueriesPerSecond
+		if qps >= underfullThreshold || qps+rangeQPS > overfullThreshold || qps >= bestQPS {
+			continue
+		}
+		bestRepl, bestQPS = repl, qps
+	}
+	if bestRepl != (roachpb.ReplicaDescriptor{}) {
+		log.VEventf(ctx, 2,
+			"s%d: transferring lease to s%d to shed load: sourceQPS=%.2f, targetQPS=%.2f, rangeQPS=%.2f, meanQPS=%.2f",
+			source.StoreID, bestRepl.StoreID, source.Capacity.QueriesPerSecond, bestQPS, rangeQPS, meanQPS)
+	}
+	return bestRepl
+}
+
 // loadBasedLeaseRebalanceScore attempts to give a score to how desirable it
 // would be to transfer a range lease from the local store to a remote store.
 // It does so using a formula based on the latency between the stores and
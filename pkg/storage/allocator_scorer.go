@@ -76,11 +76,46 @@ var statRebalanceThreshold = settings.RegisterNonNegativeFloatSetting(
 	0.20,
 )
 
+// EnableQPSBasedRebalancing controls whether range leases are moved away from
+// stores which serve many more queries per second than the other stores. The
+// queries served by a range are attributed to the store of its leaseholder,
+// so they only move along with the lease: moving the other replicas of a
+// range doesn't change the load of their stores. This only takes effect if
+// stats-based rebalancing is enabled.
+var EnableQPSBasedRebalancing = settings.RegisterBoolSetting(
+	"kv.allocator.qps_based_rebalancing.enabled",
+	"set to enable rebalancing of range leases based on the queries per second served by stores "+
+		"(requires kv.allocator.stat_based_rebalancing.enabled)",
+	false,
+)
+
+// qpsRebalanceThreshold is the same as statRebalanceThreshold, but for the
+// queries per second served by stores. QPS is even less stable than keys
+// written per second, hence the higher default.
+var qpsRebalanceThreshold = settings.RegisterNonNegativeFloatSetting(
+	"kv.allocator.qps_rebalance_threshold",
+	"minimum fraction away from the mean a store's QPS can be before it is considered overfull or underfull",
+	0.25,
+)
+
+// minQPSRebalanceDelta is the minimum difference between a store's QPS and the
+// mean QPS for the store to be considered overfull or underfull. It prevents
+// rebalancing churn in lightly loaded clusters, where small absolute
+// differences in QPS make up large fractions of the mean.
+var minQPSRebalanceDelta = settings.RegisterNonNegativeFloatSetting(
+	"kv.allocator.min_qps_rebalance_delta",
+	"minimum difference between a store's QPS and the mean QPS before it is considered overfull or underfull",
+	100,
+)
+
 type scorerOptions struct {
 	deterministic                bool
 	statsBasedRebalancingEnabled bool
+	qpsBasedRebalancingEnabled   bool
 	rangeRebalanceThreshold      float64
 	statRebalanceThreshold       float64
+	qpsRebalanceThreshold        float64
+	minQPSRebalanceDelta         float64
 }
 
 type balanceDimensions struct {
@@ -111,9 +146,10 @@ type candidate struct {
 
 func (c candidate) String() string {
 	return fmt.Sprintf("s%d, valid:%t, constraint:%.2f, converges:%d, balance:%s, rangeCount:%d, "+
-		"logicalBytes:%s, writesPerSecond:%.2f, details:(%s)",
+		"logicalBytes:%s, writesPerSecond:%.2f, queriesPerSecond:%.2f, details:(%s)",
 		c.store.StoreID, c.valid, c.constraintScore, c.convergesScore, c.balanceScore, c.rangeCount,
-		humanizeutil.IBytes(c.store.Capacity.LogicalBytes), c.store.Capacity.WritesPerSecond, c.details)
+		humanizeutil.IBytes(c.store.Capacity.LogicalBytes), c.store.Capacity.WritesPerSecond,
+		c.store.Capacity.QueriesPerSecond, c.details)
 }
 
 // less returns true if o is a better fit for some range than c is.
@@ -934,6 +970,14 @@ func underfullStatThreshold(options scorerOptions, mean float64) float64 {
 	return mean * (1 - options.statRebalanceThreshold)
 }
 
+func overfullQPSThreshold(options scorerOptions, mean float64) float64 {
+	return math.Max(mean*(1+options.qpsRebalanceThreshold), mean+options.minQPSRebalanceDelta)
+}
+
+func underfullQPSThreshold(options scorerOptions, mean float64) float64 {
+	return math.Min(mean*(1-options.qpsRebalanceThreshold), mean-options.minQPSRebalanceDelta)
+}
+
 func rebalanceFromConvergesOnMean(
 	sl StoreList, sc roachpb.StoreCapacity, rangeInfo RangeInfo, options scorerOptions,
 ) bool {
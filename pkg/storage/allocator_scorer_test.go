@@ -939,6 +939,56 @@ func TestBalanceScore(t *testing.T) {
 	}
 }
 
+// TestBalanceScoreIgnoresQPS verifies that the queries per second served by
+// stores don't affect where replicas are placed: the queries of a range are
+// served by its leaseholder, so moving any other replica doesn't move them.
+func TestBalanceScoreIgnoresQPS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	storeList := StoreList{
+		candidateRanges:           stat{mean: 1000},
+		candidateLogicalBytes:     stat{mean: 512 * 1024 * 1024},
+		candidateWritesPerSecond:  stat{mean: 1000},
+		candidateQueriesPerSecond: stat{mean: 1000},
+	}
+
+	sMean := roachpb.StoreCapacity{
+		Capacity:         1024 * 1024 * 1024,
+		Available:        512 * 1024 * 1024,
+		LogicalBytes:     512 * 1024 * 1024,
+		RangeCount:       1000,
+		WritesPerSecond:  1000,
+		QueriesPerSecond: 1000,
+	}
+	sQPSOverfull := sMean
+	sQPSOverfull.QueriesPerSecond = 3000
+	sQPSUnderfull := sMean
+	sQPSUnderfull.QueriesPerSecond = 0
+
+	ri := RangeInfo{LogicalBytes: 1024, WritesPerSecond: 1}
+	options := scorerOptions{
+		statsBasedRebalancingEnabled: true,
+		qpsBasedRebalancingEnabled:   true,
+		statRebalanceThreshold:       0.2,
+		qpsRebalanceThreshold:        0.25,
+		minQPSRebalanceDelta:         100,
+	}
+	expected := balanceScore(storeList, sMean, ri, options)
+	for i, sc := range []roachpb.StoreCapacity{sQPSOverfull, sQPSUnderfull} {
+		if a := balanceScore(storeList, sc, ri, options); a != expected {
+			t.Errorf("%d: balanceScore(storeList, %+v, %+v) got %s; want %s", i, sc, ri, a, expected)
+		}
+		if a, e := rebalanceToConvergesOnMean(storeList, sc, ri, options),
+			rebalanceToConvergesOnMean(storeList, sMean, ri, options); a != e {
+			t.Errorf("%d: rebalanceToConvergesOnMean(storeList, %+v, %+v) got %t; want %t", i, sc, ri, a, e)
+		}
+		if a, e := rebalanceFromConvergesOnMean(storeList, sc, ri, options),
+			rebalanceFromConvergesOnMean(storeList, sMean, ri, options); a != e {
+			t.Errorf("%d: rebalanceFromConvergesOnMean(storeList, %+v, %+v) got %t; want %t", i, sc, ri, a, e)
+		}
+	}
+}
+
 func TestRebalanceConvergesOnMean(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
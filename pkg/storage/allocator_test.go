@@ -1292,6 +1292,97 @@ func TestAllocatorShouldTransferLease(t *testing.T) {
 	}
 }
 
+// TestAllocatorTransferLeaseQPS verifies that leases are transferred away
+// from stores which serve too many queries per second when QPS-based
+// rebalancing is enabled.
+func TestAllocatorTransferLeaseQPS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ true)
+	defer stopper.Stop(context.Background())
+	EnableStatsBasedRebalancing.Override(&a.storePool.st.SV, true)
+
+	// 3 stores with the same number of leases where store 1 is overloaded,
+	// store 2 is underloaded and store 3 is about average (mean QPS = 1400).
+	qps := []float64{3000, 200, 1000}
+	var stores []*roachpb.StoreDescriptor
+	for i := 1; i <= 3; i++ {
+		stores = append(stores, &roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i),
+			Node:    roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i)},
+			Capacity: roachpb.StoreCapacity{
+				LeaseCount:       10,
+				QueriesPerSecond: qps[i-1],
+			},
+		})
+	}
+	sg := gossiputil.NewStoreGossiper(g)
+	sg.GossipStores(stores, t)
+
+	replicas := func(storeIDs ...roachpb.StoreID) []roachpb.ReplicaDescriptor {
+		var r []roachpb.ReplicaDescriptor
+		for _, storeID := range storeIDs {
+			r = append(r, roachpb.ReplicaDescriptor{
+				NodeID:  roachpb.NodeID(storeID),
+				StoreID: storeID,
+			})
+		}
+		return r
+	}
+
+	// The range serves 100 QPS.
+	manual := hlc.NewManualClock(123)
+	clock := hlc.NewClock(manual.UnixNano, time.Nanosecond)
+	stats := newReplicaStats(clock, nil)
+	stats.recordCount(6000, 0)
+	manual.Increment(int64(time.Minute))
+
+	testCases := []struct {
+		qpsEnabled  bool
+		leaseholder roachpb.StoreID
+		existing    []roachpb.ReplicaDescriptor
+		expected    roachpb.StoreID
+	}{
+		{qpsEnabled: false, leaseholder: 1, existing: replicas(1, 2, 3), expected: 0},
+		// The least loaded underfull store is preferred.
+		{qpsEnabled: true, leaseholder: 1, existing: replicas(1, 2, 3), expected: 2},
+		{qpsEnabled: true, leaseholder: 1, existing: replicas(1, 3), expected: 3},
+		{qpsEnabled: true, leaseholder: 1, existing: replicas(1), expected: 0},
+		// Stores which aren't overfull keep their leases.
+		{qpsEnabled: true, leaseholder: 2, existing: replicas(1, 2, 3), expected: 0},
+		{qpsEnabled: true, leaseholder: 3, existing: replicas(1, 2, 3), expected: 0},
+	}
+	for _, c := range testCases {
+		t.Run("", func(t *testing.T) {
+			EnableQPSBasedRebalancing.Override(&a.storePool.st.SV, c.qpsEnabled)
+			result := a.ShouldTransferLease(
+				context.Background(),
+				config.Constraints{},
+				c.existing,
+				c.leaseholder,
+				0,
+				stats,
+			)
+			if e := c.expected != 0; e != result {
+				t.Fatalf("expected %v, but found %v", e, result)
+			}
+			target := a.TransferLeaseTarget(
+				context.Background(),
+				config.Constraints{},
+				c.existing,
+				c.leaseholder,
+				0,
+				stats,
+				true,  /* checkTransferLeaseSource */
+				true,  /* checkCandidateFullness */
+				false, /* alwaysAllowDecisionWithoutStats */
+			)
+			if c.expected != target.StoreID {
+				t.Fatalf("expected %d, but found %d", c.expected, target.StoreID)
+			}
+		})
+	}
+}
+
 // Test out the load-based lease transfer algorithm against a variety of
 // request distributions and inter-node latencies.
 func TestAllocatorTransferLeaseTargetLoadBased(t *testing.T) {
@@ -2160,6 +2251,59 @@ func TestAllocatorRebalanceTargetDisableStatsRebalance(t *testing.T) {
 	}
 }
 
+// TestAllocatorRebalanceTargetIgnoresQPS verifies that replicas aren't moved
+// away from stores serving many queries per second, as the queries of a
+// range only move along with its lease.
+func TestAllocatorRebalanceTargetIgnoresQPS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ false)
+	defer stopper.Stop(ctx)
+
+	st := a.storePool.st
+	EnableStatsBasedRebalancing.Override(&st.SV, true)
+	EnableQPSBasedRebalancing.Override(&st.SV, true)
+	// The stores only differ by the queries they serve: the stores of the
+	// range are overloaded and store 4 serves no queries at all.
+	qps := []float64{3000, 3000, 3000, 0}
+	var stores []*roachpb.StoreDescriptor
+	for i := 1; i <= 4; i++ {
+		stores = append(stores, &roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i),
+			Node:    roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i)},
+			Capacity: roachpb.StoreCapacity{
+				RangeCount:       100,
+				WritesPerSecond:  30,
+				QueriesPerSecond: qps[i-1],
+			},
+		})
+	}
+	sg := gossiputil.NewStoreGossiper(g)
+	sg.GossipStores(stores, t)
+
+	desc := roachpb.RangeDescriptor{
+		RangeID: firstRange,
+		Replicas: []roachpb.ReplicaDescriptor{
+			{StoreID: 1, NodeID: 1, ReplicaID: 1},
+			{StoreID: 2, NodeID: 2, ReplicaID: 2},
+			{StoreID: 3, NodeID: 3, ReplicaID: 3},
+		},
+	}
+	for i := 0; i < 50; i++ {
+		target, _ := a.RebalanceTarget(
+			context.Background(),
+			config.Constraints{},
+			nil,
+			testRangeInfo(desc.Replicas, desc.RangeID),
+			storeFilterThrottled,
+			false, /* disableStatsBasedRebalancing */
+		)
+		if target != nil {
+			t.Errorf("expected no rebalance, but got %d", target.StoreID)
+		}
+	}
+}
+
 func TestAllocatorComputeActionRemoveDead(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
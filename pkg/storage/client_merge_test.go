@@ -19,9 +19,11 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -29,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -367,6 +370,116 @@ func TestStoreRangeMergeNonCollocated(t *testing.T) {
 	}
 }
 
+// TestStoreRangeMergeQueue verifies that the merge queue merges small
+// adjacent ranges, but not across table boundaries.
+func TestStoreRangeMergeQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	storeCfg := storage.TestStoreConfig(nil)
+	storeCfg.TestingKnobs.DisableSplitQueue = true
+	storage.MergeQueueEnabled.Override(&storeCfg.Settings.SV, true)
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	store := createTestStoreWithConfig(t, stopper, storeCfg)
+
+	for _, key := range []string{"b", "c", "d"} {
+		args := adminSplitArgs(roachpb.Key(key))
+		if _, pErr := client.SendWrapped(context.Background(), rg1(store), args); pErr != nil {
+			t.Fatalf("can't split range at %q: %s", key, pErr)
+		}
+	}
+
+	// The [b, c) and [c, d) ranges get merged. The first range isn't merged as
+	// it contains the meta ranges, and [d, KeyMax) isn't merged into [b, d) as
+	// it spans the system tables.
+	store.ForceMergeScanAndProcess()
+	testutils.SucceedsSoon(t, func() error {
+		desc := store.LookupReplica(roachpb.RKey("b"), nil).Desc()
+		if !desc.StartKey.Equal(roachpb.RKey("b")) || !desc.EndKey.Equal(roachpb.RKey("d")) {
+			return errors.Errorf("waiting for merge: %s", desc)
+		}
+		return nil
+	})
+	if desc := store.LookupReplica(roachpb.RKey("a"), nil).Desc(); !desc.EndKey.Equal(roachpb.RKey("b")) {
+		t.Fatalf("expected first range to end at \"b\"; got %s", desc)
+	}
+	if desc := store.LookupReplica(roachpb.RKey("d"), nil).Desc(); !desc.StartKey.Equal(roachpb.RKey("d")) {
+		t.Fatalf("expected last range to start at \"d\"; got %s", desc)
+	}
+}
+
+// laggingRaftHandler drops the raft messages of a range sent to a store while
+// dropping is set, making the store's replica lag behind.
+type laggingRaftHandler struct {
+	rangeID  roachpb.RangeID
+	dropping *int32
+	storage.RaftMessageHandler
+}
+
+func (h *laggingRaftHandler) HandleRaftRequest(
+	ctx context.Context,
+	req *storage.RaftMessageRequest,
+	respStream storage.RaftMessageResponseStream,
+) *roachpb.Error {
+	if req.RangeID == h.rangeID && atomic.LoadInt32(h.dropping) == 1 {
+		return nil
+	}
+	return h.RaftMessageHandler.HandleRaftRequest(ctx, req, respStream)
+}
+
+// TestStoreRangeMergeQueueLaggingFollower verifies that the merge queue
+// doesn't merge a range whose right-hand neighbor has a replica lagging
+// behind, and merges it once the replica has caught up.
+func TestStoreRangeMergeQueueLaggingFollower(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := storage.TestStoreConfig(nil)
+	sc.TestingKnobs.DisableSplitQueue = true
+	sc.TestingKnobs.DisableReplicateQueue = true
+	storage.MergeQueueEnabled.Override(&sc.Settings.SV, true)
+	mtc := &multiTestContext{storeConfig: &sc}
+	defer mtc.Stop()
+	mtc.Start(t, 3)
+	store := mtc.stores[0]
+
+	for _, key := range []string{"b", "c", "d"} {
+		args := adminSplitArgs(roachpb.Key(key))
+		if _, pErr := client.SendWrapped(context.Background(), rg1(store), args); pErr != nil {
+			t.Fatalf("can't split range at %q: %s", key, pErr)
+		}
+	}
+	lhsDesc := store.LookupReplica(roachpb.RKey("b"), nil).Desc()
+	rhsDesc := store.LookupReplica(roachpb.RKey("c"), nil).Desc()
+	mtc.replicateRange(lhsDesc.RangeID, 1, 2)
+	mtc.replicateRange(rhsDesc.RangeID, 1, 2)
+
+	// Make the right-hand range's replica on the third store miss a write.
+	dropping := int32(1)
+	mtc.transport.Listen(mtc.stores[2].StoreID(), &laggingRaftHandler{
+		rangeID:            rhsDesc.RangeID,
+		dropping:           &dropping,
+		RaftMessageHandler: mtc.stores[2],
+	})
+	incArgs := incrementArgs(roachpb.Key("c"), 5)
+	if _, pErr := client.SendWrapped(context.Background(), rg1(store), incArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	store.ForceMergeScanAndProcess()
+	if desc := store.LookupReplica(roachpb.RKey("b"), nil).Desc(); !desc.EndKey.Equal(rhsDesc.StartKey) {
+		t.Fatalf("expected no merge while a replica is lagging behind, got %s", desc)
+	}
+
+	atomic.StoreInt32(&dropping, 0)
+	testutils.SucceedsSoon(t, func() error {
+		store.ForceMergeScanAndProcess()
+		desc := store.LookupReplica(roachpb.RKey("b"), nil).Desc()
+		if !desc.EndKey.Equal(rhsDesc.EndKey) {
+			return errors.Errorf("waiting for merge: %s", desc)
+		}
+		return nil
+	})
+	mtc.waitForValues(roachpb.Key("c"), []int64{5, 5, 5})
+}
+
 // TestStoreRangeMergeStats starts by splitting a range, then writing random data
 // to both sides of the split. It then merges the ranges and verifies the merged
 // range has stats consistent with recomputations.
@@ -469,3 +582,150 @@ func BenchmarkStoreRangeMerge(b *testing.B) {
 		}
 	}
 }
+
+// TestStoreRangeMergeClosedTimestamp verifies that when the closed timestamp
+// of the subsumed range is ahead of the subsuming range's, the merged range
+// keeps the lower closed timestamp but evaluates writes above the higher one.
+func TestStoreRangeMergeClosedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	manual := hlc.NewManualClock(123)
+	storeCfg := storage.TestStoreConfig(hlc.NewClock(manual.UnixNano, time.Nanosecond))
+	storeCfg.TestingKnobs.DisableSplitQueue = true
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	store := createTestStoreWithConfig(t, stopper, storeCfg)
+
+	aDesc, bDesc, pErr := createSplitRanges(store)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	lhsRepl, err := store.GetReplica(aDesc.RangeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rhsRepl, err := store.GetReplica(bDesc.RangeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manual.Set((30 * time.Second).Nanoseconds())
+	lhsClosed := hlc.Timestamp{WallTime: (10 * time.Second).Nanoseconds()}
+	rhsClosed := hlc.Timestamp{WallTime: (20 * time.Second).Nanoseconds()}
+	lhsRepl.SetClosedTimestamp(lhsClosed)
+	rhsRepl.SetClosedTimestamp(rhsClosed)
+
+	args := adminMergeArgs(roachpb.KeyMin)
+	if _, pErr := client.SendWrapped(context.Background(), rg1(store), args); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if repl := store.LookupReplica(roachpb.RKey("c"), nil); repl != lhsRepl {
+		t.Fatalf("expected %s to be merged into %s", repl, lhsRepl)
+	}
+
+	// The keys of the left-hand side were never closed at the closed timestamp
+	// of the right-hand side.
+	if closed := lhsRepl.ClosedTimestamp(); closed != lhsClosed {
+		t.Fatalf("expected closed timestamp %s; got %s", lhsClosed, closed)
+	}
+	// Reads of the keys of the right-hand side may have been served at its
+	// closed timestamp, so the writes are evaluated above it.
+	if floor := lhsRepl.ClosedTimestampFloor(); floor.Less(rhsClosed) {
+		t.Fatalf("expected write floor at or above %s; got %s", rhsClosed, floor)
+	}
+	for _, key := range []string{"a", "c"} {
+		writeTS := hlc.Timestamp{WallTime: (15 * time.Second).Nanoseconds()}
+		ba := roachpb.BatchRequest{}
+		ba.RangeID = aDesc.RangeID
+		ba.Timestamp = writeTS
+		ba.Add(putArgs(roachpb.Key(key), []byte("value")))
+		br, pErr := store.Send(context.Background(), ba)
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		if !rhsClosed.Less(br.Timestamp) {
+			t.Fatalf("expected write of %q at %s to be pushed above %s; got %s",
+				key, writeTS, rhsClosed, br.Timestamp)
+		}
+	}
+}
+
+// TestStoreRangeMergeFollowerRead verifies that right after a merge, a
+// follower serves the reads of the keys of both sides at or below the lower
+// of the closed timestamps of the two ranges, and not above.
+func TestStoreRangeMergeFollowerRead(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := storage.TestStoreConfig(nil)
+	sc.TestingKnobs.DisableSplitQueue = true
+	sc.TestingKnobs.DisableReplicateQueue = true
+	mtc := &multiTestContext{storeConfig: &sc}
+	defer mtc.Stop()
+	mtc.Start(t, 2)
+	store, follower := mtc.stores[0], mtc.stores[1]
+
+	aDesc, bDesc, pErr := createSplitRanges(store)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	mtc.replicateRange(aDesc.RangeID, 1)
+	mtc.replicateRange(bDesc.RangeID, 1)
+	for _, key := range []string{"a", "c"} {
+		incArgs := incrementArgs(roachpb.Key(key), 5)
+		if _, pErr := client.SendWrapped(context.Background(), rg1(store), incArgs); pErr != nil {
+			t.Fatal(pErr)
+		}
+		mtc.waitForValues(roachpb.Key(key), []int64{5, 5})
+	}
+
+	lhsFollower, err := follower.GetReplica(aDesc.RangeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rhsFollower, err := follower.GetReplica(bDesc.RangeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtc.manualClock.Set((30 * time.Second).Nanoseconds())
+	lhsClosed := hlc.Timestamp{WallTime: (10 * time.Second).Nanoseconds()}
+	rhsClosed := hlc.Timestamp{WallTime: (20 * time.Second).Nanoseconds()}
+	lhsFollower.SetClosedTimestamp(lhsClosed)
+	rhsFollower.SetClosedTimestamp(rhsClosed)
+
+	args := adminMergeArgs(roachpb.KeyMin)
+	if _, pErr := client.SendWrapped(context.Background(), rg1(store), args); pErr != nil {
+		t.Fatal(pErr)
+	}
+	testutils.SucceedsSoon(t, func() error {
+		if repl := follower.LookupReplica(roachpb.RKey("c"), nil); repl != lhsFollower {
+			return errors.Errorf("waiting for %s to be merged into %s", repl, lhsFollower)
+		}
+		return nil
+	})
+	storagebase.FollowerReadsEnabled.Override(&follower.ClusterSettings().SV, true)
+
+	replDesc, err := lhsFollower.GetReplicaDescriptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(key string, ts hlc.Timestamp) (*roachpb.BatchResponse, *roachpb.Error) {
+		ba := roachpb.BatchRequest{}
+		ba.RangeID = aDesc.RangeID
+		ba.Replica = replDesc
+		ba.Timestamp = ts
+		ba.Add(getArgs(roachpb.Key(key)))
+		return follower.Send(context.Background(), ba)
+	}
+	for _, key := range []string{"a", "c"} {
+		br, pErr := get(key, lhsClosed)
+		if pErr != nil {
+			t.Fatalf("expected follower read of %q at %s to be served, got %s", key, lhsClosed, pErr)
+		}
+		if v, err := br.Responses[0].GetInner().(*roachpb.GetResponse).Value.GetInt(); err != nil || v != 5 {
+			t.Fatalf("expected follower read of %q to return 5, got %d (%v)", key, v, err)
+		}
+		// The keys of the left-hand side were never closed above lhsClosed.
+		readTS := hlc.Timestamp{WallTime: (15 * time.Second).Nanoseconds()}
+		if _, pErr := get(key, readTS); !testutils.IsPError(pErr, "not lease holder") {
+			t.Fatalf("expected follower read of %q at %s to be rejected, got %v", key, readTS, pErr)
+		}
+	}
+}
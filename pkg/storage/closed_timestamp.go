@@ -0,0 +1,115 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// closedTimestampTracker determines the timestamps which a leaseholder can
+// close, that is, promise not to evaluate any more writes at or below.
+//
+// Every write registers with the tracker before consulting the timestamp
+// cache and is forwarded above the tracker's next timestamp. It is released
+// once it has been assigned a lease index (or has failed to be proposed).
+// The tracker distinguishes between the writes which registered before next
+// was last moved (left) and those which registered since (right). Once all
+// of the left writes have been released, next can be closed: any write below
+// it has been assigned a lease index, and all other writes are evaluated
+// above it.
+//
+// The closed timestamp is attached to the Raft commands proposed by the
+// leaseholder. A command carrying a closed timestamp is assigned a higher
+// lease index than all the writes below that timestamp, so these writes have
+// either applied before the command or will never apply. A follower which
+// applied the command can thus serve reads at or below the closed timestamp.
+// Note that a range publishes new closed timestamps only along with its
+// writes; reads on a range which doesn't receive writes are redirected to
+// the leaseholder once they're above its last closed timestamp.
+type closedTimestampTracker struct {
+	mu struct {
+		syncutil.Mutex
+		closed hlc.Timestamp
+		next   hlc.Timestamp
+		// floor is a timestamp at or below which no write is evaluated, even
+		// if it is above next. It isn't closed by the tracker.
+		floor hlc.Timestamp
+		// epoch is incremented every time next is moved.
+		epoch             int64
+		leftRef, rightRef int
+	}
+}
+
+// track registers a write which is about to be evaluated. It returns the
+// timestamp above which the write has to be evaluated, and a function to
+// call once the write has been assigned a lease index or has failed to be
+// proposed. The function may be called multiple times.
+func (t *closedTimestampTracker) track() (hlc.Timestamp, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mu.rightRef++
+	epoch := t.mu.epoch
+	var released bool
+	minTS := t.mu.next
+	minTS.Forward(t.mu.floor)
+	return minTS, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if released {
+			return
+		}
+		released = true
+		if epoch == t.mu.epoch {
+			t.mu.rightRef--
+		} else {
+			t.mu.leftRef--
+		}
+	}
+}
+
+// close closes the tracker's next timestamp if all the writes which may have
+// been evaluated below it have been released, in which case next is moved to
+// newNext. It returns the closed timestamp.
+func (t *closedTimestampTracker) close(newNext hlc.Timestamp) hlc.Timestamp {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.leftRef == 0 && t.mu.next.Less(newNext) {
+		t.mu.closed = t.mu.next
+		t.mu.next = newNext
+		t.mu.leftRef, t.mu.rightRef = t.mu.rightRef, 0
+		t.mu.epoch++
+	}
+	return t.mu.closed
+}
+
+// floor returns the timestamp above which writes are currently evaluated.
+func (t *closedTimestampTracker) floor() hlc.Timestamp {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	floor := t.mu.next
+	floor.Forward(t.mu.floor)
+	return floor
+}
+
+// forwardFloor prevents writes from being evaluated at or below ts from now
+// on. It doesn't affect the timestamps closed by the tracker: the writes
+// being evaluated may still be below ts. It is used when a range subsumes
+// another one, whose closed timestamp may be higher.
+func (t *closedTimestampTracker) forwardFloor(ts hlc.Timestamp) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mu.floor.Forward(ts)
+}
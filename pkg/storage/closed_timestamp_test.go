@@ -0,0 +1,123 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestClosedTimestampTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := func(wallTime int64) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: wallTime}
+	}
+	var tracker closedTimestampTracker
+
+	// Without any writes, next is closed right away.
+	if closed := tracker.close(ts(1)); closed != (hlc.Timestamp{}) {
+		t.Fatalf("expected no closed timestamp; got %s", closed)
+	}
+	if closed := tracker.close(ts(2)); closed != ts(1) {
+		t.Fatalf("expected closed timestamp %s; got %s", ts(1), closed)
+	}
+
+	// A write is forwarded above the next timestamp.
+	minTS1, untrack1 := tracker.track()
+	if minTS1 != ts(2) {
+		t.Fatalf("expected min timestamp %s; got %s", ts(2), minTS1)
+	}
+	// The write was forwarded above next, so next can be closed. The write
+	// holds back the closing of the new next, though.
+	if closed := tracker.close(ts(3)); closed != ts(2) {
+		t.Fatalf("expected closed timestamp %s; got %s", ts(2), closed)
+	}
+	minTS2, untrack2 := tracker.track()
+	if minTS2 != ts(3) {
+		t.Fatalf("expected min timestamp %s; got %s", ts(3), minTS2)
+	}
+	if closed := tracker.close(ts(4)); closed != ts(2) {
+		t.Fatalf("expected closed timestamp %s; got %s", ts(2), closed)
+	}
+
+	// Releasing the first write allows closing the next timestamp. Releasing
+	// a write twice has no effect.
+	untrack1()
+	untrack1()
+	if closed := tracker.close(ts(4)); closed != ts(3) {
+		t.Fatalf("expected closed timestamp %s; got %s", ts(3), closed)
+	}
+	if closed := tracker.close(ts(5)); closed != ts(3) {
+		t.Fatalf("expected closed timestamp %s; got %s", ts(3), closed)
+	}
+	untrack2()
+	if closed := tracker.close(ts(5)); closed != ts(4) {
+		t.Fatalf("expected closed timestamp %s; got %s", ts(4), closed)
+	}
+
+	// The closed timestamp doesn't regress.
+	if closed := tracker.close(ts(1)); closed != ts(4) {
+		t.Fatalf("expected closed timestamp %s; got %s", ts(4), closed)
+	}
+}
+
+func TestClosedTimestampTrackerForwardFloor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := func(wallTime int64) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: wallTime}
+	}
+	var tracker closedTimestampTracker
+	tracker.close(ts(1))
+	tracker.close(ts(2))
+
+	// A write is in flight when the floor is forwarded, e.g. by a merge. The
+	// writes are evaluated above the floor from then on.
+	minTS1, untrack1 := tracker.track()
+	if minTS1 != ts(2) {
+		t.Fatalf("expected min timestamp %s; got %s", ts(2), minTS1)
+	}
+	tracker.forwardFloor(ts(7))
+	if floor := tracker.floor(); floor != ts(7) {
+		t.Fatalf("expected floor %s; got %s", ts(7), floor)
+	}
+	minTS2, untrack2 := tracker.track()
+	if minTS2 != ts(7) {
+		t.Fatalf("expected min timestamp %s; got %s", ts(7), minTS2)
+	}
+
+	// The floor isn't closed: the write in flight may still be evaluated
+	// below it.
+	if closed := tracker.close(ts(3)); closed != ts(2) {
+		t.Fatalf("expected closed timestamp %s; got %s", ts(2), closed)
+	}
+	if closed := tracker.close(ts(4)); closed != ts(2) {
+		t.Fatalf("expected closed timestamp %s; got %s", ts(2), closed)
+	}
+	untrack1()
+	untrack2()
+	if closed := tracker.close(ts(4)); closed != ts(3) {
+		t.Fatalf("expected closed timestamp %s; got %s", ts(3), closed)
+	}
+
+	// Lower floors have no effect.
+	tracker.forwardFloor(ts(5))
+	if floor := tracker.floor(); floor != ts(7) {
+		t.Fatalf("expected floor %s; got %s", ts(7), floor)
+	}
+}
@@ -19,6 +19,17 @@ func (t TxnMeta) Short() string {
 	return t.ID.Short()
 }
 
+// IsIgnoredSeqNum returns true if writes performed at the given sequence
+// number have been rolled back by a ROLLBACK TO SAVEPOINT.
+func (t *TxnMeta) IsIgnoredSeqNum(seq int32) bool {
+	for _, r := range t.IgnoredSeqNums {
+		if r.Start <= seq && seq <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
 // Total returns the range size as the sum of the key and value
 // bytes. This includes all non-live keys and all versioned values.
 func (ms MVCCStats) Total() int64 {
@@ -123,3 +134,15 @@ func (ms *MVCCStats) Subtract(oms MVCCStats) {
 func (meta MVCCMetadata) IsInline() bool {
 	return meta.RawBytes != nil
 }
+
+// LatestUnignoredIntent returns the most recent earlier value of the
+// intent that was not written at a sequence number ignored by txn.
+// Returns false if there is no such value.
+func (meta MVCCMetadata) LatestUnignoredIntent(txn *TxnMeta) (SequencedIntent, bool) {
+	for i := len(meta.IntentHistory) - 1; i >= 0; i-- {
+		if !txn.IsIgnoredSeqNum(meta.IntentHistory[i].Sequence) {
+			return meta.IntentHistory[i], true
+		}
+	}
+	return SequencedIntent{}, false
+}
@@ -737,6 +737,21 @@ func mvccGetInternal(
 					txn.Epoch, meta.Txn.Epoch)
 			}
 			seekKey = seekKey.Next()
+		} else if ownIntent && txn.IsIgnoredSeqNum(meta.Txn.Sequence) {
+			// The intent was written by a batch which has since been rolled
+			// back to a savepoint. Return the latest earlier value of the
+			// intent which is still visible to the transaction or, if there is
+			// none, the value below the intent.
+			if prev, ok := meta.LatestUnignoredIntent(&txn.TxnMeta); ok {
+				value := &buf.value
+				value.RawBytes = prev.Value
+				value.Timestamp = metaTimestamp
+				if err := value.Verify(metaKey.Key); err != nil {
+					return nil, nil, safeValue, err
+				}
+				return value, ignoredIntents, safeValue, nil
+			}
+			seekKey = seekKey.Next()
 		}
 	} else if txn != nil && timestamp.Less(txn.MaxTimestamp) {
 		// In this branch, the latest timestamp is ahead, and so the read of an
@@ -999,6 +1014,43 @@ func maybeGetValue(
 	return valueFn(exVal)
 }
 
+// appendIntentHistory appends to history the values of the intent described
+// by meta which are still visible to txn: the earlier values in the intent's
+// history and the value of the intent itself. The latter is omitted if it was
+// written by the same batch as txn's pending write, or after txn's latest
+// savepoint: rolling back to any savepoint would discard it anyway. This
+// keeps at most one value per savepoint in the history.
+func appendIntentHistory(
+	iter Iterator,
+	metaKey MVCCKey,
+	meta *enginepb.MVCCMetadata,
+	txn *roachpb.Transaction,
+	history []enginepb.SequencedIntent,
+) ([]enginepb.SequencedIntent, error) {
+	for _, h := range meta.IntentHistory {
+		if !txn.IsIgnoredSeqNum(h.Sequence) {
+			history = append(history, h)
+		}
+	}
+	if meta.Txn.Sequence == txn.Sequence || meta.Txn.Sequence > txn.SavepointSeq ||
+		txn.IsIgnoredSeqNum(meta.Txn.Sequence) {
+		return history, nil
+	}
+	versionKey := metaKey
+	versionKey.Timestamp = hlc.Timestamp(meta.Timestamp)
+	iter.Seek(versionKey)
+	if ok, err := iter.Valid(); err != nil {
+		return nil, err
+	} else if !ok || !iter.UnsafeKey().Equal(versionKey) {
+		return nil, errors.Errorf("unable to find intent value for %s @ %s",
+			metaKey.Key, versionKey.Timestamp)
+	}
+	return append(history, enginepb.SequencedIntent{
+		Sequence: meta.Txn.Sequence,
+		Value:    iter.Value(),
+	}), nil
+}
+
 // mvccPutInternal adds a new timestamped value to the specified key.
 // If value is nil, creates a deletion tombstone value. valueFn is
 // an optional alternative to supplying value directly. It is passed
@@ -1057,6 +1109,7 @@ func mvccPutInternal(
 	}
 
 	var meta *enginepb.MVCCMetadata
+	var intentHistory []enginepb.SequencedIntent
 	var maybeTooOldErr error
 	if ok {
 		// There is existing metadata for this key; ensure our write is permitted.
@@ -1086,6 +1139,16 @@ func mvccPutInternal(
 				ctx, iter, metaKey, value, ok, timestamp, txn, buf, valueFn); err != nil {
 				return err
 			}
+			// Within the same epoch, remember the values we're replacing so
+			// that they can be restored if this write is rolled back to a
+			// savepoint. Values which have already been rolled back are
+			// dropped from the history.
+			if txn.Epoch == meta.Txn.Epoch {
+				if intentHistory, err = appendIntentHistory(
+					iter, metaKey, meta, txn, intentHistory); err != nil {
+					return err
+				}
+			}
 			// We are replacing our own older write intent. If we are
 			// writing at the same timestamp we can simply overwrite it;
 			// otherwise we must explicitly delete the obsolete intent.
@@ -1141,10 +1204,20 @@ func mvccPutInternal(
 		var txnMeta *enginepb.TxnMeta
 		if txn != nil {
 			txnMeta = &txn.TxnMeta
+			if len(txnMeta.IgnoredSeqNums) > 0 || txnMeta.SavepointSeq != 0 {
+				// The ignored sequence numbers and savepoints are only of
+				// interest to the transaction itself; don't store them with
+				// the intent.
+				buf.newTxn = *txnMeta
+				buf.newTxn.IgnoredSeqNums = nil
+				buf.newTxn.SavepointSeq = 0
+				txnMeta = &buf.newTxn
+			}
 		}
 		buf.newMeta = enginepb.MVCCMetadata{
-			Txn:       txnMeta,
-			Timestamp: hlc.LegacyTimestamp(timestamp),
+			Txn:           txnMeta,
+			Timestamp:     hlc.LegacyTimestamp(timestamp),
+			IntentHistory: intentHistory,
 		}
 	}
 	newMeta := &buf.newMeta
@@ -1922,6 +1995,35 @@ func mvccResolveWriteIntent(
 	timestampsValid := !intent.Txn.Timestamp.Less(hlc.Timestamp(meta.Timestamp))
 	commit := intent.Status == roachpb.COMMITTED && epochsMatch && timestampsValid
 
+	// An intent written by a batch which was later rolled back to a savepoint
+	// must not be committed. Instead, revert it to the latest earlier value
+	// which was not rolled back and commit that or, if there is none, remove
+	// the intent as if the transaction had aborted.
+	if commit && intent.Txn.IsIgnoredSeqNum(meta.Txn.Sequence) {
+		prev, ok := meta.LatestUnignoredIntent(&intent.Txn)
+		if !ok {
+			commit = false
+		} else {
+			versionKey := MVCCKey{Key: intent.Key, Timestamp: hlc.Timestamp(meta.Timestamp)}
+			if err := engine.Put(versionKey, prev.Value); err != nil {
+				return err
+			}
+			// The metadata key is cleared below, so there's no need to write
+			// the updated metadata; it's only used for the stats.
+			buf.newMeta = *meta
+			buf.newMeta.ValBytes = int64(len(prev.Value))
+			buf.newMeta.Deleted = len(prev.Value) == 0
+			buf.newMeta.IntentHistory = nil
+			metaKeySize, metaValSize := origMetaKeySize, int64(buf.newMeta.Size())
+			if ms != nil {
+				ms.Add(updateStatsOnPut(intent.Key, origMetaKeySize, origMetaValSize,
+					metaKeySize, metaValSize, meta, &buf.newMeta))
+			}
+			*meta = buf.newMeta
+			origMetaKeySize, origMetaValSize = metaKeySize, metaValSize
+		}
+	}
+
 	// Note the small difference to commit epoch handling here: We allow a push
 	// from a previous epoch to move a newer intent. That's not necessary, but
 	// useful. Consider the following, where B reads at a timestamp that's
@@ -1963,8 +2065,14 @@ func mvccResolveWriteIntent(
 		var metaKeySize, metaValSize int64
 		var err error
 		if pushed {
-			// Keep intent if we're pushing timestamp.
+			// Keep intent if we're pushing timestamp. The intent retains the
+			// sequence number it was written at, which determines whether it
+			// was rolled back to a savepoint.
 			buf.newTxn = intent.Txn
+			buf.newTxn.Sequence = meta.Txn.Sequence
+			buf.newTxn.BatchIndex = meta.Txn.BatchIndex
+			buf.newTxn.IgnoredSeqNums = nil
+			buf.newTxn.SavepointSeq = 0
 			buf.newMeta.Txn = &buf.newTxn
 			metaKeySize, metaValSize, err = buf.putMeta(engine, metaKey, &buf.newMeta)
 		} else {
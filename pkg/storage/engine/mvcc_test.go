@@ -2776,6 +2776,137 @@ func TestMVCCWriteWithSequenceAndBatchIndex(t *testing.T) {
 	}
 }
 
+// TestMVCCIgnoredSeqNums verifies that the writes performed by a transaction
+// at sequence numbers which were rolled back to a savepoint are invisible to
+// the transaction and are not committed.
+func TestMVCCIgnoredSeqNums(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	engine := createTestEngine()
+	defer engine.Close()
+
+	ctx := context.Background()
+	ts := hlc.Timestamp{Logical: 1}
+	txn := *txn1
+	// A savepoint is created after the writes at sequence number 1.
+	txn.SavepointSeq = 1
+	for _, w := range []struct {
+		seq   int32
+		key   roachpb.Key
+		value roachpb.Value
+	}{
+		{1, testKey1, value1},
+		{2, testKey1, value2},
+		{2, testKey2, value2},
+		{3, testKey1, value3},
+		{3, testKey3, value3},
+	} {
+		txn.Sequence = w.seq
+		if err := MVCCPut(ctx, engine, nil, w.key, ts, w.value, &txn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Roll back the writes performed at sequence numbers 2 and 3, then write
+	// testKey2 again.
+	txn.IgnoredSeqNums = []enginepb.IgnoredSeqNumRange{{Start: 2, End: 3}}
+	txn.Sequence = 4
+	if err := MVCCPut(ctx, engine, nil, testKey2, ts, value4, &txn); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		key   roachpb.Key
+		value *roachpb.Value
+	}{
+		{testKey1, &value1},
+		{testKey2, &value4},
+		{testKey3, nil},
+	}
+	check := func(readTxn *roachpb.Transaction) {
+		for _, e := range expected {
+			value, _, err := MVCCGet(ctx, engine, e.key, ts, true, readTxn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.value == nil {
+				if value != nil {
+					t.Errorf("%s: expected no value, got %s", e.key, value.RawBytes)
+				}
+			} else if value == nil || !bytes.Equal(e.value.RawBytes, value.RawBytes) {
+				t.Errorf("%s: expected value %s, got %v", e.key, e.value.RawBytes, value)
+			}
+		}
+	}
+	check(&txn)
+
+	// Commit the transaction; only the values visible to it are committed.
+	txnCommit := txn.Clone()
+	txnCommit.Status = roachpb.COMMITTED
+	for _, e := range expected {
+		if err := MVCCResolveWriteIntent(ctx, engine, nil, roachpb.Intent{
+			Span: roachpb.Span{Key: e.key}, Txn: txnCommit.TxnMeta, Status: txnCommit.Status,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(nil)
+}
+
+// TestMVCCIntentHistory verifies that the values overwritten by a transaction
+// are only kept in the history of its intents if they were written before its
+// latest savepoint.
+func TestMVCCIntentHistory(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	engine := createTestEngine()
+	defer engine.Close()
+
+	ctx := context.Background()
+	ts := hlc.Timestamp{Logical: 1}
+	txn := *txn1
+	historyLen := func() int {
+		meta := &enginepb.MVCCMetadata{}
+		if ok, _, _, err := engine.GetProto(mvccKey(testKey1), meta); err != nil {
+			t.Fatal(err)
+		} else if !ok {
+			t.Fatal("intent not found")
+		}
+		if meta.Txn.SavepointSeq != 0 {
+			t.Fatalf("expected savepoint not to be stored in the intent; got %d", meta.Txn.SavepointSeq)
+		}
+		return len(meta.IntentHistory)
+	}
+	put := func(seq int32) {
+		txn.Sequence = seq
+		if err := MVCCPut(ctx, engine, nil, testKey1, ts, value1, &txn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without savepoints, none of the overwritten values can be restored.
+	for seq := int32(1); seq <= 5; seq++ {
+		put(seq)
+	}
+	if l := historyLen(); l != 0 {
+		t.Fatalf("expected empty intent history without savepoints; got %d values", l)
+	}
+
+	// Only the value written before the savepoint is kept, however many
+	// times the key is overwritten after it.
+	txn.SavepointSeq = 5
+	for seq := int32(6); seq <= 10; seq++ {
+		put(seq)
+	}
+	if l := historyLen(); l != 1 {
+		t.Fatalf("expected a single value in the intent history; got %d", l)
+	}
+	txn.SavepointSeq = 10
+	put(11)
+	put(12)
+	if l := historyLen(); l != 2 {
+		t.Fatalf("expected two values in the intent history; got %d", l)
+	}
+}
+
 // TestMVCCReadWithPushedTimestamp verifies that a read for a value
 // written by the transaction, but then subsequently pushed, can still
 // be read by the txn at the later timestamp, even if an earlier
@@ -99,6 +99,12 @@ func (s *Store) ForceSplitScanAndProcess() {
 	forceScanAndProcess(s, s.splitQueue.baseQueue)
 }
 
+// ForceMergeScanAndProcess iterates over all ranges and enqueues any that
+// may need to be merged.
+func (s *Store) ForceMergeScanAndProcess() {
+	forceScanAndProcess(s, s.mergeQueue.baseQueue)
+}
+
 // ForceRaftLogScanAndProcess iterates over all ranges and enqueues any that
 // need their raft logs truncated and then process each of them.
 func (s *Store) ForceRaftLogScanAndProcess() {
@@ -248,6 +254,20 @@ func (r *Replica) DescLocked() *roachpb.RangeDescriptor {
 	return r.mu.state.Desc
 }
 
+// SetClosedTimestamp sets the timestamp at or below which the replica serves
+// follower reads.
+func (r *Replica) SetClosedTimestamp(ts hlc.Timestamp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.closedTimestamp = ts
+}
+
+// ClosedTimestampFloor returns the timestamp above which the writes to the
+// replica are evaluated.
+func (r *Replica) ClosedTimestampFloor() hlc.Timestamp {
+	return r.closedTimestampTracker.floor()
+}
+
 func (r *Replica) AssertState(ctx context.Context, reader engine.Reader) {
 	r.raftMu.Lock()
 	defer r.raftMu.Unlock()
@@ -0,0 +1,219 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// LoadSplitEnabled is a setting that controls whether ranges are split
+// based on their load.
+var LoadSplitEnabled = settings.RegisterBoolSetting(
+	"kv.range_split.by_load_enabled",
+	"allow automatic splits of ranges based on where load is concentrated",
+	true,
+)
+
+// LoadSplitQPSThreshold is the QPS above which a range is split based on its
+// load.
+var LoadSplitQPSThreshold = settings.RegisterIntSetting(
+	"kv.range_split.load_qps_threshold",
+	"the QPS over which the range becomes a candidate for load based splitting",
+	250,
+)
+
+const (
+	// splitKeySampleSize is the number of request keys sampled to find a
+	// split key.
+	splitKeySampleSize = 20
+	// splitKeyMinCounter is the minimum number of requests which have to be
+	// classified relative to a sampled key before it's considered as a split
+	// key.
+	splitKeyMinCounter = 100
+	// splitKeyBalanceThreshold is the maximum imbalance between the requests
+	// to the left and to the right of a split key, as a fraction of the
+	// requests on either side.
+	splitKeyBalanceThreshold = 0.25
+	// splitKeyContainedThreshold is the maximum fraction of the requests
+	// whose span contains a split key, as such requests keep touching both
+	// sides of the split.
+	splitKeyContainedThreshold = 0.5
+
+	// loadSplitRecordDuration is the duration for which the requests to a
+	// range are sampled before a split key is suggested.
+	loadSplitRecordDuration = 10 * time.Second
+	// loadSplitSuggestionInterval is the minimum duration between two
+	// suggestions to split a range based on its load.
+	loadSplitSuggestionInterval = time.Minute
+)
+
+// splitKeySample is a sampled request key along with the number of requests
+// which would fall to its left or right, or straddle it, if the range were
+// split at the key.
+type splitKeySample struct {
+	key                    roachpb.Key
+	left, right, contained int
+}
+
+// splitKeyFinder finds a key which splits the requests received by a range
+// evenly. It keeps a reservoir sample of the start keys of the requests and
+// classifies each subsequent request relative to every sampled key.
+type splitKeyFinder struct {
+	startTime time.Time
+	count     int
+	samples   [splitKeySampleSize]splitKeySample
+}
+
+func newSplitKeyFinder(startTime time.Time) *splitKeyFinder {
+	return &splitKeyFinder{startTime: startTime}
+}
+
+// ready returns true if the requests have been sampled for long enough for
+// key() to be meaningful.
+func (f *splitKeyFinder) ready(now time.Time) bool {
+	return now.Sub(f.startTime) >= loadSplitRecordDuration
+}
+
+// record records a request touching the given span. intn returns a random
+// integer in [0, n).
+func (f *splitKeyFinder) record(span roachpb.Span, intn func(n int) int) {
+	count := f.count
+	f.count++
+	var idx int
+	if count < len(f.samples) {
+		idx = count
+	} else if idx = intn(count + 1); idx >= len(f.samples) {
+		// The request isn't sampled: classify it relative to the sampled keys.
+		for i := range f.samples {
+			s := &f.samples[i]
+			if bytes.Compare(s.key, span.Key) <= 0 {
+				// The request starts at or after the sampled key.
+				s.right++
+			} else if len(span.EndKey) > 0 && bytes.Compare(s.key, span.EndKey) < 0 {
+				s.contained++
+			} else {
+				s.left++
+			}
+		}
+		return
+	}
+	f.samples[idx] = splitKeySample{key: span.Key}
+}
+
+// key returns the sampled key which best balances the requests between the
+// two sides of a split, or nil if no key balances them well enough.
+func (f *splitKeyFinder) key() roachpb.Key {
+	bestIdx := -1
+	bestScore := math.Inf(1)
+	for i, s := range f.samples {
+		total := s.left + s.right + s.contained
+		if total < splitKeyMinCounter || s.left+s.right == 0 {
+			continue
+		}
+		balanceScore := math.Abs(float64(s.left-s.right)) / float64(s.left+s.right)
+		containedScore := float64(s.contained) / float64(total)
+		if balanceScore >= splitKeyBalanceThreshold || containedScore >= splitKeyContainedThreshold {
+			continue
+		}
+		if score := balanceScore + containedScore; score < bestScore {
+			bestIdx, bestScore = i, score
+		}
+	}
+	if bestIdx == -1 {
+		return nil
+	}
+	return f.samples[bestIdx].key
+}
+
+// loadSplitDecider decides when a range should be split based on its load.
+// It measures the range's QPS over one second intervals and starts sampling
+// the requests to find a split key once the QPS exceeds a threshold. The
+// sampling stops as soon as the QPS falls below the threshold.
+type loadSplitDecider struct {
+	intn func(n int) int
+
+	mu struct {
+		syncutil.Mutex
+		lastQPSRollover time.Time // the start of the current interval
+		count           int       // the number of requests in the current interval
+		qps             float64   // the QPS over the last complete interval
+
+		finder             *splitKeyFinder // nil if not sampling
+		lastSplitSuggested time.Time
+	}
+}
+
+func newLoadSplitDecider(intn func(n int) int) *loadSplitDecider {
+	return &loadSplitDecider{intn: intn}
+}
+
+// record records n requests at time now. spanFn is only invoked when the
+// requests are being sampled, and returns the span they touch. A threshold of
+// zero disables sampling. record returns true when the range should be
+// queued for a load-based split.
+func (d *loadSplitDecider) record(
+	now time.Time, n int, threshold float64, spanFn func() roachpb.Span,
+) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.mu.count += n
+	var shouldSplit bool
+	if elapsed := now.Sub(d.mu.lastQPSRollover); elapsed >= time.Second {
+		d.mu.qps = float64(d.mu.count) / elapsed.Seconds()
+		d.mu.count = 0
+		d.mu.lastQPSRollover = now
+
+		if threshold <= 0 || d.mu.qps < threshold {
+			d.mu.finder = nil
+		} else if d.mu.finder == nil {
+			d.mu.finder = newSplitKeyFinder(now)
+		} else if d.mu.finder.ready(now) && d.mu.finder.key() != nil &&
+			now.Sub(d.mu.lastSplitSuggested) >= loadSplitSuggestionInterval {
+			d.mu.lastSplitSuggested = now
+			shouldSplit = true
+		}
+	}
+	if d.mu.finder != nil {
+		d.mu.finder.record(spanFn(), d.intn)
+	}
+	return shouldSplit
+}
+
+// maybeSplitKey returns the key at which the range should be split to
+// balance its load, or nil if it shouldn't be split.
+func (d *loadSplitDecider) maybeSplitKey(now time.Time) roachpb.Key {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.mu.finder == nil || !d.mu.finder.ready(now) {
+		return nil
+	}
+	return d.mu.finder.key()
+}
+
+// reset discards the state of the decider, e.g. after the range was split.
+func (d *loadSplitDecider) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mu.count = 0
+	d.mu.qps = 0
+	d.mu.finder = nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func testLoadSplitKey(i int) roachpb.Key {
+	return roachpb.Key(fmt.Sprintf("key%03d", i))
+}
+
+func TestSplitKeyFinder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		name string
+		// span returns the span of the i-th request.
+		span func(i int) roachpb.Span
+		// The returned key is expected to be in [minKey, maxKey), or nil if
+		// minKey is nil.
+		minKey, maxKey roachpb.Key
+	}{
+		{
+			name:   "uniform point requests",
+			span:   func(i int) roachpb.Span { return roachpb.Span{Key: testLoadSplitKey(i % 100)} },
+			minKey: testLoadSplitKey(30),
+			maxKey: testLoadSplitKey(70),
+		},
+		{
+			name: "uniform span requests",
+			span: func(i int) roachpb.Span {
+				return roachpb.Span{Key: testLoadSplitKey(i % 100), EndKey: testLoadSplitKey(i%100 + 2)}
+			},
+			minKey: testLoadSplitKey(30),
+			maxKey: testLoadSplitKey(70),
+		},
+		{
+			name: "single hot key",
+			span: func(i int) roachpb.Span { return roachpb.Span{Key: testLoadSplitKey(1)} },
+		},
+		{
+			name: "requests spanning the range",
+			span: func(i int) roachpb.Span {
+				return roachpb.Span{Key: testLoadSplitKey(0), EndKey: testLoadSplitKey(100)}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			f := newSplitKeyFinder(time.Time{})
+			for i := 0; i < 10000; i++ {
+				f.record(tc.span(i), rng.Intn)
+			}
+			key := f.key()
+			if tc.minKey == nil {
+				if key != nil {
+					t.Fatalf("expected no split key; got %s", key)
+				}
+				return
+			}
+			if key == nil || bytes.Compare(key, tc.minKey) < 0 || bytes.Compare(key, tc.maxKey) >= 0 {
+				t.Fatalf("expected split key in [%s, %s); got %s", tc.minKey, tc.maxKey, key)
+			}
+		})
+	}
+}
+
+// TestSplitKeyFinderSampleDistribution verifies that every request is equally
+// likely to end up in the reservoir sample, including the requests recorded
+// right after the reservoir filled up.
+func TestSplitKeyFinderSampleDistribution(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const trials = 10000
+	rng := rand.New(rand.NewSource(1))
+	for _, numRequests := range []int{splitKeySampleSize + 1, 2 * splitKeySampleSize} {
+		t.Run(fmt.Sprintf("requests=%d", numRequests), func(t *testing.T) {
+			sampled := make(map[string]int, numRequests)
+			for i := 0; i < trials; i++ {
+				f := newSplitKeyFinder(time.Time{})
+				for j := 0; j < numRequests; j++ {
+					f.record(roachpb.Span{Key: testLoadSplitKey(j)}, rng.Intn)
+				}
+				for _, s := range f.samples {
+					sampled[string(s.key)]++
+				}
+			}
+			// Every request is sampled with probability
+			// splitKeySampleSize/numRequests. Check both the number of times
+			// each request is and isn't sampled, as a request which is never
+			// dropped is barely distinguishable by the former.
+			expSampled := float64(trials*splitKeySampleSize) / float64(numRequests)
+			expDropped := trials - expSampled
+			for j := 0; j < numRequests; j++ {
+				key := testLoadSplitKey(j)
+				n := float64(sampled[string(key)])
+				if n < 0.8*expSampled || n > 1.2*expSampled ||
+					trials-n < 0.8*expDropped || trials-n > 1.2*expDropped {
+					t.Errorf("expected %s to be sampled ~%.0f times out of %d; got %.0f",
+						key, expSampled, trials, n)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadSplitDecider(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const threshold = 100
+	rng := rand.New(rand.NewSource(1))
+	d := newLoadSplitDecider(rng.Intn)
+
+	// runLoad records requests at the given QPS for the given duration,
+	// starting at start. It returns the number of times the decider
+	// suggested a split.
+	runLoad := func(start time.Time, qps int, duration time.Duration, threshold float64) int {
+		var suggestions int
+		interval := time.Second / time.Duration(qps)
+		for i := 0; time.Duration(i)*interval < duration; i++ {
+			span := roachpb.Span{Key: testLoadSplitKey(i % 100)}
+			if d.record(start.Add(time.Duration(i)*interval), 1, threshold, func() roachpb.Span {
+				return span
+			}) {
+				suggestions++
+			}
+		}
+		return suggestions
+	}
+
+	start := time.Unix(1000, 0)
+
+	// A range below the threshold is never split.
+	if n := runLoad(start, threshold/2, 20*time.Second, threshold); n != 0 {
+		t.Fatalf("expected no split suggestion below the threshold; got %d", n)
+	}
+	if key := d.maybeSplitKey(start.Add(20 * time.Second)); key != nil {
+		t.Fatalf("expected no split key below the threshold; got %s", key)
+	}
+
+	// Load-based splitting is disabled with a zero threshold.
+	start = start.Add(20 * time.Second)
+	if n := runLoad(start, 2*threshold, 20*time.Second, 0); n != 0 {
+		t.Fatalf("expected no split suggestion when disabled; got %d", n)
+	}
+
+	// A range above the threshold is split once a split key has been found,
+	// and the split is only suggested once.
+	start = start.Add(20 * time.Second)
+	if n := runLoad(start, 2*threshold, 20*time.Second, threshold); n != 1 {
+		t.Fatalf("expected a single split suggestion above the threshold; got %d", n)
+	}
+	if key := d.maybeSplitKey(start.Add(20 * time.Second)); key == nil {
+		t.Fatal("expected a split key above the threshold")
+	}
+
+	// The split key is forgotten once the load falls below the threshold.
+	start = start.Add(20 * time.Second)
+	runLoad(start, threshold/2, 5*time.Second, threshold)
+	if key := d.maybeSplitKey(start.Add(5 * time.Second)); key != nil {
+		t.Fatalf("expected no split key after the load decreased; got %s", key)
+	}
+
+	// Resetting the decider forgets the split key.
+	start = start.Add(5 * time.Second)
+	runLoad(start, 2*threshold, 20*time.Second, threshold)
+	d.reset()
+	if key := d.maybeSplitKey(start.Add(20 * time.Second)); key != nil {
+		t.Fatalf("expected no split key after a reset; got %s", key)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+const (
+	// mergeQueueTimerDuration is the duration between merges of queued ranges.
+	mergeQueueTimerDuration = 0 // zero duration to process merges greedily.
+
+	// mergeQueueCatchUpTimeout is how long the merge queue waits for the
+	// replicas of the right-hand neighbor to catch up with its leaseholder
+	// before giving up on a merge.
+	mergeQueueCatchUpTimeout = 5 * time.Second
+)
+
+// MergeQueueEnabled is a setting that controls whether the merge queue is
+// enabled. It is off by default as the queue undoes the splits performed
+// manually with SPLIT AT.
+var MergeQueueEnabled = settings.RegisterBoolSetting(
+	"kv.range_merge.queue_enabled",
+	"whether the automatic merge queue is enabled",
+	false,
+)
+
+// mergeQueueMaxQPS is the highest combined QPS of two ranges for which the
+// merge queue merges them, so that busy ranges aren't merged only to be
+// split again.
+var mergeQueueMaxQPS = settings.RegisterNonNegativeFloatSetting(
+	"kv.range_merge.max_qps",
+	"the maximum combined queries per second of two ranges for the merge queue to merge them",
+	100,
+)
+
+// mergeQueue manages a queue of ranges slated to be merged into their
+// right-hand neighbor due to their small size.
+//
+// A range is merged if it's smaller than the minimum range size of its zone,
+// if the merged range would be smaller than the maximum range size and
+// wouldn't straddle a zone config or table boundary, and if the two ranges
+// aren't busy. The replicas of the right-hand range are first relocated to
+// the stores of the left-hand range, as merges require the two ranges to be
+// colocated, and must then all catch up with their leaseholder.
+//
+// The queue processes the ranges whose lease is held by its store. It only
+// considers right-hand neighbors which have a replica on its store, as the
+// size of a range is only known to its replicas.
+type mergeQueue struct {
+	*baseQueue
+	db *client.DB
+}
+
+// newMergeQueue returns a new instance of mergeQueue.
+func newMergeQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *mergeQueue {
+	mq := &mergeQueue{
+		db: db,
+	}
+	mq.baseQueue = newBaseQueue(
+		"merge", mq, store, gossip,
+		queueConfig{
+			maxSize:           defaultQueueMaxSize,
+			needsLease:        true,
+			needsSystemConfig: true,
+			successes:         store.metrics.MergeQueueSuccesses,
+			failures:          store.metrics.MergeQueueFailures,
+			pending:           store.metrics.MergeQueuePending,
+			processingNanos:   store.metrics.MergeQueueProcessingNanos,
+		},
+	)
+	return mq
+}
+
+func (mq *mergeQueue) enabled() bool {
+	return MergeQueueEnabled.Get(&mq.store.cfg.Settings.SV)
+}
+
+// shouldQueue determines whether a range should be queued for merging. This
+// is true if the range's size in bytes is below the minimum for its zone.
+// Smaller ranges are given a higher priority.
+func (mq *mergeQueue) shouldQueue(
+	ctx context.Context, now hlc.Timestamp, repl *Replica, sysCfg config.SystemConfig,
+) (shouldQ bool, priority float64) {
+	if !mq.enabled() {
+		return false, 0
+	}
+	desc := repl.Desc()
+	if !mergeableRange(desc) {
+		return false, 0
+	}
+	zone, err := sysCfg.GetZoneConfigForKey(desc.StartKey)
+	if err != nil {
+		log.Error(ctx, err)
+		return false, 0
+	}
+	if zone.RangeMinBytes <= 0 {
+		return false, 0
+	}
+	ratio := float64(repl.GetMVCCStats().Total()) / float64(zone.RangeMinBytes)
+	if ratio >= 1 {
+		return false, 0
+	}
+	return true, 1 - ratio
+}
+
+// mergeableRange returns false for the ranges which are never merged into
+// their right-hand neighbor: the last range and the meta ranges.
+func mergeableRange(desc *roachpb.RangeDescriptor) bool {
+	return !desc.EndKey.Equal(roachpb.RKeyMax) &&
+		!desc.StartKey.Less(roachpb.RKey(keys.Meta2KeyMax))
+}
+
+// process colocates the range with its right-hand neighbor and merges them,
+// after checking that the merge is warranted.
+func (mq *mergeQueue) process(
+	ctx context.Context, lhsRepl *Replica, sysCfg config.SystemConfig,
+) error {
+	if !mq.enabled() {
+		log.VEventf(ctx, 2, "skipping merge: queue has been disabled")
+		return nil
+	}
+	lhsDesc := lhsRepl.Desc()
+	if !mergeableRange(lhsDesc) {
+		return nil
+	}
+	zone, err := sysCfg.GetZoneConfigForKey(lhsDesc.StartKey)
+	if err != nil {
+		return err
+	}
+	lhsSize := lhsRepl.GetMVCCStats().Total()
+	if lhsSize >= zone.RangeMinBytes {
+		log.VEventf(ctx, 2, "skipping merge: range is %d bytes, not smaller than the minimum of %d",
+			lhsSize, zone.RangeMinBytes)
+		return nil
+	}
+
+	rhsRepl := mq.store.LookupReplica(lhsDesc.EndKey, nil)
+	if rhsRepl == nil || !rhsRepl.Desc().StartKey.Equal(lhsDesc.EndKey) {
+		log.VEventf(ctx, 2, "skipping merge: right-hand neighbor has no replica on this store")
+		return nil
+	}
+	rhsDesc := rhsRepl.Desc()
+	if sysCfg.NeedsSplit(lhsDesc.StartKey, rhsDesc.EndKey) {
+		log.VEventf(ctx, 2, "skipping merge: right-hand neighbor is across a zone config or table boundary")
+		return nil
+	}
+	if mergedSize := lhsSize + rhsRepl.GetMVCCStats().Total(); mergedSize >= zone.RangeMaxBytes {
+		log.VEventf(ctx, 2, "skipping merge: merged range would be %d bytes, not smaller than the maximum of %d",
+			mergedSize, zone.RangeMaxBytes)
+		return nil
+	}
+	// The QPS of the right-hand neighbor is only known by its leaseholder.
+	// Unless this store holds the neighbor's lease, we can't tell whether the
+	// merged range would be busy, so the merge is left to a later attempt
+	// (e.g. once the lease has moved here).
+	if !rhsRepl.OwnsValidLease(mq.store.Clock().Now()) {
+		log.VEventf(ctx, 2, "skipping merge: right-hand neighbor's lease is not held by this store")
+		return nil
+	}
+	maxQPS := mergeQueueMaxQPS.Get(&mq.store.cfg.Settings.SV)
+	if mergedQPS := lhsRepl.QueriesPerSecond() + rhsRepl.QueriesPerSecond(); mergedQPS > maxQPS {
+		log.VEventf(ctx, 2, "skipping merge: merged range would serve %.2f qps, more than the maximum of %.2f",
+			mergedQPS, maxQPS)
+		return nil
+	}
+
+	if !replicaSetsEqual(lhsDesc.Replicas, rhsDesc.Replicas) {
+		// Relocate the right-hand neighbor to the stores of this range. This
+		// store comes first, so that it remains the neighbor's leaseholder.
+		targets := []roachpb.ReplicationTarget{{
+			NodeID:  mq.store.Ident.NodeID,
+			StoreID: mq.store.Ident.StoreID,
+		}}
+		for _, repl := range lhsDesc.Replicas {
+			if repl.StoreID != mq.store.Ident.StoreID {
+				targets = append(targets, roachpb.ReplicationTarget{
+					NodeID:  repl.NodeID,
+					StoreID: repl.StoreID,
+				})
+			}
+		}
+		log.VEventf(ctx, 2, "relocating %s to %v", rhsRepl, targets)
+		if err := RelocateRange(ctx, mq.db, *rhsDesc, targets); err != nil {
+			return errors.Wrapf(err, "unable to colocate %s with %s", rhsRepl, lhsRepl)
+		}
+	}
+
+	// The merge subsumes the right-hand neighbor's data as found on each
+	// store, so a replica lagging behind its leaseholder would lose the
+	// commands it has yet to apply. Until merges freeze the right-hand range,
+	// wait for all of its replicas to catch up right before merging, and give
+	// up (the range is queued again later) if they don't.
+	if err := rhsRepl.waitForApplication(ctx, mergeQueueCatchUpTimeout); err != nil {
+		return errors.Wrapf(err, "unable to merge %s into %s", rhsRepl, lhsRepl)
+	}
+
+	log.VEventf(ctx, 2, "merging %s into %s", rhsRepl, lhsRepl)
+	if _, pErr := lhsRepl.AdminMerge(ctx, roachpb.AdminMergeRequest{
+		Span: roachpb.Span{Key: lhsDesc.StartKey.AsRawKey()},
+	}); pErr != nil {
+		return pErr.GoError()
+	}
+	return nil
+}
+
+// timer returns interval between processing successive queued merges.
+func (*mergeQueue) timer(_ time.Duration) time.Duration {
+	return mergeQueueTimerDuration
+}
+
+// purgatoryChan returns nil.
+func (*mergeQueue) purgatoryChan() <-chan struct{} {
+	return nil
+}
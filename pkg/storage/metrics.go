@@ -161,6 +161,9 @@ var (
 		Help: "Count of system KV pairs"}
 
 	// Metrics used by the rebalancing logic that aren't already captured elsewhere.
+	metaAverageQueriesPerSecond = metric.Metadata{
+		Name: "rebalancing.queriespersecond",
+		Help: "Number of kv-level requests received per second by the store, averaged over a large time period as used in rebalancing decisions"}
 	metaAverageWritesPerSecond = metric.Metadata{
 		Name: "rebalancing.writespersecond",
 		Help: "Number of keys written (i.e. applied by raft) per second to the store, averaged over a large time period as used in rebalancing decisions"}
@@ -392,6 +395,18 @@ var (
 	metaSplitQueueProcessingNanos = metric.Metadata{
 		Name: "queue.split.processingnanos",
 		Help: "Nanoseconds spent processing replicas in the split queue"}
+	metaMergeQueueSuccesses = metric.Metadata{
+		Name: "queue.merge.process.success",
+		Help: "Number of replicas successfully processed by the merge queue"}
+	metaMergeQueueFailures = metric.Metadata{
+		Name: "queue.merge.process.failure",
+		Help: "Number of replicas which failed processing in the merge queue"}
+	metaMergeQueuePending = metric.Metadata{
+		Name: "queue.merge.pending",
+		Help: "Number of pending replicas in the merge queue"}
+	metaMergeQueueProcessingNanos = metric.Metadata{
+		Name: "queue.merge.processingnanos",
+		Help: "Nanoseconds spent processing replicas in the merge queue"}
 	metaTimeSeriesMaintenanceQueueSuccesses = metric.Metadata{
 		Name: "queue.tsmaintenance.process.success",
 		Help: "Number of replicas successfully processed by the time series maintenance queue"}
@@ -524,7 +539,8 @@ type StoreMetrics struct {
 	SysCount        *metric.Gauge
 
 	// Rebalancing metrics.
-	AverageWritesPerSecond *metric.GaugeFloat64
+	AverageQueriesPerSecond *metric.GaugeFloat64
+	AverageWritesPerSecond  *metric.GaugeFloat64
 
 	// RocksDB metrics.
 	RdbBlockCacheHits           *metric.Gauge
@@ -621,6 +637,10 @@ type StoreMetrics struct {
 	SplitQueueFailures                        *metric.Counter
 	SplitQueuePending                         *metric.Gauge
 	SplitQueueProcessingNanos                 *metric.Counter
+	MergeQueueSuccesses                       *metric.Counter
+	MergeQueueFailures                        *metric.Counter
+	MergeQueuePending                         *metric.Gauge
+	MergeQueueProcessingNanos                 *metric.Counter
 	TimeSeriesMaintenanceQueueSuccesses       *metric.Counter
 	TimeSeriesMaintenanceQueueFailures        *metric.Counter
 	TimeSeriesMaintenanceQueuePending         *metric.Gauge
@@ -715,7 +735,8 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		SysCount:        metric.NewGauge(metaSysCount),
 
 		// Rebalancing metrics.
-		AverageWritesPerSecond: metric.NewGaugeFloat64(metaAverageWritesPerSecond),
+		AverageQueriesPerSecond: metric.NewGaugeFloat64(metaAverageQueriesPerSecond),
+		AverageWritesPerSecond:  metric.NewGaugeFloat64(metaAverageWritesPerSecond),
 
 		// RocksDB metrics.
 		RdbBlockCacheHits:           metric.NewGauge(metaRdbBlockCacheHits),
@@ -805,6 +826,10 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		SplitQueueFailures:                        metric.NewCounter(metaSplitQueueFailures),
 		SplitQueuePending:                         metric.NewGauge(metaSplitQueuePending),
 		SplitQueueProcessingNanos:                 metric.NewCounter(metaSplitQueueProcessingNanos),
+		MergeQueueSuccesses:                       metric.NewCounter(metaMergeQueueSuccesses),
+		MergeQueueFailures:                        metric.NewCounter(metaMergeQueueFailures),
+		MergeQueuePending:                         metric.NewGauge(metaMergeQueuePending),
+		MergeQueueProcessingNanos:                 metric.NewCounter(metaMergeQueueProcessingNanos),
 		TimeSeriesMaintenanceQueueSuccesses:       metric.NewCounter(metaTimeSeriesMaintenanceQueueFailures),
 		TimeSeriesMaintenanceQueueFailures:        metric.NewCounter(metaTimeSeriesMaintenanceQueueSuccesses),
 		TimeSeriesMaintenanceQueuePending:         metric.NewGauge(metaTimeSeriesMaintenanceQueuePending),
@@ -243,6 +243,12 @@ type Replica struct {
 	// writeStats tracks the number of keys written by applied raft commands
 	// in order to aid in replica rebalancing decisions.
 	writeStats *replicaStats
+	// loadSplitter samples the incoming BatchRequests of hot ranges in order to
+	// split them based on their load.
+	loadSplitter *loadSplitDecider
+	// closedTimestampTracker determines the timestamps closed by the replica
+	// while it holds the lease.
+	closedTimestampTracker closedTimestampTracker
 
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
@@ -349,6 +355,10 @@ type Replica struct {
 		// lease extension that were in flight at the time of the transfer cannot be
 		// used, if they eventually apply.
 		minLeaseProposedTS hlc.Timestamp
+		// The highest closed timestamp carried by a command applied to the
+		// replica. No more writes are evaluated at or below it, so the replica
+		// can serve reads at or below it even if it doesn't hold the lease.
+		closedTimestamp hlc.Timestamp
 		// Max bytes before split.
 		maxBytes int64
 		// proposals stores the Raft in-flight commands which
@@ -604,6 +614,7 @@ func newReplica(rangeID roachpb.RangeID, store *Store) *Replica {
 	// Pass nil for the localityOracle because we intentionally don't track the
 	// origin locality of write load.
 	r.writeStats = newReplicaStats(store.Clock(), nil)
+	r.loadSplitter = newLoadSplitDecider(rand.Intn)
 
 	// Init rangeStr with the range ID.
 	r.rangeStr.store(0, &roachpb.RangeDescriptor{RangeID: rangeID})
@@ -1151,6 +1162,14 @@ func (r *Replica) SetMaxBytes(maxBytes int64) {
 	r.mu.maxBytes = maxBytes
 }
 
+// ClosedTimestamp returns the timestamp at or below which the replica can
+// serve consistent reads without holding the lease.
+func (r *Replica) ClosedTimestamp() hlc.Timestamp {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.mu.closedTimestamp
+}
+
 // IsFirstRange returns true if this is the first range.
 func (r *Replica) IsFirstRange() bool {
 	return r.RangeID == 1
@@ -1777,6 +1796,7 @@ func (r *Replica) Send(
 
 	if r.leaseholderStats != nil && ba.Header.GatewayNodeID != 0 {
 		r.leaseholderStats.record(ba.Header.GatewayNodeID)
+		r.recordLoadForSplit(ba)
 	}
 
 	// Add the range log tag.
@@ -2295,7 +2315,12 @@ func (r *Replica) removeCmdsFromCommandQueue(cmds batchCmdSet) {
 // timestamp cache. When the write returns, the updated timestamp
 // will inform the batch response timestamp or batch response txn
 // timestamp.
-func (r *Replica) applyTimestampCache(ba *roachpb.BatchRequest) (bool, *roachpb.Error) {
+//
+// minReadTS is treated as a read of every key by another transaction. It is
+// used to prevent writes at or below timestamps that may have been closed.
+func (r *Replica) applyTimestampCache(
+	ba *roachpb.BatchRequest, minReadTS hlc.Timestamp,
+) (bool, *roachpb.Error) {
 	span, err := keys.RangeMatchingPred(*ba, roachpb.ConsultsTimestampCache)
 	if err != nil {
 		return false, roachpb.NewError(err)
@@ -2342,6 +2367,9 @@ func (r *Replica) applyTimestampCache(ba *roachpb.BatchRequest) (bool, *roachpb.
 
 			// Forward the timestamp if there's been a more recent read (by someone else).
 			rTS, rTxnID, _ := r.store.tsCacheMu.cache.GetMaxRead(header.Key, header.EndKey)
+			if rTS.Forward(minReadTS) {
+				rTxnID = uuid.UUID{}
+			}
 			if ba.Txn != nil {
 				if ba.Txn.ID != rTxnID {
 					nextTS := rTS.Next()
@@ -2476,10 +2504,13 @@ func (r *Replica) executeAdminBatch(
 func (r *Replica) executeReadOnlyBatch(
 	ctx context.Context, ba roachpb.BatchRequest,
 ) (br *roachpb.BatchResponse, pErr *roachpb.Error) {
-	// If the read is consistent, the read requires the range lease.
+	// If the read is consistent, the read requires the range lease, unless
+	// it can be served below the replica's closed timestamp.
 	if ba.ReadConsistency != roachpb.INCONSISTENT {
 		if _, pErr = r.redirectOnOrAcquireLease(ctx); pErr != nil {
-			return nil, pErr
+			if !r.canServeFollowerRead(ctx, &ba, pErr) {
+				return nil, pErr
+			}
 		}
 	}
 
@@ -2548,6 +2579,28 @@ func (r *Replica) executeReadOnlyBatch(
 	return br, pErr
 }
 
+// canServeFollowerRead returns whether a replica which doesn't hold the lease,
+// as indicated by pErr, can nonetheless serve the read-only batch because the
+// batch is at or below the replica's closed timestamp.
+func (r *Replica) canServeFollowerRead(
+	ctx context.Context, ba *roachpb.BatchRequest, pErr *roachpb.Error,
+) bool {
+	if _, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); !ok {
+		return false
+	}
+	if !storagebase.FollowerReadsEnabled.Get(&r.store.cfg.Settings.SV) ||
+		!storagebase.IsFollowerReadCandidate(ba) {
+		return false
+	}
+	ts := storagebase.FollowerReadTimestamp(ba)
+	if closedTS := r.ClosedTimestamp(); closedTS.Less(ts) {
+		log.VEventf(ctx, 2, "can't serve follower read at %s above closed timestamp %s", ts, closedTS)
+		return false
+	}
+	log.Event(ctx, "serving follower read")
+	return true
+}
+
 // executeWriteBatch is the entry point for client requests which may mutate the
 // range's replicated state. Requests taking this path are ultimately
 // serialized through Raft, but pass through additional machinery whose goal is
@@ -2669,6 +2722,8 @@ func (r *Replica) tryExecuteWriteBatch(
 	}()
 
 	var lease roachpb.Lease
+	var minTS hlc.Timestamp
+	untrack := func() {}
 	// For lease commands, use the provided previous lease for verification.
 	if ba.IsSingleSkipLeaseCheckRequest() {
 		lease = ba.GetPrevLeaseForLeaseRequest()
@@ -2680,13 +2735,21 @@ func (r *Replica) tryExecuteWriteBatch(
 			return nil, pErr, proposalNoRetry
 		}
 		lease = status.Lease
+
+		// Register the write with the closed timestamp tracker, which may
+		// close timestamps up to minTS while the write is being evaluated.
+		// The write must also not be evaluated at or below a timestamp closed
+		// by a previous leaseholder.
+		minTS, untrack = r.closedTimestampTracker.track()
+		minTS.Forward(r.ClosedTimestamp())
 	}
+	defer untrack()
 
 	// Examine the read and write timestamp caches for preceding
 	// commands which require this command to move its timestamp
 	// forward. Or, in the case of a transactional write, the txn
 	// timestamp and possible write-too-old bool.
-	if bumped, pErr := r.applyTimestampCache(&ba); pErr != nil {
+	if bumped, pErr := r.applyTimestampCache(&ba, minTS); pErr != nil {
 		return nil, pErr, proposalNoRetry
 	} else if bumped {
 		// If we bump the transaction's timestamp, we must absolutely
@@ -2710,6 +2773,9 @@ func (r *Replica) tryExecuteWriteBatch(
 	log.Event(ctx, "applied timestamp cache")
 
 	ch, tryAbandon, undoQuotaAcquisition, pErr := r.propose(ctx, lease, ba, endCmds, spans)
+	// The command has been assigned a lease index (or won't be proposed), so
+	// it no longer prevents timestamps from being closed.
+	untrack()
 	defer func() {
 		// NB: We may be double free-ing here, consider the following cases:
 		//  - The request was evaluated and the command resulted in an error, but a
@@ -2899,6 +2965,11 @@ func (r *Replica) insertProposalLocked(
 	}
 	if !proposal.Request.IsLeaseRequest() {
 		r.mu.lastAssignedLeaseIndex++
+		// The command is assigned a higher lease index than every write
+		// which may have been evaluated below the closed timestamp, so it
+		// can carry the closed timestamp to the followers. Lease requests
+		// don't obey the lease index ordering and can't carry it.
+		proposal.command.ClosedTimestamp = r.closedTimestampTracker.close(r.closedTimestampTarget())
 	}
 	proposal.command.MaxLeaseIndex = r.mu.lastAssignedLeaseIndex
 	proposal.command.ProposerReplica = proposerReplica
@@ -2915,6 +2986,18 @@ func (r *Replica) insertProposalLocked(
 	r.mu.proposals[proposal.idKey] = proposal
 }
 
+// closedTimestampTarget returns the timestamp the replica attempts to
+// close as the leaseholder, or a zero timestamp if it shouldn't close any
+// timestamps.
+func (r *Replica) closedTimestampTarget() hlc.Timestamp {
+	st := r.store.cfg.Settings
+	target := storagebase.ClosedTimestampTargetDuration.Get(&st.SV)
+	if !storagebase.FollowerReadsEnabled.Get(&st.SV) || target == 0 {
+		return hlc.Timestamp{}
+	}
+	return r.store.Clock().Now().Add(-target.Nanoseconds(), 0)
+}
+
 func makeIDKey() storagebase.CmdIDKey {
 	idKeyBuf := make([]byte, 0, raftCommandIDLen)
 	idKeyBuf = encoding.EncodeUint64Ascending(idKeyBuf, uint64(rand.Int63()))
@@ -4640,6 +4723,14 @@ func (r *Replica) processRaftCommand(
 		// before notifying a potentially waiting client.
 		r.handleEvalResultRaftMuLocked(ctx, lResult,
 			raftCmd.ReplicatedEvalResult, raftIndex, leaseIndex)
+
+		// Only a command proposed under the current lease and applied at its
+		// expected lease index carries a valid closed timestamp.
+		if forcedErr == nil {
+			r.mu.Lock()
+			r.mu.closedTimestamp.Forward(raftCmd.ClosedTimestamp)
+			r.mu.Unlock()
+		}
 	}
 
 	if proposedLocally {
@@ -5828,6 +5919,26 @@ func (r *Replica) QueriesPerSecond() float64 {
 	return qps
 }
 
+// recordLoadForSplit records a BatchRequest received by the leaseholder for
+// the purposes of load-based splitting, and queues the range for a split
+// once a split key balancing its load has been found.
+func (r *Replica) recordLoadForSplit(ba roachpb.BatchRequest) {
+	var threshold float64
+	if LoadSplitEnabled.Get(&r.store.cfg.Settings.SV) {
+		threshold = float64(LoadSplitQPSThreshold.Get(&r.store.cfg.Settings.SV))
+	}
+	now := timeutil.Unix(0, r.store.Clock().PhysicalNow())
+	if r.loadSplitter.record(now, 1, threshold, func() roachpb.Span {
+		rSpan, err := keys.Range(ba)
+		if err != nil {
+			return roachpb.Span{}
+		}
+		return rSpan.AsRawSpanWithNoLocals()
+	}) && r.store.splitQueue != nil {
+		r.store.splitQueue.MaybeAdd(r, r.store.Clock().Now())
+	}
+}
+
 // WritesPerSecond returns the range's average keys written per second.
 func (r *Replica) WritesPerSecond() float64 {
 	wps, _ := r.writeStats.avgQPS()
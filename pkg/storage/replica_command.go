@@ -419,6 +419,11 @@ func evalEndTransaction(
 	if reply.Txn.Epoch < h.Txn.Epoch {
 		reply.Txn.Epoch = h.Txn.Epoch
 	}
+	// Only the requester knows which of its writes were rolled back to a
+	// savepoint; the intents written by those must not be committed.
+	if reply.Txn.Epoch == h.Txn.Epoch {
+		reply.Txn.IgnoredSeqNums = h.Txn.IgnoredSeqNums
+	}
 	// Take max of requested priority and existing priority. This isn't
 	// terribly useful, but we do it for completeness.
 	if reply.Txn.Priority < h.Txn.Priority {
@@ -873,33 +878,9 @@ func (r *Replica) CheckConsistency(
 	desc := r.Desc()
 	key := desc.StartKey.AsRawKey()
 	endKey := desc.EndKey.AsRawKey()
-	id := uuid.MakeV4()
-	// Send a ComputeChecksum to all the replicas of the range.
-	{
-		var ba roachpb.BatchRequest
-		ba.RangeID = desc.RangeID
-		checkArgs := &roachpb.ComputeChecksumRequest{
-			Span: roachpb.Span{
-				Key:    key,
-				EndKey: endKey,
-			},
-			Version:    batcheval.ReplicaChecksumVersion,
-			ChecksumID: id,
-			Snapshot:   args.WithDiff,
-		}
-		ba.Add(checkArgs)
-		ba.Timestamp = r.store.Clock().Now()
-		_, pErr := r.Send(ctx, ba)
-		if pErr != nil {
-			return roachpb.CheckConsistencyResponse{}, pErr
-		}
-	}
-
-	// Get local checksum. This might involving waiting for it.
-	c, err := r.getChecksum(ctx, id)
-	if err != nil {
-		return roachpb.CheckConsistencyResponse{}, roachpb.NewError(
-			errors.Wrapf(err, "could not compute checksum for range [%s, %s]", key, endKey))
+	id, c, pErr := r.computeChecksum(ctx, desc, args.WithDiff)
+	if pErr != nil {
+		return roachpb.CheckConsistencyResponse{}, pErr
 	}
 
 	// Get remote checksums.
@@ -921,27 +902,9 @@ func (r *Replica) CheckConsistency(
 				ctx, cancel := context.WithTimeout(ctx, collectChecksumTimeout)
 				defer cancel()
 				defer wg.Done()
-				addr, err := r.store.cfg.Transport.resolver(replica.NodeID)
-				if err != nil {
-					log.Error(ctx, errors.Wrapf(err, "could not resolve node ID %d", replica.NodeID))
-					return
-				}
-				conn, err := r.store.cfg.Transport.rpcContext.GRPCDial(addr.String())
-				if err != nil {
-					log.Error(ctx,
-						errors.Wrapf(err, "could not dial node ID %d address %s", replica.NodeID, addr))
-					return
-				}
-				client := NewConsistencyClient(conn)
-				req := &CollectChecksumRequest{
-					StoreRequestHeader{NodeID: replica.NodeID, StoreID: replica.StoreID},
-					r.RangeID,
-					id,
-					c.checksum,
-				}
-				resp, err := client.CollectChecksum(ctx, req)
+				resp, err := r.collectChecksum(ctx, replica, id, c.checksum)
 				if err != nil {
-					log.Error(ctx, errors.Wrapf(err, "could not CollectChecksum from replica %s", replica))
+					log.Error(ctx, err)
 					return
 				}
 				if bytes.Equal(c.checksum, resp.Checksum) {
@@ -996,6 +959,104 @@ func (r *Replica) CheckConsistency(
 	return roachpb.CheckConsistencyResponse{}, nil
 }
 
+// computeChecksum applies a ComputeChecksum command on the range and returns
+// its ID along with the checksum computed by this replica, waiting for it if
+// necessary. The checksums of the other replicas can then be collected with
+// collectChecksum.
+func (r *Replica) computeChecksum(
+	ctx context.Context, desc *roachpb.RangeDescriptor, withSnapshot bool,
+) (uuid.UUID, ReplicaChecksum, *roachpb.Error) {
+	key := desc.StartKey.AsRawKey()
+	endKey := desc.EndKey.AsRawKey()
+	id := uuid.MakeV4()
+	// Send a ComputeChecksum to all the replicas of the range.
+	{
+		var ba roachpb.BatchRequest
+		ba.RangeID = desc.RangeID
+		checkArgs := &roachpb.ComputeChecksumRequest{
+			Span: roachpb.Span{
+				Key:    key,
+				EndKey: endKey,
+			},
+			Version:    batcheval.ReplicaChecksumVersion,
+			ChecksumID: id,
+			Snapshot:   withSnapshot,
+		}
+		ba.Add(checkArgs)
+		ba.Timestamp = r.store.Clock().Now()
+		_, pErr := r.Send(ctx, ba)
+		if pErr != nil {
+			return uuid.UUID{}, ReplicaChecksum{}, pErr
+		}
+	}
+
+	// Get local checksum. This might involving waiting for it.
+	c, err := r.getChecksum(ctx, id)
+	if err != nil {
+		return uuid.UUID{}, ReplicaChecksum{}, roachpb.NewError(
+			errors.Wrapf(err, "could not compute checksum for range [%s, %s]", key, endKey))
+	}
+	return id, c, nil
+}
+
+// collectChecksum asks the given replica for the checksum it computed when
+// applying the ComputeChecksum command with the given ID, waiting until the
+// replica has applied the command or ctx is done. checksum is the checksum
+// computed by this replica, which the remote replica compares with its own.
+func (r *Replica) collectChecksum(
+	ctx context.Context, replica roachpb.ReplicaDescriptor, id uuid.UUID, checksum []byte,
+) (*CollectChecksumResponse, error) {
+	addr, err := r.store.cfg.Transport.resolver(replica.NodeID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not resolve node ID %d", replica.NodeID)
+	}
+	conn, err := r.store.cfg.Transport.rpcContext.GRPCDial(addr.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not dial node ID %d address %s", replica.NodeID, addr)
+	}
+	client := NewConsistencyClient(conn)
+	req := &CollectChecksumRequest{
+		StoreRequestHeader{NodeID: replica.NodeID, StoreID: replica.StoreID},
+		r.RangeID,
+		id,
+		checksum,
+	}
+	resp, err := client.CollectChecksum(ctx, req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not CollectChecksum from replica %s", replica)
+	}
+	return resp, nil
+}
+
+// waitForApplication waits until every replica of the range has applied all
+// the commands this replica had applied, or until timeout elapses. This
+// replica must hold the lease. A ComputeChecksum command is applied on the
+// range, which is ordered after all these commands, and the checksum is
+// collected from every replica, which only computes it when applying the
+// command.
+func (r *Replica) waitForApplication(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	desc := r.Desc()
+	id, c, pErr := r.computeChecksum(ctx, desc, false /* withSnapshot */)
+	if pErr != nil {
+		return pErr.GoError()
+	}
+	localReplica, err := r.GetReplicaDescriptor()
+	if err != nil {
+		return errors.Wrap(err, "could not get replica descriptor")
+	}
+	for _, replica := range desc.Replicas {
+		if replica == localReplica {
+			continue
+		}
+		if _, err := r.collectChecksum(ctx, replica, id, c.checksum); err != nil {
+			return errors.Wrapf(err, "replica %s has not caught up", replica)
+		}
+	}
+	return nil
+}
+
 // getChecksum waits for the result of ComputeChecksum and returns it.
 // It returns false if there is no checksum being computed for the id,
 // or it has already been GCed.
@@ -2522,12 +2583,13 @@ func declareKeysLeaseInfo(
 	spans.Add(spanset.SpanReadOnly, roachpb.Span{Key: keys.RangeLeaseKey(header.RangeID)})
 }
 
-// TestingRelocateRange relocates a given range to a given set of stores. The first
-// store in the slice becomes the new leaseholder.
+// RelocateRange relocates a given range to a given set of stores. The first
+// store in the slice becomes the new leaseholder. It backs the TESTING_RELOCATE
+// statement and is used by the merge queue to colocate ranges.
 //
 // This is best-effort; if replication queues are enabled and a change in
 // membership happens at the same time, there will be errors.
-func TestingRelocateRange(
+func RelocateRange(
 	ctx context.Context,
 	db *client.DB,
 	rangeDesc roachpb.RangeDescriptor,
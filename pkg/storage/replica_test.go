@@ -3241,6 +3241,138 @@ func TestReplicaNoTSCacheInconsistent(t *testing.T) {
 	}
 }
 
+// TestReplicaClosedTimestamp verifies that the leaseholder closes timestamps
+// trailing the current time when follower reads are enabled, and that writes
+// are evaluated above the closed timestamp.
+func TestReplicaClosedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	put := func(key string, ts hlc.Timestamp) hlc.Timestamp {
+		pArgs := putArgs(roachpb.Key(key), []byte("value"))
+		_, respH, pErr := SendWrapped(
+			context.Background(), tc.Sender(), roachpb.Header{Timestamp: ts}, &pArgs)
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		return respH.Timestamp
+	}
+
+	// No timestamps are closed while follower reads are disabled.
+	tc.manualClock.Set((10 * time.Second).Nanoseconds())
+	put("a", tc.Clock().Now())
+	put("b", tc.Clock().Now())
+	if closed := tc.repl.ClosedTimestamp(); closed != (hlc.Timestamp{}) {
+		t.Fatalf("expected no closed timestamp; got %s", closed)
+	}
+
+	st := tc.store.cfg.Settings
+	storagebase.FollowerReadsEnabled.Override(&st.SV, true)
+	storagebase.ClosedTimestampTargetDuration.Override(&st.SV, time.Second)
+
+	// A write closes the timestamp which a previous write attempted to close
+	// once the previous write has been proposed.
+	testutils.SucceedsSoon(t, func() error {
+		tc.manualClock.Increment((10 * time.Second).Nanoseconds())
+		put("a", tc.Clock().Now())
+		if closed := tc.repl.ClosedTimestamp(); closed == (hlc.Timestamp{}) {
+			return errors.New("no closed timestamp")
+		}
+		return nil
+	})
+	now := tc.Clock().Now()
+	closed := tc.repl.ClosedTimestamp()
+	if maxClosed := now.Add(-time.Second.Nanoseconds(), 0); maxClosed.Less(closed) {
+		t.Fatalf("expected closed timestamp at or below %s; got %s", maxClosed, closed)
+	}
+
+	// A write below the closed timestamp is forwarded above it.
+	if ts := put("b", closed.Add(-time.Second.Nanoseconds(), 0)); !closed.Less(ts) {
+		t.Fatalf("expected write to be forwarded above closed timestamp %s; got %s", closed, ts)
+	}
+}
+
+// TestReplicaFollowerRead verifies that a replica which doesn't hold the
+// lease serves consistent reads at or below its closed timestamp when
+// follower reads are enabled, and redirects the other requests to the lease
+// holder.
+func TestReplicaFollowerRead(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	key := roachpb.Key("a")
+	writeTS := tc.Clock().Now()
+	pArgs := putArgs(key, []byte("value"))
+	if _, pErr := tc.SendWrappedWith(roachpb.Header{Timestamp: writeTS}, &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Move the lease to another replica.
+	secondReplica, err := tc.addBogusReplicaToRangeDesc(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc.manualClock.Set(leaseExpiry(tc.repl))
+	now := tc.Clock().Now()
+	if err := sendLeaseRequest(tc.repl, &roachpb.Lease{
+		Start:      now,
+		Expiration: now.Add(time.Hour.Nanoseconds(), 0).Clone(),
+		Replica:    secondReplica,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	closedTS := now.Add(-time.Second.Nanoseconds(), 0)
+	tc.repl.SetClosedTimestamp(closedTS)
+
+	get := func(h roachpb.Header) (roachpb.Response, *roachpb.Error) {
+		gArgs := getArgs(key)
+		return tc.SendWrappedWith(h, &gArgs)
+	}
+	expectNotLeaseHolder := func(h roachpb.Header) {
+		if _, pErr := get(h); !testutils.IsPError(pErr, "not lease holder") {
+			t.Fatalf("expected not lease holder error at %s, got %v", h.Timestamp, pErr)
+		}
+	}
+
+	// Reads are redirected to the lease holder while follower reads are
+	// disabled.
+	expectNotLeaseHolder(roachpb.Header{Timestamp: closedTS})
+
+	st := tc.store.cfg.Settings
+	storagebase.FollowerReadsEnabled.Override(&st.SV, true)
+
+	for _, ts := range []hlc.Timestamp{writeTS, closedTS} {
+		resp, pErr := get(roachpb.Header{Timestamp: ts})
+		if pErr != nil {
+			t.Fatalf("expected follower read at %s to be served, got %s", ts, pErr)
+		}
+		if v := resp.(*roachpb.GetResponse).Value; v == nil {
+			t.Fatalf("expected follower read at %s to return a value", ts)
+		}
+	}
+
+	// Reads above the closed timestamp, including those of transactions
+	// whose uncertainty interval extends above it, and writes are redirected
+	// to the lease holder.
+	expectNotLeaseHolder(roachpb.Header{Timestamp: closedTS.Next()})
+	txn := newTransaction("test", key, 1, enginepb.SERIALIZABLE, tc.Clock())
+	txn.Timestamp, txn.OrigTimestamp = closedTS, closedTS
+	txn.MaxTimestamp = now
+	expectNotLeaseHolder(roachpb.Header{Timestamp: closedTS, Txn: txn})
+	pArgs = putArgs(key, []byte("value"))
+	if _, pErr := tc.SendWrappedWith(
+		roachpb.Header{Timestamp: writeTS}, &pArgs,
+	); !testutils.IsPError(pErr, "not lease holder") {
+		t.Fatalf("expected not lease holder error, got %v", pErr)
+	}
+}
+
 // TestReplicaNoTSCacheUpdateOnFailure verifies that read and write
 // commands do not update the timestamp cache if they result in
 // failure.
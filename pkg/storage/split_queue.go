@@ -15,6 +15,7 @@
 package storage
 
 import (
+	"bytes"
 	"time"
 
 	"github.com/pkg/errors"
@@ -23,9 +24,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 const (
@@ -33,8 +36,8 @@ const (
 	splitQueueTimerDuration = 0 // zero duration to process splits greedily.
 )
 
-// splitQueue manages a queue of ranges slated to be split due to size
-// or along intersecting zone config boundaries.
+// splitQueue manages a queue of ranges slated to be split due to size,
+// along intersecting zone config boundaries or due to their load.
 type splitQueue struct {
 	*baseQueue
 	db *client.DB
@@ -63,7 +66,8 @@ func newSplitQueue(store *Store, db *client.DB, gossip *gossip.Gossip) *splitQue
 
 // shouldQueue determines whether a range should be queued for
 // splitting. This is true if the range is intersected by a zone config
-// prefix or if the range's size in bytes exceeds the limit for the zone.
+// prefix, if the range's size in bytes exceeds the limit for the zone or if
+// a key balancing the range's load has been found.
 func (sq *splitQueue) shouldQueue(
 	ctx context.Context, now hlc.Timestamp, repl *Replica, sysCfg config.SystemConfig,
 ) (shouldQ bool, priority float64) {
@@ -80,6 +84,12 @@ func (sq *splitQueue) shouldQueue(
 		priority += ratio
 		shouldQ = true
 	}
+
+	// Add priority for ranges which are split based on their load.
+	if repl.loadSplitter.maybeSplitKey(now.GoTime()) != nil {
+		priority++
+		shouldQ = true
+	}
 	return
 }
 
@@ -130,6 +140,36 @@ func (sq *splitQueue) process(ctx context.Context, r *Replica, sysCfg config.Sys
 			}
 			r.SetMaxBytes(zone.RangeMaxBytes)
 		}
+		return nil
+	}
+
+	// Finally handle case of splitting due to load.
+	now := timeutil.Unix(0, r.store.Clock().PhysicalNow())
+	if loadKey := r.loadSplitter.maybeSplitKey(now); loadKey != nil {
+		// Don't split in the middle of a SQL row, i.e. between the column
+		// families of a row.
+		splitKey, err := keys.EnsureSafeSplitKey(loadKey)
+		if err != nil {
+			log.VEventf(ctx, 2, "unable to split %s by load at key %q: %s", r, loadKey, err)
+			return nil
+		}
+		if !containsKey(*desc, splitKey) || bytes.Equal(splitKey, desc.StartKey) {
+			log.VEventf(ctx, 2, "load-based split key %q is not in the bounds of %s", splitKey, r)
+			return nil
+		}
+		log.VEventf(ctx, 2, "splitting %s by load at key %q", r, splitKey)
+		if _, _, pErr := r.adminSplitWithDescriptor(
+			ctx,
+			roachpb.AdminSplitRequest{
+				Span: roachpb.Span{
+					Key: splitKey,
+				},
+				SplitKey: splitKey,
+			},
+			desc,
+		); pErr != nil {
+			return errors.Wrapf(pErr.GoError(), "unable to split %s at key %q", r, splitKey)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,60 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storagebase
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// FollowerReadsEnabled is a setting that controls whether leaseholders close
+// timestamps and whether replicas other than the leaseholder serve
+// consistent reads below them.
+var FollowerReadsEnabled = settings.RegisterBoolSetting(
+	"kv.closed_timestamp.follower_reads_enabled",
+	"allow all replicas to serve consistent historical reads below the closed timestamp",
+	false,
+)
+
+// ClosedTimestampTargetDuration is the duration by which the closed timestamp
+// of a range trails the current time.
+var ClosedTimestampTargetDuration = settings.RegisterNonNegativeDurationSetting(
+	"kv.closed_timestamp.target_duration",
+	"if nonzero, leaseholders close timestamps trailing the current time by approximately this duration",
+	30*time.Second,
+)
+
+// IsFollowerReadCandidate returns whether the batch may be served by a
+// replica other than the leaseholder, provided its timestamp is at or below
+// that replica's closed timestamp.
+func IsFollowerReadCandidate(ba *roachpb.BatchRequest) bool {
+	return ba.IsReadOnly() && ba.ReadConsistency == roachpb.CONSISTENT
+}
+
+// FollowerReadTimestamp returns the timestamp which has to be closed on a
+// replica for it to serve the batch. For transactional batches, this is the
+// upper bound of the transaction's uncertainty interval, as the read must
+// observe any value written below it.
+func FollowerReadTimestamp(ba *roachpb.BatchRequest) hlc.Timestamp {
+	ts := ba.Timestamp
+	if ba.Txn != nil {
+		ts.Forward(ba.Txn.Timestamp)
+		ts.Forward(ba.Txn.MaxTimestamp)
+	}
+	return ts
+}
@@ -356,6 +356,7 @@ type Store struct {
 	rangeIDAlloc       *idAllocator                // Range ID allocator
 	gcQueue            *gcQueue                    // Garbage collection queue
 	splitQueue         *splitQueue                 // Range splitting queue
+	mergeQueue         *mergeQueue                 // Range merging queue
 	replicateQueue     *replicateQueue             // Replication queue
 	replicaGCQueue     *replicaGCQueue             // Replica GC queue
 	raftLogQueue       *raftLogQueue               // Raft log truncation queue
@@ -373,10 +374,12 @@ type Store struct {
 	// gossip interval. Updated atomically.
 	gossipRangeCountdown int32
 	gossipLeaseCountdown int32
-	// gossipWritesPerSecondVal serves a similar purpose, but simply records
-	// the most recently gossiped value so that we can tell if a newly measured
-	// value differs by enough to justify re-gossiping the store.
-	gossipWritesPerSecondVal syncutil.AtomicFloat64
+	// gossipQueriesPerSecondVal and gossipWritesPerSecondVal serve a similar
+	// purpose, but simply record the most recently gossiped values so that we
+	// can tell if a newly measured value differs by enough to justify
+	// re-gossiping the store.
+	gossipQueriesPerSecondVal syncutil.AtomicFloat64
+	gossipWritesPerSecondVal  syncutil.AtomicFloat64
 
 	coalescedMu struct {
 		syncutil.Mutex
@@ -723,6 +726,8 @@ type StoreTestingKnobs struct {
 	DisableReplicaRebalancing bool
 	// DisableSplitQueue disables the split queue.
 	DisableSplitQueue bool
+	// DisableMergeQueue disables the merge queue.
+	DisableMergeQueue bool
 	// DisableTimeSeriesMaintenanceQueue disables the time series maintenance
 	// queue.
 	DisableTimeSeriesMaintenanceQueue bool
@@ -875,13 +880,14 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 		)
 		s.gcQueue = newGCQueue(s, s.cfg.Gossip)
 		s.splitQueue = newSplitQueue(s, s.db, s.cfg.Gossip)
+		s.mergeQueue = newMergeQueue(s, s.db, s.cfg.Gossip)
 		s.replicateQueue = newReplicateQueue(s, s.cfg.Gossip, s.allocator, s.cfg.Clock)
 		s.replicaGCQueue = newReplicaGCQueue(s, s.db, s.cfg.Gossip)
 		s.raftLogQueue = newRaftLogQueue(s, s.db, s.cfg.Gossip)
 		s.raftSnapshotQueue = newRaftSnapshotQueue(s, s.cfg.Gossip, s.cfg.Clock)
 		s.consistencyQueue = newConsistencyQueue(s, s.cfg.Gossip)
 		s.scanner.AddQueues(
-			s.gcQueue, s.splitQueue, s.replicateQueue, s.replicaGCQueue,
+			s.gcQueue, s.splitQueue, s.mergeQueue, s.replicateQueue, s.replicaGCQueue,
 			s.raftLogQueue, s.raftSnapshotQueue, s.consistencyQueue)
 
 		if s.cfg.TimeSeriesDataStore != nil {
@@ -901,6 +907,9 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 	if cfg.TestingKnobs.DisableSplitQueue {
 		s.setSplitQueueActive(false)
 	}
+	if cfg.TestingKnobs.DisableMergeQueue {
+		s.setMergeQueueActive(false)
+	}
 	if cfg.TestingKnobs.DisableTimeSeriesMaintenanceQueue {
 		s.setTimeSeriesMaintenanceQueueActive(false)
 	}
@@ -1335,6 +1344,7 @@ func (s *Store) GossipStore(ctx context.Context) error {
 
 	// Temporarily indicate that we're gossiping the store capacity to avoid
 	// recursively triggering a gossip of the store capacity.
+	syncutil.StoreFloat64(&s.gossipQueriesPerSecondVal, -1)
 	syncutil.StoreFloat64(&s.gossipWritesPerSecondVal, -1)
 
 	storeDesc, err := s.Descriptor()
@@ -1348,6 +1358,7 @@ func (s *Store) GossipStore(ctx context.Context) error {
 	atomic.StoreInt32(&s.gossipRangeCountdown, int32(math.Ceil(math.Max(rangeCountdown, 1))))
 	leaseCountdown := float64(storeDesc.Capacity.LeaseCount) * s.cfg.GossipWhenCapacityDeltaExceedsFraction
 	atomic.StoreInt32(&s.gossipLeaseCountdown, int32(math.Ceil(math.Max(leaseCountdown, 1))))
+	syncutil.StoreFloat64(&s.gossipQueriesPerSecondVal, storeDesc.Capacity.QueriesPerSecond)
 	syncutil.StoreFloat64(&s.gossipWritesPerSecondVal, storeDesc.Capacity.WritesPerSecond)
 
 	// Unique gossip key per store.
@@ -1418,26 +1429,41 @@ func (s *Store) maybeGossipOnCapacityChange(ctx context.Context, cce capacityCha
 	}
 }
 
-// recordNewWritesPerSecond takes a recently calculated value for the number
-// of key writes the store is handling and decides whether it has changed enough
-// to justify re-gossiping the store's capacity.
-func (s *Store) recordNewWritesPerSecond(newVal float64) {
-	oldVal := syncutil.LoadFloat64(&s.gossipWritesPerSecondVal)
-	if oldVal == -1 {
+// recordNewPerSecondStats takes recently calculated values for the number of
+// queries and key writes the store is handling and decides whether either has
+// changed enough to justify re-gossiping the store's capacity.
+func (s *Store) recordNewPerSecondStats(newQPS, newWPS float64) {
+	oldQPS := syncutil.LoadFloat64(&s.gossipQueriesPerSecondVal)
+	oldWPS := syncutil.LoadFloat64(&s.gossipWritesPerSecondVal)
+	if oldQPS == -1 || oldWPS == -1 {
 		// Gossiping of store capacity is already ongoing.
 		return
 	}
-	if newVal < oldVal*.5 || newVal > oldVal*1.5 {
-		ctx := s.AnnotateCtx(context.TODO())
-		if err := s.stopper.RunAsyncTask(
-			ctx, "storage.Store: gossip on writes-per-second change",
-			func(ctx context.Context) {
-				if err := s.GossipStore(ctx); err != nil {
-					log.Warningf(ctx, "error gossiping on writes-per-second change: %s", err)
-				}
-			}); err != nil {
-			log.Warningf(ctx, "unable to gossip on writes-per-second change: %s", err)
-		}
+
+	const minAbsoluteChange = 100
+	updateForQPS := (newQPS < oldQPS*.5 || newQPS > oldQPS*1.5) && math.Abs(newQPS-oldQPS) > minAbsoluteChange
+	updateForWPS := newWPS < oldWPS*.5 || newWPS > oldWPS*1.5
+	if !updateForQPS && !updateForWPS {
+		return
+	}
+
+	var message string
+	if updateForQPS && updateForWPS {
+		message = "queries-per-second and writes-per-second change"
+	} else if updateForQPS {
+		message = "queries-per-second change"
+	} else {
+		message = "writes-per-second change"
+	}
+	ctx := s.AnnotateCtx(context.TODO())
+	if err := s.stopper.RunAsyncTask(
+		ctx, "storage.Store: gossip on "+message,
+		func(ctx context.Context) {
+			if err := s.GossipStore(ctx); err != nil {
+				log.Warningf(ctx, "error gossiping on %s: %s", message, err)
+			}
+		}); err != nil {
+		log.Warningf(ctx, "unable to gossip on %s: %s", message, err)
 	}
 }
 
@@ -1841,6 +1867,10 @@ func splitPostApply(
 	rightRng.mu.Lock()
 	// Copy the minLeaseProposedTS from the LHS.
 	rightRng.mu.minLeaseProposedTS = r.mu.minLeaseProposedTS
+	// Copy the closed timestamp from the LHS, which applied to the keys of the
+	// RHS up to the split. This also prevents the leaseholder of the RHS from
+	// evaluating writes below it.
+	rightRng.mu.closedTimestamp = r.mu.closedTimestamp
 	rightLease := *rightRng.mu.state.Lease
 	rightRng.mu.Unlock()
 	r.mu.Unlock()
@@ -1934,6 +1964,7 @@ func (s *Store) SplitRange(ctx context.Context, origRng, newRng *Replica) error
 	// spans that are now owned by the new range.
 	origRng.leaseholderStats.resetRequestCounts()
 	origRng.writeStats.splitRequestCounts(newRng.writeStats)
+	origRng.loadSplitter.reset()
 
 	if kr := s.mu.replicasByKey.ReplaceOrInsert(origRng); kr != nil {
 		return errors.Errorf("replicasByKey unexpectedly contains %s when inserting replica %s", kr, origRng)
@@ -1995,11 +2026,31 @@ func (s *Store) MergeRange(
 		// logic that depends on them.
 		subsumingRng.writeStats.resetRequestCounts()
 	}
+	subsumingRng.loadSplitter.reset()
 
 	if err := s.maybeMergeTimestampCaches(ctx, subsumingRng, subsumedRng); err != nil {
 		return err
 	}
 
+	// The subsuming range only promised not to write to its own keys at or
+	// below its closed timestamp, and the subsumed range only to its keys at
+	// or below its own, so the merged range only serves follower reads at or
+	// below the lowest of the two. The writes are evaluated above both closed
+	// timestamps from now on, since followers may have served reads below
+	// either of them.
+	subsumedRng.mu.Lock()
+	subsumedClosedTS := subsumedRng.mu.closedTimestamp
+	subsumedRng.mu.Unlock()
+	floor := subsumedRng.closedTimestampTracker.floor()
+	floor.Forward(subsumedClosedTS)
+	subsumingRng.mu.Lock()
+	floor.Forward(subsumingRng.mu.closedTimestamp)
+	if subsumedClosedTS.Less(subsumingRng.mu.closedTimestamp) {
+		subsumingRng.mu.closedTimestamp = subsumedClosedTS
+	}
+	subsumingRng.mu.Unlock()
+	subsumingRng.closedTimestampTracker.forwardFloor(floor)
+
 	// Remove and destroy the subsumed range. Note that we were called
 	// (indirectly) from raft processing so we must call removeReplicaImpl
 	// directly to avoid deadlocking on Replica.raftMu.
@@ -2293,12 +2344,21 @@ func (s *Store) Capacity() (roachpb.StoreCapacity, error) {
 	now := s.cfg.Clock.Now()
 	var leaseCount int32
 	var logicalBytes int64
+	var totalQueriesPerSecond float64
 	var totalWritesPerSecond float64
 	bytesPerReplica := make([]float64, 0, capacity.RangeCount)
+	queriesPerReplica := make([]float64, 0, capacity.RangeCount)
 	writesPerReplica := make([]float64, 0, capacity.RangeCount)
 	newStoreReplicaVisitor(s).Visit(func(r *Replica) bool {
 		if r.OwnsValidLease(now) {
 			leaseCount++
+			// Only the leaseholder knows about the queries served by a range.
+			if r.leaseholderStats != nil {
+				if qps, dur := r.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+					totalQueriesPerSecond += qps
+					queriesPerReplica = append(queriesPerReplica, qps)
+				}
+			}
 		}
 		mvccStats := r.GetMVCCStats()
 		logicalBytes += mvccStats.Total()
@@ -2314,10 +2374,12 @@ func (s *Store) Capacity() (roachpb.StoreCapacity, error) {
 	})
 	capacity.LeaseCount = leaseCount
 	capacity.LogicalBytes = logicalBytes
+	capacity.QueriesPerSecond = totalQueriesPerSecond
 	capacity.WritesPerSecond = totalWritesPerSecond
 	capacity.BytesPerReplica = roachpb.PercentilesFromData(bytesPerReplica)
+	capacity.QueriesPerReplica = roachpb.PercentilesFromData(queriesPerReplica)
 	capacity.WritesPerReplica = roachpb.PercentilesFromData(writesPerReplica)
-	s.recordNewWritesPerSecond(totalWritesPerSecond)
+	s.recordNewPerSecondStats(totalQueriesPerSecond, totalWritesPerSecond)
 
 	return capacity, nil
 }
@@ -4084,6 +4146,7 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		leaseEpochCount               int64
 		raftLeaderNotLeaseHolderCount int64
 		quiescentCount                int64
+		averageQueriesPerSecond       float64
 		averageWritesPerSecond        float64
 
 		rangeCount                int64
@@ -4109,6 +4172,11 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		}
 		if metrics.Leaseholder {
 			leaseHolderCount++
+			if rep.leaseholderStats != nil {
+				if qps, dur := rep.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+					averageQueriesPerSecond += qps
+				}
+			}
 			switch metrics.LeaseType {
 			case roachpb.LeaseNone:
 			case roachpb.LeaseExpiration:
@@ -4143,8 +4211,9 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 	s.metrics.LeaseExpirationCount.Update(leaseExpirationCount)
 	s.metrics.LeaseEpochCount.Update(leaseEpochCount)
 	s.metrics.QuiescentCount.Update(quiescentCount)
+	s.metrics.AverageQueriesPerSecond.Update(averageQueriesPerSecond)
 	s.metrics.AverageWritesPerSecond.Update(averageWritesPerSecond)
-	s.recordNewWritesPerSecond(averageWritesPerSecond)
+	s.recordNewPerSecondStats(averageQueriesPerSecond, averageWritesPerSecond)
 
 	s.metrics.RangeCount.Update(rangeCount)
 	s.metrics.UnavailableRangeCount.Update(unavailableRangeCount)
@@ -4340,6 +4409,9 @@ func (s *Store) setReplicateQueueActive(active bool) {
 func (s *Store) setSplitQueueActive(active bool) {
 	s.splitQueue.SetDisabled(!active)
 }
+func (s *Store) setMergeQueueActive(active bool) {
+	s.mergeQueue.SetDisabled(!active)
+}
 func (s *Store) setTimeSeriesMaintenanceQueueActive(active bool) {
 	s.tsMaintenanceQueue.SetDisabled(!active)
 }
@@ -24,6 +24,12 @@ export const DROP_INDEX = "drop_index";
 export const CREATE_VIEW = "create_view";
 // Recorded when a view is dropped.
 export const DROP_VIEW = "drop_view";
+// Recorded when a sequence is created.
+export const CREATE_SEQUENCE = "create_sequence";
+// Recorded when a sequence is altered.
+export const ALTER_SEQUENCE = "alter_sequence";
+// Recorded when a sequence is dropped.
+export const DROP_SEQUENCE = "drop_sequence";
 // Recorded when an in-progress schema change encounters a problem and is
 // reversed.
 export const REVERSE_SCHEMA_CHANGE = "reverse_schema_change";
@@ -47,8 +53,8 @@ export const nodeEvents = [NODE_JOIN, NODE_RESTART, NODE_DECOMMISSIONED, NODE_RE
 export const databaseEvents = [CREATE_DATABASE, DROP_DATABASE];
 export const tableEvents = [
   CREATE_TABLE, DROP_TABLE, ALTER_TABLE, CREATE_INDEX,
-  DROP_INDEX, CREATE_VIEW, DROP_VIEW, REVERSE_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE,
-  FINISH_SCHEMA_CHANGE_ROLLBACK,
+  DROP_INDEX, CREATE_VIEW, DROP_VIEW, CREATE_SEQUENCE, ALTER_SEQUENCE, DROP_SEQUENCE,
+  REVERSE_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE_ROLLBACK,
 ];
 export const settingsEvents = [SET_CLUSTER_SETTING];
 export const allEvents = [...nodeEvents, ...databaseEvents, ...tableEvents, ...settingsEvents];
@@ -49,6 +49,8 @@ const typeOptions = [
   { value: jobType.RESTORE.toString(), label: "Restores" },
   { value: jobType.IMPORT.toString(), label: "Imports" },
   { value: jobType.SCHEMA_CHANGE.toString(), label: "Schema Changes" },
+  { value: jobType.REFRESH_MATERIALIZED_VIEW.toString(), label: "Materialized View Refreshes" },
+  { value: jobType.CREATE_STATS.toString(), label: "Statistics Creations" },
 ];
 
 const typeSetting = new LocalSetting<AdminUIState, number>(
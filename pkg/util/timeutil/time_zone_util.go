@@ -12,15 +12,13 @@
 // implied. See the License for the specific language governing
 // permissions and limitations under the License.
 
-package sqlbase
+package timeutil
 
 import (
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 const fixedOffsetPrefix string = "fixed offset:"
@@ -35,13 +33,26 @@ func FixedOffsetTimeZoneToLocation(offset int, origRepr string) *time.Location {
 
 // TimeZoneStringToLocation transforms a string into a time.Location. It
 // supports the usual locations and also time zones with fixed offsets created
-// by FixedOffsetTimeZoneToLocation().
+// by FixedOffsetTimeZoneToLocation(). Location names which aren't found as
+// given are also looked up in upper and title case, so that e.g. "utc"
+// resolves to "UTC".
 func TimeZoneStringToLocation(location string) (*time.Location, error) {
 	offset, origRepr, parsed := ParseFixedOffsetTimeZone(location)
 	if parsed {
 		return FixedOffsetTimeZoneToLocation(offset, origRepr), nil
 	}
-	return timeutil.LoadLocation(location)
+	loc, err := LoadLocation(location)
+	if err != nil {
+		var err1 error
+		if loc, err1 = LoadLocation(strings.ToUpper(location)); err1 == nil {
+			return loc, nil
+		}
+		if loc, err1 = LoadLocation(strings.ToTitle(location)); err1 == nil {
+			return loc, nil
+		}
+		return nil, err
+	}
+	return loc, nil
 }
 
 // ParseFixedOffsetTimeZone takes the string representation of a time.Location